@@ -5,7 +5,6 @@ import (
 	"sort"
 
 	aiturnplayer "github.com/domino14/macondo/ai/turnplayer"
-	"github.com/domino14/macondo/board"
 	"github.com/domino14/macondo/config"
 	"github.com/domino14/macondo/endgame/negamax"
 	"github.com/domino14/macondo/equity"
@@ -14,6 +13,7 @@ import (
 	"github.com/domino14/macondo/montecarlo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/openingbook"
 	"github.com/domino14/macondo/preendgame"
 	"github.com/domino14/macondo/rangefinder"
 	"github.com/domino14/macondo/turnplayer"
@@ -25,6 +25,16 @@ type BotConfig struct {
 	PEGAdjustmentFile string
 	LeavesFile        string
 	MinSimPlies       int
+	// OpeningBookFile is the name of the opening book file in the strategy
+	// directory. If empty, the default name is used. Only simming bots use
+	// the opening book, if it exists.
+	OpeningBookFile string
+	// Personality is the name of a personality in the personalities file
+	// for the lexicon. If set, it replaces the built-in personality for the
+	// bot level. Personalities only apply to bots that don't sim.
+	Personality string
+	// PhonyOptions, if set, overrides the personality's phony options.
+	PhonyOptions *pb.PhonyOptions
 	// If UseOppRacksInAnalysis is true, will use opponent rack info for simulation/pre-endgames/etc
 	UseOppRacksInAnalysis bool
 }
@@ -39,6 +49,14 @@ type BotTurnPlayer struct {
 	simThreads  int
 	minSimPlies int
 	cfg         *BotConfig
+	// lastSimmed is true if the last call to BestPlay ran a simulation.
+	lastSimmed bool
+	// book is nil if there is no opening book for this lexicon.
+	book *openingbook.Book
+	// personality is nil if the bot uses the built-in one for its level.
+	personality *Personality
+	// phonies is nil if the bot knows every word and never bluffs.
+	phonies *phonyModel
 
 	inferencer *rangefinder.RangeFinder
 }
@@ -95,13 +113,28 @@ func addBotFields(p *turnplayer.BaseTurnPlayer, conf *BotConfig, botType pb.BotR
 		cfg:                conf,
 	}
 
+	if conf.Personality != "" {
+		personality, err := LoadPersonality(&conf.Config, p.LexiconName(), conf.Personality)
+		if err != nil {
+			return nil, err
+		}
+		btp.personality = personality
+	}
+	var phonyConfig PhonyConfig
+	if personality := btp.Personality(); personality != nil {
+		phonyConfig = personality.PhonyConfig
+	}
+	if phonyConfig = phonyConfig.withOptions(conf.PhonyOptions); phonyConfig != (PhonyConfig{}) {
+		btp.phonies, err = newPhonyModel(&conf.Config, phonyConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// If it is a simming bot, add more fields.
 	if hasSimming(botType) {
 		log.Info().Msg("adding fields for simmer")
-		leaveFile := "" // use default
-		if p.Rules().BoardName() == board.SuperCrosswordGameLayout {
-			leaveFile = "super-leaves.klv2"
-		}
+		leaveFile := equity.LeavesFilenameFor(p.Rules().BoardName(), p.Rules().Variant())
 		c, err := equity.NewCombinedStaticCalculator(
 			p.LexiconName(), p.Config(), leaveFile, equity.PEGAdjustmentFilename)
 		if err != nil {
@@ -112,6 +145,12 @@ func addBotFields(p *turnplayer.BaseTurnPlayer, conf *BotConfig, botType pb.BotR
 		if conf.MinSimPlies > 0 {
 			btp.SetMinSimPlies(conf.MinSimPlies)
 		}
+		book, err := openingbook.Load(&conf.Config, p.LexiconName(), conf.OpeningBookFile)
+		if err != nil {
+			log.Debug().Err(err).Msg("no-opening-book")
+		} else {
+			btp.book = book
+		}
 	}
 	if HasEndgame(botType) {
 		log.Info().Msg("adding fields for endgame")
@@ -135,7 +174,8 @@ func (p *BotTurnPlayer) GenerateMoves(numPlays int) []*move.Move {
 	gen := p.MoveGenerator()
 	// in case we don't have full rack info:
 	unseen := int(oppRack.NumTiles()) + p.Bag().TilesRemaining()
-	exchAllowed := unseen-game.RackTileLimit >= game.ExchangeLimit
+	rs := p.Rules().RuleSet()
+	exchAllowed := unseen-rs.RackSize >= rs.ExchangeLimit
 
 	gen.GenAll(curRack, exchAllowed)
 
@@ -147,19 +187,33 @@ func (p *BotTurnPlayer) GenerateMoves(numPlays int) []*move.Move {
 		sort.Slice(plays, func(i, j int) bool {
 			return plays[i].Equity() > plays[j].Equity()
 		})
-		return []*move.Move{filter(p.Config(), p.Game, curRack, plays, p.botType)}
+		best := filter(p.Config(), p.Game, curRack, plays, p.Personality())
+		if bluff := p.bluff(best); bluff != nil {
+			return []*move.Move{bluff}
+		}
+		return []*move.Move{best}
 	}
 
 	return p.TopPlays(plays, numPlays)
 }
 
 func (p *BotTurnPlayer) BestPlay(ctx context.Context) (*move.Move, error) {
+	p.lastSimmed = false
 	if hasSimming(p.botType) || HasEndgame(p.botType) || HasInfer(p.botType) || HasPreendgame(p.botType) {
 		return eliteBestPlay(ctx, p)
 	}
 	return p.GenerateMoves(1)[0], nil
 }
 
+// LastSimmedPlays returns the simmed plays from the last call to BestPlay,
+// sorted by win probability. It returns nil if that call did not simulate.
+func (p *BotTurnPlayer) LastSimmedPlays() []*montecarlo.SimmedPlay {
+	if !p.lastSimmed {
+		return nil
+	}
+	return p.simmer.PlaysByWinProb()
+}
+
 func (p *BotTurnPlayer) SetEquityCalculators(calcs []equity.EquityCalculator) {
 	p.SetCalculators(calcs)
 }
@@ -168,6 +222,18 @@ func (p *BotTurnPlayer) GetBotType() pb.BotRequest_BotCode {
 	return p.botType
 }
 
+// Personality returns the personality the bot uses to pick its play, or
+// nil if it always picks the best play.
+func (p *BotTurnPlayer) Personality() *Personality {
+	if p.personality != nil {
+		return p.personality
+	}
+	if personality, ok := BotConfigs[p.botType]; ok {
+		return &personality
+	}
+	return nil
+}
+
 func (p *BotTurnPlayer) SetBotType(b pb.BotRequest_BotCode) {
 	p.botType = b
 }
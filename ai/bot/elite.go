@@ -17,6 +17,7 @@ import (
 	"github.com/domino14/macondo/montecarlo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/openingbook"
 )
 
 const InferencesSimLimit = 400
@@ -28,6 +29,10 @@ const InferencesSimLimit = 400
 // and some other smart things to figure it out.
 func eliteBestPlay(ctx context.Context, p *BotTurnPlayer) (*move.Move, error) {
 	logger := zerolog.Ctx(ctx)
+	if m := bookPlay(p); m != nil {
+		logger.Info().Str("move", m.ShortDescription()).Msg("book-play")
+		return m, nil
+	}
 	var moves []*move.Move
 	// First determine what stage of the game we are in.
 	tr := p.Game.Bag().TilesRemaining()
@@ -94,6 +99,22 @@ func eliteBestPlay(ctx context.Context, p *BotTurnPlayer) (*move.Move, error) {
 
 }
 
+// bookPlay returns a play from the opening book, or nil if the position
+// is not in the book.
+func bookPlay(p *BotTurnPlayer) *move.Move {
+	if p.book == nil {
+		return nil
+	}
+	plays := p.book.Lookup(p.Game)
+	if len(plays) == 0 {
+		return nil
+	}
+	choice := openingbook.Pick(plays, openingbook.DefaultTolerance, p.Game.Rand())
+	// The book play might not be legal, for example if the book was built
+	// with a different lexicon version.
+	return openingbook.FindMove(choice, p.GenerateMoves(math.MaxInt))
+}
+
 func endGameBest(ctx context.Context, p *BotTurnPlayer, endgamePlies int) (*move.Move, error) {
 	logger := zerolog.Ctx(ctx)
 
@@ -225,6 +246,7 @@ func nonEndgameBest(ctx context.Context, p *BotTurnPlayer, simPlies int, moves [
 	if err != nil {
 		return nil, err
 	}
+	p.lastSimmed = true
 	play := p.simmer.WinningPlay()
 	logger.Debug().Interface("winning-move", play.Move().String()).Msg("sim-done")
 	return play.Move(), nil
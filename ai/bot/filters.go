@@ -2,57 +2,134 @@ package bot
 
 import (
 	"math"
+	"sort"
 
 	"github.com/domino14/word-golib/kwg"
 	"github.com/domino14/word-golib/tilemapping"
 	"github.com/rs/zerolog/log"
 	"lukechampine.com/frand"
 
+	"github.com/domino14/macondo/board"
 	"github.com/domino14/macondo/config"
 	"github.com/domino14/macondo/game"
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 )
 
+// levelPersonality returns the personality for one of the built-in bot
+// levels, which only differ in how likely they are to find plays.
+func levelPersonality(base, longWord, parallel float64, cel bool) Personality {
+	p := defaultPersonality()
+	p.BaseFindability = base
+	p.LongWordFindability = longWord
+	p.ParallelFindability = parallel
+	p.CommonWordsOnly = cel
+	return p
+}
+
 // Note: because of the nature of this algorithm, the lower these numbers, the
 // more time the bot will take to find its move.
-var BotConfigs = map[pb.BotRequest_BotCode]struct {
-	baseFindability     float64
-	longWordFindability float64
-	parallelFindability float64
-	isCel               bool
-}{
-	pb.BotRequest_LEVEL1_CEL_BOT: {baseFindability: 0.3, longWordFindability: 0.1, parallelFindability: 0.3, isCel: true},
-	pb.BotRequest_LEVEL2_CEL_BOT: {baseFindability: 0.7, longWordFindability: 0.4, parallelFindability: 0.5, isCel: true},
-	pb.BotRequest_LEVEL3_CEL_BOT: {baseFindability: 0.8, longWordFindability: 0.5, parallelFindability: 0.75, isCel: true},
-	pb.BotRequest_LEVEL4_CEL_BOT: {baseFindability: 1.0, longWordFindability: 1.0, parallelFindability: 1.0, isCel: true},
-
-	pb.BotRequest_LEVEL1_PROBABILISTIC: {baseFindability: 0.2, longWordFindability: 0.07, parallelFindability: 0.15, isCel: false},
-	pb.BotRequest_LEVEL2_PROBABILISTIC: {baseFindability: 0.4, longWordFindability: 0.2, parallelFindability: 0.3, isCel: false},
-	pb.BotRequest_LEVEL3_PROBABILISTIC: {baseFindability: 0.55, longWordFindability: 0.35, parallelFindability: 0.45, isCel: false},
-	pb.BotRequest_LEVEL4_PROBABILISTIC: {baseFindability: 0.85, longWordFindability: 0.45, parallelFindability: 0.85, isCel: false},
-	pb.BotRequest_LEVEL5_PROBABILISTIC: {baseFindability: 0.9, longWordFindability: 0.8, parallelFindability: 0.85, isCel: false},
+var BotConfigs = map[pb.BotRequest_BotCode]Personality{
+	pb.BotRequest_LEVEL1_CEL_BOT: levelPersonality(0.3, 0.1, 0.3, true),
+	pb.BotRequest_LEVEL2_CEL_BOT: levelPersonality(0.7, 0.4, 0.5, true),
+	pb.BotRequest_LEVEL3_CEL_BOT: levelPersonality(0.8, 0.5, 0.75, true),
+	pb.BotRequest_LEVEL4_CEL_BOT: levelPersonality(1.0, 1.0, 1.0, true),
+
+	pb.BotRequest_LEVEL1_PROBABILISTIC: levelPersonality(0.2, 0.07, 0.15, false),
+	pb.BotRequest_LEVEL2_PROBABILISTIC: levelPersonality(0.4, 0.2, 0.3, false),
+	pb.BotRequest_LEVEL3_PROBABILISTIC: levelPersonality(0.55, 0.35, 0.45, false),
+	pb.BotRequest_LEVEL4_PROBABILISTIC: levelPersonality(0.85, 0.45, 0.85, false),
+	pb.BotRequest_LEVEL5_PROBABILISTIC: levelPersonality(0.9, 0.8, 0.85, false),
+}
+
+// adjustForPersonality returns the plays sorted by their equity after
+// the personality's exchange and risk adjustments. The plays themselves
+// are not modified.
+func adjustForPersonality(g *game.Game, plays []*move.Move, personality *Personality) []*move.Move {
+	if personality.ExchangeBonus == 0 && personality.RiskAppetite == 0 {
+		return plays
+	}
+	spread := g.SpreadFor(g.PlayerOnTurn())
+	adjusted := make(map[*move.Move]float64, len(plays))
+	for _, play := range plays {
+		eq := play.Equity()
+		switch play.Action() {
+		case move.MoveTypeExchange:
+			eq += personality.ExchangeBonus
+		case move.MoveTypePlay:
+			eq += personality.riskAdjustment(play.TilesPlayed(), spread)
+		}
+		adjusted[play] = eq
+	}
+	sorted := make([]*move.Move, len(plays))
+	copy(sorted, plays)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return adjusted[sorted[i]] > adjusted[sorted[j]]
+	})
+	return sorted
+}
+
+// hooks returns the number of words already on the board that the play
+// hooks, that is, extends by a single tile at their front or back.
+func hooks(b *board.GameBoard, play *move.Move) int {
+	row, col, vertical := play.CoordsAndVertical()
+	ri, ci := 0, 1
+	if vertical {
+		ri, ci = ci, ri
+	}
+	// Walk in the cross direction.
+	cri, cci := ci, ri
+	count := func(r, c, dr, dc int) int {
+		n := 0
+		for r, c = r+dr, c+dc; b.PosExists(r, c) && b.HasLetter(r, c); r, c = r+dr, c+dc {
+			n++
+		}
+		return n
+	}
+	n := 0
+	for idx, t := range play.Tiles() {
+		if t == 0 {
+			continue
+		}
+		r, c := row+ri*idx, col+ci*idx
+		before := count(r, c, -cri, -cci)
+		after := count(r, c, cri, cci)
+		if (before >= 2 && after == 0) || (after >= 2 && before == 0) {
+			n++
+		}
+	}
+	return n
+}
+
+// randFloat64 uses the game's generator, if the game is seeded.
+func randFloat64(g *game.Game) float64 {
+	if rng := g.Rand(); rng != nil {
+		return rng.Float64()
+	}
+	return frand.Float64()
 }
 
-func filter(cfg *config.Config, g *game.Game, rack *tilemapping.Rack, plays []*move.Move, botType pb.BotRequest_BotCode) *move.Move {
+func filter(cfg *config.Config, g *game.Game, rack *tilemapping.Rack, plays []*move.Move,
+	personality *Personality) *move.Move {
+
 	passMove := move.NewPassMove(rack.TilesOn(), g.Alphabet())
-	botConfig, botConfigExists := BotConfigs[botType]
-	if !botConfigExists {
+	if personality == nil {
 		if len(plays) > 0 {
 			return plays[0]
 		}
 		return passMove
 	}
+	plays = adjustForPersonality(g, plays, personality)
 
-	filterFunction := func([]tilemapping.MachineWord, float64) (bool, error) { return true, nil }
-	if botConfig.isCel {
+	filterFunction := func(*move.Move, []tilemapping.MachineWord, float64) (bool, error) { return true, nil }
+	if personality.CommonWordsOnly {
 		gd, err := kwg.Get(cfg.AllSettings(), "ECWL")
 		if err != nil {
 			log.Err(err).Msg("could-not-load-ecwl")
-			filterFunction = func([]tilemapping.MachineWord, float64) (bool, error) { return false, err }
+			filterFunction = func(*move.Move, []tilemapping.MachineWord, float64) (bool, error) { return false, err }
 		} else {
 			lex := kwg.Lexicon{KWG: *gd}
-			filterFunction = func(mws []tilemapping.MachineWord, r float64) (bool, error) {
+			filterFunction = func(_ *move.Move, mws []tilemapping.MachineWord, r float64) (bool, error) {
 				err = g.ValidateWords(lex, mws)
 				if err != nil {
 					// validation error means at least one word is phony.
@@ -63,23 +140,48 @@ func filter(cfg *config.Config, g *game.Game, rack *tilemapping.Rack, plays []*m
 		}
 	}
 
-	// LEVEL4_CEL_BOT is an unfiltered CEL bot
-	if botType != pb.BotRequest_LEVEL4_CEL_BOT {
+	if personality.vocabulary != nil {
+		challengeRule := pb.ChallengeRule_VOID
+		if g.History() != nil {
+			challengeRule = g.History().ChallengeRule
+		}
+		phonyTendency := personality.phonyTendency(challengeRule)
+		filterFunctionPrev := filterFunction
+		filterFunction = func(play *move.Move, mws []tilemapping.MachineWord, r float64) (bool, error) {
+			allowed, err := filterFunctionPrev(play, mws, r)
+			if !allowed || err != nil {
+				return allowed, err
+			}
+			for _, mw := range mws {
+				if !personality.knows(mw.UserVisible(g.Alphabet())) {
+					// The bot thinks this word might be phony; it only
+					// plays it if it is willing to take the risk.
+					return randFloat64(g) < phonyTendency, nil
+				}
+			}
+			return true, nil
+		}
+	}
+
+	if !personality.unfiltered() {
 		dist := g.Bag().LetterDistribution()
 		// XXX: This should be cached
 		subChooseCombos := createSubCombos(dist)
 		filterFunctionPrev := filterFunction
-		filterFunction = func(mws []tilemapping.MachineWord, r float64) (bool, error) {
-			allowed, err := filterFunctionPrev(mws, r)
+		filterFunction = func(play *move.Move, mws []tilemapping.MachineWord, r float64) (bool, error) {
+			allowed, err := filterFunctionPrev(play, mws, r)
 			if !allowed || err != nil {
 				return allowed, err
 			}
-			ans := botConfig.baseFindability * math.Pow(botConfig.parallelFindability, float64(len(mws)-1))
+			ans := personality.BaseFindability * math.Pow(personality.ParallelFindability, float64(len(mws)-1))
+			if personality.HookFindability != 1 {
+				ans *= math.Pow(personality.HookFindability, float64(hooks(g.Board(), play)))
+			}
 
 			mw := mws[0] // assume len > 0
 			// Check for long words (7 or more letters)
 			if len(mw) >= game.ExchangeLimit {
-				ans *= probableFindability(len(mw), combinations(dist, subChooseCombos, mw, true)) * botConfig.longWordFindability
+				ans *= probableFindability(len(mw), combinations(dist, subChooseCombos, mw, true)) * personality.LongWordFindability
 			}
 			log.Debug().Float64("ans", ans).Float64("r", r).Msg("checking-answer")
 			return r < ans, nil
@@ -90,7 +192,7 @@ func filter(cfg *config.Config, g *game.Game, rack *tilemapping.Rack, plays []*m
 	for _, play := range plays {
 		var err error
 		allowed := true
-		r := frand.Float64()
+		r := randFloat64(g)
 
 		if play.Action() == move.MoveTypePlay {
 			mws, err = g.Board().FormedWords(play)
@@ -98,13 +200,13 @@ func filter(cfg *config.Config, g *game.Game, rack *tilemapping.Rack, plays []*m
 				log.Err(err).Msg("formed-words-filter-error")
 				break
 			}
-			allowed, err = filterFunction(mws, r)
+			allowed, err = filterFunction(play, mws, r)
 			if err != nil {
 				log.Err(err).Msg("bot-type-move-filter-internal-error")
 				break
 			}
 		} else if play.Action() == move.MoveTypeExchange {
-			if r >= botConfig.baseFindability {
+			if r >= personality.BaseFindability {
 				allowed = false
 			}
 		}
@@ -0,0 +1,266 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/domino14/word-golib/cache"
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/config"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+const PersonalitiesFilename = "personalities.json"
+
+// Personality describes how a bot that is meant to play like a human
+// picks its move. Plays are considered from best to worst (by equity,
+// after the adjustments below), and the bot picks the first one that
+// it "finds".
+type Personality struct {
+	// The chance of finding a play is BaseFindability, times
+	// ParallelFindability for every extra word formed, times
+	// HookFindability for every hook that the play needs. Long words
+	// (7 letters or more) are also less likely to be found, depending on
+	// LongWordFindability and on how many ways there are to draw them.
+	BaseFindability     float64 `json:"base_findability"`
+	LongWordFindability float64 `json:"long_word_findability"`
+	ParallelFindability float64 `json:"parallel_findability"`
+	HookFindability     float64 `json:"hook_findability"`
+
+	// CommonWordsOnly limits the bot to words in the common-word lexicon.
+	CommonWordsOnly bool `json:"common_words_only"`
+
+	// VocabularyFile is a list of words, one per line, with the most
+	// common words first. It is looked for in the strategy directory for
+	// the lexicon. The bot only knows the first VocabularySize words of the
+	// list, and every word of KnownWordLength letters or fewer.
+	VocabularyFile  string `json:"vocabulary_file"`
+	VocabularySize  int    `json:"vocabulary_size"`
+	KnownWordLength int    `json:"known_word_length"`
+	// PhonyTendency is the chance that the bot plays a word it does not
+	// know (and so might think is phony), keyed by challenge rule, such as
+	// "DOUBLE" or "FIVE_POINT". Missing rules default to 0. It only applies
+	// if the personality has a vocabulary.
+	PhonyTendency map[string]float64 `json:"phony_tendency"`
+
+	// ExchangeBonus is added to the equity of every exchange. Use a
+	// negative value for a bot that avoids exchanging.
+	ExchangeBonus float64 `json:"exchange_bonus"`
+	// RiskAppetite is the equity added per tile played when the bot is
+	// trailing by RiskSpread points or more; it scales down to zero when
+	// the game is tied, and turns into a penalty when the bot is ahead.
+	// A positive value makes the bot turn over more tiles when behind and
+	// play it safer when ahead.
+	RiskAppetite float64 `json:"risk_appetite"`
+	RiskSpread   float64 `json:"risk_spread"`
+
+	// PhonyConfig is how the bot challenges, and whether it bluffs.
+	PhonyConfig
+
+	vocabulary map[string]bool
+}
+
+// defaultPersonality is a bot that finds every play.
+func defaultPersonality() Personality {
+	return Personality{
+		BaseFindability:     1,
+		LongWordFindability: 1,
+		ParallelFindability: 1,
+		HookFindability:     1,
+		RiskSpread:          100,
+	}
+}
+
+// unfiltered returns true if the personality finds every play it knows.
+func (p *Personality) unfiltered() bool {
+	return p.BaseFindability == 1 && p.LongWordFindability == 1 &&
+		p.ParallelFindability == 1 && p.HookFindability == 1
+}
+
+// rawPersonality is a personality as written in the config file. Base is
+// the name of a built-in bot level (such as LEVEL3_PROBABILISTIC) or of
+// another personality in the file, that this one starts from.
+type rawPersonality struct {
+	Base string `json:"base"`
+}
+
+// ReadPersonalities reads personalities in JSON format: an object with one
+// entry per personality name.
+func ReadPersonalities(r io.Reader) (map[string]*Personality, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	ps := map[string]*Personality{}
+	var resolve func(name string, seen map[string]bool) (*Personality, error)
+	resolve = func(name string, seen map[string]bool) (*Personality, error) {
+		if p, ok := ps[name]; ok {
+			return p, nil
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("personality %v has a circular base", name)
+		}
+		seen[name] = true
+		bts, ok := raw[name]
+		if !ok {
+			if code, ok := pb.BotRequest_BotCode_value[name]; ok {
+				if p, ok := BotConfigs[pb.BotRequest_BotCode(code)]; ok {
+					return &p, nil
+				}
+			}
+			return nil, fmt.Errorf("personality %v not found", name)
+		}
+		base := rawPersonality{}
+		if err := json.Unmarshal(bts, &base); err != nil {
+			return nil, fmt.Errorf("personality %v: %w", name, err)
+		}
+		p := defaultPersonality()
+		if base.Base != "" {
+			bp, err := resolve(base.Base, seen)
+			if err != nil {
+				return nil, err
+			}
+			p = *bp
+			p.PhonyTendency = nil
+		}
+		if err := json.Unmarshal(bts, &p); err != nil {
+			return nil, fmt.Errorf("personality %v: %w", name, err)
+		}
+		for rule := range p.PhonyTendency {
+			if _, ok := pb.ChallengeRule_value[rule]; !ok {
+				return nil, fmt.Errorf("personality %v: challenge rule %v not recognized", name, rule)
+			}
+		}
+		ps[name] = &p
+		return &p, nil
+	}
+	for name := range raw {
+		if _, err := resolve(name, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	return ps, nil
+}
+
+// LoadPersonality loads the personality with the given name from the
+// personalities file for the lexicon (or the default one), along with its
+// vocabulary, if any.
+func LoadPersonality(cfg *config.Config, lexiconName, name string) (*Personality, error) {
+	obj, err := cache.Load(cfg.AllSettings(), "personalities:"+lexiconName+":"+PersonalitiesFilename,
+		PersonalitiesCacheLoadFunc)
+	if err != nil {
+		return nil, err
+	}
+	ps, ok := obj.(map[string]*Personality)
+	if !ok {
+		return nil, errors.New("personalities not correct type")
+	}
+	p, ok := ps[name]
+	if !ok {
+		return nil, fmt.Errorf("personality %v not found", name)
+	}
+	if p.VocabularyFile == "" || p.VocabularySize <= 0 {
+		return p, nil
+	}
+	// Don't modify the cached personality.
+	pcopy := *p
+	vocab, err := cache.Load(cfg.AllSettings(),
+		"vocabulary:"+lexiconName+":"+p.VocabularyFile+":"+strconv.Itoa(p.VocabularySize),
+		VocabularyCacheLoadFunc)
+	if err != nil {
+		return nil, err
+	}
+	pcopy.vocabulary, ok = vocab.(map[string]bool)
+	if !ok {
+		return nil, errors.New("vocabulary not correct type")
+	}
+	return &pcopy, nil
+}
+
+func strategyPath(cfg map[string]any, lexiconName, filename string) string {
+	return filepath.Join(cfg[config.ConfigDataPath].(string), "strategy", lexiconName, filename)
+}
+
+func PersonalitiesCacheLoadFunc(cfg map[string]any, key string) (interface{}, error) {
+	// Key looks like personalities:lexicon:filename
+	fields := strings.Split(key, ":")
+	if fields[0] != "personalities" {
+		return nil, errors.New("personalitiescacheloadfunc - bad cache key: " + key)
+	}
+	if len(fields) != 3 {
+		return nil, errors.New("cache key missing fields")
+	}
+	f, _, err := cache.Open(strategyPath(cfg, fields[1], fields[2]))
+	if err != nil {
+		log.Debug().Str("lexicon", fields[1]).Msg("no lexicon-specific personalities")
+		f, _, err = cache.Open(strategyPath(cfg, "default", fields[2]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer f.Close()
+	return ReadPersonalities(f)
+}
+
+func VocabularyCacheLoadFunc(cfg map[string]any, key string) (interface{}, error) {
+	// Key looks like vocabulary:lexicon:filename:size
+	fields := strings.Split(key, ":")
+	if fields[0] != "vocabulary" {
+		return nil, errors.New("vocabularycacheloadfunc - bad cache key: " + key)
+	}
+	if len(fields) != 4 {
+		return nil, errors.New("cache key missing fields")
+	}
+	size, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	f, _, err := cache.Open(strategyPath(cfg, fields[1], fields[2]))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vocab := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() && len(vocab) < size {
+		// Allow for other columns, such as the frequency itself.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		vocab[strings.ToUpper(fields[0])] = true
+	}
+	return vocab, scanner.Err()
+}
+
+// knows returns whether the word is in the personality's vocabulary.
+func (p *Personality) knows(word string) bool {
+	if p.vocabulary == nil {
+		return true
+	}
+	return len([]rune(word)) <= p.KnownWordLength || p.vocabulary[word]
+}
+
+// phonyTendency returns the chance of playing an unknown word under
+// the given challenge rule.
+func (p *Personality) phonyTendency(rule pb.ChallengeRule) float64 {
+	return p.PhonyTendency[rule.String()]
+}
+
+// riskAdjustment returns the equity adjustment for a play that plays the
+// given number of tiles, when the bot is ahead by spread points.
+func (p *Personality) riskAdjustment(tilesPlayed, spread int) float64 {
+	if p.RiskAppetite == 0 || p.RiskSpread <= 0 {
+		return 0
+	}
+	trailing := math.Max(-1, math.Min(1, -float64(spread)/p.RiskSpread))
+	return p.RiskAppetite * trailing * float64(tilesPlayed)
+}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/cgp"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+// CAT at 8H.
+const catCGP = "15/15/15/15/15/15/15/7CAT5/15/15/15/15/15/15/15 ABORSTV/ 0/0 0 lex NWL20;"
+
+func catGame(t *testing.T) *game.Game {
+	is := is.New(t)
+	g, err := cgp.ParseCGP(&DefaultConfig, catCGP)
+	is.NoErr(err)
+	g.RecalculateBoard()
+	return g.Game
+}
+
+func TestReadPersonalities(t *testing.T) {
+	is := is.New(t)
+	ps, err := ReadPersonalities(strings.NewReader(`{
+		"risky": {"base": "careful", "risk_appetite": 2, "phony_tendency": {"DOUBLE": 0.5}},
+		"careful": {"base": "LEVEL3_PROBABILISTIC", "exchange_bonus": -5,
+			"phony_tendency": {"VOID": 0.1}},
+		"plain": {"hook_findability": 0.5}
+	}`))
+	is.NoErr(err)
+	is.Equal(len(ps), 3)
+
+	careful := ps["careful"]
+	is.Equal(careful.BaseFindability, 0.55)
+	is.Equal(careful.ExchangeBonus, -5.0)
+	is.Equal(careful.HookFindability, 1.0)
+
+	risky := ps["risky"]
+	is.Equal(risky.BaseFindability, 0.55)
+	is.Equal(risky.ExchangeBonus, -5.0)
+	is.Equal(risky.RiskAppetite, 2.0)
+	// Phony tendencies are not inherited.
+	is.Equal(risky.phonyTendency(pb.ChallengeRule_VOID), 0.0)
+	is.Equal(risky.phonyTendency(pb.ChallengeRule_DOUBLE), 0.5)
+
+	plain := ps["plain"]
+	is.Equal(plain.BaseFindability, 1.0)
+	is.Equal(plain.HookFindability, 0.5)
+	is.True(!plain.unfiltered())
+}
+
+func TestReadPersonalitiesErrors(t *testing.T) {
+	is := is.New(t)
+	_, err := ReadPersonalities(strings.NewReader(`{"a": {"base": "b"}, "b": {"base": "a"}}`))
+	is.True(err != nil)
+	_, err = ReadPersonalities(strings.NewReader(`{"a": {"base": "LEVEL9_PROBABILISTIC"}}`))
+	is.True(err != nil)
+	_, err = ReadPersonalities(strings.NewReader(`{"a": {"phony_tendency": {"QUADRUPLE": 1}}}`))
+	is.True(err != nil)
+}
+
+func TestLoadDefaultPersonalities(t *testing.T) {
+	is := is.New(t)
+	p, err := LoadPersonality(&DefaultConfig, "NWL20", "exchanger")
+	is.NoErr(err)
+	is.Equal(p.BaseFindability, 0.85)
+	is.Equal(p.ExchangeBonus, 5.0)
+	_, err = LoadPersonality(&DefaultConfig, "NWL20", "nonexistent")
+	is.True(err != nil)
+}
+
+func TestRiskAdjustment(t *testing.T) {
+	is := is.New(t)
+	p := defaultPersonality()
+	p.RiskAppetite = 2
+	is.Equal(p.riskAdjustment(5, 0), 0.0)
+	is.Equal(p.riskAdjustment(5, -50), 5.0)
+	is.Equal(p.riskAdjustment(5, -300), 10.0)
+	is.Equal(p.riskAdjustment(5, 300), -10.0)
+}
+
+func TestHooks(t *testing.T) {
+	is := is.New(t)
+	g := catGame(t)
+	// CATS
+	m, err := g.CreateAndScorePlacementMove("K5", "ROBS", "ABORSTV")
+	is.NoErr(err)
+	is.Equal(hooks(g.Board(), m), 1)
+	// Parallel, but no hooks.
+	m, err = g.CreateAndScorePlacementMove("9H", "AT", "ABORSTV")
+	is.NoErr(err)
+	is.Equal(hooks(g.Board(), m), 0)
+}
+
+func TestFilterExchangeBonus(t *testing.T) {
+	is := is.New(t)
+	g := catGame(t)
+	alph := g.Alphabet()
+	play, err := g.CreateAndScorePlacementMove("9H", "AT", "ABORSTV")
+	is.NoErr(err)
+	play.SetEquity(10)
+	exch := move.NewExchangeMove(tilemapping.MachineWord{22}, tilemapping.MachineWord{1, 2, 15, 18, 19, 20}, alph)
+	exch.SetEquity(8)
+	rack := g.RackFor(0)
+
+	p := defaultPersonality()
+	is.Equal(filter(&DefaultConfig, g, rack, []*move.Move{play, exch}, &p), play)
+	p.ExchangeBonus = 3
+	is.Equal(filter(&DefaultConfig, g, rack, []*move.Move{play, exch}, &p), exch)
+}
+
+func TestFilterVocabulary(t *testing.T) {
+	is := is.New(t)
+	g := catGame(t)
+	rack := g.RackFor(0)
+	// Forms ROBS and CATS.
+	robs, err := g.CreateAndScorePlacementMove("K5", "ROBS", "ABORSTV")
+	is.NoErr(err)
+	// Forms AT, CA, and AT.
+	at, err := g.CreateAndScorePlacementMove("9H", "AT", "ABORSTV")
+	is.NoErr(err)
+	plays := []*move.Move{robs, at}
+
+	p := defaultPersonality()
+	p.vocabulary = map[string]bool{"CATS": true}
+	p.KnownWordLength = 2
+	is.Equal(filter(&DefaultConfig, g, rack, plays, &p), at)
+	p.vocabulary["ROBS"] = true
+	is.Equal(filter(&DefaultConfig, g, rack, plays, &p), robs)
+
+	delete(p.vocabulary, "ROBS")
+	p.PhonyTendency = map[string]float64{"DOUBLE": 1}
+	g.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	is.Equal(filter(&DefaultConfig, g, rack, plays, &p), robs)
+	g.SetChallengeRule(pb.ChallengeRule_VOID)
+	is.Equal(filter(&DefaultConfig, g, rack, plays, &p), at)
+}
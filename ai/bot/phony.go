@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"math"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/config"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+)
+
+// PhonyConfig configures how a bot deals with phonies: when it challenges
+// its opponent's plays, and whether it plays phonies itself.
+type PhonyConfig struct {
+	// KnownWordsLexicon is the name of a lexicon with the words that the
+	// bot knows, usually a subset of the game's lexicon. If it is empty,
+	// the bot knows every word, and challenges exactly the phonies.
+	KnownWordsLexicon string `json:"known_words_lexicon"`
+	// UnknownWordPhonyProb is the chance that a word the bot does not know
+	// is phony.
+	UnknownWordPhonyProb float64 `json:"unknown_word_phony_prob"`
+	// BluffLexicon is the name of a lexicon to take phonies from, usually a
+	// bigger one than the game's. If it is empty, the bot never plays
+	// phonies on purpose.
+	BluffLexicon string `json:"bluff_lexicon"`
+	// OpponentChallengeProb is the chance that the opponent challenges a
+	// phony.
+	OpponentChallengeProb float64 `json:"opponent_challenge_prob"`
+}
+
+// withOptions returns the config with the options that are set in opts
+// replacing its own.
+func (c PhonyConfig) withOptions(opts *pb.PhonyOptions) PhonyConfig {
+	if opts == nil {
+		return c
+	}
+	if opts.KnownWordsLexicon != "" {
+		c.KnownWordsLexicon = opts.KnownWordsLexicon
+	}
+	if opts.UnknownWordPhonyProb != 0 {
+		c.UnknownWordPhonyProb = opts.UnknownWordPhonyProb
+	}
+	if opts.BluffLexicon != "" {
+		c.BluffLexicon = opts.BluffLexicon
+	}
+	if opts.OpponentChallengeProb != 0 {
+		c.OpponentChallengeProb = opts.OpponentChallengeProb
+	}
+	return c
+}
+
+// phonyModel is a PhonyConfig with its lexica loaded.
+type phonyModel struct {
+	PhonyConfig
+	// known is nil if the bot knows every word.
+	known *kwg.KWG
+	// bluff is nil if the bot does not bluff.
+	bluff *kwg.KWG
+}
+
+func newPhonyModel(cfg *config.Config, pc PhonyConfig) (*phonyModel, error) {
+	pm := &phonyModel{PhonyConfig: pc}
+	var err error
+	if pc.KnownWordsLexicon != "" {
+		if pm.known, err = kwg.Get(cfg.AllSettings(), pc.KnownWordsLexicon); err != nil {
+			return nil, err
+		}
+	}
+	if pc.BluffLexicon != "" {
+		if pm.bluff, err = kwg.Get(cfg.AllSettings(), pc.BluffLexicon); err != nil {
+			return nil, err
+		}
+	}
+	return pm, nil
+}
+
+// PhonyProbability returns the chance, as far as the bot can tell, that the
+// last play is phony. A bot that knows every word returns either 0 or 1.
+func (p *BotTurnPlayer) PhonyProbability() float64 {
+	words := p.LastWordsFormed()
+	if len(words) == 0 {
+		return 0
+	}
+	validProb := 1.0
+	for _, w := range words {
+		mw := []tilemapping.MachineWord{w}
+		if p.phonies == nil || p.phonies.known == nil {
+			if p.ValidateWords(p.Lexicon(), mw) != nil {
+				return 1
+			}
+		} else if p.ValidateWords(kwg.Lexicon{KWG: *p.phonies.known}, mw) != nil {
+			// The bot does not know this word.
+			validProb *= 1 - p.phonies.UnknownWordPhonyProb
+		}
+	}
+	return 1 - validProb
+}
+
+// ShouldChallenge returns whether the bot should challenge its opponent's
+// last play. It weighs the chance that the play is phony against what a
+// wrong challenge costs under the game's challenge rule.
+func (p *BotTurnPlayer) ShouldChallenge() bool {
+	last := p.LastEvent()
+	if last == nil || last.Type != pb.GameEvent_TILE_PLACEMENT_MOVE {
+		return false
+	}
+	rule := p.History().ChallengeRule
+	if rule == pb.ChallengeRule_VOID {
+		return false
+	}
+	prob := p.PhonyProbability()
+	if prob == 0 {
+		return false
+	}
+	if prob == 1 {
+		return true
+	}
+	// What a successful challenge takes away from the opponent.
+	gain := float64(last.Score)
+	var cost float64
+	switch rule {
+	case pb.ChallengeRule_FIVE_POINT:
+		cost = 5
+	case pb.ChallengeRule_TEN_POINT:
+		cost = 10
+	case pb.ChallengeRule_DOUBLE:
+		// We lose our turn.
+		cost = math.Max(0, p.bestStaticEquity())
+	case pb.ChallengeRule_TRIPLE:
+		// Someone loses the game either way.
+		return prob > 0.5
+	}
+	log.Debug().Float64("prob", prob).Float64("gain", gain).Float64("cost", cost).
+		Msg("challenge-decision")
+	return prob*gain >= (1-prob)*cost
+}
+
+func (p *BotTurnPlayer) bestStaticEquity() float64 {
+	curRack := p.RackFor(p.PlayerOnTurn())
+	oppRack := p.RackFor(p.NextPlayer())
+	gen := p.MoveGenerator()
+	gen.GenAll(curRack, false)
+	plays := gen.(*movegen.GordonGenerator).Plays()
+	p.AssignEquity(plays, p.Board(), p.Bag(), oppRack)
+	best := math.Inf(-1)
+	for _, m := range plays {
+		best = math.Max(best, m.Equity())
+	}
+	return best
+}
+
+// bluff returns a phony that the bot expects to do better than the given
+// play, or nil if there is none. A phony that gets challenged off is
+// valued as a pass.
+func (p *BotTurnPlayer) bluff(best *move.Move) *move.Move {
+	if p.phonies == nil || p.phonies.bluff == nil {
+		return nil
+	}
+	switch p.History().ChallengeRule {
+	case pb.ChallengeRule_VOID, pb.ChallengeRule_TRIPLE:
+		return nil
+	}
+	// Note that cross-checks come from the game's lexicon, so only the
+	// main word of a bluff can be phony.
+	gen := movegen.NewGordonGenerator(p.phonies.bluff, p.Board(), p.Bag().LetterDistribution())
+	gen.SetRuleSet(p.Rules().RuleSet())
+	gen.SetVariant(p.Rules().Variant())
+	curRack := p.RackFor(p.PlayerOnTurn())
+	gen.GenAll(curRack, false)
+	var phonies []*move.Move
+	for _, m := range gen.Plays() {
+		if m.Action() != move.MoveTypePlay {
+			continue
+		}
+		words, err := p.Board().FormedWords(m)
+		if err != nil || p.ValidateWords(p.Lexicon(), words) == nil {
+			continue
+		}
+		phonies = append(phonies, m)
+	}
+	if len(phonies) == 0 {
+		return nil
+	}
+	p.AssignEquity(phonies, p.Board(), p.Bag(), p.RackFor(p.NextPlayer()))
+	unchallenged := 1 - p.phonies.OpponentChallengeProb
+	var bluff *move.Move
+	bestEquity := best.Equity()
+	for _, m := range phonies {
+		if eq := unchallenged * m.Equity(); eq > bestEquity {
+			bluff, bestEquity = m, eq
+		}
+	}
+	if bluff != nil {
+		log.Debug().Str("bluff", bluff.ShortDescription()).Float64("expected", bestEquity).
+			Str("instead-of", best.ShortDescription()).Msg("bluffing")
+	}
+	return bluff
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+// afterPlay plays the given move for the first player of a CAT game, and
+// returns a bot for the second player.
+func afterPlay(t *testing.T, rule pb.ChallengeRule, coords, word string, opts *pb.PhonyOptions) *BotTurnPlayer {
+	is := is.New(t)
+	g := catGame(t)
+	g.SetChallengeRule(rule)
+	m, err := g.CreateAndScorePlacementMove(coords, word, "ABORSTV")
+	is.NoErr(err)
+	is.NoErr(g.PlayMove(m, true, 0))
+	is.NoErr(g.SetRackFor(1, tilemapping.RackFromString("EEIILNU", g.Alphabet())))
+	p, err := NewBotTurnPlayerFromGame(g, &BotConfig{Config: DefaultConfig, PhonyOptions: opts},
+		pb.BotRequest_HASTY_BOT)
+	is.NoErr(err)
+	return p
+}
+
+func TestChallengeKnowsEveryWord(t *testing.T) {
+	is := is.New(t)
+	// TO
+	p := afterPlay(t, pb.ChallengeRule_DOUBLE, "J8", ".O", nil)
+	is.Equal(p.PhonyProbability(), 0.0)
+	is.True(!p.ShouldChallenge())
+	// VS
+	p = afterPlay(t, pb.ChallengeRule_DOUBLE, "K7", "VS", nil)
+	is.Equal(p.PhonyProbability(), 1.0)
+	is.True(p.ShouldChallenge())
+	// Challenges are not allowed (and neither are phonies).
+	p = afterPlay(t, pb.ChallengeRule_VOID, "J8", ".O", nil)
+	is.True(!p.ShouldChallenge())
+}
+
+func TestChallengeUnknownWords(t *testing.T) {
+	is := is.New(t)
+	opts := &pb.PhonyOptions{KnownWordsLexicon: "NWL20", UnknownWordPhonyProb: 0.3}
+	// The bot knows TO.
+	p := afterPlay(t, pb.ChallengeRule_DOUBLE, "J8", ".O", opts)
+	is.Equal(p.PhonyProbability(), 0.0)
+	// The bot does not know VS, as it is phony.
+	p = afterPlay(t, pb.ChallengeRule_SINGLE, "K7", "VS", opts)
+	prob := p.PhonyProbability()
+	is.True(prob >= 0.3 && prob < 1)
+	// Free to challenge.
+	is.True(p.ShouldChallenge())
+
+	p = afterPlay(t, pb.ChallengeRule_TRIPLE, "K7", "VS", opts)
+	is.Equal(p.ShouldChallenge(), prob > 0.5)
+
+	// A wrong challenge costs more than the play is worth.
+	p = afterPlay(t, pb.ChallengeRule_TEN_POINT, "K7", "VS", opts)
+	last := p.LastEvent()
+	is.Equal(p.ShouldChallenge(), prob*float64(last.Score) >= (1-prob)*10)
+}
+
+func TestNoBluffsWithoutChallenges(t *testing.T) {
+	is := is.New(t)
+	opts := &pb.PhonyOptions{BluffLexicon: "CSW21"}
+	for _, rule := range []pb.ChallengeRule{pb.ChallengeRule_VOID, pb.ChallengeRule_TRIPLE} {
+		g := catGame(t)
+		g.SetChallengeRule(rule)
+		p, err := NewBotTurnPlayerFromGame(g, &BotConfig{Config: DefaultConfig, PhonyOptions: opts},
+			pb.BotRequest_HASTY_BOT)
+		is.NoErr(err)
+		is.True(p.bluff(p.GenerateMoves(1)[0]) == nil)
+	}
+}
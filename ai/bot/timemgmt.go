@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/domino14/macondo/game"
+)
+
+// timeReserve is kept on the clock for the rest of the game, so that the
+// bot does not go over time.
+const timeReserve = 5 * time.Second
+
+// tilesPerTurn is about how many tiles the bot plays every turn.
+const tilesPerTurn = 4.25
+
+// TimeForMove returns how long the player on turn should take for their
+// move, given how much time they have left for the rest of the game. It is
+// never more than limit.
+func TimeForMove(g *game.Game, millisRemaining int, limit time.Duration) time.Duration {
+	// We only play about half of the tiles that are left in the bag.
+	ourCount := int(g.RackFor(g.PlayerOnTurn()).NumTiles())
+	unseen := g.Bag().TilesRemaining() + int(g.RackFor(g.NextPlayer()).NumTiles())
+	actuallyInBag := max(unseen-g.Rules().RuleSet().RackSize, 0)
+	estimatedTurnsLeft := (float64(actuallyInBag)/2 + float64(ourCount)) / tilesPerTurn
+	if estimatedTurnsLeft < 1 {
+		estimatedTurnsLeft = 1
+	}
+	left := time.Duration(millisRemaining)*time.Millisecond - timeReserve
+	if left <= 0 {
+		return 0
+	}
+	return min(time.Duration(float64(left)/estimatedTurnsLeft), limit)
+}
@@ -0,0 +1,20 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTimeForMove(t *testing.T) {
+	is := is.New(t)
+	g := catGame(t)
+	// With a full bag, there are plenty of turns left.
+	long := TimeForMove(g, 20*60*1000, time.Hour)
+	is.True(long > 0 && long < 2*time.Minute)
+	is.Equal(TimeForMove(g, 20*60*1000, 10*time.Second), 10*time.Second)
+	// Nothing is left after the reserve.
+	is.Equal(TimeForMove(g, 3000, time.Hour), time.Duration(0))
+	is.Equal(TimeForMove(g, -5000, time.Hour), time.Duration(0))
+}
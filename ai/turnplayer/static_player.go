@@ -58,6 +58,8 @@ func AddAIFields(p *turnplayer.BaseTurnPlayer, conf *config.Config, calculators
 		return nil, err
 	}
 	gen := movegen.NewGordonGenerator(gd, p.Board(), p.Bag().LetterDistribution())
+	gen.SetRuleSet(p.Rules().RuleSet())
+	gen.SetVariant(p.Rules().Variant())
 	gen.SetEquityCalculators(calculators)
 	ret := &AIStaticTurnPlayer{*p, calculators, gen, conf}
 	return ret, nil
@@ -94,7 +96,8 @@ func (p *AIStaticTurnPlayer) GenerateMoves(numPlays int) []*move.Move {
 	oppRack := p.RackFor(p.NextPlayer())
 	// in case we don't have full rack info:
 	unseen := int(oppRack.NumTiles()) + p.Bag().TilesRemaining()
-	exchAllowed := unseen-game.RackTileLimit >= game.ExchangeLimit
+	rs := p.Rules().RuleSet()
+	exchAllowed := unseen-rs.RackSize >= rs.ExchangeLimit
 	p.gen.GenAll(curRack, exchAllowed)
 
 	plays := p.gen.(*movegen.GordonGenerator).Plays()
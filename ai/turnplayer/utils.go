@@ -17,11 +17,12 @@ func GenBestStaticTurn(g *game.Game, p AITurnPlayer, playerIdx int) *move.Move {
 	// XXX: This is not ideal, but refactor later:
 	mg.(*movegen.GordonGenerator).SetGame(g)
 
-	// Add an exchange only if there are 7 or more tiles in the bag.
+	// Add an exchange only if there are enough tiles in the bag.
 	// in case we don't have full rack info:
 	oppRack := g.RackFor(1 - playerIdx)
 	unseen := int(oppRack.NumTiles()) + g.Bag().TilesRemaining()
-	exchAllowed := unseen-game.RackTileLimit >= game.ExchangeLimit
+	rs := g.Rules().RuleSet()
+	exchAllowed := unseen-rs.RackSize >= rs.ExchangeLimit
 	mg.GenAll(g.RackFor(playerIdx), exchAllowed)
 	return mg.Plays()[0]
 }
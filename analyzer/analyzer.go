@@ -1,10 +1,12 @@
 package analyzer
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/domino14/word-golib/tilemapping"
 
@@ -14,6 +16,8 @@ import (
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/montecarlo"
 	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/progress"
+	"github.com/domino14/macondo/render"
 	"github.com/domino14/macondo/turnplayer"
 )
 
@@ -41,33 +45,112 @@ var SampleJson = []byte(`{
   "..............."
 ]}`)
 
+// JsonBoard is a position to analyze. It is given field by field, or as a
+// whole with Cgp or GameHistory. See JsonSchemaVersion. Its keys are in
+// snake_case; those of version 1 are single words, so they are read in
+// any case.
 type JsonBoard struct {
-	Scores  []int
-	Onturn  int
-	Size    int
-	Lexicon string
-	Board   []string
-	Rack    string
+	// Version is the version of the schema the position was written for.
+	Version int      `json:"version,omitempty"`
+	Scores  []int    `json:"scores,omitempty"`
+	Onturn  int      `json:"onturn,omitempty"`
+	Size    int      `json:"size,omitempty"`
+	Lexicon string   `json:"lexicon,omitempty"`
+	Board   []string `json:"board,omitempty"`
+	Rack    string   `json:"rack,omitempty"`
+	// History is the moves that led to the position, if they are known.
+	// They are replayed from an empty board, so Board may then be left
+	// out; if it is given, it must match. Inference needs a history.
+	History []JsonHistoryMove `json:"history,omitempty"`
+	// Unseen is the tiles in the bag and on the opponent's rack, if they
+	// are known. Otherwise they are all the tiles that are not on the
+	// board or on Rack.
+	Unseen string `json:"unseen,omitempty"`
+	// LetterDistribution, BoardLayout, Variant and ChallengeRule take the
+	// names the shell's setconfig command does. They default to english,
+	// CrosswordGame, classic and void.
+	LetterDistribution string `json:"letter_distribution,omitempty"`
+	BoardLayout        string `json:"board_layout,omitempty"`
+	Variant            string `json:"variant,omitempty"`
+	ChallengeRule      string `json:"challenge_rule,omitempty"`
+	// Cgp is the whole position as a CGP string. Only Version may be
+	// given with it.
+	Cgp string `json:"cgp,omitempty"`
+	// GameHistory is the game as a GameHistory in its JSON form, such as
+	// the shell's export writes. The position is the one after Turn events:
+	// 0 is the empty board, and if Turn is left out, it is the end of the
+	// game. Only Version and Turn may be given with it.
+	GameHistory json.RawMessage `json:"game_history,omitempty"`
+	Turn        *int            `json:"turn,omitempty"`
+}
+
+// JsonHistoryMove is a move in the history of a JsonBoard.
+type JsonHistoryMove struct {
+	Player int `json:"player"`
+	// Move is in the notation of the shell: "8D QI", "exchange ABC" or
+	// "pass". Tiles played through can be given as letters or as '.'.
+	Move string `json:"move"`
+	// Rack is the player's rack before the move. If it is not known, the
+	// player is given just the tiles they used. Give racks when the bag
+	// is empty, so that the game does not end early.
+	Rack string `json:"rack,omitempty"`
 }
 
 type JsonMove struct {
-	Action             string
-	Row                int
-	Column             int
-	Vertical           bool
-	DisplayCoordinates string
-	Tiles              string
-	Leave              string
-	Equity             float64
-	Score              int
+	Action             string  `json:"action"`
+	Row                int     `json:"row"`
+	Column             int     `json:"column"`
+	Vertical           bool    `json:"vertical"`
+	DisplayCoordinates string  `json:"display_coordinates"`
+	Tiles              string  `json:"tiles"`
+	Leave              string  `json:"leave"`
+	Equity             float64 `json:"equity"`
+	Score              int     `json:"score"`
+	// Words are the words the play makes, main word first. They are left
+	// out of endgame sequences.
+	Words []string `json:"words,omitempty"`
+	// Sim is the play's statistics from the last sim, in SimState.
+	Sim *JsonSimStats `json:"sim,omitempty"`
+}
+
+// jsonMoveV1 is a JsonMove with the keys of version 1. Analyze writes
+// moves this way for a position of version 1.
+type jsonMoveV1 struct {
+	Action             string   `json:"Action"`
+	Row                int      `json:"Row"`
+	Column             int      `json:"Column"`
+	Vertical           bool     `json:"Vertical"`
+	DisplayCoordinates string   `json:"DisplayCoordinates"`
+	Tiles              string   `json:"Tiles"`
+	Leave              string   `json:"Leave"`
+	Equity             float64  `json:"Equity"`
+	Score              int      `json:"Score"`
+	Words              []string `json:"Words,omitempty"`
+}
+
+// JsonSimStats are the statistics of a simmed play. The errors are the
+// half-widths of 99% confidence intervals.
+type JsonSimStats struct {
+	WinPct      float64 `json:"win_pct"`
+	WinPctError float64 `json:"win_pct_error"`
+	Equity      float64 `json:"equity"`
+	EquityError float64 `json:"equity_error"`
+	Ignored     bool    `json:"ignored"`
 }
 
 type Analyzer struct {
-	config  *config.Config
-	moves   []*move.Move
-	simmer  *montecarlo.Simmer
-	options *turnplayer.GameOptions
-	game    *bot.BotTurnPlayer
+	config *config.Config
+	moves  []*move.Move
+	simmer *montecarlo.Simmer
+	// simProgress is the latest progress event of the sim.
+	simProgress progress.Event
+	options     *turnplayer.GameOptions
+	game        *bot.BotTurnPlayer
+	// version is the schema version of the position that was loaded.
+	version int
+	// busy is set while an endgame, pre-endgame or inference runs. Other
+	// methods that use the game fail while it is.
+	busy atomic.Bool
 }
 
 func MakeJsonMove(m *move.Move) JsonMove {
@@ -82,6 +165,23 @@ func MakeJsonMove(m *move.Move) JsonMove {
 	return j
 }
 
+// jsonMove is MakeJsonMove with the words that m makes on the board of the
+// analyzer's game.
+func (an *Analyzer) jsonMove(m *move.Move) JsonMove {
+	j := MakeJsonMove(m)
+	if m.Action() != move.MoveTypePlay {
+		return j
+	}
+	words, err := an.game.Board().FormedWords(m)
+	if err != nil {
+		return j
+	}
+	for _, w := range words {
+		j.Words = append(j.Words, w.UserVisible(an.game.Alphabet()))
+	}
+	return j
+}
+
 func NewAnalyzer(config *config.Config) *Analyzer {
 	options := &turnplayer.GameOptions{}
 	an := &Analyzer{}
@@ -106,7 +206,10 @@ func (an *Analyzer) newGame() error {
 		{Nickname: "self", RealName: "Macondo Bot"},
 		{Nickname: "opponent", RealName: "Arthur Dent"},
 	}
-	conf := &bot.BotConfig{Config: *an.config}
+	conf := &bot.BotConfig{
+		Config:     *an.config,
+		LeavesFile: equity.LeavesFilenameFor(an.options.BoardLayoutName, an.options.Variant),
+	}
 
 	game, err := bot.NewBotTurnPlayer(conf, an.options, players, pb.BotRequest_HASTY_BOT)
 	if err != nil {
@@ -123,41 +226,66 @@ func (an *Analyzer) loadJson(j []byte) error {
 	if err != nil {
 		return fmt.Errorf("parse json failed: %w", err)
 	}
-	if len(b.Scores) != 2 {
-		return errors.New("invalid scores")
+	an.options = &turnplayer.GameOptions{}
+	err = b.validate(an.options)
+	if err != nil {
+		return err
 	}
-	if b.Onturn < 0 || b.Onturn > 1 {
-		return errors.New("invalid onturn")
+	an.version = max(1, b.Version)
+	switch {
+	case b.Cgp != "":
+		return an.loadCgp(b.Cgp)
+	case len(b.GameHistory) > 0:
+		return an.loadGameHistory(b.GameHistory, b.Turn)
 	}
-	an.options.SetLexicon([]string{b.Lexicon})
 	err = an.newGame()
 	if err != nil {
 		return fmt.Errorf("creating game failed: %w", err)
 	}
 	var g = an.game
-	g.SetPointsFor(0, b.Scores[0])
-	g.SetPointsFor(1, b.Scores[1])
+	if len(b.History) > 0 {
+		err = an.playHistory(b.History, b.Board)
+		if err != nil {
+			return err
+		}
+		// Racks are worked out again below.
+		g.ThrowRacksIn()
+	} else {
+		bd := g.Board()
+		letters := []tilemapping.MachineLetter{}
+		for row, str := range b.Board {
+			str = strings.Replace(str, ".", " ", -1)
+			letters = append(letters, bd.SetRow(row, str, g.Alphabet())...)
+		}
+		// Reset the state of the bag; empty player racks (they are set to
+		// random racks) and refill the bag from scratch
+		g.ThrowRacksIn()
+		g.Bag().Refill()
+		// Then remove the visible tiles on the board
+		err = g.Bag().RemoveTiles(letters)
+		if err != nil {
+			return &SchemaError{Field: "board", Reason: "removing board tiles failed: " + err.Error()}
+		}
+	}
+	if len(b.Scores) == 2 {
+		g.SetPointsFor(0, b.Scores[0])
+		g.SetPointsFor(1, b.Scores[1])
+	}
 	g.SetPlayerOnTurn(b.Onturn)
-	bd := g.Board()
-	letters := []tilemapping.MachineLetter{}
-	for row, str := range b.Board {
-		str = strings.Replace(str, ".", " ", -1)
-		letters = append(letters, bd.SetRow(row, str, g.Alphabet())...)
-	}
-	// Reset the state of the bag; empty player racks (they are set to
-	// random racks) and refill the bag from scratch
-	g.ThrowRacksIn()
-	g.Bag().Refill()
-	// Then remove the visible tiles on the board
-	err = g.Bag().RemoveTiles(letters)
-	if err != nil {
-		return fmt.Errorf("removing board tiles failed: %w", err)
+	if b.Unseen != "" {
+		err = an.setUnseen(b.Unseen, b.Rack)
+		if err != nil {
+			return err
+		}
+	}
+	if b.ChallengeRule != "" {
+		g.SetChallengeRule(an.options.ChallengeRule)
 	}
 	// Set the current rack. This will also give opponent a random rack
 	// from what remains, and edit the bag accordingly.
 	err = g.SetCurrentRack(b.Rack)
 	if err != nil {
-		return fmt.Errorf("setting rack to %v failed: %w", b.Rack, err)
+		return &SchemaError{Field: "rack", Reason: fmt.Sprintf("setting rack to %v failed: %v", b.Rack, err)}
 	}
 	g.RecalculateBoard()
 
@@ -165,6 +293,9 @@ func (an *Analyzer) loadJson(j []byte) error {
 }
 
 func (an *Analyzer) LoadGame(jsonBoard []byte) error {
+	if err := an.idle(); err != nil {
+		return err
+	}
 	err := an.loadJson(jsonBoard)
 	if err != nil {
 		return fmt.Errorf("loading game failed: %w", err)
@@ -176,10 +307,21 @@ func (an *Analyzer) GenerateMoves(numPlays int) {
 	an.moves = an.game.GenerateMoves(numPlays)
 }
 
+// ToJsonMoves returns the moves that were generated, with the keys of the
+// version of the position.
 func (an *Analyzer) ToJsonMoves() ([]byte, error) {
+	if an.version == 1 {
+		out := make([]jsonMoveV1, len(an.moves))
+		for i, m := range an.moves {
+			j := an.jsonMove(m)
+			out[i] = jsonMoveV1{j.Action, j.Row, j.Column, j.Vertical, j.DisplayCoordinates,
+				j.Tiles, j.Leave, j.Equity, j.Score, j.Words}
+		}
+		return json.Marshal(out)
+	}
 	out := make([]JsonMove, len(an.moves))
 	for i, m := range an.moves {
-		out[i] = MakeJsonMove(m)
+		out[i] = an.jsonMove(m)
 	}
 	return json.Marshal(out)
 }
@@ -203,7 +345,7 @@ func (an *Analyzer) RunTest() error {
 	g := an.game
 	fmt.Println(g.Board().ToDisplayText(g.Alphabet()))
 	// Display the moves
-	var ms []JsonMove
+	var ms []jsonMoveV1
 	err = json.Unmarshal(moves, &ms)
 	if err != nil {
 		return err
@@ -214,12 +356,39 @@ func (an *Analyzer) RunTest() error {
 	return nil
 }
 
+// Render draws the position that was last analyzed, in the svg or png
+// format, with the best numArrows plays drawn as arrows.
+func (an *Analyzer) Render(format string, numArrows int) ([]byte, error) {
+	if an.game == nil {
+		return nil, errors.New("no position loaded")
+	}
+	if err := an.idle(); err != nil {
+		return nil, err
+	}
+	if numArrows < 0 {
+		return nil, errors.New("the number of arrows must not be negative")
+	}
+	f, err := render.ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	opts := render.Options{Candidates: an.moves[:min(numArrows, len(an.moves))]}
+	var buf bytes.Buffer
+	if err := render.Render(&buf, an.game.Game, f, 0, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func AnalyzeBoard(jsonBoard []byte) ([]byte, error) {
 	an := NewDefaultAnalyzer()
 	return an.Analyze(jsonBoard)
 }
 
 func (an *Analyzer) SimInit() error {
+	if err := an.idle(); err != nil {
+		return err
+	}
 	simmer := &montecarlo.Simmer{}
 
 	c, err := equity.NewCombinedStaticCalculator(
@@ -229,6 +398,7 @@ func (an *Analyzer) SimInit() error {
 		return fmt.Errorf("init sim failed: %w", err)
 	}
 	simmer.Init(an.game.Game, []equity.EquityCalculator{c}, c, an.config)
+	simmer.SetProgressListener(func(e progress.Event) { an.simProgress = e }, 0)
 	simmer.Reset()
 	err = simmer.PrepareSim(2, an.moves)
 	if err != nil {
@@ -243,6 +413,9 @@ func (an *Analyzer) SimSingleThread(iters int) error {
 	if simmer == nil {
 		return errors.New("sim not initialized")
 	}
+	if err := an.idle(); err != nil {
+		return err
+	}
 	simmer.SimSingleThread(iters)
 	return nil
 }
@@ -253,11 +426,32 @@ func (an *Analyzer) SimState() ([]byte, error) {
 	if simmer == nil {
 		return nil, errors.New("sim not initialized")
 	}
+	if err := an.idle(); err != nil {
+		return nil, err
+	}
+	plays := []JsonMove{}
+	for _, sp := range simmer.PlaysByWinProb() {
+		j := an.jsonMove(sp.Move())
+		j.Sim = &JsonSimStats{
+			WinPct:      100 * sp.WinProb(),
+			WinPctError: 100 * sp.WinProbStdErr(),
+			Equity:      sp.EquityMean(),
+			EquityError: sp.EquityStdErr(),
+			Ignored:     sp.Ignored(),
+		}
+		plays = append(plays, j)
+	}
 	return json.Marshal(struct {
-		EquityStats  string `json:"equity_stats"`
-		ScoreDetails string `json:"score_details"`
+		EquityStats  string         `json:"equity_stats"`
+		ScoreDetails string         `json:"score_details"`
+		Iterations   int            `json:"iterations"`
+		Plays        []JsonMove     `json:"plays"`
+		Progress     progress.Event `json:"progress"`
 	}{
 		EquityStats:  simmer.EquityStats(),
 		ScoreDetails: simmer.ScoreDetails(),
+		Iterations:   simmer.Iterations(),
+		Plays:        plays,
+		Progress:     an.simProgress,
 	})
 }
@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+)
+
+// playHistory replays the moves of a history on the (empty) board of the
+// analyzer's game. If rows are given, the board must end up the same.
+func (an *Analyzer) playHistory(history []JsonHistoryMove, rows []string) error {
+	g := an.game
+	alph := g.Alphabet()
+	// Plays that were not challenged off stay on the board, as with the
+	// double challenge rule, even if they are phonies.
+	g.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	for i, h := range history {
+		field := fmt.Sprintf("history[%d]", i)
+		g.SetPlayerOnTurn(h.Player)
+		fields := strings.Fields(h.Move)
+		var tiles []tilemapping.MachineLetter
+		var err error
+		if h.Rack != "" {
+			tiles, err = tilemapping.ToMachineLetters(h.Rack, alph)
+		} else {
+			tiles, err = an.tilesUsed(fields)
+		}
+		if err != nil {
+			return &SchemaError{Field: field, Reason: err.Error()}
+		}
+		if len(tiles) > 0 {
+			rack := tilemapping.NewRack(alph)
+			rack.Set(tiles)
+			if err := g.SetRackFor(h.Player, rack); err != nil {
+				return &SchemaError{Field: field, Reason: "setting rack failed: " + err.Error()}
+			}
+		}
+		m, err := g.ParseMove(h.Player, false, fields)
+		if err != nil {
+			return &SchemaError{Field: field, Reason: err.Error()}
+		}
+		if err := g.PlayMove(m, true, 0); err != nil {
+			return &SchemaError{Field: field, Reason: err.Error()}
+		}
+	}
+	bd := g.Board()
+	for r, row := range rows {
+		want := strings.ReplaceAll(row, " ", ".")
+		var got strings.Builder
+		for c := 0; c < bd.Dim(); c++ {
+			ml := bd.GetLetter(r, c)
+			if ml == 0 {
+				got.WriteString(".")
+			} else {
+				got.WriteString(ml.UserVisible(alph, false))
+			}
+		}
+		if got.String() != want {
+			return &SchemaError{Field: "board", Reason: fmt.Sprintf(
+				"row %d is %q after the history, not %q", r+1, got.String(), want)}
+		}
+	}
+	return nil
+}
+
+// tilesUsed returns the tiles from the rack that a move uses: the tiles it
+// places on the board, or the tiles it exchanges.
+func (an *Analyzer) tilesUsed(fields []string) ([]tilemapping.MachineLetter, error) {
+	if len(fields) != 2 {
+		// A pass, or a move that ParseMove will reject.
+		return nil, nil
+	}
+	alph := an.game.Alphabet()
+	if fields[0] == "exchange" {
+		return tilemapping.ToMachineLetters(fields[1], alph)
+	}
+	mw, err := tilemapping.ToMachineWord(fields[1], alph)
+	if err != nil {
+		return nil, err
+	}
+	row, col, vertical := move.FromBoardGameCoords(fields[0])
+	bd := an.game.Board()
+	var tiles []tilemapping.MachineLetter
+	for i, ml := range mw {
+		r, c := row, col+i
+		if vertical {
+			r, c = row+i, col
+		}
+		if ml == 0 || (r < bd.Dim() && c < bd.Dim() && bd.GetLetter(r, c) != 0) {
+			// Played through.
+			continue
+		}
+		tiles = append(tiles, ml.IntrinsicTileIdx())
+	}
+	return tiles, nil
+}
+
+// setUnseen makes the bag hold just the unseen tiles and the rack, to be
+// drawn from by SetCurrentRack. Both racks must be in the bag already.
+func (an *Analyzer) setUnseen(unseen, rack string) error {
+	alph := an.game.Alphabet()
+	tiles, err := tilemapping.ToMachineLetters(unseen+rack, alph)
+	if err != nil {
+		return &SchemaError{Field: "unseen", Reason: err.Error()}
+	}
+	bag := an.game.Bag()
+	// The tiles must be among those not on the board.
+	if err := bag.RemoveTiles(tiles); err != nil {
+		return &SchemaError{Field: "unseen", Reason: "the tiles do not match the board: " + err.Error()}
+	}
+	if err := bag.RemoveTiles(bag.Peek()); err != nil {
+		return err
+	}
+	bag.PutBack(tiles)
+	return nil
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/config"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+const historyJson = `{
+"onturn": 0,
+"size": 15,
+"rack": "AEINRST",
+"lexicon": "NWL20",
+"history": [
+  {"Player": 0, "Move": "8G QI"},
+  {"Player": 1, "Move": "H7 H.S"}
+],
+"board": [
+  "...............",
+  "...............",
+  "...............",
+  "...............",
+  "...............",
+  "...............",
+  ".......H.......",
+  "......QI.......",
+  ".......S.......",
+  "...............",
+  "...............",
+  "...............",
+  "...............",
+  "...............",
+  "..............."
+]}`
+
+func TestLoadHistory(t *testing.T) {
+	is := is.New(t)
+	an := NewAnalyzer(&DefaultConfig)
+	is.NoErr(an.LoadGame([]byte(historyJson)))
+	g := an.game
+	is.Equal(g.Turn(), 2)
+	is.Equal(g.PlayerOnTurn(), 0)
+	is.Equal(g.PointsFor(0), 22)
+	is.Equal(g.RackLettersFor(0), "AEINRST")
+	// Four tiles are on the board and seven on each rack.
+	is.Equal(g.Bag().TilesRemaining(), 100-4-7-7)
+}
+
+func TestLoadHistoryBoardMismatch(t *testing.T) {
+	is := is.New(t)
+	an := NewAnalyzer(&DefaultConfig)
+	j := []byte(`{"onturn": 0, "size": 15, "rack": "AEINRST", "lexicon": "NWL20",
+"history": [{"Player": 0, "Move": "8G QI"}],
+"board": ["...............", "...............", "...............",
+  "...............", "...............", "...............",
+  "...............", "......QA......."]}`)
+	is.True(an.LoadGame(j) != nil)
+}
+
+func TestLoadUnseen(t *testing.T) {
+	is := is.New(t)
+	an := NewAnalyzer(&DefaultConfig)
+	j := []byte(`{"onturn": 1, "size": 15, "rack": "AEINRST", "lexicon": "NWL20",
+"history": [{"Player": 0, "Move": "8G QI"}], "unseen": "EEIOUVW"}`)
+	is.NoErr(an.LoadGame(j))
+	g := an.game
+	is.Equal(g.Bag().TilesRemaining(), 0)
+	is.Equal(g.RackLettersFor(1), "AEINRST")
+	is.Equal(g.RackLettersFor(0), "EEIOUVW")
+
+	// There is only one Q.
+	j = []byte(`{"onturn": 1, "size": 15, "rack": "AEINRST", "lexicon": "NWL20",
+"history": [{"Player": 0, "Move": "8G QI"}], "unseen": "Q"}`)
+	is.True(an.LoadGame(j) != nil)
+}
+
+func TestPositionRoundTrip(t *testing.T) {
+	is := is.New(t)
+	an := NewAnalyzer(&DefaultConfig)
+	moves, err := an.Analyze([]byte(historyJson))
+	is.NoErr(err)
+	// A position of version 1 gets its moves with the keys of version 1.
+	is.True(bytes.Contains(moves, []byte(`"DisplayCoordinates"`)))
+
+	pos, err := an.Position()
+	is.NoErr(err)
+	var b JsonBoard
+	is.NoErr(json.Unmarshal(pos, &b))
+	is.Equal(b.Version, JsonSchemaVersion)
+	is.Equal(b.Cgp, "")
+	is.Equal(*b.Turn, 2)
+	moves, err = an.Analyze(pos)
+	is.NoErr(err)
+	is.True(bytes.Contains(moves, []byte(`"display_coordinates"`)))
+	is.Equal(an.game.Turn(), 2)
+
+	// Turn 0 is the empty board, before the first move.
+	zero := 0
+	b.Turn = &zero
+	pos, err = json.Marshal(&b)
+	is.NoErr(err)
+	is.NoErr(an.LoadGame(pos))
+	is.Equal(an.game.Turn(), 0)
+	is.True(an.game.Board().IsEmpty())
+	pos, err = an.Position()
+	is.NoErr(err)
+	var again JsonBoard
+	is.NoErr(json.Unmarshal(pos, &again))
+	is.Equal(*again.Turn, 0)
+	is.Equal(len(again.GameHistory) > 0, true)
+}
+
+func TestBusy(t *testing.T) {
+	is := is.New(t)
+	an := NewAnalyzer(&DefaultConfig)
+	an.busy.Store(true)
+	// Nothing may use the game while an endgame, pre-endgame or inference
+	// runs.
+	is.Equal(an.LoadGame([]byte(historyJson)), errBusy)
+	_, err := an.Analyze([]byte(historyJson))
+	is.Equal(err, errBusy)
+	is.Equal(an.SimInit(), errBusy)
+	an.end()
+	is.NoErr(an.idle())
+}
+
+func TestRenderArrows(t *testing.T) {
+	is := is.New(t)
+	an := NewAnalyzer(&DefaultConfig)
+	_, err := an.Analyze([]byte(historyJson))
+	is.NoErr(err)
+	_, err = an.Render("svg", -1)
+	is.True(err != nil)
+	// There may be more arrows than plays.
+	_, err = an.Render("svg", 100)
+	is.NoErr(err)
+}
@@ -0,0 +1,291 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/cgp"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/turnplayer"
+)
+
+// JsonSchemaVersion is the newest version of the JsonBoard schema. A
+// position without a version is read as version 1, which had only the
+// fields from Scores to Unseen.
+const JsonSchemaVersion = 2
+
+// SchemaError is a problem with a field of a JsonBoard. LoadGame returns
+// all the problems that it finds, joined together.
+type SchemaError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *SchemaError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// SchemaErrors returns the schema errors in err, which may be joined or
+// wrapped.
+func SchemaErrors(err error) []*SchemaError {
+	var found []*SchemaError
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *SchemaError:
+			found = append(found, e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return found
+}
+
+// ErrorJson returns err as a JSON object with the error message and the
+// schema errors in it, if any.
+func ErrorJson(err error) []byte {
+	out, _ := json.Marshal(struct {
+		Error  string         `json:"error"`
+		Fields []*SchemaError `json:"fields,omitempty"`
+	}{
+		Error:  err.Error(),
+		Fields: SchemaErrors(err),
+	})
+	return out
+}
+
+// setFields returns the names of the fields that describe the position
+// itself, and that are set.
+func (b *JsonBoard) setFields() []string {
+	var set []string
+	add := func(isSet bool, name string) {
+		if isSet {
+			set = append(set, name)
+		}
+	}
+	add(len(b.Scores) > 0, "scores")
+	add(b.Onturn != 0, "onturn")
+	add(b.Size != 0, "size")
+	add(b.Lexicon != "", "lexicon")
+	add(len(b.Board) > 0, "board")
+	add(b.Rack != "", "rack")
+	add(len(b.History) > 0, "history")
+	add(b.Unseen != "", "unseen")
+	add(b.LetterDistribution != "", "letter_distribution")
+	add(b.BoardLayout != "", "board_layout")
+	add(b.Variant != "", "variant")
+	add(b.ChallengeRule != "", "challenge_rule")
+	return set
+}
+
+// validate returns every problem with the fields of the position, and
+// sets up the game options for a position given field by field.
+func (b *JsonBoard) validate(opts *turnplayer.GameOptions) error {
+	var problems []error
+	bad := func(field, format string, a ...any) {
+		problems = append(problems, &SchemaError{Field: field, Reason: fmt.Sprintf(format, a...)})
+	}
+	version := b.Version
+	if version == 0 {
+		version = 1
+	}
+	if version < 1 || version > JsonSchemaVersion {
+		bad("version", "unsupported version %d, the newest is %d", b.Version, JsonSchemaVersion)
+		return errors.Join(problems...)
+	}
+	if version == 1 {
+		for _, f := range []struct {
+			isSet bool
+			name  string
+		}{
+			{b.LetterDistribution != "", "letter_distribution"},
+			{b.BoardLayout != "", "board_layout"}, {b.Variant != "", "variant"},
+			{b.ChallengeRule != "", "challenge_rule"}, {b.Cgp != "", "cgp"},
+			{len(b.GameHistory) > 0, "game_history"}, {b.Turn != nil, "turn"},
+		} {
+			if f.isSet {
+				bad(f.name, "needs version %d", JsonSchemaVersion)
+			}
+		}
+		if len(problems) > 0 {
+			return errors.Join(problems...)
+		}
+	}
+
+	if b.Cgp != "" || len(b.GameHistory) > 0 {
+		source := "cgp"
+		if b.Cgp != "" && len(b.GameHistory) > 0 {
+			bad("game_history", "cannot be given with cgp")
+		} else if len(b.GameHistory) > 0 {
+			source = "game_history"
+		}
+		for _, f := range b.setFields() {
+			bad(f, "cannot be given with %s", source)
+		}
+		if b.Turn != nil && source != "game_history" {
+			bad("turn", "can only be given with game_history")
+		}
+		if b.Turn != nil && *b.Turn < 0 {
+			bad("turn", "must not be negative")
+		}
+		return errors.Join(problems...)
+	}
+
+	if b.Turn != nil {
+		bad("turn", "can only be given with game_history")
+	}
+	if b.Lexicon == "" {
+		bad("lexicon", "is required")
+	}
+	if len(b.Scores) != 2 && (len(b.History) == 0 || len(b.Scores) != 0) {
+		bad("scores", "must have 2 scores, or be left out if a history is given")
+	}
+	if b.Onturn < 0 || b.Onturn > 1 {
+		bad("onturn", "must be 0 or 1")
+	}
+	for i, h := range b.History {
+		if h.Player < 0 || h.Player > 1 {
+			bad(fmt.Sprintf("history[%d].player", i), "must be 0 or 1")
+		}
+	}
+
+	lexicon := []string{b.Lexicon}
+	if b.LetterDistribution != "" {
+		lexicon = append(lexicon, b.LetterDistribution)
+	}
+	if err := opts.SetLexicon(lexicon); err != nil {
+		bad("lexicon", "%v", err)
+	}
+	if b.BoardLayout != "" {
+		if err := opts.SetBoardLayoutName(b.BoardLayout); err != nil {
+			bad("board_layout", "%v", err)
+		}
+	}
+	if b.Variant != "" {
+		if err := opts.SetVariant(b.Variant); err != nil {
+			bad("variant", "%v", err)
+		}
+	}
+	if b.ChallengeRule != "" {
+		if err := opts.SetChallenge(b.ChallengeRule); err != nil {
+			bad("challenge_rule", "%v", err)
+		}
+	}
+	dim := len(board.CrosswordGameBoard)
+	if opts.BoardLayoutName == board.SuperCrosswordGameLayout {
+		dim = len(board.SuperCrosswordGameBoard)
+	}
+	if b.Size != 0 && b.Size != dim {
+		bad("size", "is %d, but the board layout is %d by %d", b.Size, dim, dim)
+	}
+	if len(b.Board) > dim {
+		bad("board", "has %d rows, more than %d", len(b.Board), dim)
+	}
+	return errors.Join(problems...)
+}
+
+// loadCgp loads a position from a CGP string, as the shell's cgp command
+// does.
+func (an *Analyzer) loadCgp(cgpstr string) error {
+	g, err := cgp.ParseCGP(an.config, cgpstr)
+	if err != nil {
+		return &SchemaError{Field: "cgp", Reason: err.Error()}
+	}
+	if err := an.newGameFrom(g.Game); err != nil {
+		return err
+	}
+	// Set challenge rule to double by default, unless the CGP has one.
+	if _, ok := g.Opcodes["cr"]; !ok {
+		an.game.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	}
+	an.game.RecalculateBoard()
+	return nil
+}
+
+// loadGameHistory loads the position after the given turn of a game
+// history, or at the end of it if turn is nil.
+func (an *Analyzer) loadGameHistory(data []byte, turnp *int) error {
+	h := &pb.GameHistory{}
+	if err := protojson.Unmarshal(data, h); err != nil {
+		return &SchemaError{Field: "game_history", Reason: err.Error()}
+	}
+	if len(h.Players) != 2 {
+		return &SchemaError{Field: "game_history", Reason: "must have 2 players"}
+	}
+	turn := len(h.Events)
+	if turnp != nil {
+		turn = *turnp
+	}
+	if turn > len(h.Events) {
+		return &SchemaError{Field: "turn", Reason: fmt.Sprintf(
+			"is %d, but the history only has %d events", turn, len(h.Events))}
+	}
+	if h.Lexicon == "" {
+		h.Lexicon = an.config.GetString(config.ConfigDefaultLexicon)
+	}
+	boardLayout, ldName, variant := game.HistoryToVariant(h)
+	rules, err := game.NewBasicGameRules(an.config, h.Lexicon, boardLayout, ldName,
+		game.CrossScoreAndSet, variant)
+	if err != nil {
+		return fmt.Errorf("creating game failed: %w", err)
+	}
+	g, err := game.NewFromHistory(h, rules, turn)
+	if err != nil {
+		return &SchemaError{Field: "game_history", Reason: err.Error()}
+	}
+	return an.newGameFrom(g)
+}
+
+// newGameFrom makes the analyzer's game from g, as the shell does when it
+// loads a game.
+func (an *Analyzer) newGameFrom(g *game.Game) error {
+	conf := &bot.BotConfig{
+		Config:     *an.config,
+		LeavesFile: equity.LeavesFilenameFor(g.History().BoardLayout, g.Rules().Variant()),
+	}
+	p, err := bot.NewBotTurnPlayerFromGame(g, conf, pb.BotRequest_HASTY_BOT)
+	if err != nil {
+		return fmt.Errorf("creating game failed: %w", err)
+	}
+	p.SetBackupMode(game.InteractiveGameplayMode)
+	p.SetStateStackLength(1)
+	an.game = p
+	return nil
+}
+
+// Position returns the position that was last loaded, as a JsonBoard of
+// the newest version: a game history and the turn of the position in it,
+// or a CGP if the game has no events.
+func (an *Analyzer) Position() ([]byte, error) {
+	if an.game == nil {
+		return nil, errors.New("no position loaded")
+	}
+	if err := an.idle(); err != nil {
+		return nil, err
+	}
+	b := &JsonBoard{Version: JsonSchemaVersion}
+	if h := an.game.History(); len(h.Events) > 0 {
+		data, err := protojson.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		turn := an.game.Turn()
+		b.GameHistory, b.Turn = data, &turn
+	} else {
+		b.Cgp = an.game.WriteCGP(game.CGPOptions{WithBag: true})
+	}
+	return json.Marshal(b)
+}
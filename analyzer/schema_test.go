@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/turnplayer"
+)
+
+func schemaFields(t *testing.T, j string) []string {
+	var b JsonBoard
+	if err := json.Unmarshal([]byte(j), &b); err != nil {
+		t.Fatal(err)
+	}
+	var fields []string
+	for _, e := range SchemaErrors(b.validate(&turnplayer.GameOptions{})) {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+	is.Equal(schemaFields(t, string(SampleJson)), nil)
+	is.Equal(schemaFields(t, `{"version": 2, "scores": [0, 0], "lexicon": "CSW21",
+		"board_layout": "SuperCrosswordGame", "size": 21, "variant": "wordsmog",
+		"challenge_rule": "double"}`), nil)
+	is.Equal(schemaFields(t, `{"version": 2, "lexicon": "NWL20",
+		"history": [{"player": 0, "move": "8G QI"}]}`), nil)
+	is.Equal(schemaFields(t, `{"lexicon": "NWL20", "unseen": "ABC",
+		"history": [{"player": 0, "move": "8G QI"}]}`), nil)
+	is.Equal(schemaFields(t, `{"version": 2, "cgp": "15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 ABCDEFG/ 0/0 0 lex NWL20;"}`), nil)
+	is.Equal(schemaFields(t, `{"version": 2, "game_history": {"players": []}, "turn": 3}`), nil)
+	// Turn 0 is the empty board.
+	is.Equal(schemaFields(t, `{"version": 2, "game_history": {"players": []}, "turn": 0}`), nil)
+	// The keys of version 1 are read in any case.
+	is.Equal(schemaFields(t, `{"Scores": [0, 0], "Lexicon": "NWL20", "Rack": "ABC"}`), nil)
+
+	is.Equal(schemaFields(t, `{"version": 3}`), []string{"version"})
+	// Newer fields need the version to be given.
+	is.Equal(schemaFields(t, `{"scores": [0, 0], "lexicon": "NWL20", "unseen": "ABC",
+		"cgp": "x", "turn": 0}`), []string{"cgp", "turn"})
+	is.Equal(schemaFields(t, `{"version": 2, "scores": [0], "onturn": 2, "size": 21,
+		"board_layout": "Hexagonal", "variant": "chess", "challenge_rule": "maybe",
+		"history": [{"player": 3, "move": "pass"}], "turn": 1}`),
+		[]string{"turn", "lexicon", "scores", "onturn", "history[0].player",
+			"board_layout", "variant", "challenge_rule", "size"})
+	is.Equal(schemaFields(t, `{"version": 2, "cgp": "x", "game_history": {},
+		"rack": "ABC", "lexicon": "NWL20"}`),
+		[]string{"game_history", "lexicon", "rack"})
+	is.Equal(schemaFields(t, `{"version": 2, "cgp": "x", "turn": 2}`), []string{"turn"})
+	is.Equal(schemaFields(t, `{"version": 2, "cgp": "x", "turn": 0}`), []string{"turn"})
+	is.Equal(schemaFields(t, `{"version": 2, "game_history": {}, "turn": -1}`), []string{"turn"})
+}
+
+func TestErrorJson(t *testing.T) {
+	is := is.New(t)
+	var b JsonBoard
+	is.NoErr(json.Unmarshal([]byte(`{"version": 2, "scores": [0, 0], "onturn": 5}`), &b))
+	err := fmt.Errorf("loading game failed: %w", b.validate(&turnplayer.GameOptions{}))
+	is.Equal(string(ErrorJson(err)), `{"error":"loading game failed: lexicon: is required\nonturn: must be 0 or 1",`+
+		`"fields":[{"field":"lexicon","reason":"is required"},{"field":"onturn","reason":"must be 0 or 1"}]}`)
+	is.Equal(string(ErrorJson(fmt.Errorf("oops"))), `{"error":"oops"}`)
+}
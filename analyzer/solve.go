@@ -0,0 +1,387 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/endgame/negamax"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/preendgame"
+	"github.com/domino14/macondo/progress"
+	"github.com/domino14/macondo/rangefinder"
+)
+
+// These defaults are the same as the shell's. The server uses them too.
+const (
+	DefaultEndgamePlies = 4
+	DefaultMaxSolutions = 30
+	DefaultInferSeconds = 5
+	MaxInferredLeaves   = 50
+)
+
+// ProgressInterval is how often the endgame, pre-endgame and inference
+// engines send progress events.
+const ProgressInterval = 250 * time.Millisecond
+
+var errBusy = errors.New("another analysis is running")
+
+// EndgameOptions are the options of the shell's endgame command. Zero
+// values, and options left out, take the shell's defaults.
+type EndgameOptions struct {
+	Plies     int  `json:"plies"`
+	MaxTime   int  `json:"maxtime"`
+	Threads   int  `json:"threads"`
+	DisableID bool `json:"disable_id"`
+	DisableTT bool `json:"disable_tt"`
+	FirstWin  bool `json:"first_win_optim"`
+}
+
+type EndgameResult struct {
+	Spread      int        `json:"spread"`
+	FinalSpread int        `json:"final_spread"`
+	Sequence    []JsonMove `json:"sequence"`
+}
+
+// PEGOptions are the options of the shell's peg command. Zero values take
+// the shell's defaults.
+type PEGOptions struct {
+	EndgamePlies    int      `json:"endgameplies"`
+	MaxTime         int      `json:"maxtime"`
+	Threads         int      `json:"threads"`
+	MaxSolutions    int      `json:"maxsolutions"`
+	OppRack         string   `json:"opprack"`
+	EarlyCutoff     bool     `json:"early_cutoff"`
+	SkipNonEmptying bool     `json:"skip_non_emptying"`
+	SkipLoss        bool     `json:"skip_loss"`
+	SkipTiebreaker  bool     `json:"skip_tiebreaker"`
+	DisableID       bool     `json:"disable_id"`
+	OnlySolve       []string `json:"only_solve"`
+}
+
+type PEGPlay struct {
+	Move    JsonMove `json:"move"`
+	WinProb float64  `json:"win_prob"`
+	Points  float32  `json:"points"`
+	Spread  int      `json:"spread"`
+	Ignored bool     `json:"ignored"`
+}
+
+type PEGResult struct {
+	Plays []PEGPlay `json:"plays"`
+	Stats string    `json:"stats"`
+}
+
+// InferOptions are the options of the shell's infer command. Zero values
+// take the shell's defaults.
+type InferOptions struct {
+	Threads int `json:"threads"`
+	Time    int `json:"time"`
+}
+
+type InferredLeave struct {
+	Leave string `json:"leave"`
+	Count int    `json:"count"`
+}
+
+type InferResult struct {
+	Inferences int             `json:"inferences"`
+	Leaves     []InferredLeave `json:"leaves"`
+	Stats      string          `json:"stats"`
+}
+
+// begin marks the analyzer as busy with an analysis, until end is called.
+func (an *Analyzer) begin() error {
+	if an.game == nil {
+		return errors.New("no position loaded")
+	}
+	if !an.busy.CompareAndSwap(false, true) {
+		return errBusy
+	}
+	return nil
+}
+
+func (an *Analyzer) end() {
+	an.busy.Store(false)
+}
+
+// idle returns an error if an endgame, pre-endgame or inference is
+// running, as these use the position that was last loaded.
+func (an *Analyzer) idle() error {
+	if an.busy.Load() {
+		return errBusy
+	}
+	return nil
+}
+
+func decodeOptions(opts []byte, v any) error {
+	if len(opts) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(opts, v); err != nil {
+		return fmt.Errorf("parse options failed: %w", err)
+	}
+	return nil
+}
+
+// WithMaxTime returns a context derived from ctx that is cancelled after the
+// given number of seconds, if that is positive.
+func WithMaxTime(ctx context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if seconds > 0 {
+		return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
+
+// EndgameSolve is an endgame that is set up to be solved. It is solved on
+// a copy of the game, with its own move generator, so that the game can
+// still be read while it is solved.
+type EndgameSolve struct {
+	*negamax.Solver
+	plies  int
+	spread int
+}
+
+// NewEndgameSolve sets up the endgame of g, with the options in o other
+// than MaxTime, which is left to the caller.
+func NewEndgameSolve(cfg *config.Config, g *game.Game, o EndgameOptions) (*EndgameSolve, error) {
+	if o.Plies == 0 {
+		o.Plies = DefaultEndgamePlies
+	}
+	if o.Threads == 0 {
+		o.Threads = min(max(runtime.NumCPU()-1, 1), negamax.MaxLazySMPThreads)
+	}
+	gd, err := kwg.Get(cfg.AllSettings(), g.LexiconName())
+	if err != nil {
+		return nil, err
+	}
+	gc := g.Copy()
+	gc.SetBackupMode(game.SimulationMode)
+	solver := new(negamax.Solver)
+	gen := movegen.NewGordonGenerator(gd, gc.Board(), gc.Bag().LetterDistribution())
+	if err := solver.Init(gen, gc); err != nil {
+		return nil, err
+	}
+	solver.SetIterativeDeepening(!o.DisableID)
+	solver.SetTranspositionTableOptim(!o.DisableTT)
+	solver.SetThreads(o.Threads)
+	solver.SetFirstWinOptim(o.FirstWin)
+	return &EndgameSolve{Solver: solver, plies: o.Plies, spread: g.CurrentSpread()}, nil
+}
+
+// Run solves the endgame. It returns how much spread the best sequence
+// gains for the player on turn, the spread after it, and the sequence.
+func (e *EndgameSolve) Run(ctx context.Context) (spread, finalSpread int, seq []*move.Move, err error) {
+	val, seq, err := e.Solve(ctx, e.plies)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return int(val), int(val) + e.spread, seq, nil
+}
+
+// Endgame solves the endgame of the position that was last loaded, with
+// options given as a JSON EndgameOptions. It sends its progress to l, if
+// that is not nil, and stops early if ctx is done.
+func (an *Analyzer) Endgame(ctx context.Context, opts []byte, l progress.Listener) ([]byte, error) {
+	if err := an.begin(); err != nil {
+		return nil, err
+	}
+	defer an.end()
+	var o EndgameOptions
+	if err := decodeOptions(opts, &o); err != nil {
+		return nil, err
+	}
+	ctx, cancel := WithMaxTime(ctx, o.MaxTime)
+	defer cancel()
+
+	solve, err := NewEndgameSolve(an.config, an.game.Game, o)
+	if err != nil {
+		return nil, err
+	}
+	solve.SetProgressListener(l, ProgressInterval)
+	spread, finalSpread, seq, err := solve.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := EndgameResult{Spread: spread, FinalSpread: finalSpread, Sequence: []JsonMove{}}
+	for _, m := range seq {
+		res.Sequence = append(res.Sequence, MakeJsonMove(m))
+	}
+	return json.Marshal(res)
+}
+
+// PEGSolve is a pre-endgame that is set up to be solved.
+type PEGSolve struct {
+	*preendgame.Solver
+	maxSolutions int
+}
+
+// NewPEGSolve sets up the pre-endgame of g, with the options in o other
+// than MaxTime, which is left to the caller.
+func NewPEGSolve(cfg *config.Config, g *bot.BotTurnPlayer, o PEGOptions) (*PEGSolve, error) {
+	if o.EndgamePlies == 0 {
+		o.EndgamePlies = DefaultEndgamePlies
+	}
+	if o.MaxSolutions == 0 {
+		o.MaxSolutions = DefaultMaxSolutions
+	}
+	movesToSolve := []*move.Move{}
+	for _, ms := range o.OnlySolve {
+		m, err := g.ParseMove(g.PlayerOnTurn(), false, strings.Fields(ms))
+		if err != nil {
+			return nil, err
+		}
+		movesToSolve = append(movesToSolve, m)
+	}
+	gd, err := kwg.Get(cfg.AllSettings(), g.LexiconName())
+	if err != nil {
+		return nil, err
+	}
+	solver := new(preendgame.Solver)
+	solver.Init(g.Game, gd)
+	if o.Threads != 0 {
+		solver.SetThreads(o.Threads)
+	}
+	if o.OppRack != "" {
+		r, err := tilemapping.ToMachineLetters(strings.ToUpper(o.OppRack), g.Alphabet())
+		if err != nil {
+			return nil, err
+		}
+		solver.SetKnownOppRack(r)
+	}
+	solver.SetEndgamePlies(o.EndgamePlies)
+	solver.SetEarlyCutoffOptim(o.EarlyCutoff)
+	solver.SetSkipNonEmptyingOptim(o.SkipNonEmptying)
+	solver.SetSkipTiebreaker(o.SkipTiebreaker)
+	solver.SetSkipLossOptim(o.SkipLoss)
+	solver.SetIterativeDeepening(!o.DisableID)
+	solver.SetSolveOnly(movesToSolve)
+	return &PEGSolve{Solver: solver, maxSolutions: o.MaxSolutions}, nil
+}
+
+// Run solves the pre-endgame, and returns the best plays, at most
+// MaxSolutions of them.
+func (p *PEGSolve) Run(ctx context.Context) ([]*preendgame.PreEndgamePlay, error) {
+	plays, err := p.Solve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return plays[:min(len(plays), p.maxSolutions)], nil
+}
+
+// PreEndgame solves the pre-endgame of the position that was last loaded,
+// with options given as a JSON PEGOptions. It sends its progress to l, if
+// that is not nil, and returns the best plays so far if ctx is done.
+func (an *Analyzer) PreEndgame(ctx context.Context, opts []byte, l progress.Listener) ([]byte, error) {
+	if err := an.begin(); err != nil {
+		return nil, err
+	}
+	defer an.end()
+	var o PEGOptions
+	if err := decodeOptions(opts, &o); err != nil {
+		return nil, err
+	}
+	ctx, cancel := WithMaxTime(ctx, o.MaxTime)
+	defer cancel()
+
+	solve, err := NewPEGSolve(an.config, an.game, o)
+	if err != nil {
+		return nil, err
+	}
+	solve.SetProgressListener(l, ProgressInterval)
+	plays, err := solve.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := PEGResult{Plays: []PEGPlay{}, Stats: solve.SolutionStats(len(plays))}
+	for _, p := range plays {
+		res.Plays = append(res.Plays, PEGPlay{
+			Move:    an.jsonMove(p.Play),
+			WinProb: p.WinProb(),
+			Points:  p.Points,
+			Spread:  p.Spread,
+			Ignored: p.Ignore,
+		})
+	}
+	return json.Marshal(res)
+}
+
+// Infer infers what the opponent kept after their last move, which must
+// be in the history of the position that was last loaded. Its options
+// are given as a JSON InferOptions. It sends its progress to l, if that
+// is not nil, and stops early if ctx is done.
+func (an *Analyzer) Infer(ctx context.Context, opts []byte, l progress.Listener) ([]byte, error) {
+	if err := an.begin(); err != nil {
+		return nil, err
+	}
+	defer an.end()
+	var o InferOptions
+	if err := decodeOptions(opts, &o); err != nil {
+		return nil, err
+	}
+	if o.Time == 0 {
+		o.Time = DefaultInferSeconds
+	}
+	ctx, cancel := WithMaxTime(ctx, o.Time)
+	defer cancel()
+
+	g := an.game
+	c, err := equity.NewCombinedStaticCalculator(
+		g.LexiconName(), an.config, "", equity.PEGAdjustmentFilename)
+	if err != nil {
+		return nil, err
+	}
+	rf := &rangefinder.RangeFinder{}
+	rf.Init(g.Game, []equity.EquityCalculator{c}, an.config)
+	if o.Threads != 0 {
+		rf.SetThreads(o.Threads)
+	}
+	rf.SetProgressListener(l, ProgressInterval)
+	err = rf.PrepareFinder(g.RackFor(g.PlayerOnTurn()).TilesOn())
+	if err != nil {
+		return nil, err
+	}
+	if err := rf.Infer(ctx); err != nil {
+		return nil, err
+	}
+
+	inferences := rf.Inferences()
+	res := InferResult{Inferences: len(inferences), Leaves: CountLeaves(inferences, g.Alphabet()),
+		Stats: rf.AnalyzeInferences(false)}
+	return json.Marshal(res)
+}
+
+// CountLeaves returns how often each leave was inferred, most often first,
+// keeping at most MaxInferredLeaves of them.
+func CountLeaves(inferences [][]tilemapping.MachineLetter, alph *tilemapping.TileMapping) []InferredLeave {
+	counts := map[string]int{}
+	for _, inf := range inferences {
+		mw := tilemapping.MachineWord(slices.Clone(inf))
+		slices.Sort(mw)
+		counts[mw.UserVisible(alph)]++
+	}
+	leaves := []InferredLeave{}
+	for leave, ct := range counts {
+		leaves = append(leaves, InferredLeave{Leave: leave, Count: ct})
+	}
+	slices.SortFunc(leaves, func(a, b InferredLeave) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Leave, b.Leave)
+	})
+	return leaves[:min(len(leaves), MaxInferredLeaves)]
+}
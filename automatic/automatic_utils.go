@@ -14,6 +14,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/domino14/word-golib/tilemapping"
 	"github.com/rs/zerolog/log"
 	"github.com/samber/lo"
 	"golang.org/x/sync/errgroup"
@@ -54,7 +55,11 @@ func (r *GameRunner) CompVsCompStatic(addToHistory bool) error {
 }
 
 func (r *GameRunner) playFull(addToHistory bool, gidx int) error {
-	r.StartGame(gidx)
+	return r.playFullWithBag(addToHistory, gidx, nil)
+}
+
+func (r *GameRunner) playFullWithBag(addToHistory bool, gidx int, bag *tilemapping.Bag) error {
+	r.StartGameWithBag(gidx, bag)
 	log.Trace().Msgf("playing full, game %v", r.game.History().Uid)
 
 	for r.game.Playing() == pb.PlayState_PLAYING {
@@ -76,6 +81,9 @@ func (r *GameRunner) playFull(addToHistory bool, gidx int) error {
 			r.game.FirstPlayer().RealName,
 		)
 	}
+	if r.datachan != nil {
+		r.finishGameRecords()
+	}
 	return nil
 }
 
@@ -110,12 +118,17 @@ func playerNames(players []AutomaticRunnerPlayer) []string {
 	return names
 }
 
+// PlayerNames returns unique display names for the given players.
+func PlayerNames(players []AutomaticRunnerPlayer) []string {
+	return playerNames(players)
+}
+
 type Job struct{ gidx int }
 
 func StartCompVCompStaticGames(ctx context.Context, cfg *config.Config,
 	numGames int, block bool, threads int,
 	outputFilename, lexicon, letterDistribution string,
-	players []AutomaticRunnerPlayer) error {
+	players []AutomaticRunnerPlayer, dataset *DatasetOptions) error {
 
 	if len(players) != 2 {
 		return errors.New("must have two players")
@@ -144,6 +157,16 @@ func StartCompVCompStaticGames(ctx context.Context, cfg *config.Config,
 		return err
 	}
 
+	var dataChan chan *pb.TrainingRecord
+	var dataWriter *DatasetWriter
+	if dataset != nil {
+		if dataset.NumCandidates <= 0 {
+			dataset.NumCandidates = DefaultDatasetCandidates
+		}
+		dataChan = make(chan *pb.TrainingRecord, 100)
+		dataWriter = NewDatasetWriter(*dataset)
+	}
+
 	log.Info().Msgf("Starting %v games, %v threads", numGames, threads)
 
 	CVCCounter.Set(0)
@@ -168,6 +191,10 @@ func StartCompVCompStaticGames(ctx context.Context, cfg *config.Config,
 			defer wg.Done()
 			r := GameRunner{logchan: logChan, gamechan: gameChan,
 				config: cfg, lexicon: lexicon, letterDistribution: letterDistribution}
+			if dataset != nil {
+				r.datachan = dataChan
+				r.numCandidates = dataset.NumCandidates
+			}
 			err := r.Init(players)
 			if err != nil {
 				log.Err(err).Msg("error initializing runner")
@@ -219,6 +246,9 @@ func StartCompVCompStaticGames(ctx context.Context, cfg *config.Config,
 		log.Info().Msg("All games finished.")
 		close(logChan)
 		close(gameChan)
+		if dataChan != nil {
+			close(dataChan)
+		}
 		log.Info().Msg("Exiting feeder subroutine!")
 		return ctx.Err()
 	})
@@ -247,6 +277,26 @@ func StartCompVCompStaticGames(ctx context.Context, cfg *config.Config,
 		return nil
 	})
 
+	if dataChan != nil {
+		g.Go(func() error {
+			for rec := range dataChan {
+				if err := dataWriter.Write(rec); err != nil {
+					log.Err(err).Msg("error-writing-dataset")
+					// Returning stops the games; keep taking their records
+					// so that the ones still being played can finish.
+					go func() {
+						for range dataChan {
+						}
+					}()
+					dataWriter.Close()
+					return err
+				}
+			}
+			log.Info().Int("shards", dataWriter.shard+1).Msg("Exiting dataset writer goroutine!")
+			return dataWriter.Close()
+		})
+	}
+
 	if block {
 		err = g.Wait()
 		return err
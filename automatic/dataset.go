@@ -0,0 +1,217 @@
+package automatic
+
+// Training-data export. Each position in a self-play game is written out
+// as a single record, once the game is over and its result is known.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/domino14/macondo/ai/bot"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+)
+
+const DefaultDatasetCandidates = 10
+
+type DatasetFormat int
+
+const (
+	// DatasetJSONL writes one JSON object per line.
+	DatasetJSONL DatasetFormat = iota
+	// DatasetProtobuf writes size-delimited TrainingRecord messages.
+	DatasetProtobuf
+)
+
+func (f DatasetFormat) extension() string {
+	if f == DatasetProtobuf {
+		return "pb"
+	}
+	return "jsonl"
+}
+
+// ParseDatasetFormat parses a user-visible dataset format name.
+func ParseDatasetFormat(s string) (DatasetFormat, error) {
+	switch s {
+	case "", "jsonl", "json":
+		return DatasetJSONL, nil
+	case "pb", "protobuf":
+		return DatasetProtobuf, nil
+	}
+	return 0, fmt.Errorf("dataset format %v not recognized", s)
+}
+
+// DatasetOptions configures training-data export for automatic games.
+type DatasetOptions struct {
+	// Prefix is the path prefix of every shard. Shards are named
+	// <Prefix>-00000.jsonl, <Prefix>-00001.jsonl, and so on.
+	Prefix string
+	Format DatasetFormat
+	// RecordsPerShard is the maximum number of records per shard file.
+	// If it is 0, all records go into a single shard.
+	RecordsPerShard int
+	// NumCandidates is the number of candidate plays to record per position.
+	NumCandidates int
+}
+
+// DatasetWriter writes training records into one or more shard files.
+type DatasetWriter struct {
+	opts    DatasetOptions
+	shard   int
+	written int
+	file    *os.File
+	w       *bufio.Writer
+	jsonOpt protojson.MarshalOptions
+}
+
+func NewDatasetWriter(opts DatasetOptions) *DatasetWriter {
+	return &DatasetWriter{
+		opts:    opts,
+		jsonOpt: protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+	}
+}
+
+// ShardFilename returns the filename for the shard with the given index.
+func (d *DatasetWriter) ShardFilename(idx int) string {
+	return fmt.Sprintf("%s-%05d.%s", d.opts.Prefix, idx, d.opts.Format.extension())
+}
+
+func (d *DatasetWriter) rotate() error {
+	if d.file != nil {
+		if err := d.closeShard(); err != nil {
+			return err
+		}
+		d.shard++
+	}
+	f, err := os.Create(d.ShardFilename(d.shard))
+	if err != nil {
+		return err
+	}
+	d.file = f
+	d.w = bufio.NewWriter(f)
+	d.written = 0
+	return nil
+}
+
+func (d *DatasetWriter) closeShard() error {
+	if err := d.w.Flush(); err != nil {
+		return err
+	}
+	err := d.file.Close()
+	d.file = nil
+	d.w = nil
+	return err
+}
+
+// Write writes a single record, starting a new shard if needed. As the
+// shard is buffered, an error writing it may only be returned by a later
+// Write, or by Close.
+func (d *DatasetWriter) Write(rec *pb.TrainingRecord) error {
+	if d.file == nil || (d.opts.RecordsPerShard > 0 && d.written >= d.opts.RecordsPerShard) {
+		if err := d.rotate(); err != nil {
+			return err
+		}
+	}
+	switch d.opts.Format {
+	case DatasetProtobuf:
+		if _, err := protodelim.MarshalTo(d.w, rec); err != nil {
+			return err
+		}
+	default:
+		bts, err := d.jsonOpt.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		if _, err := d.w.Write(bts); err != nil {
+			return err
+		}
+		if err := d.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	d.written++
+	return nil
+}
+
+// Close flushes and closes the current shard.
+func (d *DatasetWriter) Close() error {
+	if d.file == nil {
+		return nil
+	}
+	return d.closeShard()
+}
+
+type candidateGenerator interface {
+	GenerateMoves(numPlays int) []*move.Move
+}
+
+// positionRecord creates a training record for the position the player
+// on turn is currently facing. It must be called before the player moves.
+func (r *GameRunner) positionRecord(playerIdx int) *pb.TrainingRecord {
+	rec := &pb.TrainingRecord{
+		GameId: r.game.Uid(),
+		Turn:   int32(r.game.Turn()),
+		Cgp:    r.game.ToCGP(false),
+		OnTurn: int32(playerIdx),
+	}
+	cg, ok := r.aiplayers[playerIdx].(candidateGenerator)
+	if !ok {
+		return rec
+	}
+	// GenBestStaticTurn may have left a top-play-only recorder in place.
+	r.aiplayers[playerIdx].MoveGenerator().SetPlayRecorder(movegen.AllPlaysRecorder)
+	for _, m := range cg.GenerateMoves(r.numCandidates) {
+		rec.Candidates = append(rec.Candidates, &pb.TrainingCandidate{
+			Play:   m.ShortDescription(),
+			Score:  int32(m.Score()),
+			Equity: m.Equity(),
+		})
+	}
+	return rec
+}
+
+// addSimResults replaces the candidates of the record with the plays the
+// bot just simmed, if it simmed at all.
+func (r *GameRunner) addSimResults(rec *pb.TrainingRecord, playerIdx int) {
+	btp, ok := r.aiplayers[playerIdx].(*bot.BotTurnPlayer)
+	if !ok {
+		return
+	}
+	simmed := btp.LastSimmedPlays()
+	if len(simmed) == 0 {
+		return
+	}
+	rec.Candidates = rec.Candidates[:0]
+	for _, sp := range simmed {
+		m := sp.Move()
+		rec.Candidates = append(rec.Candidates, &pb.TrainingCandidate{
+			Play:   m.ShortDescription(),
+			Score:  int32(m.Score()),
+			Equity: m.Equity(),
+			WinPct: sp.WinProb(),
+			Simmed: true,
+		})
+	}
+}
+
+// finishGameRecords fills in the final result of every pending record and
+// sends them off to the dataset channel.
+func (r *GameRunner) finishGameRecords() {
+	for _, rec := range r.pendingRecords {
+		spread := r.game.SpreadFor(int(rec.OnTurn))
+		rec.FinalSpread = int32(spread)
+		switch {
+		case spread > 0:
+			rec.FinalResult = 1
+		case spread == 0:
+			rec.FinalResult = 0.5
+		}
+		r.datachan <- rec
+	}
+	r.pendingRecords = nil
+}
@@ -0,0 +1,107 @@
+package automatic
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+func testRecords(n int) []*pb.TrainingRecord {
+	recs := make([]*pb.TrainingRecord, n)
+	for i := range recs {
+		recs[i] = &pb.TrainingRecord{
+			GameId: "abc",
+			Turn:   int32(i),
+			Cgp:    "15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL20;",
+			Candidates: []*pb.TrainingCandidate{
+				{Play: "8D RETAINS", Score: 66, Equity: 66, WinPct: 0.65, Simmed: true},
+			},
+			Chosen:      "8D RETAINS",
+			FinalSpread: -12,
+		}
+	}
+	return recs
+}
+
+func TestDatasetWriterJSONLShards(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	w := NewDatasetWriter(DatasetOptions{
+		Prefix:          filepath.Join(dir, "data"),
+		Format:          DatasetJSONL,
+		RecordsPerShard: 2,
+	})
+	for _, rec := range testRecords(5) {
+		is.NoErr(w.Write(rec))
+	}
+	is.NoErr(w.Close())
+
+	turns := []int32{}
+	for shard, expected := range []int{2, 2, 1} {
+		f, err := os.Open(w.ShardFilename(shard))
+		is.NoErr(err)
+		sc := bufio.NewScanner(f)
+		lines := 0
+		for sc.Scan() {
+			rec := &pb.TrainingRecord{}
+			is.NoErr(protojson.Unmarshal(sc.Bytes(), rec))
+			is.Equal(rec.Candidates[0].Play, "8D RETAINS")
+			is.Equal(rec.FinalResult, 0.0)
+			turns = append(turns, rec.Turn)
+			lines++
+		}
+		f.Close()
+		is.Equal(lines, expected)
+	}
+	is.Equal(turns, []int32{0, 1, 2, 3, 4})
+	_, err := os.Stat(w.ShardFilename(3))
+	is.True(os.IsNotExist(err))
+}
+
+func TestDatasetWriterProtobuf(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	w := NewDatasetWriter(DatasetOptions{
+		Prefix: filepath.Join(dir, "data"),
+		Format: DatasetProtobuf,
+	})
+	for _, rec := range testRecords(3) {
+		is.NoErr(w.Write(rec))
+	}
+	is.NoErr(w.Close())
+	is.Equal(filepath.Ext(w.ShardFilename(0)), ".pb")
+
+	f, err := os.Open(w.ShardFilename(0))
+	is.NoErr(err)
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for i := 0; i < 3; i++ {
+		rec := &pb.TrainingRecord{}
+		is.NoErr(protodelim.UnmarshalFrom(r, rec))
+		is.Equal(rec.Turn, int32(i))
+		is.Equal(rec.FinalSpread, int32(-12))
+	}
+}
+
+func TestDatasetWriterError(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	w := NewDatasetWriter(DatasetOptions{Prefix: filepath.Join(dir, "data")})
+	// A shard that cannot be written to, as on a full disk.
+	f, err := os.Create(filepath.Join(dir, "readonly"))
+	is.NoErr(err)
+	f.Close()
+	f, err = os.Open(f.Name())
+	is.NoErr(err)
+	w.file = f
+	w.w = bufio.NewWriterSize(f, 16)
+	is.True(w.Write(testRecords(1)[0]) != nil)
+	is.True(w.Close() != nil)
+}
@@ -37,8 +37,13 @@ type GameRunner struct {
 	config             *config.Config
 	logchan            chan string
 	gamechan           chan string
+	datachan           chan *pb.TrainingRecord
+	numCandidates      int
+	pendingRecords     []*pb.TrainingRecord
 	aiplayers          [2]aiturnplayer.AITurnPlayer
 	order              [2]int
+	// seed, if non-zero, seeds game gidx with seed+gidx.
+	seed uint64
 }
 
 // NewGameRunner just instantiates and initializes a game runner.
@@ -113,10 +118,17 @@ func (r *GameRunner) Init(players []AutomaticRunnerPlayer) error {
 		r.aiplayers[idx] = btp
 	}
 	r.order = [2]int{0, 1}
+	r.seed = r.config.GetUint64(config.ConfigSeed)
 	return nil
 }
 
 func (r *GameRunner) StartGame(gidx int) {
+	r.StartGameWithBag(gidx, nil)
+}
+
+// StartGameWithBag is like StartGame, but it deals from the given bag
+// instead of a freshly shuffled one. If bag is nil, a new bag is used.
+func (r *GameRunner) StartGameWithBag(gidx int, bag *tilemapping.Bag) {
 	// r.order must be {0, 1} if gidx is even, and {1, 0} if odd
 	flip := false
 	if gidx%2 == 1 {
@@ -134,7 +146,14 @@ func (r *GameRunner) StartGame(gidx int) {
 		r.aiplayers[0], r.aiplayers[1] = r.aiplayers[1], r.aiplayers[0]
 		r.order[0], r.order[1] = r.order[1], r.order[0]
 	}
-	r.game.StartGame()
+	if r.seed != 0 {
+		r.game.SetSeed(r.seed + uint64(gidx))
+	}
+	if bag == nil {
+		r.game.StartGame()
+	} else {
+		r.game.StartGameWithBag(bag)
+	}
 }
 
 func (r *GameRunner) Game() *game.Game {
@@ -166,7 +185,16 @@ func (r *GameRunner) genBestMoveForBot(playerIdx int) *move.Move {
 
 // PlayBestTurn generates the best move for the player and plays it on the board.
 func (r *GameRunner) PlayBestTurn(playerIdx int, addToHistory bool) error {
+	var rec *pb.TrainingRecord
+	if r.datachan != nil {
+		rec = r.positionRecord(playerIdx)
+	}
 	bestPlay := r.genBestMoveForBot(playerIdx)
+	if rec != nil {
+		r.addSimResults(rec, playerIdx)
+		rec.Chosen = bestPlay.ShortDescription()
+		r.pendingRecords = append(r.pendingRecords, rec)
+	}
 	// save rackLetters for logging.
 	rackLetters := r.game.RackLettersFor(playerIdx)
 	tilesRemaining := r.game.Bag().TilesRemaining()
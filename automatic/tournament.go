@@ -0,0 +1,255 @@
+package automatic
+
+// A tournament runner for bot-vs-bot matches. Every pair of players plays
+// a series of game pairs. Both games in a pair are dealt from the same
+// tile order, with the players switching seats, to reduce the effect of
+// luck on the result.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/stats"
+)
+
+// pairSeedMultiplier spreads out the seeds for the tile orders of
+// consecutive pairs.
+const pairSeedMultiplier = 0x9e3779b97f4a7c15
+
+type pairJob struct {
+	match int
+	pair  int
+}
+
+// TournamentPlayer is a single entrant in a tournament.
+type TournamentPlayer struct {
+	Name string
+	AutomaticRunnerPlayer
+}
+
+type TournamentOptions struct {
+	Lexicon            string
+	LetterDistribution string
+	// PairsPerMatch is the maximum number of game pairs each two players
+	// play against each other.
+	PairsPerMatch int
+	Threads       int
+	// SPRT, if not nil, stops a match as soon as the test is conclusive.
+	SPRT *stats.SPRT
+	// Seed, if non-zero, makes the tile orders and every game repeatable.
+	// See game.SetSeed.
+	Seed uint64
+}
+
+// MatchResult holds the results of a match, from the point of view
+// of the first player.
+type MatchResult struct {
+	sync.Mutex
+	Player1 string
+	Player2 string
+	Wins    int
+	Draws   int
+	Losses  int
+	Spread  stats.Statistic
+	SPRT    stats.SPRTResult
+
+	players [2]AutomaticRunnerPlayer
+}
+
+func (m *MatchResult) Games() int {
+	return m.Wins + m.Draws + m.Losses
+}
+
+// Elo returns the Elo difference between the two players of the match,
+// along with a 95% confidence interval.
+func (m *MatchResult) Elo() (float64, float64, float64) {
+	return stats.EloDifference(m.Wins, m.Draws, m.Losses, stats.Z95)
+}
+
+func (m *MatchResult) addGame(spread int) {
+	switch {
+	case spread > 0:
+		m.Wins++
+	case spread < 0:
+		m.Losses++
+	default:
+		m.Draws++
+	}
+	m.Spread.Push(float64(spread))
+}
+
+func (m *MatchResult) decided() bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.SPRT != stats.SPRTContinue
+}
+
+func (m *MatchResult) String() string {
+	m.Lock()
+	defer m.Unlock()
+	elo, lo, hi := m.Elo()
+	s := fmt.Sprintf("%s vs %s: +%d =%d -%d (%d games), spread %.2f±%.2f, Elo %.1f [%.1f, %.1f]",
+		m.Player1, m.Player2, m.Wins, m.Draws, m.Losses, m.Games(),
+		m.Spread.Mean(), m.Spread.StandardError(stats.Z95), elo, lo, hi)
+	if m.SPRT != stats.SPRTContinue {
+		s += ", SPRT: " + m.SPRT.String()
+	}
+	return s
+}
+
+// RunTournament plays a round robin between all the given players and
+// returns the result of every match. It blocks until all games are done,
+// or until the context is cancelled.
+func RunTournament(ctx context.Context, cfg *config.Config, players []TournamentPlayer,
+	opts TournamentOptions) ([]*MatchResult, error) {
+
+	if len(players) < 2 {
+		return nil, errors.New("need at least two players")
+	}
+	if opts.Threads < 1 {
+		return nil, errors.New("need at least one thread")
+	}
+	if opts.PairsPerMatch < 1 {
+		return nil, errors.New("need at least one game pair per match")
+	}
+	if opts.Threads > 1 && lo.SomeBy(players, func(p TournamentPlayer) bool {
+		return bot.HasEndgame(p.BotCode) || bot.HasPreendgame(p.BotCode)
+	}) {
+		return nil, errors.New("cannot run multiple games in parallel if any player uses endgame or pre-endgame")
+	}
+	names := lo.Map(players, func(p TournamentPlayer, _ int) string { return p.Name })
+	if len(lo.Uniq(names)) != len(names) {
+		return nil, errors.New("player names must be unique")
+	}
+	ld, err := tilemapping.GetDistribution(cfg.AllSettings(), opts.LetterDistribution)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*MatchResult{}
+	for i := 0; i < len(players); i++ {
+		for j := i + 1; j < len(players); j++ {
+			matches = append(matches, &MatchResult{
+				Player1: players[i].Name,
+				Player2: players[j].Name,
+				players: [2]AutomaticRunnerPlayer{
+					players[i].AutomaticRunnerPlayer, players[j].AutomaticRunnerPlayer},
+			})
+		}
+	}
+	addToHistory := lo.SomeBy(players, func(p TournamentPlayer) bool {
+		return bot.HasInfer(p.BotCode)
+	})
+
+	log.Info().Int("matches", len(matches)).Int("pairsPerMatch", opts.PairsPerMatch).
+		Int("threads", opts.Threads).Msg("starting-tournament")
+
+	jobs := make(chan pairJob, opts.Threads*5)
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		defer close(jobs)
+		// Interleave the matches so that they all progress at the same rate.
+		for p := 0; p < opts.PairsPerMatch; p++ {
+			for m := range matches {
+				select {
+				case jobs <- pairJob{m, p}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		return nil
+	})
+
+	for t := 0; t < opts.Threads; t++ {
+		g.Go(func() error {
+			// Each thread keeps one runner per match.
+			runners := map[int]*GameRunner{}
+			for j := range jobs {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				match := matches[j.match]
+				if match.decided() {
+					continue
+				}
+				r, ok := runners[j.match]
+				if !ok {
+					r = &GameRunner{config: cfg, lexicon: opts.Lexicon,
+						letterDistribution: opts.LetterDistribution}
+					if err := r.Init(match.players[:]); err != nil {
+						return err
+					}
+					if opts.Seed != 0 {
+						r.seed = opts.Seed + uint64(j.match)<<32
+					}
+					runners[j.match] = r
+				}
+				spreads, err := r.playPair(addToHistory, ld, j.pair)
+				if err != nil {
+					return err
+				}
+				match.Lock()
+				for _, s := range spreads {
+					match.addGame(s)
+				}
+				if opts.SPRT != nil {
+					match.SPRT = opts.SPRT.Test(match.Wins, match.Draws, match.Losses)
+				}
+				match.Unlock()
+			}
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return matches, err
+	}
+	return matches, nil
+}
+
+// playPair plays two games from the same tile order, with the players
+// switching seats. It returns the final spread of each game from the point
+// of view of the first player passed to Init.
+func (r *GameRunner) playPair(addToHistory bool, ld *tilemapping.LetterDistribution, pair int) ([2]int, error) {
+	var spreads [2]int
+	var bag *tilemapping.Bag
+	if r.seed != 0 {
+		bag = game.NewSeededBag(ld, r.seed^(uint64(pair)*pairSeedMultiplier))
+	} else {
+		bag = ld.MakeBag()
+		bag.SetFixedOrder(true)
+	}
+	for i := 0; i < 2; i++ {
+		err := r.playFullWithBag(addToHistory, 2*pair+i, bag.Copy())
+		if err != nil {
+			return spreads, err
+		}
+		spreads[i] = r.game.PointsForNick("p1") - r.game.PointsForNick("p2")
+	}
+	return spreads, nil
+}
+
+// TournamentReport returns a human-readable summary of the tournament.
+func TournamentReport(results []*MatchResult) string {
+	var sb strings.Builder
+	for _, m := range results {
+		sb.WriteString(m.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
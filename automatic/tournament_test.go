@@ -0,0 +1,92 @@
+package automatic
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/stats"
+)
+
+func TestRunTournament(t *testing.T) {
+	is := is.New(t)
+	players := []TournamentPlayer{
+		{"HastyBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_HASTY_BOT, 0}},
+		{"NoLeaveBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_NO_LEAVE_BOT, 0}},
+		{"HastyBot1", AutomaticRunnerPlayer{"", "", macondo.BotRequest_HASTY_BOT, 0}},
+	}
+	results, err := RunTournament(context.Background(), &DefaultConfig, players,
+		TournamentOptions{
+			Lexicon:            "NWL20",
+			LetterDistribution: "English",
+			PairsPerMatch:      20,
+			Threads:            4,
+		})
+	is.NoErr(err)
+	is.Equal(len(results), 3)
+	for _, r := range results {
+		is.Equal(r.Games(), 40)
+		is.Equal(r.SPRT, stats.SPRTContinue)
+	}
+	is.Equal(results[0].Player1, "HastyBot")
+	is.Equal(results[0].Player2, "NoLeaveBot")
+	is.Equal(results[2].Player1, "NoLeaveBot")
+	is.Equal(results[2].Player2, "HastyBot1")
+}
+
+func TestTournamentSPRTStopsEarly(t *testing.T) {
+	is := is.New(t)
+	players := []TournamentPlayer{
+		{"HastyBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_HASTY_BOT, 0}},
+		{"NoLeaveBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_NO_LEAVE_BOT, 0}},
+	}
+	results, err := RunTournament(context.Background(), &DefaultConfig, players,
+		TournamentOptions{
+			Lexicon:            "NWL20",
+			LetterDistribution: "English",
+			PairsPerMatch:      5000,
+			Threads:            1,
+			SPRT:               &stats.SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05},
+		})
+	is.NoErr(err)
+	is.Equal(results[0].SPRT, stats.SPRTAcceptH1)
+	is.True(results[0].Games() < 10000)
+}
+
+func TestTournamentBadOptions(t *testing.T) {
+	is := is.New(t)
+	players := []TournamentPlayer{
+		{"HastyBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_HASTY_BOT, 0}},
+		{"HastyBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_HASTY_BOT, 0}},
+	}
+	_, err := RunTournament(context.Background(), &DefaultConfig, players,
+		TournamentOptions{PairsPerMatch: 1, Threads: 1})
+	is.Equal(err.Error(), "player names must be unique")
+}
+
+func TestSeededTournamentIsRepeatable(t *testing.T) {
+	is := is.New(t)
+	players := []TournamentPlayer{
+		{"HastyBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_HASTY_BOT, 0}},
+		{"NoLeaveBot", AutomaticRunnerPlayer{"", "", macondo.BotRequest_NO_LEAVE_BOT, 0}},
+	}
+	run := func(threads int) *MatchResult {
+		results, err := RunTournament(context.Background(), &DefaultConfig, players,
+			TournamentOptions{
+				Lexicon:            "NWL20",
+				LetterDistribution: "English",
+				PairsPerMatch:      10,
+				Threads:            threads,
+				Seed:               99,
+			})
+		is.NoErr(err)
+		return results[0]
+	}
+	// Each game only depends on the seed, not on which thread played it.
+	r1, r4 := run(1), run(4)
+	is.Equal([3]int{r1.Wins, r1.Draws, r1.Losses}, [3]int{r4.Wins, r4.Draws, r4.Losses})
+	is.True(math.Abs(r1.Spread.Mean()-r4.Spread.Mean()) < 1e-9)
+}
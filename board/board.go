@@ -846,9 +846,11 @@ func (g *GameBoard) formedCrossWord(crossVertical bool, letter tilemapping.Machi
 // ScoreWord scores the move at the given row and column. Note that this
 // function is called when the board is potentially transposed, so we
 // assume the row stays static as we iterate through the letters of the
-// word.
+// word. The bingo bonus comes from the rule set, or from the default rule
+// set of the variant if rs is nil.
 func (g *GameBoard) ScoreWord(word tilemapping.MachineWord, row, col, tilesPlayed int,
-	crossDir BoardDirection, ld *tilemapping.LetterDistribution, va variant.Variant, lex lexicon.Lexicon) int {
+	crossDir BoardDirection, ld *tilemapping.LetterDistribution, va variant.Variant,
+	rs *variant.RuleSet, lex lexicon.Lexicon) int {
 
 	isGmo := va == variant.VarGmo
 
@@ -858,8 +860,12 @@ func (g *GameBoard) ScoreWord(word tilemapping.MachineWord, row, col, tilesPlaye
 	mainWordScore := 0
 	crossScores := 0
 	bingoBonus := 0
-	if tilesPlayed == 7 {
-		bingoBonus = va.GetBingoBonus()
+	if rs == nil {
+		def := va.DefaultRuleSet()
+		rs = &def
+	}
+	if rs.IsBingo(tilesPlayed) {
+		bingoBonus = rs.BingoBonus
 	}
 	wordMultiplier := 1
 	if isGmo {
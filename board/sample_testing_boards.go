@@ -495,6 +495,14 @@ maven: Turn 23
    `
 )
 
+// EnglishSampleBoards are all the sample boards above that use the English
+// letter distribution.
+var EnglishSampleBoards = []VsWho{
+	VsEd, VsMatt, VsJeremy, VsOxy, VsMatt2, VsRoy, VsMacondo1, JDvsNB,
+	VsAlec, VsAlec2, VsJoey, VsCanik, JoeVsPaul, VsJoel, EldarVsNigel,
+	TestDupe, NoahVsMishu, NoahVsMishu2, NoahVsMishu3, MavenVsMacondo,
+}
+
 // SetToGame sets the board to a specific game in progress. It is used to
 // generate test cases.
 func (b *GameBoard) SetToGame(alph *tilemapping.TileMapping, game VsWho) *TilesInPlay {
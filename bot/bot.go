@@ -21,13 +21,12 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/domino14/macondo/ai/bot"
-	"github.com/domino14/macondo/board"
 	mcfg "github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/equity"
 	"github.com/domino14/macondo/game"
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/turnplayer"
-	"github.com/domino14/macondo/variant"
 )
 
 type LambdaEvent struct {
@@ -35,6 +34,9 @@ type LambdaEvent struct {
 	BotType      int    `json:"botType"`
 	ReplyChannel string `json:"replyChannel"`
 	GameID       string `json:"gameId"`
+	// Render, if set to svg or png, makes the lambda draw the position
+	// instead of playing a move. A png is returned base64-encoded.
+	Render string `json:"render,omitempty"`
 }
 
 var (
@@ -57,6 +59,7 @@ type Bot struct {
 	game         *bot.BotTurnPlayer
 	awscfg       aws.Config
 	lambdaClient *lambda.Client
+	moveSource   MoveSource
 }
 
 func NewBot(cfg *mcfg.Config, options *turnplayer.GameOptions) *Bot {
@@ -65,8 +68,14 @@ func NewBot(cfg *mcfg.Config, options *turnplayer.GameOptions) *Bot {
 	bot.options = options
 	bot.game = nil
 
-	ctx := context.Background()
 	var err error
+	bot.moveSource, err = NewMoveSource(cfg)
+	if err != nil {
+		log.Err(err).Msg("using-native-move-source")
+		bot.moveSource = NativeMoveSource{}
+	}
+
+	ctx := context.Background()
 	bot.awscfg, err = config.LoadDefaultConfig(ctx)
 	if err != nil {
 		log.Err(err).Msg("loading-aws-default-cfg")
@@ -132,7 +141,8 @@ func evalSingleMove(g *bot.BotTurnPlayer, evtIdx int) *pb.SingleEvaluation {
 	moves := g.GenerateMoves(100000)
 	// find the played move in the list of moves
 	topEquity := moves[0].Equity()
-	topIsBingo := moves[0].TilesPlayed() == game.RackTileLimit && moves[0].Action() == move.MoveTypePlay
+	rs := g.Rules().RuleSet()
+	topIsBingo := rs.IsBingo(moves[0].TilesPlayed()) && moves[0].Action() == move.MoveTypePlay
 	foundEquity := float64(0)
 	playedBingo := false
 	hasStarPlay := false
@@ -153,7 +163,7 @@ func evalSingleMove(g *bot.BotTurnPlayer, evtIdx int) *pb.SingleEvaluation {
 				}
 				// Same move
 				foundEquity = m.Equity()
-				playedBingo = m.TilesPlayed() == game.RackTileLimit && m.Action() == move.MoveTypePlay
+				playedBingo = rs.IsBingo(m.TilesPlayed()) && m.Action() == move.MoveTypePlay
 				break
 			}
 		}
@@ -200,12 +210,10 @@ func (b *Bot) handle(data []byte) *pb.BotResponse {
 	evalReq := req.EvaluationRequest
 	botType := req.BotType
 
-	leavesFile := ""
-	if ng.History().BoardLayout == board.SuperCrosswordGameLayout {
-		leavesFile = "super-leaves.klv2"
-	}
+	leavesFile := equity.LeavesFilenameFor(ng.History().BoardLayout, ng.Rules().Variant())
 
-	conf := &bot.BotConfig{Config: *b.config, LeavesFile: leavesFile}
+	conf := &bot.BotConfig{Config: *b.config, LeavesFile: leavesFile,
+		Personality: req.Personality, PhonyOptions: req.PhonyOptions}
 	g, err := bot.NewBotTurnPlayerFromGame(ng, conf, botType)
 	if err != nil {
 		return errorResponse("Could not create AI player", err)
@@ -218,19 +226,10 @@ func (b *Bot) handle(data []byte) *pb.BotResponse {
 		// Generate all possible moves.
 		return b.evaluationResponse(evalReq)
 	}
-	isWordSmog := g.Rules().Variant() == variant.VarWordSmog || g.Rules().Variant() == variant.VarWordSmogSuper
-	// TODO: use this
-	// isGmo := g.Rules().Variant() == variant.VarGmo
-	// See if we need to challenge the last move
-	valid := true
-	if g.LastEvent() != nil && g.LastEvent().Type == pb.GameEvent_TILE_PLACEMENT_MOVE {
-		err = g.ValidateWords(g.Lexicon(), g.LastWordsFormed())
-		valid = (err == nil)
-	}
-
 	var m *move.Move
 
-	if !valid {
+	// See if we need to challenge the last move
+	if g.ShouldChallenge() {
 		m, _ = g.NewChallengeMove(g.PlayerOnTurn())
 	} else if g.IsPlaying() {
 		if g.Game.Playing() == pb.PlayState_WAITING_FOR_FINAL_PASS {
@@ -241,16 +240,25 @@ func (b *Bot) handle(data []byte) *pb.BotResponse {
 				return errorResponse(ErrNeedSimmingBot.Error(), nil)
 			}
 
-			var moves []*move.Move
-			if !isWordSmog {
+			// Leave enough time on the clock for the rest of the game.
+			timeout := WolgesTimeout
+			if req.MillisRemaining != 0 {
+				timeout = bot.TimeForMove(g.Game, int(req.MillisRemaining), WolgesTimeout)
+				log.Debug().Int32("millis-remaining", req.MillisRemaining).
+					Dur("time-for-move", timeout).Msg("time-management")
+			}
+
+			var source MoveSource = NativeMoveSource{}
+			if timeout >= MinWolgesTime {
+				source = b.moveSource
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			moves, err := source.Moves(ctx, b.game, 1)
+			cancel()
+			if err != nil || len(moves) == 0 {
+				log.Err(err).Str("source", source.Name()).Msg("move-source-error")
+				// Just generate a move using the regular generator.
 				moves = b.game.GenerateMoves(1)
-			} else {
-				moves, err = wolgesAnalyze(b.config, b.game)
-				if err != nil {
-					log.Err(err).Msg("wolges-analyze-error")
-					// Just generate a move using the regular generator.
-					moves = b.game.GenerateMoves(1)
-				}
 			}
 			m = moves[0]
 		}
@@ -26,6 +26,9 @@ func MakeRequest(game *bot.BotTurnPlayer, cfg *config.Config) ([]byte, error) {
 		history.Lexicon = cfg.GetString(config.ConfigDefaultLexicon)
 	}
 	req := pb.BotRequest{GameHistory: history}
+	if clock := game.Clock(); clock != nil {
+		req.MillisRemaining = int32(clock.Remaining(game.PlayerOnTurn()))
+	}
 	return proto.Marshal(&req)
 }
 
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/ai/bot"
+	mcfg "github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/move"
+)
+
+const (
+	MoveSourceNative     = "native"
+	MoveSourceWolges     = "wolges"
+	MoveSourceCrossCheck = "crosscheck"
+)
+
+// A MoveSource comes up with the moves the bot picks from.
+type MoveSource interface {
+	Name() string
+	// Moves returns up to n moves for the player on turn, best first.
+	Moves(ctx context.Context, p *bot.BotTurnPlayer, n int) ([]*move.Move, error)
+}
+
+// NativeMoveSource generates moves in process, with the player's own move
+// generator and equity calculators. It works offline, for every lexicon,
+// letter distribution and variant the game rules can be made for.
+type NativeMoveSource struct{}
+
+func (NativeMoveSource) Name() string {
+	return MoveSourceNative
+}
+
+func (NativeMoveSource) Moves(ctx context.Context, p *bot.BotTurnPlayer, n int) ([]*move.Move, error) {
+	return p.GenerateMoves(n), nil
+}
+
+// CrossCheckMoveSource returns the moves of Primary. It also asks Check for
+// its best move, and logs a warning if the two disagree. Errors from Check
+// are only logged.
+type CrossCheckMoveSource struct {
+	Primary MoveSource
+	Check   MoveSource
+}
+
+func (c *CrossCheckMoveSource) Name() string {
+	return MoveSourceCrossCheck
+}
+
+func (c *CrossCheckMoveSource) Moves(ctx context.Context, p *bot.BotTurnPlayer, n int) ([]*move.Move, error) {
+	moves, err := c.Primary.Moves(ctx, p, n)
+	if err != nil || len(moves) == 0 {
+		return moves, err
+	}
+	check, err := c.Check.Moves(ctx, p, 1)
+	if err != nil {
+		log.Err(err).Str("source", c.Check.Name()).Msg("cross-check-error")
+		return moves, nil
+	}
+	if len(check) == 0 {
+		log.Warn().Str("source", c.Check.Name()).Msg("cross-check-no-moves")
+		return moves, nil
+	}
+	if !moves[0].Equals(check[0], true, true) {
+		log.Warn().
+			Str("cgp", p.ToCGP(false)).
+			Str(c.Primary.Name(), moves[0].ShortDescription()).
+			Int(c.Primary.Name()+"-score", moves[0].Score()).
+			Str(c.Check.Name(), check[0].ShortDescription()).
+			Int(c.Check.Name()+"-score", check[0].Score()).
+			Msg("move-source-mismatch")
+	}
+	return moves, nil
+}
+
+// NewMoveSource returns the move source named by ConfigBotMoveSource.
+func NewMoveSource(cfg *mcfg.Config) (MoveSource, error) {
+	name := strings.ToLower(cfg.GetString(mcfg.ConfigBotMoveSource))
+	url := cfg.GetString(mcfg.ConfigWolgesAwsmUrl)
+	switch name {
+	case "", MoveSourceNative:
+		return NativeMoveSource{}, nil
+	case MoveSourceWolges, MoveSourceCrossCheck:
+		if url == "" {
+			return nil, fmt.Errorf("move source %s needs %s to be set", name, mcfg.ConfigWolgesAwsmUrl)
+		}
+		if name == MoveSourceWolges {
+			return &WolgesMoveSource{URL: url}, nil
+		}
+		return &CrossCheckMoveSource{Primary: NativeMoveSource{}, Check: &WolgesMoveSource{URL: url}}, nil
+	}
+	return nil, fmt.Errorf("unknown move source %q; try %s, %s or %s",
+		name, MoveSourceNative, MoveSourceWolges, MoveSourceCrossCheck)
+}
@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/cgp"
+	"github.com/domino14/macondo/config"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+func player(t *testing.T, cgpstr string) *bot.BotTurnPlayer {
+	is := is.New(t)
+	g, err := cgp.ParseCGP(&DefaultConfig, cgpstr)
+	is.NoErr(err)
+	g.RecalculateBoard()
+	p, err := bot.NewBotTurnPlayerFromGame(g.Game, &bot.BotConfig{Config: DefaultConfig},
+		pb.BotRequest_HASTY_BOT)
+	is.NoErr(err)
+	return p
+}
+
+func TestNewMoveSource(t *testing.T) {
+	is := is.New(t)
+	for _, tc := range []struct {
+		source, url string
+		name        string
+	}{
+		{"", "", MoveSourceNative},
+		{"native", "", MoveSourceNative},
+		{"Wolges", "http://localhost:5000", MoveSourceWolges},
+		{"crosscheck", "http://localhost:5000", MoveSourceCrossCheck},
+		{"wolges", "", ""},
+		{"crosscheck", "", ""},
+		{"quackle", "", ""},
+	} {
+		cfg := config.DefaultConfig()
+		cfg.Set(config.ConfigBotMoveSource, tc.source)
+		cfg.Set(config.ConfigWolgesAwsmUrl, tc.url)
+		ms, err := NewMoveSource(&cfg)
+		if tc.name == "" {
+			is.True(err != nil)
+			continue
+		}
+		is.NoErr(err)
+		is.Equal(ms.Name(), tc.name)
+		switch ms := ms.(type) {
+		case *WolgesMoveSource:
+			is.Equal(ms.URL, tc.url)
+		case *CrossCheckMoveSource:
+			is.Equal(ms.Primary.Name(), MoveSourceNative)
+			is.Equal(ms.Check.(*WolgesMoveSource).URL, tc.url)
+		}
+	}
+}
+
+type fixedMoveSource struct {
+	moves []*move.Move
+	err   error
+}
+
+func (f *fixedMoveSource) Name() string {
+	return "fixed"
+}
+
+func (f *fixedMoveSource) Moves(ctx context.Context, p *bot.BotTurnPlayer, n int) ([]*move.Move, error) {
+	return f.moves, f.err
+}
+
+func TestCrossCheckMismatch(t *testing.T) {
+	is := is.New(t)
+	p := player(t, "15/15/15/15/15/15/15/7CAT5/15/15/15/15/15/15/15 ABORSTV/ 0/0 0 lex NWL20;")
+	pass, err := p.NewPassMove(0)
+	is.NoErr(err)
+	tiles := tilemapping.MachineWord{1, 2}
+	exch := move.NewExchangeMove(tiles, p.RackFor(0).TilesOn()[2:], p.Alphabet())
+
+	var buf bytes.Buffer
+	defer func(l zerolog.Logger) { log.Logger = l }(log.Logger)
+	log.Logger = zerolog.New(&buf)
+
+	cc := &CrossCheckMoveSource{
+		Primary: &fixedMoveSource{moves: []*move.Move{pass}},
+		Check:   &fixedMoveSource{moves: []*move.Move{exch}},
+	}
+	moves, err := cc.Moves(context.Background(), p, 1)
+	is.NoErr(err)
+	is.Equal(moves, []*move.Move{pass})
+	is.True(strings.Contains(buf.String(), "move-source-mismatch"))
+
+	buf.Reset()
+	cc.Check = &fixedMoveSource{moves: []*move.Move{pass}}
+	_, err = cc.Moves(context.Background(), p, 1)
+	is.NoErr(err)
+	is.True(!strings.Contains(buf.String(), "move-source-mismatch"))
+
+	// The checking source failing does not matter.
+	cc.Check = &fixedMoveSource{err: errors.New("down")}
+	moves, err = cc.Moves(context.Background(), p, 1)
+	is.NoErr(err)
+	is.Equal(moves, []*move.Move{pass})
+}
+
+func TestWolgesMoves(t *testing.T) {
+	is := is.New(t)
+	// QU and L·L are single tiles in Catalan.
+	p := player(t, "15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 QUAL·L?ERS/ 0/0 0 lex DISC2; ld catalan;")
+
+	var payload WolgesAnalyzePayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal(r.URL.Path, "/analyze")
+		is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+		w.Write([]byte(`[
+			{"equity": 40.5, "action": "play", "down": false, "lane": 7, "idx": 6,
+			 "word": [19, 1, 13, -6], "score": 38},
+			{"equity": 10, "action": "exchange", "tiles": [19, 0]},
+			{"equity": 0, "action": "exchange", "tiles": []}]`))
+	}))
+	defer srv.Close()
+
+	moves, err := (&WolgesMoveSource{URL: srv.URL}).Moves(context.Background(), p, 3)
+	is.NoErr(err)
+	is.Equal(payload.Lexicon, "DISC2")
+	is.Equal(payload.Rules, "CrosswordGame/catalan")
+	is.Equal(payload.Count, 3)
+	is.Equal(payload.Rack, []int{0, 1, 6, 13, 19, 20, 21})
+	is.Equal(len(moves), 3)
+
+	// 8G QUAL·Le, with a blank E.
+	is.Equal(moves[0].Action(), move.MoveTypePlay)
+	is.Equal(moves[0].BoardCoords(), "8G")
+	is.Equal(moves[0].Tiles(), tilemapping.MachineWord{19, 1, 13, tilemapping.MachineLetter(6).Blank()})
+	is.Equal(moves[0].Leave(), tilemapping.MachineWord{6, 20, 21})
+	is.Equal(moves[0].Score(), 38)
+	is.Equal(moves[0].Equity(), 40.5)
+
+	is.Equal(moves[1].Action(), move.MoveTypeExchange)
+	is.Equal(moves[1].Tiles(), tilemapping.MachineWord{19, 0})
+	is.Equal(moves[1].Leave(), tilemapping.MachineWord{1, 6, 13, 20, 21})
+
+	is.Equal(moves[2].Action(), move.MoveTypePass)
+
+	// A score that does not add up is an error.
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"action": "play", "lane": 7, "idx": 6, "word": [19, 1, 13, -6], "score": 37}]`))
+	})
+	_, err = (&WolgesMoveSource{URL: srv.URL}).Moves(context.Background(), p, 1)
+	is.True(err != nil)
+}
+
+func TestNativeMovesOtherLanguages(t *testing.T) {
+	is := is.New(t)
+	for _, cgpstr := range []string{
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 ÄNGSTER/ 0/0 0 lex RD28; ld german;",
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 BLÅBÆRS/ 0/0 0 lex NSF23; ld norwegian;",
+	} {
+		p := player(t, cgpstr)
+		moves, err := NativeMoveSource{}.Moves(context.Background(), p, 10)
+		is.NoErr(err)
+		is.True(len(moves) > 1)
+		is.Equal(moves[0].Action(), move.MoveTypePlay)
+		is.True(moves[0].Score() > 0)
+		rack := p.RackFor(0).TilesOn()
+		for _, m := range moves {
+			if m.Action() != move.MoveTypePlay {
+				continue
+			}
+			_, err := tilemapping.Leave(rack, m.Tiles(), false)
+			is.NoErr(err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/chzyer/readline"
 	"github.com/nats-io/nats.go"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/domino14/macondo/ai/bot"
 	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/turnplayer"
@@ -28,12 +30,15 @@ const (
 var (
 	errNoData            = errors.New("no data in this line")
 	errWrongOptionSyntax = errors.New("wrong format; all options need arguments")
+	errFlagFell          = errors.New("flag fell; the game is over")
 )
 
 // Options to configure the interactve shell
 type ShellOptions struct {
 	turnplayer.GameOptions
 	lowercaseMoves bool
+	// timeControl is nil for untimed games.
+	timeControl *game.TimeControl
 }
 
 func NewShellOptions() *ShellOptions {
@@ -55,13 +60,18 @@ func (opts *ShellOptions) Show(key string) (bool, string) {
 	case "challenge":
 		rule := turnplayer.ShowChallengeRule(opts.ChallengeRule)
 		return true, fmt.Sprintf("%v", rule)
+	case "clock":
+		if opts.timeControl == nil {
+			return true, "off"
+		}
+		return true, opts.timeControl.String()
 	default:
 		return false, "No such option: " + key
 	}
 }
 
 func (opts *ShellOptions) ToDisplayText() string {
-	keys := []string{"lexicon", "challenge", "lower"}
+	keys := []string{"lexicon", "challenge", "lower", "clock"}
 	out := strings.Builder{}
 	out.WriteString("Settings:\n")
 	for _, key := range keys {
@@ -143,7 +153,7 @@ func (sc *ShellController) IsPlaying() bool {
 }
 
 func (sc *ShellController) IsBotOnTurn() bool {
-	return sc.game != nil && sc.game.PlayerOnTurn() == BotPlayer
+	return sc.IsPlaying() && sc.game.PlayerOnTurn() == BotPlayer
 }
 
 func (sc *ShellController) getMove() error {
@@ -155,6 +165,10 @@ func (sc *ShellController) getMove() error {
 	} else {
 		sc.showMessage("Bot returned move: " + m.ShortDescription())
 	}
+	if sc.game.CheckFlag() {
+		sc.showMessage(sc.game.ToDisplayText())
+		return errFlagFell
+	}
 	err = sc.game.PlayMove(m, true, 0)
 	if err != nil {
 		return err
@@ -176,6 +190,9 @@ func (sc *ShellController) newGame() (*Response, error) {
 		return nil, err
 	}
 	sc.game = g
+	if sc.options.timeControl != nil {
+		g.SetClock(game.NewClock(*sc.options.timeControl, len(players)))
+	}
 	if g.PlayerOnTurn() == SelfPlayer {
 		return Msg(sc.game.ToDisplayText()), nil
 	} else {
@@ -184,7 +201,40 @@ func (sc *ShellController) newGame() (*Response, error) {
 }
 
 func (sc *ShellController) show() (*Response, error) {
-	return Msg(sc.game.ToDisplayText()), nil
+	return Msg(sc.game.ToDisplayText() + sc.clockText()), nil
+}
+
+// clockText shows how much time each player has left, if the game is timed.
+func (sc *ShellController) clockText() string {
+	clock := sc.game.Clock()
+	if clock == nil {
+		return ""
+	}
+	out := strings.Builder{}
+	out.WriteString("\nClock:\n")
+	for i, p := range sc.game.History().Players {
+		remaining := time.Duration(clock.Remaining(i)) * time.Millisecond
+		out.WriteString(fmt.Sprintf("  %s: %v\n", p.Nickname, remaining.Round(time.Second)))
+	}
+	return out.String()
+}
+
+// setClock sets the time control for new games, for example "25m+0s/10".
+// "off" makes new games untimed.
+func (sc *ShellController) setClock(args []string) (*Response, error) {
+	if len(args) != 1 {
+		return nil, errors.New("clock <initial>[+<increment>][/<max overtime minutes>] or clock off")
+	}
+	if args[0] == "off" {
+		sc.options.timeControl = nil
+		return Msg("Clock is off for new games"), nil
+	}
+	tc, err := game.ParseTimeControl(args[0])
+	if err != nil {
+		return nil, err
+	}
+	sc.options.timeControl = &tc
+	return Msg("Clock for new games: " + tc.String()), nil
 }
 
 func (sc *ShellController) play(args []string) (*Response, error) {
@@ -217,6 +267,9 @@ func (sc *ShellController) pass() (*Response, error) {
 }
 
 func (sc *ShellController) commit(m *move.Move) (*Response, error) {
+	if sc.game.CheckFlag() {
+		return nil, errFlagFell
+	}
 	sc.showMessage("Committing move: " + m.ShortDescription())
 	err := sc.game.PlayMove(m, true, 0)
 	if err != nil {
@@ -252,6 +305,8 @@ func (sc *ShellController) handle(line string) (*Response, error) {
 		return sc.pass()
 	case "aiplay", "ai", "a":
 		return sc.aiplay()
+	case "clock":
+		return sc.setClock(args)
 	default:
 		msg := fmt.Sprintf("command %v not found", strconv.Quote(cmd))
 		log.Info().Msg(msg)
@@ -5,13 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/domino14/macondo/ai/bot"
-	"github.com/domino14/macondo/config"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/variant"
 	"github.com/domino14/word-golib/tilemapping"
@@ -22,17 +22,23 @@ import (
 // See github.com/andy-k/wolges
 //     github.com/andy-k/wolges-wasm
 //     github.com/andy-k/wolges-awsm
+//
+// It is an optional move source; the bot does not need it to play any
+// lexicon. See MoveSource.
 
 // see analyzer.tsx in liwords repo to see where a lot of this conversion
 // code comes from.
 
 const WolgesTimeout = 5 * time.Second
 
-// // Wolges ordering:
-// var GermanTiles = []rune("AÄBCDEFGHIJKLMNOÖPQRSTUÜVWXYZ")
-// var GermanBlankTiles = []rune("aäbcdefghijklmnoöpqrstuüvwxyz")
-// var NorwegianTiles = []rune("ABCDEFGHIJKLMNOPQRSTUVWXYÜZÆÄØÖÅ")
-// var NorwegianBlankTiles = []rune("abcdefghijklmnopqrstuvwxyüzæäøöå")
+// MinWolgesTime is the least time worth waiting for wolges. With less time
+// on the clock, the bot uses its own move generator.
+const MinWolgesTime = 500 * time.Millisecond
+
+// Wolges numbers tiles the same way as our letter distributions, for every
+// distribution, including the ones with multi-character tiles. The only
+// difference is that a blank is sent as the negative of the tile it
+// stands for.
 
 type WolgesAnalyzePayload struct {
 	Rack    []int   `json:"rack"`
@@ -54,13 +60,86 @@ type WolgesAnalyzeResponse struct {
 	Score  int     `json:"score"`
 }
 
-func wolgesAnalyze(cfg *config.Config, g *bot.BotTurnPlayer) ([]*move.Move, error) {
-	// cfg.WolgesAwsmURL
+// WolgesMoveSource asks a wolges-awsm server for moves.
+type WolgesMoveSource struct {
+	URL string
+	// Client is used for the requests; if nil, http.DefaultClient is.
+	Client *http.Client
+}
+
+func (w *WolgesMoveSource) Name() string {
+	return MoveSourceWolges
+}
+
+func (w *WolgesMoveSource) Moves(ctx context.Context, g *bot.BotTurnPlayer, n int) ([]*move.Move, error) {
+	r, err := w.Analyze(ctx, g, n)
+	if err != nil {
+		return nil, err
+	}
+	moves := make([]*move.Move, 0, len(r))
+	for _, wr := range r {
+		m, err := WolgesMove(g, wr)
+		if err != nil {
+			return nil, err
+		}
+		// The bot cannot trust a move list that does not add up.
+		if m.Action() == move.MoveTypePlay && m.Score() != wr.Score {
+			return nil, fmt.Errorf("wolges scored %s as %d, but it scores %d",
+				m.ShortDescription(), wr.Score, m.Score())
+		}
+		m.SetEquity(float64(wr.Equity))
+		moves = append(moves, m)
+	}
+	return moves, nil
+}
+
+// Analyze asks the server for the best n moves of the player on turn, and
+// returns its answer as is.
+func (w *WolgesMoveSource) Analyze(ctx context.Context, g *bot.BotTurnPlayer, n int) ([]WolgesAnalyzeResponse, error) {
+	wap := wolgesPayload(g, n)
+	bts, err := json.Marshal(wap)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Str("payload", string(bts)).Msg("sending-to-wolges")
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL+"/analyze", bytes.NewReader(bts))
+	if err != nil {
+		return nil, err
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	log.Debug().Msg("made HTTP post, getting response...")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	readbts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Str("body", string(readbts)).Msg("raw-from-wolges")
+	var r []WolgesAnalyzeResponse
+	err = json.Unmarshal(readbts, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Interface("r", r).Msg("from-wolges")
+	if len(r) < 1 {
+		return nil, errors.New("unexpected-wolges-response-length")
+	}
+	return r, nil
+}
+
+func wolgesPayload(g *bot.BotTurnPlayer, count int) *WolgesAnalyzePayload {
 	// convert game to the needed data structure
 	dim := g.Board().Dim()
 
 	// there's some boards in this house
-	wap := WolgesAnalyzePayload{}
+	wap := &WolgesAnalyzePayload{}
 	// assume square board.
 	wap.Board = make([][]int, dim)
 	for i := 0; i < dim; i++ {
@@ -71,20 +150,8 @@ func wolgesAnalyze(cfg *config.Config, g *bot.BotTurnPlayer) ([]*move.Move, erro
 
 	wap.Lexicon = g.LexiconName()
 
-	letterDistribution := ""
-	lowercasedLexicon := strings.ToLower(wap.Lexicon)
-	switch {
-	case strings.HasPrefix(lowercasedLexicon, "rd"):
-		letterDistribution = "german"
-	case strings.HasPrefix(lowercasedLexicon, "nsf"):
-		letterDistribution = "norwegian"
-	case strings.HasPrefix(lowercasedLexicon, "fra"):
-		letterDistribution = "french"
-	case strings.HasPrefix(lowercasedLexicon, "disc"):
-		letterDistribution = "catalan"
-	case strings.HasPrefix(lowercasedLexicon, "osps"):
-		letterDistribution = "polish"
-	default:
+	letterDistribution := strings.ToLower(g.Rules().LetterDistributionName())
+	if letterDistribution == "" {
 		letterDistribution = "english"
 	}
 
@@ -120,18 +187,10 @@ func wolgesAnalyze(cfg *config.Config, g *bot.BotTurnPlayer) ([]*move.Move, erro
 		wap.Rules += "/" + letterDistribution
 	}
 
-	tm := g.Bag().LetterDistribution().TileMapping()
-
 	// populate board
-	for i := 0; i < g.Board().Dim(); i++ {
-		for j := 0; j < g.Board().Dim(); j++ {
-			// wolges now uses our letter ordering, except blanks are encoded differently.
-			letter := g.Board().GetLetter(i, j)
-			code := int(letter)
-			if letter.IsBlanked() {
-				code = -int(letter.Unblank())
-			}
-			wap.Board[i][j] = code
+	for i := 0; i < dim; i++ {
+		for j := 0; j < dim; j++ {
+			wap.Board[i][j] = toWolgesTile(g.Board().GetLetter(i, j))
 		}
 	}
 
@@ -139,98 +198,63 @@ func wolgesAnalyze(cfg *config.Config, g *bot.BotTurnPlayer) ([]*move.Move, erro
 		wap.Rack = append(wap.Rack, int(c))
 	}
 
-	wap.Count = 1
+	wap.Count = max(1, count)
+	return wap
+}
 
-	bts, err := json.Marshal(wap)
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Str("payload", string(bts)).Msg("sending-to-wolges")
-	// Now let's send a request.
-	ctx, cancel := context.WithTimeout(context.Background(), WolgesTimeout)
-	defer cancel()
-	req, err := http.NewRequest("POST", cfg.GetString(config.ConfigWolgesAwsmUrl)+"/analyze", bytes.NewReader(bts))
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Msg("made HTTP post, getting response...")
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	readbts, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Str("body", string(readbts)).Msg("raw-from-wolges")
-	var r []WolgesAnalyzeResponse
-	err = json.Unmarshal(readbts, &r)
-	if err != nil {
-		return nil, err
+func toWolgesTile(ml tilemapping.MachineLetter) int {
+	if ml.IsBlanked() {
+		return -int(ml.Unblank())
 	}
+	return int(ml)
+}
 
-	log.Info().Interface("r", r).Msg("from-wolges")
-	if len(r) < 1 {
-		return nil, errors.New("unexpected-wolges-response-length")
+func fromWolgesTile(t int) tilemapping.MachineLetter {
+	if t < 0 {
+		return tilemapping.MachineLetter(-t).Blank()
 	}
+	return tilemapping.MachineLetter(t)
+}
 
-	best := r[0]
-	switch best.Action {
+// WolgesMove makes a move out of a wolges answer. It works with machine
+// letters throughout, so that multi-character tiles are never re-parsed
+// from strings. A play is scored by macondo, not by wolges; it is an error
+// if it cannot be made in the position.
+func WolgesMove(g *bot.BotTurnPlayer, wr WolgesAnalyzeResponse) (*move.Move, error) {
+	rack := g.RackFor(g.PlayerOnTurn()).TilesOn()
+	switch wr.Action {
 	case "exchange":
-
-		if len(best.Tiles) == 0 {
+		if len(wr.Tiles) == 0 {
 			// actually a pass
-			p, err := g.NewPassMove(g.PlayerOnTurn())
-			if err != nil {
-				return nil, err
-			}
-			return []*move.Move{p}, nil
-
-		} else {
-			var str strings.Builder
-			for _, t := range best.Tiles {
-				str.WriteString(tm.Letter(tilemapping.MachineLetter(t)))
-			}
-			exch, err := g.NewExchangeMove(g.PlayerOnTurn(), str.String())
-			if err != nil {
-				return nil, err
-			}
-			return []*move.Move{exch}, nil
+			return g.NewPassMove(g.PlayerOnTurn())
+		}
+		tiles := make(tilemapping.MachineWord, len(wr.Tiles))
+		for i, t := range wr.Tiles {
+			tiles[i] = fromWolgesTile(t)
+		}
+		leave, err := tilemapping.Leave(rack, tiles, true)
+		if err != nil {
+			return nil, err
 		}
+		return move.NewExchangeMove(tiles, leave, g.Alphabet()), nil
 
 	case "play":
-		vertical := best.Down
+		vertical := wr.Down
 		var row, col int
 		if vertical {
-			row = best.Idx
-			col = best.Lane
+			row = wr.Idx
+			col = wr.Lane
 		} else {
-			row = best.Lane
-			col = best.Idx
-		}
-		coords := move.ToBoardGameCoords(row, col, vertical)
-		rack := g.RackLettersFor(g.PlayerOnTurn())
-		var str strings.Builder
-		for _, t := range best.Word {
-			if t == 0 {
-				str.WriteString(string(tilemapping.ASCIIPlayedThrough))
-			} else {
-				if t < 0 {
-					// re-encode blank.
-					t = (-t) | tilemapping.BlankMask
-				}
-				letter := tm.Letter(tilemapping.MachineLetter(t))
-				str.WriteString(letter)
-			}
+			row = wr.Lane
+			col = wr.Idx
 		}
-
-		m, err := g.CreateAndScorePlacementMove(coords, str.String(), string(rack))
-		if err != nil {
-			return nil, err
+		word := make(tilemapping.MachineWord, len(wr.Word))
+		for i, t := range wr.Word {
+			// 0 is a tile played through.
+			word[i] = fromWolgesTile(t)
 		}
-		return []*move.Move{m}, nil
+		return g.CreateAndScorePlacementMoveFromTiles(row, col, vertical, word, rack)
 	}
 
-	return nil, errors.New("not handled: " + best.Action)
+	return nil, errors.New("not handled: " + wr.Action)
 }
@@ -1,8 +1,38 @@
+// Package cgp reads game positions in the CGP format. A CGP is the board,
+// the racks, the scores and the number of scoreless turns, followed by
+// opcodes:
+//
+//	15/15/15/15/15/15/15/7FOO5/15/15/15/15/15/15/15 ABCDEFG/ 10/0 0 lex NWL23; lp 8H FOO;
+//
+// The player to move is listed first. The opcodes this package knows are:
+//
+//	bdn   board layout name
+//	bag   the tiles in the bag; any other unseen tiles are on the
+//	      opponent's rack
+//	bb    bingo bonus
+//	cr    challenge rule (void, single, double, triple, 5pt, 10pt)
+//	gid   game ID
+//	ld    letter distribution
+//	lex   lexicon
+//	lp    the last play, made by the opponent: a position and tiles such as
+//	      "8H FOO", "-" for a pass, "-ABC" or "-3" for an exchange. A play
+//	      followed by "--" was a phony that came off the board.
+//	mcnz  maximum number of scoreless turns in a row
+//	rs    rule set
+//	seed  seed for the bag and for sims
+//	tc    time control, such as "25m/10"
+//	tmr   remaining time of both players in milliseconds, such as
+//	      "124000/-3000"
+//	var   variant
+//
+// Game.WriteCGP writes these opcodes back, along with any other opcodes
+// the CGP had.
 package cgp
 
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -15,13 +45,33 @@ import (
 	"github.com/domino14/word-golib/tilemapping"
 )
 
+var positionRegex = regexp.MustCompile(`^([A-Za-z]\d+|\d+[A-Za-z])$`)
+
 type ParsedCGP struct {
 	*game.Game
+	// Opcodes are all the opcodes of the CGP, including those of other
+	// programs, with their arguments.
 	Opcodes map[string]string
+	// LastPlay is the play from the lp opcode, or nil if there was none.
+	// Its PlayerIndex is 1, as the opponent made it.
+	LastPlay *pb.GameEvent
 }
 
 // ParseCGP returns an instantiated Game instance from the given CGP string.
 func ParseCGP(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
+	return parseCGP(cfg, cgpstr, false)
+}
+
+// ParseCGPStrict is like ParseCGP, but it also checks that the position
+// could happen in a game: that the board has the right size, that the racks
+// are not too big, and that the board, racks and bag do not use more of any
+// tile than the letter distribution has. If a bag is given, no tiles may be
+// missing either. All the problems found are returned together.
+func ParseCGPStrict(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
+	return parseCGP(cfg, cgpstr, true)
+}
+
+func parseCGP(cfg *config.Config, cgpstr string, strict bool) (*ParsedCGP, error) {
 
 	var err error
 
@@ -65,9 +115,15 @@ func ParseCGP(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
 	boardLayoutName := "CrosswordGame"
 	letterDistributionName := "english"
 	lexiconName := "NWL23"
-	maxScorelessTurns := game.DefaultMaxScorelessTurns
+	var maxScorelessTurns, bingoBonus int
+	ruleSetName := ""
 	va := variant.VarClassic
 	gid := ""
+	var seed uint64
+	var challengeRule pb.ChallengeRule
+	var timeControl game.TimeControl
+	var millisRemaining []int
+	var lastPlay, bag string
 	opcodes := map[string]string{}
 
 	for _, op := range ops {
@@ -114,6 +170,23 @@ func ParseCGP(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
 			}
 			opcodes["mcnz"] = opWithParams[1]
 
+		case "rs":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for rs operation")
+			}
+			ruleSetName = opWithParams[1]
+			opcodes["rs"] = opWithParams[1]
+
+		case "bb":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for bb operation")
+			}
+			bingoBonus, err = strconv.Atoi(opWithParams[1])
+			if err != nil {
+				return nil, err
+			}
+			opcodes["bb"] = opWithParams[1]
+
 		case "var":
 			if len(opWithParams) != 2 {
 				return nil, errors.New("wrong number of arguments for var operation")
@@ -121,9 +194,74 @@ func ParseCGP(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
 			va = variant.Variant(opWithParams[1])
 			opcodes["var"] = opWithParams[1]
 
+		case "seed":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for seed operation")
+			}
+			seed, err = strconv.ParseUint(opWithParams[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			opcodes["seed"] = opWithParams[1]
+
+		case "cr":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for cr operation")
+			}
+			challengeRule, err = game.ParseChallengeRuleName(opWithParams[1])
+			if err != nil {
+				return nil, fmt.Errorf("bad challenge rule %q: %w", opWithParams[1], err)
+			}
+			opcodes["cr"] = opWithParams[1]
+
+		case "tc":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for tc operation")
+			}
+			timeControl, err = game.ParseTimeControl(opWithParams[1])
+			if err != nil {
+				return nil, fmt.Errorf("bad time control %q: %w", opWithParams[1], err)
+			}
+			opcodes["tc"] = opWithParams[1]
+
 		case "tmr":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for tmr operation")
+			}
+			millisRemaining = nil
+			for _, t := range strings.Split(opWithParams[1], "/") {
+				millis, err := strconv.Atoi(t)
+				if err != nil {
+					return nil, fmt.Errorf("bad time remaining %q: %w", opWithParams[1], err)
+				}
+				millisRemaining = append(millisRemaining, millis)
+			}
+			if len(millisRemaining) != len(playerRacks) {
+				return nil, errors.New("tmr operation must have a time for every player")
+			}
 			opcodes["tmr"] = opWithParams[1]
 
+		case "lp":
+			if len(opWithParams) != 2 {
+				return nil, errors.New("wrong number of arguments for lp operation")
+			}
+			lastPlay = opWithParams[1]
+			opcodes["lp"] = opWithParams[1]
+
+		case "bag":
+			// An empty bag has no argument.
+			if len(opWithParams) == 2 {
+				bag = strings.TrimSpace(opWithParams[1])
+			}
+			opcodes["bag"] = bag
+
+		default:
+			// Opcodes of other programs are kept, and written back.
+			arg := ""
+			if len(opWithParams) == 2 {
+				arg = opWithParams[1]
+			}
+			opcodes[opWithParams[0]] = arg
 		}
 
 	}
@@ -133,16 +271,56 @@ func ParseCGP(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The bb and mcnz operations override the rule set.
+	ruleset := *rules.RuleSet()
+	if ruleSetName != "" {
+		ruleset, err = game.LoadRuleSet(cfg, ruleSetName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := opcodes["bb"]; ok {
+		ruleset.BingoBonus = bingoBonus
+	}
+	if _, ok := opcodes["mcnz"]; ok {
+		ruleset.MaxScorelessTurns = maxScorelessTurns
+	}
+	err = rules.SetRuleSet(ruleset)
+	if err != nil {
+		return nil, err
+	}
+	tm := rules.LetterDistribution().TileMapping()
 
 	// "Decompress" the gameboard letters.
 	fullRows := make([][]tilemapping.MachineLetter, len(rows))
 
 	for i, row := range rows {
-		fullRows[i], err = rowToLetters(row, rules.LetterDistribution().TileMapping())
+		fullRows[i], err = rowToLetters(row, tm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var lastPlayEvt *pb.GameEvent
+	if lastPlay != "" {
+		lastPlayEvt, err = parseLastPlay(lastPlay, tm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var bagTiles []tilemapping.MachineLetter
+	if _, ok := opcodes["bag"]; ok {
+		bagTiles, err = tilemapping.ToMachineLetters(bag, tm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if strict {
+		err = validate(rules, fullRows, playerRacks, bagTiles, opcodes, nzero, lastPlayEvt)
 		if err != nil {
 			return nil, err
 		}
 	}
+
 	players := []*pb.PlayerInfo{}
 	lastKnownRacks := []string{}
 	for i, rack := range playerRacks {
@@ -155,14 +333,201 @@ func ParseCGP(cfg *config.Config, cgpstr string) (*ParsedCGP, error) {
 	if err != nil {
 		return nil, err
 	}
-	g.SetMaxScorelessTurns(maxScorelessTurns)
+	if seed != 0 {
+		g.SetSeed(seed)
+	}
+	if _, ok := opcodes["bag"]; ok {
+		if err = setBag(g, bagTiles); err != nil {
+			return nil, err
+		}
+	}
 	g.SetScorelessTurns(nzero)
+	g.SetChallengeRule(challengeRule)
+	_, hasTC := opcodes["tc"]
+	if _, hasTmr := opcodes["tmr"]; hasTC || hasTmr {
+		if !hasTC {
+			// The time control is not known, only the times left.
+			timeControl = game.TimeControl{
+				MaxOvertimeMinutes: game.DefaultMaxOvertimeMinutes,
+				OvertimePenalty:    game.DefaultOvertimePenalty,
+			}
+		}
+		clock := game.NewClock(timeControl, len(players))
+		g.SetClock(clock)
+		// The clock is left stopped, so that the times stay as they were
+		// given; whoever plays on from here starts it.
+		clock.Stop()
+		for i, millis := range millisRemaining {
+			clock.SetRemaining(i, millis)
+		}
+	}
 	g.History().StartingCgp = cgpstr
 	g.History().Uid = gid
 	g.History().IdAuth = "" //  maybe provide this later, id
 
 	log.Debug().Msgf("got gid %v", gid)
-	return &ParsedCGP{Game: g, Opcodes: opcodes}, nil
+	return &ParsedCGP{Game: g, Opcodes: opcodes, LastPlay: lastPlayEvt}, nil
+}
+
+// parseLastPlay parses the argument of the lp opcode.
+func parseLastPlay(lp string, tm *tilemapping.TileMapping) (*pb.GameEvent, error) {
+	evt := &pb.GameEvent{PlayerIndex: 1}
+	fields := strings.Fields(lp)
+	withdrawn := len(fields) > 1 && fields[len(fields)-1] == game.LastPlayWithdrawn
+	if withdrawn {
+		fields = fields[:len(fields)-1]
+	}
+	switch {
+	case len(fields) == 1 && fields[0] == "-" && !withdrawn:
+		evt.Type = pb.GameEvent_PASS
+	case len(fields) == 1 && strings.HasPrefix(fields[0], "-") && !withdrawn:
+		evt.Type = pb.GameEvent_EXCHANGE
+		tiles := fields[0][1:]
+		if n, err := strconv.Atoi(tiles); err == nil && n > 0 {
+			// Only the number of tiles exchanged is known.
+			evt.NumTilesFromRack = uint32(n)
+			break
+		}
+		mls, err := tilemapping.ToMachineLetters(tiles, tm)
+		if err != nil {
+			return nil, fmt.Errorf("bad last play %q: %w", lp, err)
+		}
+		evt.Exchanged = tiles
+		evt.NumTilesFromRack = uint32(len(mls))
+	case len(fields) == 2 && positionRegex.MatchString(fields[0]):
+		evt.Type = pb.GameEvent_TILE_PLACEMENT_MOVE
+		if withdrawn {
+			evt.Type = pb.GameEvent_PHONY_TILES_RETURNED
+		}
+		evt.Position = fields[0]
+		evt.PlayedTiles = fields[1]
+		if _, err := tilemapping.ToMachineLetters(evt.PlayedTiles, tm); err != nil {
+			return nil, fmt.Errorf("bad last play %q: %w", lp, err)
+		}
+		game.CalculateCoordsFromStringPosition(evt)
+	default:
+		return nil, fmt.Errorf("bad last play %q", lp)
+	}
+	return evt, nil
+}
+
+// setBag leaves exactly the given tiles in the bag, and puts the other
+// unseen tiles on the opponent's rack.
+func setBag(g *game.Game, bagTiles []tilemapping.MachineLetter) error {
+	rest := g.Bag().Copy()
+	if err := rest.RemoveTiles(bagTiles); err != nil {
+		return fmt.Errorf("the bag has tiles that are not unseen: %w", err)
+	}
+	unaccounted := rest.Peek()
+	if len(unaccounted) == 0 {
+		return nil
+	}
+	opp := g.RackFor(1).TilesOn()
+	if rackSize := g.Rules().RuleSet().RackSize; len(opp)+len(unaccounted) > rackSize {
+		return fmt.Errorf("%d unseen tiles are not in the bag, and do not fit on the opponent's rack",
+			len(unaccounted))
+	}
+	rack := tilemapping.NewRack(g.Alphabet())
+	rack.Set(append(opp, unaccounted...))
+	g.ThrowRacksInFor(1)
+	if err := g.SetRackForOnly(1, rack); err != nil {
+		return err
+	}
+	g.History().LastKnownRacks[1] = rack.String()
+	if g.Bag().TilesRemaining() == 0 && g.RackFor(0).NumTiles() == 0 {
+		g.SetPlaying(pb.PlayState_GAME_OVER)
+		g.History().PlayState = pb.PlayState_GAME_OVER
+	}
+	return nil
+}
+
+// validate returns every way in which the position could not happen in a
+// game.
+func validate(rules *game.GameRules, rows [][]tilemapping.MachineLetter, racks []string,
+	bag []tilemapping.MachineLetter, opcodes map[string]string, nzero int, lastPlay *pb.GameEvent) error {
+
+	var problems []error
+	ld := rules.LetterDistribution()
+	tm := ld.TileMapping()
+	dim := rules.Board().Dim()
+	rs := rules.RuleSet()
+
+	if len(rows) != dim {
+		problems = append(problems, fmt.Errorf("the board has %d rows instead of %d", len(rows), dim))
+	}
+	counts := make([]int, tm.NumLetters())
+	for i, row := range rows {
+		if len(row) != dim {
+			problems = append(problems, fmt.Errorf("row %d has %d squares instead of %d", i+1, len(row), dim))
+		}
+		for _, ml := range row {
+			if ml != 0 {
+				counts[ml.IntrinsicTileIdx()]++
+			}
+		}
+	}
+	for i, rack := range racks {
+		mls, err := tilemapping.ToMachineLetters(rack, tm)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("rack %d: %w", i+1, err))
+			continue
+		}
+		if len(mls) > rs.RackSize {
+			problems = append(problems, fmt.Errorf("rack %d has %d tiles, more than %d", i+1, len(mls), rs.RackSize))
+		}
+		for _, ml := range mls {
+			counts[ml]++
+		}
+	}
+	for _, ml := range bag {
+		counts[ml]++
+	}
+	dist := ld.Distribution()
+	total := 0
+	for ml, n := range counts {
+		total += n
+		if n > int(dist[ml]) {
+			problems = append(problems, fmt.Errorf("there are %d %s tiles, but the letter distribution only has %d",
+				n, tilemapping.MachineLetter(ml).UserVisible(tm, false), dist[ml]))
+		}
+	}
+	if _, ok := opcodes["bag"]; ok && total < int(ld.NumTotalLetters()) {
+		problems = append(problems, fmt.Errorf("%d tiles are not on the board, the racks or in the bag",
+			int(ld.NumTotalLetters())-total))
+	}
+	if nzero >= rs.MaxScorelessTurns {
+		problems = append(problems, fmt.Errorf("%d scoreless turns in a row would have ended the game", nzero))
+	}
+	if lastPlay != nil && lastPlay.Type == pb.GameEvent_TILE_PLACEMENT_MOVE && len(rows) == dim {
+		if !onBoard(lastPlay, rows, tm) {
+			problems = append(problems, fmt.Errorf("the last play %s %s is not on the board",
+				lastPlay.Position, lastPlay.PlayedTiles))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// onBoard returns whether the tiles of the play are on the board.
+func onBoard(evt *pb.GameEvent, rows [][]tilemapping.MachineLetter, tm *tilemapping.TileMapping) bool {
+	mls, err := tilemapping.ToMachineLetters(evt.PlayedTiles, tm)
+	if err != nil {
+		return false
+	}
+	row, col := int(evt.Row), int(evt.Column)
+	for _, ml := range mls {
+		if row >= len(rows) || col >= len(rows[row]) {
+			return false
+		}
+		if ml != 0 && rows[row][col] != ml {
+			return false
+		}
+		if evt.Direction == pb.GameEvent_VERTICAL {
+			row++
+		} else {
+			col++
+		}
+	}
+	return true
 }
 
 func rowToLetters(row string, tm *tilemapping.TileMapping) ([]tilemapping.MachineLetter, error) {
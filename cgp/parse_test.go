@@ -1,12 +1,16 @@
 package cgp
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
 
 	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/testhelpers"
+	"github.com/domino14/macondo/variant"
 	"github.com/domino14/word-golib/tilemapping"
 )
 
@@ -52,3 +56,133 @@ func TestRowToLettersMultichar(t *testing.T) {
 		is.Equal(parsed, tc.parsed)
 	}
 }
+
+func TestParseRuleSet(t *testing.T) {
+	is := is.New(t)
+	g, err := ParseCGP(&DefaultConfig,
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL18; rs box; bb 40;")
+	is.NoErr(err)
+	rs := g.Rules().RuleSet()
+	is.Equal(rs.Name, "box")
+	is.Equal(rs.EndRackScoring, variant.EndRackTransfer)
+	is.Equal(rs.BingoBonus, 40)
+	is.Equal(g.ToCGP(false),
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL18; ld english; cr void; rs box; bb 40; mcnz 6;")
+
+	_, err = ParseCGP(&DefaultConfig,
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL18; rs nosuchrules;")
+	is.True(err != nil)
+}
+
+func TestRoundTrip(t *testing.T) {
+	is := is.New(t)
+	cgpstr := "15/15/15/15/15/15/15/7FOO5/15/15/15/15/15/15/15 ABCDEEG/HIJKLMN 0/12 1 lex NWL18; ld english; " +
+		"cr single; gid abc123; seed 42; tc 25m0s/10; tmr 60000/-2000; lp 8H FOO;"
+	g, err := ParseCGP(&DefaultConfig, cgpstr)
+	is.NoErr(err)
+	is.Equal(g.History().ChallengeRule, pb.ChallengeRule_SINGLE)
+	is.Equal(g.Uid(), "abc123")
+	is.Equal(g.Clock().TimeControl().InitialMillis, 25*60*1000)
+	is.Equal(g.Clock().Remaining(0), 60000)
+	is.Equal(g.Clock().Remaining(1), -2000)
+	is.Equal(g.Clock().Running(), -1)
+	is.Equal(g.LastPlay.Type, pb.GameEvent_TILE_PLACEMENT_MOVE)
+	is.Equal(g.LastPlay.Position, "8H")
+	is.Equal(g.LastPlay.PlayedTiles, "FOO")
+	is.Equal(g.LastPlay.PlayerIndex, uint32(1))
+	is.Equal(g.ToCGP(false), cgpstr)
+
+	withBag := g.WriteCGP(game.CGPOptions{WithBag: true})
+	g2, err := ParseCGPStrict(&DefaultConfig, withBag)
+	is.NoErr(err)
+	is.Equal(g2.WriteCGP(game.CGPOptions{WithBag: true}), withBag)
+	is.Equal(g2.ToCGP(false), cgpstr)
+	is.Equal(g2.Bag().TilesRemaining(), g.Bag().TilesRemaining())
+
+	// The bot doesn't see the opponent's rack or the seed.
+	is.Equal(g.ToCGP(true), "15/15/15/15/15/15/15/7FOO5/15/15/15/15/15/15/15 ABCDEEG/ 0/12 1 lex NWL18; ld english; "+
+		"cr single; gid abc123; tc 25m0s/10; tmr 60000/-2000; lp 8H FOO;")
+
+	// The times left are kept without a time control, as are the opcodes of
+	// other programs, and a void challenge rule is written out.
+	cgpstr = "15/15/15/15/15/15/15/7FOO5/15/15/15/15/15/15/15 ABCDEEG/HIJKLMN 0/12 1 lex NWL18; ld english; " +
+		"cr void; tmr 60000/-2000; xyz foo bar; abc;"
+	g, err = ParseCGP(&DefaultConfig, cgpstr)
+	is.NoErr(err)
+	is.Equal(g.Opcodes["xyz"], "foo bar")
+	is.Equal(g.Clock().Remaining(0), 60000)
+	is.Equal(g.Clock().Remaining(1), -2000)
+	is.Equal(g.ToCGP(false), cgpstr)
+	g2, err = ParseCGP(&DefaultConfig, g.ToCGP(false))
+	is.NoErr(err)
+	is.Equal(g2.ToCGP(false), cgpstr)
+	is.Equal(g2.History().ChallengeRule, pb.ChallengeRule_VOID)
+}
+
+func TestParseLastPlay(t *testing.T) {
+	is := is.New(t)
+	tm := testhelpers.EnglishAlphabet()
+	testcases := []struct {
+		lp        string
+		evtType   pb.GameEvent_Type
+		exchanged string
+		numTiles  uint32
+	}{
+		{"-", pb.GameEvent_PASS, "", 0},
+		{"-ABC", pb.GameEvent_EXCHANGE, "ABC", 3},
+		{"-4", pb.GameEvent_EXCHANGE, "", 4},
+		{"H8 F.Oo", pb.GameEvent_TILE_PLACEMENT_MOVE, "", 0},
+		{"8H FOO --", pb.GameEvent_PHONY_TILES_RETURNED, "", 0},
+	}
+	for _, tc := range testcases {
+		evt, err := parseLastPlay(tc.lp, tm)
+		is.NoErr(err)
+		is.Equal(evt.Type, tc.evtType)
+		is.Equal(evt.Exchanged, tc.exchanged)
+		is.Equal(evt.NumTilesFromRack, tc.numTiles)
+	}
+	for _, lp := range []string{"FOO", "8H", "- --", "Z9Z FOO", "8H F0O"} {
+		_, err := parseLastPlay(lp, tm)
+		is.True(err != nil)
+	}
+}
+
+func TestBagFillsOpponentRack(t *testing.T) {
+	is := is.New(t)
+	// The same endgame as in the zobrist tests, with the opponent's rack
+	// left out.
+	g, err := ParseCGP(&DefaultConfig,
+		"1LEMNISCI2L1ER/7O1PAINT1/4A2L1RAVE2/WEDGE2Z1I1R3/4R1JAUNTEd2/4OXO2K5/2YOB3P6/3FAUNAE6/4T3GUY4/6BESTEaD2/7T2HIE2/7H4VUG/2CORMOID6/7O7/7NONIDEAL AAFIRTW/ 373/393 0 lex NWL18; bag;")
+	is.NoErr(err)
+	is.Equal(g.RackFor(1).String(), "EIQSS")
+	is.Equal(g.Bag().TilesRemaining(), 0)
+
+	// A bag with too few tiles leaves more than a rack's worth unseen.
+	_, err = ParseCGP(&DefaultConfig,
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL18; bag ABC;")
+	is.True(err != nil)
+}
+
+func TestParseCGPStrict(t *testing.T) {
+	is := is.New(t)
+	// Three Zs, an eight-tile rack and a short row.
+	_, err := ParseCGPStrict(&DefaultConfig,
+		"15/15/15/15/15/15/15/7ZZ6/15/15/15/15/15/15/14 ZAEINRST/ 0/0 0 lex NWL18; lp 8A ZZ;")
+	is.True(err != nil)
+	msg := err.Error()
+	is.True(strings.Contains(msg, "there are 3 Z tiles, but the letter distribution only has 1"))
+	is.True(strings.Contains(msg, "rack 1 has 8 tiles, more than 7"))
+	is.True(strings.Contains(msg, "row 15 has 14 squares instead of 15"))
+	is.True(strings.Contains(msg, "the last play 8A ZZ is not on the board"))
+
+	// Tiles missing from a known bag.
+	_, err = ParseCGPStrict(&DefaultConfig,
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL18; bag ABC;")
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "90 tiles are not on the board, the racks or in the bag"))
+
+	// The lenient parser doesn't check the rack size.
+	_, err = ParseCGP(&DefaultConfig,
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 ZAEINRST/ 0/0 0 lex NWL18;")
+	is.NoErr(err)
+}
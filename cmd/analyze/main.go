@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 
 	"github.com/rs/zerolog"
@@ -10,9 +16,65 @@ import (
 
 	"github.com/domino14/macondo/analyzer"
 	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/progress"
 )
 
+const defaultSimIterations = 1000
+
+// simOptions are the options of the sim mode.
+type simOptions struct {
+	Iterations int `json:"iterations"`
+}
+
+// run analyzes the JSON position in pos, and returns the result as JSON.
+// Progress events are written to stderr, one JSON object per line.
+func run(ctx context.Context, an *analyzer.Analyzer, mode string, pos, opts []byte) ([]byte, error) {
+	listener := func(e progress.Event) {
+		if data, err := json.Marshal(e); err == nil {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+	}
+	moves, err := an.Analyze(pos)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case "moves":
+		return moves, nil
+	case "position":
+		return an.Position()
+	case "sim":
+		o := simOptions{Iterations: defaultSimIterations}
+		if len(opts) > 0 {
+			if err := json.Unmarshal(opts, &o); err != nil {
+				return nil, fmt.Errorf("parse options failed: %w", err)
+			}
+		}
+		if err := an.SimInit(); err != nil {
+			return nil, err
+		}
+		if err := an.SimSingleThread(o.Iterations); err != nil {
+			return nil, err
+		}
+		return an.SimState()
+	case "endgame":
+		return an.Endgame(ctx, opts, listener)
+	case "preendgame":
+		return an.PreEndgame(ctx, opts, listener)
+	case "infer":
+		return an.Infer(ctx, opts, listener)
+	}
+	return nil, errors.New("unknown mode " + mode)
+}
+
 func main() {
+	mode := flag.String("mode", "moves",
+		"the analysis: moves, sim, endgame, preendgame, infer or position")
+	posFile := flag.String("position", "",
+		"the JSON position to analyze, or - for stdin; the sample position is analyzed if it is left out")
+	opts := flag.String("options", "", "the JSON options of the analysis")
+	flag.Parse()
+
 	// Determine the directory of the executable. We will use this
 	// directory to find the data files if an absolute path is not
 	// provided for these!
@@ -35,8 +97,30 @@ func main() {
 	}
 
 	an := analyzer.NewAnalyzer(cfg)
-	err = an.RunTest()
-	if err != nil {
-		fmt.Println(err)
+	if *posFile == "" {
+		err = an.RunTest()
+		if err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	var pos []byte
+	if *posFile == "-" {
+		pos, err = io.ReadAll(os.Stdin)
+	} else {
+		pos, err = os.ReadFile(*posFile)
+	}
+	if err == nil {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		var out []byte
+		out, err = run(ctx, an, *mode, pos, []byte(*opts))
+		if err == nil {
+			fmt.Println(string(out))
+			return
+		}
 	}
+	fmt.Println(string(analyzer.ErrorJson(err)))
+	os.Exit(1)
 }
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -21,6 +23,7 @@ import (
 	"github.com/domino14/macondo/config"
 	"github.com/domino14/macondo/game"
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/render"
 )
 
 var cfg *config.Config
@@ -39,35 +42,29 @@ func HandleRequest(ctx context.Context, evt bot.LambdaEvent) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	botTime := HardTimeLimit
+	if evt.Render != "" {
+		return renderPosition(g.Game, evt.Render)
+	}
+	botMillis := HardTimeLimit * 1000
 	if tmr, ok := g.Opcodes["tmr"]; ok {
 		tmrs := strings.Split(tmr, "/")
 		if len(tmrs) > 0 {
-			botTime, err = strconv.Atoi(tmrs[0])
+			botMillis, err = strconv.Atoi(tmrs[0])
 			if err != nil {
 				return "", err
 			}
-			botTime /= 1000 // convert from milliseconds
 		}
 	} else {
 		logger.Warn().Msg("no timer found in CGP")
 	}
 
-	// Estimate: bot plays 4.25 tiles per turn. Divide bag by 2 because bot will only
-	// play half the tiles left.
-	ourCount := int(g.RackFor(g.PlayerOnTurn()).NumTiles())
-	unseen := g.Bag().TilesRemaining() + int(g.RackFor(g.NextPlayer()).NumTiles())
-	actuallyInBag := max(unseen-game.RackTileLimit, 0)
-
-	estimatedTurnsLeft := (float64(actuallyInBag)/2 + float64(ourCount)) / 4.25
-	maxTimeShouldTake := min(float64(botTime-5)/estimatedTurnsLeft, HardTimeLimit)
-	logger.Info().Float64("bot-estimated-turns-left", estimatedTurnsLeft).
-		Int("inbag", actuallyInBag).
+	maxTimeShouldTake := aibot.TimeForMove(g.Game, botMillis, HardTimeLimit*time.Second)
+	logger.Info().Int("millis-remaining", botMillis).
 		Str("cgp", evt.CGP).
-		Float64("max-time-should-take", maxTimeShouldTake).Msg("time-management")
+		Dur("max-time-should-take", maxTimeShouldTake).Msg("time-management")
 
 	var cancel context.CancelFunc
-	ctx, cancel = context.WithTimeout(ctx, time.Duration(maxTimeShouldTake)*time.Second)
+	ctx, cancel = context.WithTimeout(ctx, maxTimeShouldTake)
 	ctx = logger.WithContext(ctx)
 
 	lexicon := g.History().Lexicon
@@ -131,6 +128,23 @@ func HandleRequest(ctx context.Context, evt bot.LambdaEvent) (string, error) {
 	return m.ShortDescription(), nil
 }
 
+// renderPosition returns a diagram of the position; a png is encoded in
+// base64 so that it fits in the string response.
+func renderPosition(g *game.Game, format string) (string, error) {
+	f, err := render.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := render.Render(&buf, g, f, 0, render.Options{}); err != nil {
+		return "", err
+	}
+	if f == render.PNG {
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	}
+	return buf.String(), nil
+}
+
 func main() {
 	ex, err := os.Executable()
 	if err != nil {
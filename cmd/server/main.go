@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/server"
+)
+
+const (
+	GracefulShutdownTimeout = 20 * time.Second
+)
+
+var (
+	addr  = flag.String("addr", "localhost:8585", "address to serve HTTP on")
+	stdio = flag.Bool("stdio", false, "read requests from stdin and write responses to stdout, instead of serving HTTP")
+)
+
+func main() {
+	flag.Parse()
+	// Determine the directory of the executable. We will use this
+	// directory to find the data files if an absolute path is not
+	// provided for these!
+	ex, err := os.Executable()
+	if err != nil {
+		panic(err)
+	}
+	exPath := filepath.Dir(ex)
+
+	cfg := &config.Config{}
+	cfg.Load(flag.Args())
+	cfg.AdjustRelativePaths(exPath)
+
+	// Log to stderr, so that stdout is left for responses in stdio mode.
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	if cfg.GetBool("debug") {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	} else {
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	}
+
+	sv := server.New(cfg)
+	defer sv.Close()
+
+	if *stdio {
+		if err := sv.ServeStream(os.Stdin, os.Stdout); err != nil {
+			log.Err(err).Msg("stdio-error")
+		}
+		return
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: sv.Handler()}
+	idleConnsClosed := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		// We received an interrupt signal, shut down.
+		log.Info().Msg("got quit signal...")
+		ctx, cancel := context.WithTimeout(context.Background(), GracefulShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Err(err).Msg("shutdown-error")
+		}
+		close(idleConnsClosed)
+	}()
+
+	log.Info().Str("addr", *addr).Msg("serving")
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal().Err(err).Msg("listen-error")
+	}
+	<-idleConnsClosed
+	log.Info().Msg("server gracefully shutting down")
+}
@@ -23,6 +23,15 @@ const (
 	ConfigWolgesAwsmUrl                    = "wolges-awsm-url"
 	ConfigCPUProfile                       = "cpu-profile"
 	ConfigMEMProfile                       = "mem-profile"
+	// ConfigSeed, if non-zero, seeds all new games. See game.SetSeed.
+	ConfigSeed = "seed"
+	// ConfigDefaultRuleSet, if set, names the rule set of all new games.
+	// See game.LoadRuleSet.
+	ConfigDefaultRuleSet = "default-ruleset"
+	// ConfigBotMoveSource is where the bot gets its moves: "native" (the
+	// default), "wolges" for a wolges-awsm server at ConfigWolgesAwsmUrl, or
+	// "crosscheck" to use native moves and log where wolges disagrees.
+	ConfigBotMoveSource = "bot-move-source"
 )
 
 type Config struct {
@@ -78,10 +87,13 @@ func (c *Config) Load(args []string) error {
 	c.BindEnv(ConfigLambdaFunctionName)
 	c.BindEnv(ConfigNatsURL)
 	c.BindEnv(ConfigWolgesAwsmUrl)
+	c.BindEnv(ConfigBotMoveSource)
 	c.BindEnv(ConfigDebug)
 	c.BindEnv(ConfigKWGPathPrefix)
 	c.BindEnv(ConfigCPUProfile)
 	c.BindEnv(ConfigMEMProfile)
+	c.BindEnv(ConfigSeed)
+	c.BindEnv(ConfigDefaultRuleSet)
 
 	c.SetDefault(ConfigDataPath, "./data") // will be fixed by toAbsPath below if unspecified.
 	c.SetDefault(ConfigDefaultLexicon, "NWL23")
@@ -5,6 +5,7 @@ import (
 	"github.com/domino14/macondo/gaddag"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/tinymove"
+	"github.com/domino14/word-golib/kwg"
 	"github.com/domino14/word-golib/tilemapping"
 )
 
@@ -23,9 +24,10 @@ type GenericMove interface {
 }
 
 // Public cross_set.Generator Interface
-// There are two concrete implementations below,
+// There are three concrete implementations below,
 // - CrossScoreOnlyGenerator{Dist}
 // - GaddagCrossSetGenerator{Dist, Gaddag}
+// - AnagramCrossSetGenerator{Dist, KWG}
 
 type Generator interface {
 	Generate(b *Board, row int, col int, dir board.BoardDirection)
@@ -383,3 +385,74 @@ func GenCrossSet(b *Board, row int, col int, dir board.BoardDirection,
 		}
 	}
 }
+
+// ----------------------------------------------------------------------
+// AnagramCrossSetGenerator generates cross sets for the WordSmog variants,
+// where a word is valid if it is an anagram of a word in the lexicon.
+
+type AnagramCrossSetGenerator struct {
+	Dist *tilemapping.LetterDistribution
+	KWG  *kwg.KWG
+}
+
+func (g *AnagramCrossSetGenerator) Generate(b *Board, row int, col int, dir board.BoardDirection) {
+	GenAnagramCrossSet(b, row, col, dir, g.KWG, g.Dist)
+}
+
+func (g *AnagramCrossSetGenerator) GenerateAll(b *Board) {
+	generateAll(g, b)
+}
+
+func (g *AnagramCrossSetGenerator) UpdateForMove(b *Board, m *move.Move) {
+	updateForMove(g, b, m)
+}
+
+func (g *AnagramCrossSetGenerator) UpdateForSmallMove(b *Board, m *tinymove.SmallMove, moveTiles *[board.MaxBoardDim]tilemapping.MachineLetter) {
+	updateForSmallMove(g, b, m, moveTiles)
+}
+
+// GenAnagramCrossSet generates the cross-set of a square for the WordSmog
+// variants. A letter is allowed if the word it makes with the tiles on
+// either side of the square is an anagram of a word.
+func GenAnagramCrossSet(b *Board, row int, col int, dir board.BoardDirection,
+	k *kwg.KWG, ld *tilemapping.LetterDistribution) {
+
+	if row < 0 || row >= b.Dim() || col < 0 || col >= b.Dim() {
+		return
+	}
+	if b.HasLetter(row, col) {
+		b.ClearCrossSet(row, col, dir)
+		b.SetCrossScore(row, col, 0, dir)
+		return
+	}
+	if b.LeftAndRightEmpty(row, col) {
+		b.SetCrossSet(row, col, board.TrivialCrossSet, dir)
+		b.SetCrossScore(row, col, 0, dir)
+		return
+	}
+	leftCol := b.WordEdge(row, col-1, Left)
+	rightCol := b.WordEdge(row, col+1, Right)
+	scoreR := b.TraverseBackwardsForScore(row, rightCol, ld)
+	scoreL := b.TraverseBackwardsForScore(row, col-1, ld)
+	b.SetCrossScore(row, col, scoreR+scoreL, dir)
+
+	// Only the letters matter, not their order. The square itself is the
+	// last letter of the word.
+	word := make(tilemapping.MachineWord, 0, rightCol-leftCol+1)
+	for c := leftCol; c <= rightCol; c++ {
+		if c != col {
+			word = append(word, b.GetLetter(row, c).Unblank())
+		}
+	}
+	word = append(word, 0)
+
+	da := kwg.DaPool.Get().(*kwg.KWGAnagrammer)
+	defer kwg.DaPool.Put(da)
+	b.SetCrossSet(row, col, 0, dir)
+	for ml := tilemapping.MachineLetter(1); ml < tilemapping.MachineLetter(ld.TileMapping().NumLetters()); ml++ {
+		word[len(word)-1] = ml
+		if valid, err := da.IsValidJumble(k, word); err == nil && valid {
+			b.SetCrossSetLetter(row, col, dir, ml)
+		}
+	}
+}
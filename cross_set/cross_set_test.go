@@ -685,3 +685,34 @@ func BenchmarkMakePlay(b *testing.B) {
 	}
 
 }
+
+func TestAnagramCrossSetsContainClassic(t *testing.T) {
+	is := is.New(t)
+
+	gd, err := kwg.Get(DefaultConfig.AllSettings(), "NWL20")
+	is.NoErr(err)
+	dist, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	alph := dist.TileMapping()
+
+	classic := board.MakeBoard(board.CrosswordGameBoard)
+	classic.SetToGame(alph, VsMatt)
+	GenAllCrossSets(classic, gd, dist)
+
+	smog := board.MakeBoard(board.CrosswordGameBoard)
+	smog.SetToGame(alph, VsMatt)
+	gen := AnagramCrossSetGenerator{Dist: dist, KWG: gd}
+	gen.GenerateAll(smog)
+
+	// Every word is an anagram of itself, so an anagram cross-set allows
+	// at least the letters that the classic one does, with the same scores.
+	for _, dir := range []board.BoardDirection{board.HorizontalDirection, board.VerticalDirection} {
+		for row := 0; row < classic.Dim(); row++ {
+			for col := 0; col < classic.Dim(); col++ {
+				cs := classic.GetCrossSet(row, col, dir)
+				is.Equal(smog.GetCrossSet(row, col, dir)&cs, cs)
+				is.Equal(smog.GetCrossScore(row, col, dir), classic.GetCrossScore(row, col, dir))
+			}
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/progress"
 	"github.com/domino14/macondo/tinymove"
 	"github.com/domino14/macondo/tinymove/conversions"
 	"github.com/domino14/word-golib/tilemapping"
@@ -58,8 +59,16 @@ const (
 
 const MaxLazySMPThreads = 10
 
+// progressCheckNodes is how many nodes are searched between checks for
+// whether a progress event is due.
+const progressCheckNodes = 1 << 12
+
 var (
 	ErrNoEndgameSolution = errors.New("no endgame solution found")
+	// ErrRackTooBig is returned for rule sets with racks that are bigger
+	// than a tinymove.SmallMove can hold.
+	ErrRackTooBig = fmt.Errorf("the endgame solver only supports racks of up to %d tiles",
+		len(tinymove.TBitMasks))
 )
 
 // Credit: MIT-licensed https://github.com/algerbrex/blunder/blob/main/engine/search.go
@@ -201,12 +210,17 @@ type Solver struct {
 	nodes           atomic.Uint64
 
 	logStream  io.Writer
+	progress   *progress.Reporter
 	busy       bool
 	threadLogs []playLog
 }
 
 // Init initializes the solver
 func (s *Solver) Init(m movegen.MoveGenerator, game *game.Game) error {
+	rs := game.Rules().RuleSet()
+	if rs.RackSize > len(tinymove.TBitMasks) {
+		return ErrRackTooBig
+	}
 	s.ttable = GlobalTranspositionTable
 	s.stmMovegen = m
 	s.game = game
@@ -219,6 +233,8 @@ func (s *Solver) Init(m movegen.MoveGenerator, game *game.Game) error {
 	if s.stmMovegen != nil {
 		s.stmMovegen.SetGenPass(true)
 		s.stmMovegen.SetPlayRecorder(movegen.AllPlaysSmallRecorder)
+		s.stmMovegen.SetRuleSet(rs)
+		s.stmMovegen.SetVariant(game.Rules().Variant())
 	}
 
 	return nil
@@ -229,6 +245,33 @@ func (s *Solver) SetLogStream(l io.Writer) {
 	s.logStream = l
 }
 
+// SetProgressListener sends the progress of solves to l: when a depth is
+// completed, when an interval has passed, and when the solve ends.
+func (s *Solver) SetProgressListener(l progress.Listener, interval time.Duration) {
+	s.progress = progress.NewReporter(l, interval)
+}
+
+// fillProgress sets the principal variation found so far on a progress event.
+func (s *Solver) fillProgress(e *progress.Event) {
+	e.Iterations = s.nodes.Load()
+	e.Value = float64(s.bestPVValue)
+	e.PV = e.PV[:0]
+	for _, m := range s.principalVariation.Moves[:s.principalVariation.numMoves] {
+		e.PV = append(e.PV, m.ShortDescription())
+	}
+	if len(e.PV) > 0 {
+		e.Leader = e.PV[0]
+	}
+}
+
+// reportDepth sends a progress event for a depth that was just completed.
+func (s *Solver) reportDepth(depth int) {
+	s.progress.Update(true, func(e *progress.Event) {
+		s.fillProgress(e)
+		e.Depth = depth
+	})
+}
+
 func (s *Solver) Movegen() movegen.MoveGenerator {
 	return s.stmMovegen
 }
@@ -258,6 +301,8 @@ func (s *Solver) makeGameCopies() error {
 		mg.SetSortingParameter(movegen.SortByNone)
 		mg.SetGenPass(true)
 		mg.SetPlayRecorder(movegen.AllPlaysSmallRecorder)
+		mg.SetRuleSet(s.game.Rules().RuleSet())
+		mg.SetVariant(s.game.Rules().Variant())
 		s.movegens = append(s.movegens, mg)
 	}
 	return nil
@@ -486,6 +531,7 @@ func (s *Solver) iterativelyDeepenLazySMP(ctx context.Context, plies int) error
 
 				s.principalVariation = pv
 				s.bestPVValue = val - int16(s.initialSpread)
+				s.reportDepth(p)
 				log.Info().
 					Int16("α", α).
 					Int16("β", β).
@@ -751,6 +797,7 @@ func (s *Solver) iterativelyDeepen(ctx context.Context, plies int) error {
 		})
 		s.principalVariation = pv
 		s.bestPVValue = val - int16(s.initialSpread)
+		s.reportDepth(p)
 	}
 	return nil
 
@@ -831,7 +878,11 @@ func (s *Solver) negamax(ctx context.Context, nodeKey uint64, depth int, α, β
 		if err != nil {
 			return 0, err
 		}
-		s.nodes.Add(1)
+		if n := s.nodes.Add(1); n%progressCheckNodes == 0 && s.progress.Due() {
+			s.progress.Update(false, func(e *progress.Event) {
+				e.Iterations = n
+			})
+		}
 		childKey := uint64(0)
 		if s.transpositionTableOptim {
 			childKey = s.ttable.Zobrist().AddMove(nodeKey, &children[idx], stmRack, moveTiles,
@@ -963,6 +1014,7 @@ func (s *Solver) Solve(ctx context.Context, plies int) (int16, []*move.Move, err
 	// + 2 since lazysmp can search at a higher ply count
 	s.game.SetStateStackLength(plies + 2)
 
+	s.progress.Start(ctx, progress.SourceEndgame)
 	g := &errgroup.Group{}
 	done := make(chan bool)
 
@@ -998,6 +1050,7 @@ func (s *Solver) Solve(ctx context.Context, plies int) (int16, []*move.Move, err
 
 	bestSeq = s.principalVariation.Moves[:s.principalVariation.numMoves]
 	bestV = s.bestPVValue
+	s.progress.Finish(s.fillProgress)
 	log.Info().Str("ttable-stats", s.ttable.Stats()).
 		Float64("time-elapsed-sec", time.Since(tstart).Seconds()).
 		Msg("solve-returning")
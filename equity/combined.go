@@ -30,6 +30,11 @@ func NewCombinedStaticCalculator(lexiconName string,
 		pegfile = PEGAdjustmentFilename
 	}
 	leaves, err := cache.Load(cfg.AllSettings(), "leavefile:"+lexiconName+":"+leaveFilename, LeaveCacheLoadFunc)
+	if err != nil && leaveFilename == WordSmogLeavesFilename {
+		// Classic leaves are still much better than none.
+		log.Info().Str("lexicon", lexiconName).Msg("no wordsmog leaves, using classic leaves")
+		leaves, err = cache.Load(cfg.AllSettings(), "leavefile:"+lexiconName+":"+LeavesFilename, LeaveCacheLoadFunc)
+	}
 	if err != nil {
 		log.Err(err).Msg("loading-leaves")
 	}
@@ -66,7 +71,10 @@ func (csc CombinedStaticCalculator) Equity(play *move.Move, board *board.GameBoa
 
 	if bag.TilesRemaining() > 0 {
 		leaveAdjustment = csc.leaveValues.LeaveValue(leave)
-		bagPlusSeven := bag.TilesRemaining() - play.TilesPlayed() + 7
+		// This is the bag plus a full rack (of seven tiles in classic
+		// rules) after the play. The rack is full while there are tiles in
+		// the bag, so this works for any rack size.
+		bagPlusSeven := bag.TilesRemaining() + len(leave)
 		if bagPlusSeven < len(csc.preEndgameAdjustmentValues) {
 			preEndgameAdjustment := csc.preEndgameAdjustmentValues[bagPlusSeven]
 			// log.Debug().Float64("peg-adjust", preEndgameAdjustment).Int("bagPlusSeven", bagPlusSeven).Msg("equity calc")
@@ -27,7 +27,8 @@ func endgameAdjustment(play *move.Move, oppRack *tilemapping.Rack, ld *tilemappi
 		// plus some constant. XXX: Determine this in a better way.
 		return -float64(play.Leave().Score(ld))*2 - 10
 	}
-	// Otherwise, this play goes out. Apply opp rack.
+	// Otherwise, this play goes out. Apply opp rack. This is the change in
+	// spread with both variant.EndRackDouble and variant.EndRackTransfer.
 	if oppRack == nil {
 		return 0
 	}
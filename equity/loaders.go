@@ -9,13 +9,31 @@ import (
 
 	"github.com/domino14/word-golib/cache"
 	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/variant"
 )
 
 const (
-	PEGAdjustmentFilename = "preendgame.json"
-	LeavesFilename        = "leaves.klv2"
+	PEGAdjustmentFilename  = "preendgame.json"
+	LeavesFilename         = "leaves.klv2"
+	SuperLeavesFilename    = "super-leaves.klv2"
+	WordSmogLeavesFilename = "wordsmog-leaves.klv2"
 )
 
+// LeavesFilenameFor returns the leave file for games on the given board
+// layout and variant, or "" for the default leave file. Leaves are worth
+// more in WordSmog, where any anagram of a word can be played.
+func LeavesFilenameFor(boardLayout string, va variant.Variant) string {
+	switch {
+	case boardLayout == board.SuperCrosswordGameLayout:
+		return SuperLeavesFilename
+	case va.IsWordSmog():
+		return WordSmogLeavesFilename
+	}
+	return ""
+}
+
 func stratFileForLexicon(strategyDir string, filename string, lexiconName string) (io.ReadCloser, error) {
 	file, _, err := cache.Open(filepath.Join(strategyDir, lexiconName, filename))
 	if err != nil {
@@ -152,6 +152,7 @@ func (g *Game) ResetToFirstState() {
 func (g *Game) Copy() *Game {
 	copy := &Game{
 		config:            g.config,
+		rules:             g.rules,
 		onturn:            g.onturn,
 		turnnum:           g.turnnum,
 		board:             g.board.Copy(),
@@ -167,6 +168,8 @@ func (g *Game) Copy() *Game {
 		// only be called at the beginning of everything.
 		stackPtr: 0,
 	}
+	copy.seed = g.seed
+	copy.pcg, copy.rng = g.copyRNG()
 	// Also set the copy's stack.
 	copy.SetStateStackLength(len(g.stateStack))
 	return copy
@@ -0,0 +1,230 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/board"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/variant"
+)
+
+// LastPlayWithdrawn is appended to the lp opcode of a CGP when the last play
+// was a phony that was taken back.
+const LastPlayWithdrawn = "--"
+
+var challengeRuleNames = map[pb.ChallengeRule]string{
+	pb.ChallengeRule_VOID:       "void",
+	pb.ChallengeRule_SINGLE:     "single",
+	pb.ChallengeRule_DOUBLE:     "double",
+	pb.ChallengeRule_TRIPLE:     "triple",
+	pb.ChallengeRule_FIVE_POINT: "5pt",
+	pb.ChallengeRule_TEN_POINT:  "10pt",
+}
+
+// ChallengeRuleName returns the short name of a challenge rule, as it is
+// written in a CGP or typed in the shell.
+func ChallengeRuleName(rule pb.ChallengeRule) string {
+	return challengeRuleNames[rule]
+}
+
+// ParseChallengeRuleName is the reverse of ChallengeRuleName.
+func ParseChallengeRuleName(name string) (pb.ChallengeRule, error) {
+	for rule, n := range challengeRuleNames {
+		if n == name {
+			return rule, nil
+		}
+	}
+	return pb.ChallengeRule_VOID, errors.New("Valid options: 'void', 'single', 'double', 'triple', '5pt', '10pt'")
+}
+
+// cgpOpcodes are the opcodes that WriteCGP writes itself. Other opcodes
+// of the CGP that the game was loaded from are copied.
+var cgpOpcodes = []string{"bag", "bb", "bdn", "cr", "gid", "ld", "lex", "lp", "mcnz",
+	"rs", "seed", "tc", "tmr", "var"}
+
+// CGPOptions changes what WriteCGP writes.
+type CGPOptions struct {
+	// ForBot leaves out what the player on turn could not know: the
+	// opponent's rack (unless it was just shown by a phony that came off),
+	// the tiles they exchanged, and the game's seed.
+	ForBot bool
+	// WithBag writes the tiles in the bag with the bag opcode.
+	WithBag bool
+}
+
+// ToCGP converts the game to a CGP string. See cgp directory.
+func (g *Game) ToCGP(formatForBot bool) string {
+	return g.WriteCGP(CGPOptions{ForBot: formatForBot})
+}
+
+// WriteCGP converts the game to a CGP string. Opcodes are only written if
+// they differ from what a CGP without them would mean, so that parsing
+// the string again gives back the same game.
+func (g *Game) WriteCGP(opts CGPOptions) string {
+	fen := g.board.ToFEN(g.alph)
+	ourRack := g.curPlayer().rack.TilesOn().UserVisible(g.alph)
+	theirRack := g.oppPlayer().rack.TilesOn().UserVisible(g.alph)
+	ourScore := g.curPlayer().points
+	theirScore := g.oppPlayer().points
+	zeroPt := g.scorelessTurns
+	lex := g.lexicon.Name()
+	ld := ""
+	if g.history != nil {
+		ld = g.history.LetterDistribution
+	}
+	if opts.ForBot {
+		// Clear opponent rack -- if this is a bot move, bot should know
+		// nothing of it.
+		theirRack = ""
+		tm := g.letterDistribution.TileMapping()
+		if g.history != nil && g.turnnum > 0 {
+			oppEvt := g.history.Events[g.turnnum-1]
+			if oppEvt.Type == pb.GameEvent_PHONY_TILES_RETURNED {
+				// we know opp's last partial or full rack
+				if tiles, err := tilemapping.ToMachineLetters(oppEvt.PlayedTiles, tm); err != nil {
+					log.Err(err).Str("playedTiles", oppEvt.PlayedTiles).Msg("unable-to-convert-opp-rack")
+				} else {
+					// convert back to string without the play-through tiles
+					for _, t := range tiles {
+						if t == 0 {
+							continue
+						}
+						theirRack += t.IntrinsicTileIdx().UserVisible(tm, false)
+					}
+				}
+			}
+		}
+	}
+
+	cgp := fmt.Sprintf("%s %s/%s %d/%d %d lex %s;",
+		fen, ourRack, theirRack, ourScore, theirScore, zeroPt, lex)
+	if ld != "" {
+		cgp += fmt.Sprintf(" ld %s;", ld)
+	}
+	if rs := g.rules.ruleset; rs != g.rules.variant.DefaultRuleSet() {
+		cgp += fmt.Sprintf(" rs %s; bb %d; mcnz %d;", rs.Name, rs.BingoBonus, rs.MaxScorelessTurns)
+	}
+	if bdn := g.rules.BoardName(); bdn != "" && bdn != board.CrosswordGameLayout {
+		cgp += fmt.Sprintf(" bdn %s;", bdn)
+	}
+	if va := g.rules.Variant(); va != "" && va != variant.VarClassic {
+		cgp += fmt.Sprintf(" var %s;", va)
+	}
+	if g.history != nil {
+		// Void is written too, as the shell loads a CGP without cr as double.
+		cgp += fmt.Sprintf(" cr %s;", ChallengeRuleName(g.history.ChallengeRule))
+		if g.history.Uid != "" {
+			cgp += fmt.Sprintf(" gid %s;", g.history.Uid)
+		}
+	}
+	if seed, ok := g.Seed(); ok && !opts.ForBot {
+		cgp += fmt.Sprintf(" seed %d;", seed)
+	}
+	if g.clock != nil {
+		// The time control is not known if only the times left were.
+		if g.clock.TimeControl().InitialMillis > 0 {
+			cgp += fmt.Sprintf(" tc %s;", g.clock.TimeControl())
+		}
+		cgp += fmt.Sprintf(" tmr %d/%d;", g.clock.Remaining(g.onturn), g.clock.Remaining(g.NextPlayer()))
+	}
+	if lp := g.lastPlayCGP(opts.ForBot); lp != "" {
+		cgp += fmt.Sprintf(" lp %s;", lp)
+	}
+	if opts.WithBag {
+		tiles := g.bag.Peek()
+		slices.Sort(tiles)
+		if len(tiles) == 0 {
+			cgp += " bag;"
+		} else {
+			cgp += fmt.Sprintf(" bag %s;", tilemapping.MachineWord(tiles).UserVisible(g.alph))
+		}
+	}
+	if g.history != nil {
+		for _, op := range otherCGPOpcodes(g.history.StartingCgp) {
+			cgp += " " + op + ";"
+		}
+	}
+	return cgp
+}
+
+// otherCGPOpcodes returns the opcodes of a CGP string that WriteCGP does
+// not write itself, with their arguments, in order.
+func otherCGPOpcodes(cgp string) []string {
+	fields := strings.SplitN(cgp, " ", 5)
+	if len(fields) < 5 {
+		return nil
+	}
+	var ops []string
+	for _, op := range strings.Split(fields[4], ";") {
+		op = strings.TrimSpace(op)
+		code, _, _ := strings.Cut(op, " ")
+		if op != "" && !slices.Contains(cgpOpcodes, code) {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// lastPlayCGP returns the last play before the current turn, the way the
+// lp opcode has it. If the game has no turns yet, it returns the last play
+// of the CGP that the game was loaded from.
+func (g *Game) lastPlayCGP(forBot bool) string {
+	if g.history == nil {
+		return ""
+	}
+	withdrawn := false
+	for i := g.turnnum - 1; i >= 0; i-- {
+		evt := g.history.Events[i]
+		switch evt.Type {
+		case pb.GameEvent_TILE_PLACEMENT_MOVE:
+			lp := evt.Position + " " + evt.PlayedTiles
+			if withdrawn {
+				lp += " " + LastPlayWithdrawn
+			}
+			return lp
+		case pb.GameEvent_PASS, pb.GameEvent_UNSUCCESSFUL_CHALLENGE_TURN_LOSS:
+			return "-"
+		case pb.GameEvent_EXCHANGE:
+			if forBot && evt.Exchanged != "" {
+				// Only the number of tiles is known.
+				tiles, err := tilemapping.ToMachineLetters(evt.Exchanged, g.alph)
+				if err != nil {
+					return "-"
+				}
+				return fmt.Sprintf("-%d", len(tiles))
+			}
+			return "-" + evt.Exchanged
+		case pb.GameEvent_PHONY_TILES_RETURNED:
+			withdrawn = true
+		}
+		// Challenge bonuses and end-of-game events are not plays; the
+		// play is before them.
+	}
+	if g.turnnum == 0 {
+		lp, _ := CGPOpcode(g.history.StartingCgp, "lp")
+		return lp
+	}
+	return ""
+}
+
+// CGPOpcode returns the argument of the given opcode in a CGP string, and
+// whether the opcode is there at all.
+func CGPOpcode(cgp, name string) (string, bool) {
+	fields := strings.SplitN(cgp, " ", 5)
+	if len(fields) < 5 {
+		return "", false
+	}
+	for _, op := range strings.Split(fields[4], ";") {
+		code, arg, _ := strings.Cut(strings.TrimSpace(op), " ")
+		if code == name {
+			return arg, true
+		}
+	}
+	return "", false
+}
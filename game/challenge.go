@@ -43,6 +43,7 @@ func (g *Game) ChallengeEvent(addlBonus int, millis int) (bool, error) {
 	}
 	// Note that the player on turn right now needs to be the player
 	// who is making the challenge.
+	millis = g.clockMillis(millis)
 	illegalWords := validateWords(g.lexicon, g.lastWordsFormed, g.rules.Variant())
 	playLegal := len(illegalWords) == 0
 
@@ -129,7 +130,7 @@ func (g *Game) ChallengeEvent(addlBonus int, millis int) (bool, error) {
 
 		// Finally, let's re-shuffle the bag. This is so we don't give the
 		// player who played the phony knowledge about the next few tiles in the bag.
-		g.bag.Shuffle()
+		g.shuffleBag()
 	} else {
 		log.Debug().Msg("Unsuccessful challenge")
 
@@ -204,7 +205,7 @@ func validateWords(lex lexicon.Lexicon, words []tilemapping.MachineWord, va vari
 	alph := lex.GetAlphabet()
 	for _, word := range words {
 		var valid bool
-		if va == variant.VarWordSmog || va == variant.VarWordSmogSuper {
+		if va.IsWordSmog() {
 			valid = lex.HasAnagram(word)
 		} else if va == variant.VarGmo {
 			// TODO: should be possible to look up the word in reverse order directly instead of allocating a separate slice
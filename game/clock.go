@@ -0,0 +1,263 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+const (
+	// DefaultOvertimePenalty is the number of points lost for every minute
+	// (or part of one) over time.
+	DefaultOvertimePenalty = 10
+	// DefaultMaxOvertimeMinutes is how long a player can go over time
+	// before their flag falls.
+	DefaultMaxOvertimeMinutes = 10
+)
+
+// TimeControl describes the clock of a game.
+type TimeControl struct {
+	InitialMillis   int
+	IncrementMillis int
+	// MaxOvertimeMinutes is how long a player can go over time before
+	// their flag falls and they lose the game. If it is 0, the flag falls
+	// as soon as the player runs out of time.
+	MaxOvertimeMinutes int
+	// OvertimePenalty is the number of points lost at the end of the game
+	// for every minute, or part of one, that a player went over time.
+	OvertimePenalty int
+}
+
+// ParseTimeControl parses a time control such as "25m", "20m+5s" or
+// "15m+0s/3", where the number after the slash is the maximum overtime in
+// minutes. The overtime penalty is always DefaultOvertimePenalty.
+func ParseTimeControl(s string) (TimeControl, error) {
+	tc := TimeControl{
+		MaxOvertimeMinutes: DefaultMaxOvertimeMinutes,
+		OvertimePenalty:    DefaultOvertimePenalty,
+	}
+	if before, after, ok := strings.Cut(s, "/"); ok {
+		ot, err := strconv.Atoi(after)
+		if err != nil {
+			return tc, fmt.Errorf("bad maximum overtime %q: %w", after, err)
+		}
+		tc.MaxOvertimeMinutes = ot
+		s = before
+	}
+	initial, increment, hasIncrement := strings.Cut(s, "+")
+	d, err := time.ParseDuration(initial)
+	if err != nil {
+		return tc, err
+	}
+	tc.InitialMillis = int(d.Milliseconds())
+	if hasIncrement {
+		d, err = time.ParseDuration(increment)
+		if err != nil {
+			return tc, err
+		}
+		tc.IncrementMillis = int(d.Milliseconds())
+	}
+	if tc.InitialMillis <= 0 {
+		return tc, errors.New("initial time must be positive")
+	}
+	return tc, nil
+}
+
+func (tc TimeControl) String() string {
+	s := (time.Duration(tc.InitialMillis) * time.Millisecond).String()
+	if tc.IncrementMillis > 0 {
+		s += "+" + (time.Duration(tc.IncrementMillis) * time.Millisecond).String()
+	}
+	return fmt.Sprintf("%s/%d", s, tc.MaxOvertimeMinutes)
+}
+
+// Clock is a chess clock for a game. Remaining times are in milliseconds,
+// and go negative when a player is over time.
+type Clock struct {
+	tc        TimeControl
+	remaining []int
+	// onturn is the player whose clock is running, or -1 if it is stopped.
+	onturn    int
+	turnStart time.Time
+	now       func() time.Time
+}
+
+func NewClock(tc TimeControl, numPlayers int) *Clock {
+	c := &Clock{
+		tc:        tc,
+		remaining: make([]int, numPlayers),
+		onturn:    -1,
+		now:       time.Now,
+	}
+	for i := range c.remaining {
+		c.remaining[i] = tc.InitialMillis
+	}
+	return c
+}
+
+// SetNowFunc replaces the function the clock uses to tell the time. It is
+// meant for tests.
+func (c *Clock) SetNowFunc(now func() time.Time) {
+	c.now = now
+}
+
+func (c *Clock) TimeControl() TimeControl {
+	return c.tc
+}
+
+// Start starts the given player's clock, stopping any other.
+func (c *Clock) Start(player int) {
+	c.Stop()
+	c.onturn = player
+	c.turnStart = c.now()
+}
+
+// Stop stops the running clock, if any.
+func (c *Clock) Stop() {
+	if c.onturn < 0 {
+		return
+	}
+	c.remaining[c.onturn] -= int(c.now().Sub(c.turnStart).Milliseconds())
+	c.onturn = -1
+}
+
+// Running returns the player whose clock is running, or -1.
+func (c *Clock) Running() int {
+	return c.onturn
+}
+
+// Press ends the given player's turn: it stops their clock, adds the
+// increment, and starts the next player's clock. It returns the player's
+// remaining time.
+func (c *Clock) Press(player int) int {
+	if c.onturn == player {
+		c.Stop()
+	}
+	c.remaining[player] += c.tc.IncrementMillis
+	c.Start((player + 1) % len(c.remaining))
+	return c.remaining[player]
+}
+
+// Remaining returns the player's remaining time, including the time used
+// so far in their turn.
+func (c *Clock) Remaining(player int) int {
+	r := c.remaining[player]
+	if c.onturn == player {
+		r -= int(c.now().Sub(c.turnStart).Milliseconds())
+	}
+	return r
+}
+
+// SetRemaining sets the player's remaining time, for example to match the
+// time that a server reports. If the player's clock is running, their turn
+// starts over now.
+func (c *Clock) SetRemaining(player, millis int) {
+	c.remaining[player] = millis
+	if c.onturn == player {
+		c.turnStart = c.now()
+	}
+}
+
+// Flagged returns whether the player has gone over the maximum overtime.
+func (c *Clock) Flagged(player int) bool {
+	return c.Remaining(player) < -c.tc.MaxOvertimeMinutes*60000
+}
+
+// Penalty returns the number of points the player loses for going over
+// time.
+func (c *Clock) Penalty(player int) int {
+	over := -c.Remaining(player)
+	if over <= 0 {
+		return 0
+	}
+	// Every minute or part of one counts.
+	minutes := (over + 59999) / 60000
+	return minutes * c.tc.OvertimePenalty
+}
+
+// SetClock sets the game's clock. The clock of the player on turn starts
+// running, unless the game is over. A nil clock removes it.
+func (g *Game) SetClock(c *Clock) {
+	g.clock = c
+	if c != nil && g.playing != pb.PlayState_GAME_OVER {
+		c.Start(g.onturn)
+	}
+}
+
+// Clock returns the game's clock, or nil if it has none.
+func (g *Game) Clock() *Clock {
+	return g.clock
+}
+
+// pressClock ends the turn of the player on turn and returns their
+// remaining time. If millis is not 0, it is taken to be their remaining
+// time, as reported from elsewhere.
+func (g *Game) pressClock(millis int) int {
+	if g.clock == nil {
+		return millis
+	}
+	if millis != 0 {
+		g.clock.SetRemaining(g.onturn, millis)
+		g.clock.Start((g.onturn + 1) % len(g.players))
+		return millis
+	}
+	return g.clock.Press(g.onturn)
+}
+
+// clockMillis returns the remaining time of the player on turn, if millis
+// is 0 and the game has a clock.
+func (g *Game) clockMillis(millis int) int {
+	if g.clock == nil || millis != 0 {
+		return millis
+	}
+	return g.clock.Remaining(g.onturn)
+}
+
+// addTimePenalties adds a TIME_PENALTY event for every player who went
+// over time. It is called at the end of the game.
+func (g *Game) addTimePenalties() {
+	if g.clock == nil {
+		return
+	}
+	g.clock.Stop()
+	for pidx := range g.players {
+		penalty := g.clock.Penalty(pidx)
+		if penalty == 0 {
+			continue
+		}
+		g.players[pidx].points -= penalty
+		g.turnnum++
+		g.addEventToHistory(&pb.GameEvent{
+			PlayerIndex:     uint32(pidx),
+			Type:            pb.GameEvent_TIME_PENALTY,
+			Rack:            g.players[pidx].rackLetters(),
+			LostScore:       int32(penalty),
+			Cumulative:      int32(g.players[pidx].points),
+			MillisRemaining: int32(g.clock.Remaining(pidx)),
+		})
+	}
+	// Only once.
+	g.clock = nil
+}
+
+// CheckFlag ends the game if the player on turn has gone over the maximum
+// overtime. Their opponent wins, no matter the score. It returns whether
+// the flag fell.
+func (g *Game) CheckFlag() bool {
+	if g.clock == nil || g.playing == pb.PlayState_GAME_OVER || !g.clock.Flagged(g.onturn) {
+		return false
+	}
+	flagged := g.onturn
+	log.Debug().Int("player", flagged).Msg("flag-fell")
+	g.playing = pb.PlayState_GAME_OVER
+	g.history.PlayState = g.playing
+	g.AddFinalScoresToHistory()
+	g.history.Winner = int32(otherPlayer(flagged))
+	return true
+}
@@ -0,0 +1,132 @@
+package game_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/variant"
+)
+
+// fakeTime is a clock time that only moves when told to.
+type fakeTime struct {
+	t time.Time
+}
+
+func (f *fakeTime) now() time.Time          { return f.t }
+func (f *fakeTime) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func timedGame(t *testing.T, tc game.TimeControl) (*game.Game, *fakeTime) {
+	is := is.New(t)
+	players := []*pb.PlayerInfo{
+		{Nickname: "JD", RealName: "Jesse"},
+		{Nickname: "cesar", RealName: "César"},
+	}
+	rules, err := game.NewBasicGameRules(&DefaultConfig, "NWL18", board.CrosswordGameLayout, "English", game.CrossScoreAndSet, variant.VarClassic)
+	is.NoErr(err)
+	g, err := game.NewGame(rules, players)
+	is.NoErr(err)
+	g.StartGame()
+	g.SetPlayerOnTurn(0)
+	g.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	ft := &fakeTime{t: time.Unix(1700000000, 0)}
+	clock := game.NewClock(tc, 2)
+	clock.SetNowFunc(ft.now)
+	g.SetClock(clock)
+	return g, ft
+}
+
+func pass(g *game.Game) error {
+	return g.PlayMove(move.NewPassMove(g.RackFor(g.PlayerOnTurn()).TilesOn(), g.Alphabet()), true, 0)
+}
+
+func TestParseTimeControl(t *testing.T) {
+	is := is.New(t)
+	tc, err := game.ParseTimeControl("20m+5s/3")
+	is.NoErr(err)
+	is.Equal(tc, game.TimeControl{InitialMillis: 1200000, IncrementMillis: 5000,
+		MaxOvertimeMinutes: 3, OvertimePenalty: game.DefaultOvertimePenalty})
+	is.Equal(tc.String(), "20m0s+5s/3")
+
+	tc, err = game.ParseTimeControl("25m")
+	is.NoErr(err)
+	is.Equal(tc.InitialMillis, 1500000)
+	is.Equal(tc.IncrementMillis, 0)
+	is.Equal(tc.MaxOvertimeMinutes, game.DefaultMaxOvertimeMinutes)
+
+	_, err = game.ParseTimeControl("0s")
+	is.True(err != nil)
+	_, err = game.ParseTimeControl("25m/x")
+	is.True(err != nil)
+}
+
+func TestClockMillisRemaining(t *testing.T) {
+	is := is.New(t)
+	g, ft := timedGame(t, game.TimeControl{InitialMillis: 60000, IncrementMillis: 2000,
+		OvertimePenalty: 10})
+
+	ft.advance(30 * time.Second)
+	is.Equal(g.Clock().Remaining(0), 30000)
+	is.NoErr(pass(g))
+	is.Equal(g.History().Events[0].MillisRemaining, int32(32000))
+	// It's the other player's time that runs now.
+	ft.advance(10 * time.Second)
+	is.Equal(g.Clock().Remaining(0), 32000)
+	is.Equal(g.Clock().Remaining(1), 50000)
+
+	// Times that are passed in win over the clock.
+	is.NoErr(g.PlayMove(move.NewPassMove(g.RackFor(1).TilesOn(), g.Alphabet()), true, 45000))
+	is.Equal(g.History().Events[1].MillisRemaining, int32(45000))
+	is.Equal(g.Clock().Remaining(1), 45000)
+	is.Equal(g.Clock().Running(), 0)
+}
+
+func TestTimePenaltyAtGameEnd(t *testing.T) {
+	is := is.New(t)
+	g, ft := timedGame(t, game.TimeControl{InitialMillis: 60000, MaxOvertimeMinutes: 10,
+		OvertimePenalty: 10})
+
+	// 1.5 minutes over time is 2 started minutes.
+	ft.advance(150 * time.Second)
+	for i := 0; i < 6; i++ {
+		is.NoErr(pass(g))
+	}
+	is.Equal(g.Playing(), pb.PlayState_GAME_OVER)
+	evts := g.History().Events
+	last := evts[len(evts)-1]
+	is.Equal(last.Type, pb.GameEvent_TIME_PENALTY)
+	is.Equal(last.PlayerIndex, uint32(0))
+	is.Equal(last.LostScore, int32(20))
+	is.Equal(last.MillisRemaining, int32(-90000))
+	is.Equal(g.History().FinalScores[0], last.Cumulative)
+	is.Equal(int32(g.PointsFor(0)), last.Cumulative)
+	// Player 1 was not over time.
+	for _, evt := range evts {
+		is.True(evt.Type != pb.GameEvent_TIME_PENALTY || evt.PlayerIndex == 0)
+	}
+	// Penalties are only added once.
+	g.AddFinalScoresToHistory()
+	is.Equal(len(g.History().Events), len(evts))
+}
+
+func TestFlagFall(t *testing.T) {
+	is := is.New(t)
+	g, ft := timedGame(t, game.TimeControl{InitialMillis: 60000, MaxOvertimeMinutes: 1,
+		OvertimePenalty: 10})
+	g.SetPointsFor(0, 300)
+
+	ft.advance(2 * time.Minute)
+	is.True(!g.CheckFlag())
+	ft.advance(time.Millisecond)
+	is.True(g.CheckFlag())
+	is.Equal(g.Playing(), pb.PlayState_GAME_OVER)
+	// Player 0 is ahead, even after the penalty, but their flag fell.
+	is.Equal(g.History().FinalScores[0], int32(280))
+	is.Equal(g.History().Winner, int32(1))
+	is.True(!g.CheckFlag())
+}
@@ -5,6 +5,8 @@ package game
 import (
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"slices"
 	"strings"
 
 	"github.com/domino14/word-golib/tilemapping"
@@ -18,6 +20,7 @@ import (
 	"github.com/domino14/macondo/lexicon"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/tinymove"
+	"github.com/domino14/macondo/variant"
 )
 
 const (
@@ -26,6 +29,8 @@ const (
 
 	MacondoCreation = "Created with Macondo"
 
+	// ExchangeLimit and RackTileLimit are for the classic rule set. Games
+	// follow the rule set of their GameRules; see RuleSet.
 	ExchangeLimit = 7
 	RackTileLimit = 7
 
@@ -72,11 +77,19 @@ type Game struct {
 	stackPtr   int
 	// rules contains the original game rules passed in to create this game.
 	rules *GameRules
+	// clock is nil if the game is untimed. It is not copied, as copies
+	// are for simming.
+	clock *Clock
 
 	// sturnsBackup - a variable used to hold value of scorelessTurns prior to
 	// putting game in endgame mode.
 	sturnsBackup int
 	stripBackup  [board.MaxBoardDim]tilemapping.MachineLetter
+
+	// seed, pcg and rng are only set if the game is seeded. See seed.go.
+	seed uint64
+	pcg  *rand.PCG
+	rng  *rand.Rand
 }
 
 func (g *Game) Config() *config.Config {
@@ -167,12 +180,17 @@ func NewGame(rules *GameRules, playerinfo []*pb.PlayerInfo) (*Game, error) {
 	game.lexicon = rules.Lexicon()
 	game.config = rules.Config()
 	game.rules = rules
-	game.maxScorelessTurns = DefaultMaxScorelessTurns
-	game.bag = game.letterDistribution.MakeBag()
+	game.maxScorelessTurns = rules.ruleset.MaxScorelessTurns
+	game.newBag()
+	if game.config != nil {
+		if seed := game.config.GetUint64(config.ConfigSeed); seed != 0 {
+			game.SetSeed(seed)
+		}
+	}
 	game.players = make([]*playerState, len(playerinfo))
 	ids := map[string]bool{}
 	for idx, p := range playerinfo {
-		game.players[idx] = newPlayerState(p.Nickname, p.UserId, p.RealName)
+		game.players[idx] = newPlayerState(p.Nickname, p.UserId, p.RealName, rules.ruleset.RackSize)
 		ids[p.Nickname] = true
 	}
 	if len(ids) < len(playerinfo) {
@@ -204,7 +222,7 @@ func NewFromHistory(history *pb.GameHistory, rules *GameRules, turnnum int) (*Ga
 	}
 
 	// Initialize the bag and player rack structures to avoid panics.
-	game.bag = game.letterDistribution.MakeBag()
+	game.newBag()
 	for i := 0; i < game.NumPlayers(); i++ {
 		game.players[i].rack = tilemapping.NewRack(game.alph)
 	}
@@ -227,7 +245,7 @@ func NewFromSnapshot(rules *GameRules, players []*pb.PlayerInfo, lastKnownRacks
 
 	game.history = newHistory(game.players)
 
-	game.bag = game.letterDistribution.MakeBag()
+	game.newBag()
 	for i := 0; i < game.NumPlayers(); i++ {
 		game.players[i].rack = tilemapping.NewRack(game.alph)
 	}
@@ -303,18 +321,29 @@ func (g *Game) RenamePlayer(idx int, playerinfo *pb.PlayerInfo) error {
 
 // StartGame starts a game anew, dealing out tiles to both players.
 func (g *Game) StartGame() {
+	g.newBag()
+	g.StartGameWithBag(g.bag)
+}
+
+// StartGameWithBag starts the game with the given bag. This is useful for
+// replaying games with the same tile order; see Bag.SetFixedOrder.
+func (g *Game) StartGameWithBag(bag *tilemapping.Bag) {
 	g.Board().Clear()
-	g.bag = g.letterDistribution.MakeBag()
+	g.bag = bag
+	if g.rng != nil {
+		g.bag.SetFixedOrder(true)
+	}
 	g.history = newHistory(g.players)
 	// Deal out tiles
 	for i := 0; i < g.NumPlayers(); i++ {
 
-		err := g.bag.Draw(7, g.players[i].placeholderRack)
+		rackSize := g.rules.ruleset.RackSize
+		err := g.bag.Draw(rackSize, g.players[i].placeholderRack)
 		if err != nil {
 			panic(err)
 		}
 		g.players[i].rack = tilemapping.NewRack(g.alph)
-		g.players[i].setRackTiles(g.players[i].placeholderRack[:7], g.alph)
+		g.players[i].setRackTiles(g.players[i].placeholderRack[:rackSize], g.alph)
 		g.players[i].resetScore()
 	}
 	g.history.LastKnownRacks = []string{
@@ -351,9 +380,9 @@ func (g *Game) ValidateMove(m *move.Move) ([]tilemapping.MachineWord, error) {
 		if g.playing == pb.PlayState_WAITING_FOR_FINAL_PASS {
 			return nil, errors.New("you can only pass or challenge")
 		}
-		if g.bag.TilesRemaining() < ExchangeLimit {
+		if limit := g.rules.ruleset.ExchangeLimit; g.bag.TilesRemaining() < limit {
 			return nil, fmt.Errorf("not allowed to exchange with fewer than %d tiles in the bag",
-				ExchangeLimit)
+				limit)
 		}
 		// Make sure we have the tiles we are trying to exchange.
 		for _, t := range m.Tiles() {
@@ -389,7 +418,7 @@ func (g *Game) ValidateMove(m *move.Move) ([]tilemapping.MachineWord, error) {
 }
 
 func (g *Game) validateTilePlayMove(m *move.Move) ([]tilemapping.MachineWord, error) {
-	if m.TilesPlayed() > RackTileLimit {
+	if m.TilesPlayed() > g.rules.ruleset.RackSize {
 		return nil, errors.New("your play contained too many tiles")
 	}
 	// Check that our move actually uses the tiles on our rack.
@@ -422,12 +451,28 @@ func (g *Game) validateTilePlayMove(m *move.Move) ([]tilemapping.MachineWord, er
 }
 
 func (g *Game) endOfGameCalcs(onturn int, addToHistory bool) {
-	unplayedPts := g.calculateRackPts(otherPlayer(onturn)) * 2
+	opp := otherPlayer(onturn)
+	unplayedPts := g.calculateRackPts(opp)
+	if g.rules.ruleset.EndRackScoring == variant.EndRackTransfer {
+		g.players[opp].points -= unplayedPts
+	} else {
+		unplayedPts *= 2
+	}
 
 	g.players[onturn].points += unplayedPts
 	if addToHistory {
 		g.turnnum++ // since we're adding a new event.
 		g.addEventToHistory(g.endRackEvt(onturn, unplayedPts))
+		if g.rules.ruleset.EndRackScoring == variant.EndRackTransfer {
+			g.turnnum++
+			g.addEventToHistory(&pb.GameEvent{
+				PlayerIndex: uint32(opp),
+				Cumulative:  int32(g.players[opp].points),
+				Rack:        g.players[opp].rack.String(),
+				LostScore:   int32(unplayedPts),
+				Type:        pb.GameEvent_END_RACK_PENALTY,
+			})
+		}
 	}
 	// log.Debug().Int("onturn", onturn).Int("unplayedpts", unplayedPts).Interface("players", g.players).
 	// 	Msg("endOfGameCalcs")
@@ -457,7 +502,7 @@ func (g *Game) SetEndgameMode(m bool) {
 		}
 		g.maxScorelessTurns = 2
 	} else {
-		g.maxScorelessTurns = DefaultMaxScorelessTurns
+		g.maxScorelessTurns = g.rules.ruleset.MaxScorelessTurns
 		g.scorelessTurns = g.sturnsBackup
 	}
 }
@@ -477,7 +522,8 @@ func convertToVisible(words []tilemapping.MachineWord,
 // by simulators as it implements a subset of possible moves, and by remote
 // gameplay engines as much as possible.
 // If the millis argument is passed in, it adds this value to the history
-// as the time remaining for the user (when they played the move).
+// as the time remaining for the user (when they played the move). If it is
+// 0 and the game has a clock, the time remaining comes from the clock.
 func (g *Game) PlayMove(m *move.Move, addToHistory bool, millis int) error {
 
 	// We need to handle challenges separately.
@@ -496,6 +542,7 @@ func (g *Game) PlayMove(m *move.Move, addToHistory bool, millis int) error {
 			return err
 		}
 		g.lastWordsFormed = wordsFormed
+		millis = g.pressClock(millis)
 	}
 
 	switch m.Action() {
@@ -511,7 +558,7 @@ func (g *Game) PlayMove(m *move.Move, addToHistory bool, millis int) error {
 		g.scorelessTurns = 0
 		g.players[g.onturn].points += score
 		g.players[g.onturn].turns += 1
-		if m.TilesPlayed() == RackTileLimit {
+		if g.rules.ruleset.IsBingo(m.TilesPlayed()) {
 			g.players[g.onturn].bingos++
 		}
 		drew := g.bag.DrawAtMost(m.TilesPlayed(), g.players[g.onturn].placeholderRack)
@@ -580,6 +627,7 @@ func (g *Game) PlayMove(m *move.Move, addToHistory bool, millis int) error {
 		if err != nil {
 			return err
 		}
+		g.reseedBag()
 		copy(g.players[g.onturn].placeholderRack[len(m.Tiles()):], []tilemapping.MachineLetter(m.Leave()))
 		g.players[g.onturn].setRackTiles(g.players[g.onturn].placeholderRack[:len(m.Tiles())+len(m.Leave())], g.alph)
 		g.lastScorelessTurns = g.scorelessTurns
@@ -637,7 +685,7 @@ func (g *Game) PlaySmallMove(m *tinymove.SmallMove) (
 		g.scorelessTurns = 0
 		g.players[g.onturn].points += score
 		g.players[g.onturn].turns += 1
-		if m.TilesPlayed() == RackTileLimit {
+		if g.rules.ruleset.IsBingo(m.TilesPlayed()) {
 			g.players[g.onturn].bingos++
 		}
 		// XXX: assume we are only using this for endgames! Drawing doesn't work
@@ -666,7 +714,9 @@ func (g *Game) PlaySmallMove(m *tinymove.SmallMove) (
 }
 
 // AddFinalScoresToHistory adds the final scores and winner to the history.
+// If the game has a clock, players who went over time lose points first.
 func (g *Game) AddFinalScoresToHistory() {
+	g.addTimePenalties()
 	g.history.FinalScores = make([]int32, len(g.players))
 	for pidx, p := range g.players {
 		g.history.FinalScores[pidx] = int32(p.points)
@@ -743,8 +793,18 @@ func (g *Game) CreateAndScorePlacementMove(coords string, tiles string, rack str
 	if err != nil {
 		return nil, err
 	}
+	return g.CreateAndScorePlacementMoveFromTiles(row, col, vertical, mw, rackmw)
+}
 
-	err = modifyForPlaythrough(mw, g.board, vertical, row, col)
+// CreateAndScorePlacementMoveFromTiles is like CreateAndScorePlacementMove,
+// but it takes machine letters. A tile that is already on the board can be
+// given as 0 or as the tile itself. Use this when the play does not come
+// from a person, so that multi-character tiles are never re-parsed.
+func (g *Game) CreateAndScorePlacementMoveFromTiles(row, col int, vertical bool,
+	mw tilemapping.MachineWord, rackmw tilemapping.MachineWord) (*move.Move, error) {
+
+	mw = slices.Clone(mw)
+	err := modifyForPlaythrough(mw, g.board, vertical, row, col)
 	if err != nil {
 		return nil, err
 	}
@@ -772,7 +832,7 @@ func (g *Game) CreateAndScorePlacementMove(coords string, tiles string, rack str
 	// ScoreWord assumes the play is always horizontal, so we have to
 	// do the transpositions beforehand.
 	score := g.Board().ScoreWord(mw, row, col, tilesPlayed,
-		crossDir, g.bag.LetterDistribution(), g.rules.variant, g.rules.lexicon)
+		crossDir, g.bag.LetterDistribution(), g.rules.variant, &g.rules.ruleset, g.rules.lexicon)
 	// reset row, col back for the actual creation of the play.
 	if vertical {
 		row, col = col, row
@@ -820,6 +880,7 @@ func (g *Game) PlayToTurn(turnnum int) error {
 
 	g.board.Clear()
 	g.bag.Refill()
+	g.reseedBag()
 	g.players.resetScore()
 	g.players.resetRacks()
 	g.turnnum = 0
@@ -955,7 +1016,7 @@ func (g *Game) PlayTurn(t int) error {
 		g.board.PlayMove(m)
 		g.crossSetGen.UpdateForMove(g.board, m)
 		g.players[g.onturn].points += m.Score()
-		if m.TilesPlayed() == RackTileLimit {
+		if g.rules.ruleset.IsBingo(m.TilesPlayed()) {
 			g.players[g.onturn].bingos++
 		}
 		evt.WordsFormed = convertToVisible(g.lastWordsFormed, g.alph)
@@ -996,6 +1057,7 @@ func (g *Game) PlayTurn(t int) error {
 		if err != nil {
 			panic(err)
 		}
+		g.reseedBag()
 		copy(g.players[g.onturn].placeholderRack[len(m.Tiles()):], []tilemapping.MachineLetter(m.Leave()))
 		g.players[g.onturn].setRackTiles(g.players[g.onturn].placeholderRack[:len(m.Tiles())+len(m.Leave())], g.alph)
 		g.players[g.onturn].turns += 1
@@ -1030,6 +1092,7 @@ func (g *Game) SetRackFor(playerIdx int, rack *tilemapping.Rack) error {
 		log.Error().Msgf("Unable to set rack: %v", err)
 		return err
 	}
+	g.reseedBag()
 
 	// success; set our rack
 	g.players[playerIdx].rack = rack
@@ -1048,6 +1111,7 @@ func (g *Game) SetRackForOnly(playerIdx int, rack *tilemapping.Rack) error {
 		log.Error().Msgf("Unable to set rack: %v", err)
 		return err
 	}
+	g.reseedBag()
 	// success; set our rack
 	g.players[playerIdx].rack = rack
 	return nil
@@ -1063,6 +1127,7 @@ func (g *Game) SetRacksForBoth(racks []*tilemapping.Rack) error {
 			return err
 		}
 	}
+	g.reseedBag()
 	for idx, player := range g.players {
 		player.rack = racks[idx]
 	}
@@ -1074,10 +1139,12 @@ func (g *Game) ThrowRacksIn() {
 	for _, p := range g.players {
 		p.throwRackIn(g.bag)
 	}
+	g.reseedBag()
 }
 
 func (g *Game) ThrowRacksInFor(pidx int) {
 	g.players[pidx].throwRackIn(g.bag)
+	g.reseedBag()
 }
 
 // SetRandomRack sets the player's  rack to a random rack drawn from the bag.
@@ -1089,8 +1156,16 @@ func (g *Game) SetRandomRack(playerIdx int, knownRack []tilemapping.MachineLette
 	var extraDrawn []tilemapping.MachineLetter
 	if len(knownRack) == 0 {
 		// we're using the other player's rack as a placeholder. This is ugly.
-		ndrawn := g.bag.Redraw(g.players[1-playerIdx].placeholderRack[:n],
-			g.players[playerIdx].placeholderRack)
+		var ndrawn int
+		if g.rng == nil {
+			ndrawn = g.bag.Redraw(g.players[1-playerIdx].placeholderRack[:n],
+				g.players[playerIdx].placeholderRack)
+		} else {
+			// Same as Redraw, but re-order the bag before drawing.
+			g.bag.PutBack(g.players[1-playerIdx].placeholderRack[:n])
+			g.reseedBag()
+			ndrawn = g.bag.DrawAtMost(g.rules.ruleset.RackSize, g.players[playerIdx].placeholderRack)
+		}
 		// note that ndrawn does not need to match n
 		g.players[playerIdx].setRackTiles(g.players[playerIdx].placeholderRack[:ndrawn], g.alph)
 	} else {
@@ -1105,8 +1180,9 @@ func (g *Game) SetRandomRack(playerIdx int, knownRack []tilemapping.MachineLette
 			}
 			return nil, err
 		}
+		g.reseedBag()
 		// In case we didn't have a full rack.
-		nTilesToDraw := lo.Max([]int{n, RackTileLimit}) - len(knownRack)
+		nTilesToDraw := lo.Max([]int{n, g.rules.ruleset.RackSize}) - len(knownRack)
 
 		copy(g.players[1-playerIdx].placeholderRack, knownRack)
 		ndrawn := g.bag.DrawAtMost(nTilesToDraw, g.players[1-playerIdx].placeholderRack[len(knownRack):])
@@ -1256,49 +1332,3 @@ func (g *Game) ScorelessTurns() int {
 func (g *Game) LastScorelessTurns() int {
 	return g.lastScorelessTurns
 }
-
-// ToCGP converts the game to a CGP string. See cgp directory.
-func (g *Game) ToCGP(formatForBot bool) string {
-	fen := g.board.ToFEN(g.alph)
-	ourRack := g.curPlayer().rack.TilesOn().UserVisible(g.alph)
-	theirRack := g.oppPlayer().rack.TilesOn().UserVisible(g.alph)
-	ourScore := g.curPlayer().points
-	theirScore := g.oppPlayer().points
-	zeroPt := g.scorelessTurns
-	lex := g.lexicon.Name()
-	ld := ""
-	if g.history != nil {
-		ld = g.history.LetterDistribution
-	}
-	if formatForBot {
-		// Clear opponent rack -- if this is a bot move, bot should know
-		// nothing of it.
-		theirRack = ""
-		tm := g.letterDistribution.TileMapping()
-		if g.history != nil && g.turnnum > 0 {
-			oppEvt := g.history.Events[g.turnnum-1]
-			if oppEvt.Type == pb.GameEvent_PHONY_TILES_RETURNED {
-				// we know opp's last partial or full rack
-				if tiles, err := tilemapping.ToMachineLetters(oppEvt.PlayedTiles, tm); err != nil {
-					log.Err(err).Str("playedTiles", oppEvt.PlayedTiles).Msg("unable-to-convert-opp-rack")
-				} else {
-					// convert back to string without the play-through tiles
-					for _, t := range tiles {
-						if t == 0 {
-							continue
-						}
-						theirRack += t.IntrinsicTileIdx().UserVisible(tm, false)
-					}
-				}
-			}
-		}
-	}
-
-	cgp := fmt.Sprintf("%s %s/%s %d/%d %d lex %s;",
-		fen, ourRack, theirRack, ourScore, theirScore, zeroPt, lex)
-	if ld != "" {
-		cgp += fmt.Sprintf(" ld %s;", ld)
-	}
-
-	return cgp
-}
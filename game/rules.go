@@ -2,9 +2,14 @@ package game
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/domino14/word-golib/cache"
 	"github.com/domino14/word-golib/kwg"
 	"github.com/domino14/word-golib/tilemapping"
+	"github.com/rs/zerolog/log"
 
 	"github.com/domino14/macondo/board"
 	"github.com/domino14/macondo/config"
@@ -16,6 +21,8 @@ import (
 const (
 	CrossScoreOnly   = "cs"
 	CrossScoreAndSet = "css"
+
+	RuleSetsFilename = "rulesets.json"
 )
 
 // GameRules is a simple struct that encapsulates the instantiated objects
@@ -27,6 +34,7 @@ type GameRules struct {
 	lexicon     lexicon.Lexicon
 	crossSetGen cross_set.Generator
 	variant     variant.Variant
+	ruleset     variant.RuleSet
 	boardname   string
 	distname    string
 }
@@ -67,6 +75,20 @@ func (g GameRules) Variant() variant.Variant {
 	return g.variant
 }
 
+func (g *GameRules) RuleSet() *variant.RuleSet {
+	return &g.ruleset
+}
+
+// SetRuleSet replaces the rule set that the rules were created with. It must
+// be called before any games are created with these rules.
+func (g *GameRules) SetRuleSet(rs variant.RuleSet) error {
+	if err := rs.Validate(); err != nil {
+		return err
+	}
+	g.ruleset = rs
+	return nil
+}
+
 func NewBasicGameRules(cfg *config.Config,
 	lexiconName, boardLayoutName, letterDistributionName, csetGenName string,
 	variant variant.Variant) (*GameRules, error) {
@@ -111,7 +133,19 @@ func NewBasicGameRules(cfg *config.Config,
 				return nil, err
 			}
 			lex = &kwg.Lexicon{KWG: *k}
-			csgen = &cross_set.GaddagCrossSetGenerator{Dist: dist, Gaddag: k}
+			if variant.IsWordSmog() {
+				csgen = &cross_set.AnagramCrossSetGenerator{Dist: dist, KWG: k}
+			} else {
+				csgen = &cross_set.GaddagCrossSetGenerator{Dist: dist, Gaddag: k}
+			}
+		}
+	}
+
+	ruleset := variant.DefaultRuleSet()
+	if name := cfg.GetString(config.ConfigDefaultRuleSet); name != "" {
+		ruleset, err = LoadRuleSet(cfg, name)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -124,6 +158,45 @@ func NewBasicGameRules(cfg *config.Config,
 		lexicon:     lex,
 		crossSetGen: csgen,
 		variant:     variant,
+		ruleset:     ruleset,
 	}
 	return rules, nil
 }
+
+// LoadRuleSet returns the rule set with the given name from RuleSetsFilename
+// in the data path. The default rule sets of the variants can always be
+// loaded by the name of the variant, and "classic" is ClassicRuleSet.
+func LoadRuleSet(cfg *config.Config, name string) (variant.RuleSet, error) {
+	obj, err := cache.Load(cfg.AllSettings(), "rulesets:"+RuleSetsFilename, RuleSetsCacheLoadFunc)
+	if err == nil {
+		if rs, ok := obj.(map[string]variant.RuleSet)[name]; ok {
+			return rs, nil
+		}
+	} else {
+		log.Debug().Err(err).Msg("no rule sets file")
+	}
+	switch name {
+	case variant.ClassicRuleSet.Name:
+		return variant.ClassicRuleSet, nil
+	case string(variant.VarGmo):
+		return variant.VarGmo.DefaultRuleSet(), nil
+	}
+	return variant.RuleSet{}, fmt.Errorf("rule set %q not found", name)
+}
+
+func RuleSetsCacheLoadFunc(cfg map[string]any, key string) (interface{}, error) {
+	// Key looks like rulesets:filename
+	fields := strings.Split(key, ":")
+	if fields[0] != "rulesets" {
+		return nil, errors.New("rulesetscacheloadfunc - bad cache key: " + key)
+	}
+	if len(fields) != 2 {
+		return nil, errors.New("cache key missing fields")
+	}
+	f, _, err := cache.Open(filepath.Join(cfg[config.ConfigDataPath].(string), fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return variant.ReadRuleSets(f)
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/variant"
+)
+
+func ruleSetGame(t *testing.T, name string) *Game {
+	is := is.New(t)
+	players := []*pb.PlayerInfo{
+		{Nickname: "JD", RealName: "Jesse"},
+		{Nickname: "cesar", RealName: "César"},
+	}
+	rules, err := NewBasicGameRules(
+		&DefaultConfig, "NWL18", board.CrosswordGameLayout, "english",
+		CrossScoreAndSet, variant.VarClassic)
+	is.NoErr(err)
+	rs, err := LoadRuleSet(&DefaultConfig, name)
+	is.NoErr(err)
+	is.NoErr(rules.SetRuleSet(rs))
+	g, err := NewGame(rules, players)
+	is.NoErr(err)
+	g.StartGame()
+	g.SetPlayerOnTurn(0)
+	return g
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	is := is.New(t)
+	rs, err := LoadRuleSet(&DefaultConfig, "classic")
+	is.NoErr(err)
+	is.Equal(rs, variant.ClassicRuleSet)
+	rs, err = LoadRuleSet(&DefaultConfig, "gmowords")
+	is.NoErr(err)
+	is.Equal(rs.BingoBonus, 35)
+	_, err = LoadRuleSet(&DefaultConfig, "nosuchrules")
+	is.True(err != nil)
+}
+
+func TestEightTileRack(t *testing.T) {
+	is := is.New(t)
+	g := ruleSetGame(t, "eight")
+	is.Equal(int(g.RackFor(0).NumTiles()), 8)
+	is.Equal(int(g.RackFor(1).NumTiles()), 8)
+	is.Equal(g.Bag().TilesRemaining(), 84)
+	is.Equal(g.maxScorelessTurns, variant.ClassicRuleSet.MaxScorelessTurns)
+	is.True(g.ToCGP(false) != "")
+}
+
+func TestTransferEndRackScoring(t *testing.T) {
+	is := is.New(t)
+	g := ruleSetGame(t, "box")
+	g.SetPointsFor(0, 300)
+	g.SetPointsFor(1, 250)
+	g.SetRackFor(1, tilemapping.RackFromString("QZ", g.Alphabet()))
+	g.endOfGameCalcs(0, true)
+	// Player 0 gets the value of the rack, and player 1 loses it.
+	is.Equal(g.PointsFor(0), 320)
+	is.Equal(g.PointsFor(1), 230)
+	evts := g.History().Events
+	is.Equal(evts[len(evts)-1].Type, pb.GameEvent_END_RACK_PENALTY)
+	is.Equal(evts[len(evts)-1].LostScore, int32(20))
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/domino14/word-golib/tilemapping"
+)
+
+// The bag shuffles with its own unseedable random number generator. A
+// seeded game keeps its bag in fixed order instead (so that drawing is
+// deterministic), and re-orders it with the game's own generator after any
+// bag operation that might have shuffled it.
+
+// seedStream is the second PCG parameter; it has no special meaning.
+const seedStream = 0x6d61636f6e646f
+
+// SetSeed makes all the random decisions in this game (the tile order in the
+// bag, and the seeds for any sims or inferences run on this game) depend only
+// on the given seed.
+func (g *Game) SetSeed(seed uint64) {
+	g.seed = seed
+	g.pcg = rand.NewPCG(seed, seedStream)
+	g.rng = rand.New(g.pcg)
+	if g.bag != nil {
+		g.bag.SetFixedOrder(true)
+		g.shuffleBag()
+		// Start over, so that the generator does not depend on how many
+		// tiles happened to be in the bag.
+		g.pcg.Seed(seed, seedStream)
+	}
+}
+
+// Seed returns the seed this game was last seeded with, and whether it
+// was seeded at all.
+func (g *Game) Seed() (uint64, bool) {
+	return g.seed, g.rng != nil
+}
+
+// DeriveSeed returns a new seed from the game's random number generator.
+// It returns false if the game is not seeded.
+func (g *Game) DeriveSeed() (uint64, bool) {
+	if g.rng == nil {
+		return 0, false
+	}
+	return g.rng.Uint64(), true
+}
+
+// Rand returns the game's seeded random number generator, or nil if the
+// game is not seeded.
+func (g *Game) Rand() *rand.Rand {
+	return g.rng
+}
+
+// shuffleBag shuffles the bag. If the game is seeded, the resulting order
+// only depends on the state of the game's generator and the tiles in the bag.
+func (g *Game) shuffleBag() {
+	if g.rng == nil {
+		g.bag.Shuffle()
+		return
+	}
+	shuffleSeeded(g.bag, g.rng)
+}
+
+// reseedBag re-orders the bag with the game's generator after a bag
+// operation that may have shuffled it. It does nothing if the game is
+// not seeded.
+func (g *Game) reseedBag() {
+	if g.rng != nil {
+		shuffleSeeded(g.bag, g.rng)
+	}
+}
+
+// newBag sets a new, full bag for the game.
+func (g *Game) newBag() {
+	g.bag = g.letterDistribution.MakeBag()
+	if g.rng != nil {
+		g.bag.SetFixedOrder(true)
+		shuffleSeeded(g.bag, g.rng)
+	}
+}
+
+func (g *Game) copyRNG() (*rand.PCG, *rand.Rand) {
+	if g.pcg == nil {
+		return nil, nil
+	}
+	state, err := g.pcg.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	pcg := &rand.PCG{}
+	if err = pcg.UnmarshalBinary(state); err != nil {
+		panic(err)
+	}
+	return pcg, rand.New(pcg)
+}
+
+func shuffleSeeded(bag *tilemapping.Bag, rng *rand.Rand) {
+	tiles := bag.Tiles()
+	slices.Sort(tiles)
+	rng.Shuffle(len(tiles), bag.SwapTile)
+}
+
+// NewSeededBag returns a full bag in fixed order, shuffled according to
+// the given seed.
+func NewSeededBag(ld *tilemapping.LetterDistribution, seed uint64) *tilemapping.Bag {
+	bag := tilemapping.NewBag(ld, ld.TileMapping())
+	bag.SetFixedOrder(true)
+	shuffleSeeded(bag, rand.New(rand.NewPCG(seed, seedStream)))
+	return bag
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+func TestNewSeededBag(t *testing.T) {
+	is := is.New(t)
+	ld, err := tilemapping.GetDistribution(DefaultConfig.AllSettings(), "english")
+	is.NoErr(err)
+	b1 := NewSeededBag(ld, 42)
+	b2 := NewSeededBag(ld, 42)
+	b3 := NewSeededBag(ld, 43)
+	is.Equal(b1.Peek(), b2.Peek())
+	is.True(len(b1.Peek()) == 100)
+	is.True(!slicesEqual(b1.Peek(), b3.Peek()))
+
+	// Draws come from the fixed order.
+	ml1 := make([]tilemapping.MachineLetter, 7)
+	ml2 := make([]tilemapping.MachineLetter, 7)
+	is.NoErr(b1.Draw(7, ml1))
+	is.NoErr(b2.Draw(7, ml2))
+	is.Equal(ml1, ml2)
+}
+
+func slicesEqual(a, b []tilemapping.MachineLetter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func seededGame(t *testing.T, seed uint64) *Game {
+	is := is.New(t)
+	rules, err := NewBasicGameRules(
+		&DefaultConfig, "NWL20", board.CrosswordGameLayout, "english",
+		CrossScoreAndSet, "")
+	is.NoErr(err)
+	g, err := NewGame(rules, []*pb.PlayerInfo{
+		{Nickname: "p1", RealName: "Player 1"},
+		{Nickname: "p2", RealName: "Player 2"},
+	})
+	is.NoErr(err)
+	g.SetSeed(seed)
+	g.StartGame()
+	return g
+}
+
+func TestSeededGameIsRepeatable(t *testing.T) {
+	is := is.New(t)
+	g1 := seededGame(t, 1234)
+	g2 := seededGame(t, 1234)
+	is.Equal(g1.RackLettersFor(0), g2.RackLettersFor(0))
+	is.Equal(g1.RackLettersFor(1), g2.RackLettersFor(1))
+	is.Equal(g1.Bag().Peek(), g2.Bag().Peek())
+
+	// Random racks (as used by sims) are repeatable too.
+	for i := 0; i < 10; i++ {
+		_, err := g1.SetRandomRack(1, nil)
+		is.NoErr(err)
+		_, err = g2.SetRandomRack(1, nil)
+		is.NoErr(err)
+		is.Equal(g1.RackLettersFor(1), g2.RackLettersFor(1))
+	}
+
+	// A copy continues with the same random stream.
+	g3 := g1.Copy()
+	_, err := g1.SetRandomRack(1, nil)
+	is.NoErr(err)
+	_, err = g3.SetRandomRack(1, nil)
+	is.NoErr(err)
+	is.Equal(g1.RackLettersFor(1), g3.RackLettersFor(1))
+
+	s1, _ := g1.DeriveSeed()
+	s3, _ := g3.DeriveSeed()
+	is.Equal(s1, s3)
+}
+
+func TestReseedDoesNotDependOnBag(t *testing.T) {
+	is := is.New(t)
+	g1 := seededGame(t, 1234)
+	g2 := seededGame(t, 1234)
+	// Empty out one bag; reseeding must still give the same stream.
+	ml := make([]tilemapping.MachineLetter, g1.Bag().TilesRemaining())
+	is.NoErr(g1.Bag().Draw(len(ml), ml))
+	g1.SetSeed(99)
+	g2.SetSeed(99)
+	s1, _ := g1.DeriveSeed()
+	s2, _ := g2.DeriveSeed()
+	is.Equal(s1, s2)
+}
@@ -36,7 +36,7 @@ func (g *Game) EventFromMove(m *move.Move) *pb.GameEvent {
 		evt.PlayedTiles = m.Tiles().UserVisiblePlayedTiles(m.Alphabet())
 		evt.Score = int32(m.Score())
 		evt.Type = pb.GameEvent_TILE_PLACEMENT_MOVE
-		evt.IsBingo = m.TilesPlayed() == RackTileLimit
+		evt.IsBingo = g.rules.ruleset.IsBingo(m.TilesPlayed())
 		evt.NumTilesFromRack = uint32(m.TilesPlayed())
 		CalculateCoordsFromStringPosition(evt)
 
@@ -0,0 +1,103 @@
+package gcgio
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	is := is.New(t)
+	history, err := ParseGCG(&DefaultConfig, "./testdata/vs_frentz.gcg")
+	is.NoErr(err)
+
+	bts, err := GameHistoryToJSON(history)
+	is.NoErr(err)
+
+	read, err := ParseJSONFromReader(&DefaultConfig, strings.NewReader(string(bts)))
+	is.NoErr(err)
+	is.True(proto.Equal(history, read))
+	// Blanks keep their case.
+	played := []string{}
+	for _, evt := range read.Events {
+		played = append(played, evt.PlayedTiles)
+	}
+	is.True(slices.Contains(played, "CRAAlED"))
+}
+
+func TestJSONMustBePlayable(t *testing.T) {
+	is := is.New(t)
+	_, err := ParseJSONFromReader(&DefaultConfig, strings.NewReader(`{
+		"players": [{"nickname": "a"}, {"nickname": "b"}],
+		"events": [{"rack": "ABC", "position": "8H", "played_tiles": "ZZZ", "score": 30}]
+	}`))
+	is.True(err != nil)
+}
+
+func TestMoveListRoundTrip(t *testing.T) {
+	is := is.New(t)
+	history, err := ParseGCG(&DefaultConfig, "./testdata/doug_v_emely.gcg")
+	is.NoErr(err)
+
+	ml, err := GameHistoryToMoveList(history)
+	is.NoErr(err)
+	lines := strings.Split(ml, "\n")
+	is.Equal(lines[0], "Player 1: doug")
+	is.Equal(lines[4], "  1. doug   DINNVWY   8D WINDY          +32    32")
+	is.Equal(lines[10], "  7. emely  DEIILTZ   (phony)           -24    55")
+
+	read, err := ParseMoveListFromReader(&DefaultConfig, strings.NewReader(ml))
+	is.NoErr(err)
+	is.Equal(len(read.Events), len(history.Events))
+	for i, evt := range history.Events {
+		is.Equal(read.Events[i].Type, evt.Type)
+		is.Equal(read.Events[i].Rack, evt.Rack)
+		is.Equal(read.Events[i].PlayedTiles, evt.PlayedTiles)
+		is.Equal(read.Events[i].Cumulative, evt.Cumulative)
+		is.Equal(read.Events[i].IsBingo, evt.IsBingo)
+	}
+	is.Equal(read.PlayState, pb.PlayState_GAME_OVER)
+	is.Equal(read.FinalScores, history.FinalScores)
+
+	again, err := GameHistoryToMoveList(read)
+	is.NoErr(err)
+	is.Equal(again, ml)
+}
+
+func TestMoveListUnknownRacks(t *testing.T) {
+	is := is.New(t)
+	history, err := ParseMoveListFromReader(&DefaultConfig, strings.NewReader(`Player 1: doug (Doug B)
+Player 2: emely
+Lexicon: NWL18
+
+1. doug - 8D WINDY +32 32
+2. emely - 7C GaLE +14 14
+3. doug - (exch ABC) +0 32
+`))
+	is.NoErr(err)
+	is.Equal(history.Players[0].RealName, "Doug B")
+	is.Equal(history.Events[0].Rack, "WINDY")
+	is.Equal(history.Events[1].Rack, "G?LE")
+	is.Equal(history.Events[2].Exchanged, "ABC")
+	is.Equal(history.PlayState, pb.PlayState_PLAYING)
+}
+
+func TestHTMLSheet(t *testing.T) {
+	is := is.New(t)
+	history, err := ParseGCG(&DefaultConfig, "./testdata/doug_v_emely.gcg")
+	is.NoErr(err)
+	history.Events[0].Note = "<b>opening</b>"
+
+	sheet, err := GameHistoryToHTML(&DefaultConfig, history)
+	is.NoErr(err)
+	is.Equal(strings.Count(sheet, "<svg "), len(history.Events))
+	is.True(strings.Contains(sheet, "<title>doug vs. emely</title>"))
+	is.True(strings.Contains(sheet, "&lt;b&gt;opening&lt;/b&gt;"))
+	// The blank in RE.IgION is drawn in lowercase.
+	is.True(strings.Contains(sheet, ">g</text>"))
+}
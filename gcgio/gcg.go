@@ -1,5 +1,5 @@
-// Package gcgio implements a GCG parser. It might also implement
-// other io methods.
+// Package gcgio implements a GCG parser. It also reads and writes JSON game
+// records and plain move lists, and writes printable HTML game sheets.
 package gcgio
 
 import (
@@ -61,6 +61,12 @@ const (
 	ContinuationToken
 	IncompleteToken
 	TileDeclarationToken
+	ClockToken
+	RatingToken
+	EventNameToken
+	RoundToken
+	DateToken
+	UnknownPragmaToken
 )
 
 type gcgdatum struct {
@@ -96,8 +102,18 @@ const (
 	PtsLostForLastRackRegex   = `>(?P<nick>\S+):\s+(?P<rack>\S+)\s+\((?P<rack>\S+)\)\s+\-(?P<penalty>\d+)\s+(?P<cumul>-?\d+)`
 	IncompleteRegex           = "#incomplete.*"
 	TileDeclarationRegex      = `#tile (?P<uppercase>\S+)\s+(?P<lowercase>\S+)`
+	ClockRegex                = `#clock (?P<remaining>-?\d+:\d\d)`
+	RatingRegex               = `#rating(?P<p_number>[1-2])\s+(?P<rating>\d+)`
+	EventNameRegex            = `#event (?P<event>.+)`
+	RoundRegex                = `#round (?P<round>.+)`
+	DateRegex                 = `#date (?P<date>.+)`
+	UnknownPragmaRegex        = `^#\S+`
 )
 
+// unsuccessfulChallengeNote is the note that tells a pass apart from losing
+// a turn to an unsuccessful challenge.
+const unsuccessfulChallengeNote = "#unsuccessful-challenge"
+
 var compiledEncodingRegexp *regexp.Regexp
 
 type parser struct {
@@ -105,6 +121,18 @@ type parser struct {
 
 	history *pb.GameHistory
 	game    *game.Game
+	layout  gcgLayout
+}
+
+// gcgLayout is where the pragma lines of a GCG file were, which a game
+// history does not keep. header has the names of the header pragmas in
+// order, with an empty name for each unknown one. events has, by event
+// index, the kinds of the lines that followed the event: "note" for a
+// #note line, "note+" for a line that goes on with a note, "clock" and
+// "unknown".
+type gcgLayout struct {
+	header []string
+	events map[int][]string
 }
 
 // init initializes the regexp list.
@@ -142,6 +170,13 @@ func init() {
 		{TileDistributionNameToken, regexp.MustCompile(TileDistributionNameRegex)},
 		{IncompleteToken, regexp.MustCompile(IncompleteRegex)},
 		{TileDeclarationToken, regexp.MustCompile(TileDeclarationRegex)},
+		{ClockToken, regexp.MustCompile(ClockRegex)},
+		{RatingToken, regexp.MustCompile(RatingRegex)},
+		{EventNameToken, regexp.MustCompile(EventNameRegex)},
+		{RoundToken, regexp.MustCompile(RoundRegex)},
+		{DateToken, regexp.MustCompile(DateRegex)},
+		// This one must be last, as it matches any pragma.
+		{UnknownPragmaToken, regexp.MustCompile(UnknownPragmaRegex)},
 	}
 }
 
@@ -162,7 +197,70 @@ func nickToPIndex(nick string, players []*pb.PlayerInfo) (uint32, error) {
 	return 0, errPlayerDoesNotExist
 }
 
-func (p *parser) addEventOrPragma(cfg *config.Config, token Token, match []string) error {
+// parseClock parses a remaining time such as 12:34 or -1:05 into
+// milliseconds.
+func parseClock(str string) (int32, error) {
+	neg := strings.HasPrefix(str, "-")
+	mins, secs, _ := strings.Cut(strings.TrimPrefix(str, "-"), ":")
+	m, err := matchToInt32(mins)
+	if err != nil {
+		return 0, err
+	}
+	sec, err := matchToInt32(secs)
+	if err != nil {
+		return 0, err
+	}
+	millis := (m*60 + sec) * 1000
+	if neg {
+		millis = -millis
+	}
+	return millis, nil
+}
+
+// formatClock formats a remaining time the way parseClock reads it. Parts
+// of a second are dropped.
+func formatClock(millis int32) string {
+	sign := ""
+	if millis < 0 {
+		sign = "-"
+		millis = -millis
+	}
+	secs := millis / 1000
+	return fmt.Sprintf("%s%d:%02d", sign, secs/60, secs%60)
+}
+
+// headerPragma records that the named pragma was in the header, so that
+// it can be written back in the same place.
+func (p *parser) headerPragma(name string) {
+	p.layout.header = append(p.layout.header, name)
+}
+
+// eventPragma records the kind of a pragma line that followed the last
+// event.
+func (p *parser) eventPragma(kind string) {
+	if p.layout.events == nil {
+		p.layout.events = map[int][]string{}
+	}
+	i := len(p.history.Events) - 1
+	p.layout.events[i] = append(p.layout.events[i], kind)
+}
+
+// keepPragma keeps a line that macondo does not understand, so that it can
+// be written back out as it is. Lines before the first event are part of
+// the header; after that they go with the event they follow.
+func (p *parser) keepPragma(line string) {
+	if len(p.history.Events) == 0 {
+		// An empty name stands for the next unknown pragma.
+		p.headerPragma("")
+		p.history.UnknownPragmas = append(p.history.UnknownPragmas, line)
+		return
+	}
+	evt := p.history.Events[len(p.history.Events)-1]
+	evt.UnknownPragmas = append(evt.UnknownPragmas, line)
+	p.eventPragma("unknown")
+}
+
+func (p *parser) addEventOrPragma(cfg *config.Config, token Token, match []string, line string) error {
 	var err error
 
 	if token == MoveToken || token == PassToken || token == ExchangeToken {
@@ -225,36 +323,41 @@ func (p *parser) addEventOrPragma(cfg *config.Config, token Token, match []strin
 			Nickname: match[2],
 			RealName: match[3],
 		})
-
+		p.headerPragma("player" + match[1])
 		return nil
 	case TitleToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.Title = match[1]
+		p.headerPragma("title")
 		return nil
 	case DescriptionToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.Description = match[1]
+		p.headerPragma("description")
 	case IDToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.IdAuth = match[1]
 		p.history.Uid = match[2]
-	case Rack1Token:
-		// assume if there is a rack2 token, that rack1 will come before it.
+		p.headerPragma("id")
+	case Rack1Token, Rack2Token:
 		if p.history.LastKnownRacks == nil {
-			p.history.LastKnownRacks = []string{match[1], ""}
+			p.history.LastKnownRacks = []string{"", ""}
 		}
-	case Rack2Token:
-		if p.history.LastKnownRacks == nil {
-			p.history.LastKnownRacks = []string{"", match[1]}
-		} else {
-			// There is already a rack1 at the [0] position.
-			p.history.LastKnownRacks[1] = match[1]
+		pidx, name := 0, "rack1"
+		if token == Rack2Token {
+			pidx, name = 1, "rack2"
+		}
+		p.history.LastKnownRacks[pidx] = match[1]
+		// The racks are usually given after the last event. They are only
+		// part of the header if they come before the first one.
+		if len(p.history.Events) == 0 {
+			p.headerPragma(name)
 		}
 	case EncodingToken:
 		return errEncodingWrongPlace
@@ -301,34 +404,89 @@ func (p *parser) addEventOrPragma(cfg *config.Config, token Token, match []strin
 	case NoteToken:
 		lastEvtIdx := len(p.history.Events) - 1
 		if lastEvtIdx < 0 {
-			log.Warn().Msg("note pragma may not precede events")
-		} else {
-			p.history.Events[lastEvtIdx].Note += match[1]
+			// There is no event to attach it to.
+			p.keepPragma(line)
+			return nil
+		}
+		evt := p.history.Events[lastEvtIdx]
+		if match[1] == unsuccessfulChallengeNote && evt.Type == pb.GameEvent_PASS && evt.Note == "" {
+			// This is how GameHistoryToGCG writes a lost turn.
+			evt.Type = pb.GameEvent_UNSUCCESSFUL_CHALLENGE_TURN_LOSS
+			return nil
+		}
+		if evt.Note != "" {
+			evt.Note += "\n"
 		}
+		evt.Note += match[1]
+		p.eventPragma("note")
 		return nil
+	case ClockToken:
+		lastEvtIdx := len(p.history.Events) - 1
+		if lastEvtIdx < 0 {
+			p.keepPragma(line)
+			return nil
+		}
+		evt := p.history.Events[lastEvtIdx]
+		evt.MillisRemaining, err = parseClock(match[1])
+		p.eventPragma("clock")
+		return err
 	case LexiconToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.Lexicon = match[1]
+		p.headerPragma("lexicon")
 		return nil
 	case BoardLayoutToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.BoardLayout = match[1]
+		p.headerPragma("board-layout")
 		return nil
 	case TileDistributionNameToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.LetterDistribution = match[1]
+		p.headerPragma("tile-distribution")
 		return nil
 	case GameTypeToken:
 		if len(p.history.Events) > 0 {
 			return errPragmaPrecedeEvent
 		}
 		p.history.Variant = match[1]
+		p.headerPragma("game-type")
+		return nil
+	case RatingToken:
+		if len(p.history.Events) > 0 {
+			return errPragmaPrecedeEvent
+		}
+		pidx := int(match[1][0] - '1')
+		if pidx >= len(p.history.Players) {
+			return errPlayerDoesNotExist
+		}
+		p.history.Players[pidx].Rating, err = matchToInt32(match[2])
+		if err != nil {
+			return err
+		}
+		p.headerPragma("rating" + match[1])
+		return nil
+	case EventNameToken, RoundToken, DateToken:
+		if len(p.history.Events) > 0 {
+			return errPragmaPrecedeEvent
+		}
+		switch token {
+		case EventNameToken:
+			p.history.EventName = match[1]
+			p.headerPragma("event")
+		case RoundToken:
+			p.history.Round = match[1]
+			p.headerPragma("round")
+		case DateToken:
+			p.history.Date = match[1]
+			p.headerPragma("date")
+		}
 		return nil
 	// need to handle continuation as well as the actual tileSet or gameBoard pragmas.
 	case PhonyTilesReturnedToken:
@@ -487,9 +645,11 @@ func (p *parser) addEventOrPragma(cfg *config.Config, token Token, match []strin
 		p.history.Events = append(p.history.Events, evt)
 		return p.game.PlayLatestEvent()
 
-	case TileDeclarationToken:
-		// for now, just ignore this token. We're going to go by the letter
-		// distribution to parse the gcg.
+	case TileDeclarationToken, TileSetToken, GameBoardToken, ContinuationToken,
+		IncompleteToken, UnknownPragmaToken:
+		// We go by the letter distribution and board layout to parse the
+		// gcg, so these are only kept to be written back out.
+		p.keepPragma(line)
 	default:
 		log.Info().Int("token", int(token)).Interface("match", match).Msg("ignoring-token")
 	}
@@ -504,7 +664,7 @@ func (p *parser) parseLine(cfg *config.Config, line string) error {
 		match := datum.regex.FindStringSubmatch(line)
 		if match != nil {
 			foundMatch = true
-			err := p.addEventOrPragma(cfg, datum.token, match)
+			err := p.addEventOrPragma(cfg, datum.token, match, line)
 			if err != nil {
 				return err
 			}
@@ -516,7 +676,15 @@ func (p *parser) parseLine(cfg *config.Config, line string) error {
 		// maybe it's a multi-line note.
 		if p.lastToken == NoteToken {
 			lastEventIdx := len(p.history.Events) - 1
-			p.history.Events[lastEventIdx].Note += ("\n" + line)
+			if lastEventIdx < 0 {
+				// The note before the first event was kept as it is.
+				last := len(p.history.UnknownPragmas) - 1
+				p.history.UnknownPragmas[last] += ("\n" + line)
+				return nil
+			}
+			evt := p.history.Events[lastEventIdx]
+			evt.Note += ("\n" + line)
+			p.eventPragma("note+")
 			return nil
 		}
 		// ignore empty lines
@@ -568,6 +736,14 @@ func encodingOrFirstLine(reader io.Reader) (string, string, error) {
 }
 
 func ParseGCGFromReader(cfg *config.Config, reader io.Reader) (*pb.GameHistory, error) {
+	parser, err := parse(cfg, reader)
+	if err != nil {
+		return nil, err
+	}
+	return parser.history, nil
+}
+
+func parse(cfg *config.Config, reader io.Reader) (*parser, error) {
 	var err error
 	parser := &parser{
 		history: &pb.GameHistory{
@@ -620,7 +796,7 @@ func ParseGCGFromReader(cfg *config.Config, reader io.Reader) (*pb.GameHistory,
 	}
 	// Set challenge rule back to void since we don't know or care what it is.
 	parser.history.ChallengeRule = pb.ChallengeRule_VOID
-	return parser.history, nil
+	return parser, nil
 }
 
 // ParseGCG parses a GCG file into a GameHistory.
@@ -632,48 +808,150 @@ func ParseGCG(cfg *config.Config, filename string) (*pb.GameHistory, error) {
 	return ParseGCGFromReader(cfg, f)
 }
 
-func writeGCGHeader(s *strings.Builder, h *pb.GameHistory, addlInfo bool) {
-	s.WriteString("#character-encoding UTF-8\n")
-	if addlInfo {
-		if h.Title != "" {
-			s.WriteString("#title " + h.Title + "\n")
+// layoutOf returns the layout of the GCG file the history was read from,
+// which is found by reading its original GCG again. It returns nil if the
+// history was not read from a GCG file.
+func layoutOf(h *pb.GameHistory) *gcgLayout {
+	if h.OriginalGcg == "" {
+		return nil
+	}
+	cfg := config.DefaultConfig()
+	p, err := parse(&cfg, strings.NewReader(h.OriginalGcg))
+	if err != nil {
+		log.Debug().Err(err).Msg("cannot-read-original-gcg")
+		return nil
+	}
+	return &p.layout
+}
+
+// defaultHeaderOrder is the order of the header pragmas of a game that
+// was not read from a GCG file.
+var defaultHeaderOrder = []string{
+	"title", "description", "id", "event", "round", "date", "lexicon",
+	"game-type", "board-layout", "tile-distribution",
+	"player1", "player2", "rating1", "rating2",
+}
+
+// headerLines returns the header pragma lines of the history, by pragma
+// name.
+func headerLines(h *pb.GameHistory, addlInfo, fromFile bool) map[string]string {
+	lines := map[string]string{}
+	for i, p := range h.Players[:2] {
+		realname := p.RealName
+		if realname == "" {
+			realname = p.Nickname
 		}
-		if h.Description != "" {
-			s.WriteString("#description " + h.Description + "\n")
+		lines[fmt.Sprintf("player%d", i+1)] = fmt.Sprintf("#player%d %v %v\n", i+1, p.Nickname, realname)
+	}
+	if !addlInfo {
+		return lines
+	}
+	add := func(name, value string) {
+		if value != "" {
+			lines[name] = "#" + name + " " + value + "\n"
 		}
-		if h.IdAuth != "" && h.Uid != "" {
-			s.WriteString("#id " + h.IdAuth + " " + h.Uid + "\n")
+	}
+	add("title", h.Title)
+	add("description", h.Description)
+	if h.IdAuth != "" && h.Uid != "" {
+		add("id", h.IdAuth+" "+h.Uid)
+	}
+	add("event", h.EventName)
+	add("round", h.Round)
+	add("date", h.Date)
+	add("lexicon", h.Lexicon)
+	add("game-type", h.Variant)
+	if h.BoardLayout != board.CrosswordGameLayout {
+		add("board-layout", h.BoardLayout)
+	}
+	if h.LetterDistribution != "" && h.LetterDistribution != "english" {
+		add("tile-distribution", h.LetterDistribution)
+		if !fromFile {
+			// Write out multi-tile pragmata. A GCG file that was read in
+			// keeps its own.
+			lines["tile-distribution"] += multiTilePragmas(h.LetterDistribution)
 		}
-		if h.Lexicon != "" {
-			s.WriteString("#lexicon " + h.Lexicon + "\n")
+	}
+	for i, p := range h.Players[:2] {
+		if p.Rating != 0 {
+			add(fmt.Sprintf("rating%d", i+1), strconv.Itoa(int(p.Rating)))
 		}
-		if h.Variant != "" {
-			s.WriteString("#game-type " + h.Variant + "\n")
+	}
+	for i, rack := range h.LastKnownRacks {
+		if i < 2 && rack != "" {
+			add(fmt.Sprintf("rack%d", i+1), rack)
 		}
-		if h.BoardLayout != "" && h.BoardLayout != board.CrosswordGameLayout {
-			s.WriteString("#board-layout " + h.BoardLayout + "\n")
+	}
+	return lines
+}
+
+func multiTilePragmas(dist string) string {
+	var s strings.Builder
+	cfg := config.DefaultConfig()
+	tm, err := tilemapping.GetDistribution(cfg.AllSettings(), dist)
+	if err != nil {
+		// Log the error
+		log.Err(err).Str("dist", dist).Msg("cannot-get-distribution")
+		return ""
+	}
+	for idx := uint8(0); idx < tm.TileMapping().NumLetters(); idx++ {
+		letter := tm.TileMapping().Letter(tilemapping.MachineLetter(idx))
+		if len([]rune(letter)) > 1 {
+			s.WriteString("#tile " + letter + " " + strings.ToLower(letter) + "\n")
 		}
-		if h.LetterDistribution != "" && h.LetterDistribution != "english" {
-			s.WriteString("#tile-distribution " + h.LetterDistribution + "\n")
-			// Write out multi-tile pragmata
-			cfg := config.DefaultConfig()
-			tm, err := tilemapping.GetDistribution(cfg.AllSettings(), h.LetterDistribution)
-			if err != nil {
-				// Log the error
-				log.Err(err).Str("dist", h.LetterDistribution).Msg("cannot-get-distribution")
-			}
-			for idx := uint8(0); idx < tm.TileMapping().NumLetters(); idx++ {
-				letter := tm.TileMapping().Letter(tilemapping.MachineLetter(idx))
-				if len([]rune(letter)) > 1 {
-					s.WriteString("#tile " + letter + " " + strings.ToLower(letter) + "\n")
-				}
+	}
+	return s.String()
+}
+
+// writeGCGHeader writes the header in the order it was read in, if the
+// history came from a GCG file. Pragmas that were not there, for example
+// because they were set afterwards, follow in the default order. It returns
+// the lines that go after the events.
+func writeGCGHeader(s *strings.Builder, h *pb.GameHistory, addlInfo bool, layout *gcgLayout) []string {
+	s.WriteString("#character-encoding UTF-8\n")
+	lines := headerLines(h, addlInfo, layout != nil)
+	unknown := h.UnknownPragmas
+	if !addlInfo {
+		unknown = nil
+	}
+	var header []string
+	if layout != nil {
+		header = layout.header
+	}
+	for _, name := range header {
+		if name == "" {
+			if len(unknown) > 0 {
+				s.WriteString(unknown[0] + "\n")
+				unknown = unknown[1:]
 			}
+			continue
+		}
+		if l, ok := lines[name]; ok {
+			s.WriteString(l)
+			delete(lines, name)
+		}
+	}
+	for _, name := range defaultHeaderOrder {
+		if l, ok := lines[name]; ok {
+			s.WriteString(l)
 		}
 	}
+	for _, l := range unknown {
+		s.WriteString(l + "\n")
+	}
 	log.Debug().Msg("wrote header")
+	// The racks are written after the events, unless they were read in
+	// the header.
+	var trailer []string
+	for _, name := range []string{"rack1", "rack2"} {
+		if l, ok := lines[name]; ok {
+			trailer = append(trailer, l)
+		}
+	}
+	return trailer
 }
 
-func writeEvent(s *strings.Builder, h *pb.GameHistory, evt *pb.GameEvent) error {
+func writeEvent(s *strings.Builder, h *pb.GameHistory, evt *pb.GameEvent, kinds []string) error {
 
 	nick := h.Players[evt.GetPlayerIndex()].Nickname
 	rack := evt.GetRack()
@@ -720,31 +998,61 @@ func writeEvent(s *strings.Builder, h *pb.GameHistory, evt *pb.GameEvent) error
 		// Treat exactly like a pass, but append a note. The GCG format
 		// does not distinguish between these two cases.
 		fmt.Fprintf(s, ">%v: %v - +0 %d\n", nick, rack, evt.Cumulative)
-		fmt.Fprint(s, "#note #unsuccessful-challenge\n")
+		fmt.Fprint(s, "#note "+unsuccessfulChallengeNote+"\n")
 
 	default:
 		return fmt.Errorf("event type %v not supported", evtType)
 
 	}
-	if note != "" {
-		// Note that the note can have line breaks within it ...
-		fmt.Fprintf(s, "#note %v\n", note)
-	}
+	writeEventPragmas(s, evt, note, kinds)
 	return nil
 
 }
 
-func writePlayer(s *strings.Builder, pn int, p *pb.PlayerInfo) {
-	realname := p.RealName
-	if realname == "" {
-		realname = p.Nickname
+// writeEventPragmas writes the note, clock and unknown pragmas of an event,
+// in the order of kinds, which are the kinds of the lines that followed it
+// when it was read in.
+func writeEventPragmas(s *strings.Builder, evt *pb.GameEvent, note string, kinds []string) {
+	var noteLines []string
+	if note != "" {
+		noteLines = strings.Split(note, "\n")
+	}
+	unknown := evt.UnknownPragmas
+	clockWritten := false
+	for _, kind := range kinds {
+		switch kind {
+		case "note", "note+":
+			if len(noteLines) == 0 {
+				continue
+			}
+			if kind == "note" {
+				s.WriteString("#note ")
+			}
+			s.WriteString(noteLines[0] + "\n")
+			noteLines = noteLines[1:]
+		case "clock":
+			fmt.Fprintf(s, "#clock %v\n", formatClock(evt.MillisRemaining))
+			clockWritten = true
+		case "unknown":
+			if len(unknown) == 0 {
+				continue
+			}
+			s.WriteString(unknown[0] + "\n")
+			unknown = unknown[1:]
+		}
+	}
+	// Whatever the recorded order does not account for, such as a note
+	// added after the file was read, goes at the end.
+	if len(noteLines) > 0 {
+		// Note that the note can have line breaks within it ...
+		fmt.Fprintf(s, "#note %v\n", strings.Join(noteLines, "\n"))
+	}
+	if !clockWritten && evt.MillisRemaining != 0 {
+		fmt.Fprintf(s, "#clock %v\n", formatClock(evt.MillisRemaining))
+	}
+	for _, l := range unknown {
+		s.WriteString(l + "\n")
 	}
-	fmt.Fprintf(s, "#player%d %v %v\n", pn, p.Nickname, realname)
-}
-
-func writePlayers(s *strings.Builder, players []*pb.PlayerInfo) {
-	writePlayer(s, 1, players[0])
-	writePlayer(s, 2, players[1])
 }
 
 func isPassBeforeEndRackPoints(h *pb.GameHistory, i int) bool {
@@ -760,17 +1068,24 @@ func GameHistoryToGCG(h *pb.GameHistory, addlHeaderInfo bool) (string, error) {
 		return "", errors.New("cannot turn a game history with a starting CGP into a GCG file")
 	}
 	var str strings.Builder
-	writeGCGHeader(&str, h, addlHeaderInfo)
-	writePlayers(&str, h.Players)
+	layout := layoutOf(h)
+	trailer := writeGCGHeader(&str, h, addlHeaderInfo, layout)
 
 	for i, evt := range h.Events {
 		if !isPassBeforeEndRackPoints(h, i) {
-			err := writeEvent(&str, h, evt)
+			var kinds []string
+			if layout != nil {
+				kinds = layout.events[i]
+			}
+			err := writeEvent(&str, h, evt, kinds)
 			if err != nil {
 				return "", err
 			}
 		}
 	}
+	for _, l := range trailer {
+		str.WriteString(l)
+	}
 
 	return str.String(), nil
 }
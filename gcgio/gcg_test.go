@@ -13,6 +13,7 @@ import (
 	"github.com/domino14/word-golib/tilemapping"
 	"github.com/matryer/is"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/domino14/macondo/board"
 	"github.com/domino14/macondo/config"
@@ -274,3 +275,60 @@ func TestIsBingo(t *testing.T) {
 	assert.True(t, history.Events[0].IsBingo)
 	assert.False(t, history.Events[1].IsBingo)
 }
+
+func TestAnnotatedRoundTrip(t *testing.T) {
+	is := is.New(t)
+	history, err := ParseGCG(&DefaultConfig, "./testdata/annotated.gcg")
+	is.NoErr(err)
+
+	is.Equal(history.Players[0].Rating, int32(1850))
+	is.Equal(history.Players[1].Rating, int32(1720))
+	is.Equal(history.EventName, "Toronto Open")
+	is.Equal(history.Round, "5")
+	is.Equal(history.Date, "2023-04-02")
+	is.Equal(history.UnknownPragmas, []string{"#x-annotator-version 1.0.4", "#note pre-game thoughts"})
+	is.Equal(history.LastKnownRacks, []string{"AEJNOSV", "DEEILTV"})
+
+	is.Equal(history.Events[0].Note, "opening\nsecond line of the note")
+	is.Equal(history.Events[0].MillisRemaining, int32(1450000))
+	// Separate notes and a note that goes on over two lines are told apart,
+	// and a clock at zero is kept.
+	is.Equal(history.Events[1].Note, "fine\nbut\nnot great")
+	is.Equal(history.Events[1].MillisRemaining, int32(0))
+	is.Equal(history.Events[1].UnknownPragmas, []string{"#x-emphasis bold"})
+	is.Equal(history.Events[2].Type, pb.GameEvent_UNSUCCESSFUL_CHALLENGE_TURN_LOSS)
+	is.Equal(history.Events[2].Note, "too bad")
+	is.Equal(history.Events[3].MillisRemaining, int32(-45000))
+
+	gcgstr, err := GameHistoryToGCG(history, true)
+	is.NoErr(err)
+	is.Equal(gcgstr, slurp("./testdata/annotated.gcg"))
+
+	// The layout of the file is found again from the original GCG, so it
+	// is kept through the JSON form of the history too.
+	data, err := protojson.Marshal(history)
+	is.NoErr(err)
+	again := &pb.GameHistory{}
+	is.NoErr(protojson.Unmarshal(data, again))
+	gcgstr, err = GameHistoryToGCG(again, true)
+	is.NoErr(err)
+	is.Equal(gcgstr, slurp("./testdata/annotated.gcg"))
+}
+
+func TestHeaderPragmasSetLater(t *testing.T) {
+	is := is.New(t)
+	history, err := ParseGCG(&DefaultConfig, "./testdata/doug_v_emely.gcg")
+	is.NoErr(err)
+	history.Title = "Round 3"
+	history.Players[1].Rating = 1500
+
+	gcgstr, err := GameHistoryToGCG(history, true)
+	is.NoErr(err)
+	lines := strings.Split(gcgstr, "\n")
+	is.Equal(lines[1], "#player1 doug doug")
+	is.Equal(lines[2], "#player2 emely emely")
+	// The pragmas that the file did not have follow in the usual order.
+	is.Equal(lines[3], "#title Round 3")
+	is.Equal(lines[4], "#lexicon "+history.Lexicon)
+	is.Equal(lines[5], "#rating2 1500")
+}
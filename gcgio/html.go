@@ -0,0 +1,141 @@
+package gcgio
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/config"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/render"
+)
+
+// svgSquareSize is the size of a square in the game sheet's diagrams.
+const svgSquareSize = 24
+
+// boardSVG draws the board. The squares in highlight are drawn as the
+// latest play.
+func boardSVG(b *board.GameBoard, ld *tilemapping.LetterDistribution, highlight map[int]bool) (string, error) {
+	d := render.NewBoardDiagram(b, ld)
+	for row := range d.Squares {
+		for col := range d.Squares[row] {
+			d.Squares[row][col].LastPlay = highlight[b.GetSqIdx(row, col)]
+		}
+	}
+	var s strings.Builder
+	if err := d.Write(&s, render.SVG, svgSquareSize); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+type sheetTurn struct {
+	Number int
+	Player string
+	Rack   string
+	Move   string
+	Score  string
+	Total  int32
+	Note   string
+	Board  template.HTML
+}
+
+type sheet struct {
+	Title   string
+	Players []string
+	Lexicon string
+	Turns   []sheetTurn
+	Final   string
+}
+
+var sheetTemplate = template.Must(template.New("sheet").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+.turn { display: inline-block; vertical-align: top; margin: 0 1em 1em 0; page-break-inside: avoid; }
+.turn h2 { font-size: 1em; margin: 0.2em 0; }
+.note { max-width: 400px; font-style: italic; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Lexicon}}<p>Lexicon: {{.Lexicon}}</p>
+{{end}}{{range .Turns}}<div class="turn">
+<h2>{{.Number}}. {{.Player}}: {{.Move}} {{.Score}} ({{.Total}})</h2>
+{{if .Rack}}<p>Rack: {{.Rack}}</p>
+{{end}}{{.Board}}
+{{if .Note}}<p class="note">{{.Note}}</p>
+{{end}}</div>
+{{end}}{{if .Final}}<p>{{.Final}}</p>
+{{end}}</body>
+</html>
+`))
+
+// GameHistoryToHTML returns a printable HTML game sheet, with a diagram of
+// the board after every turn.
+func GameHistoryToHTML(cfg *config.Config, h *pb.GameHistory) (string, error) {
+	g, err := replay(cfg, h, 0)
+	if err != nil {
+		return "", err
+	}
+	ld := g.Bag().LetterDistribution()
+	sh := sheet{Lexicon: h.Lexicon}
+	for _, p := range h.Players {
+		sh.Players = append(sh.Players, p.Nickname)
+	}
+	sh.Title = h.Title
+	if sh.Title == "" {
+		sh.Title = strings.Join(sh.Players, " vs. ")
+	}
+
+	last := make([]tilemapping.MachineLetter, len(g.Board().GetSquares()))
+	for t, evt := range h.Events {
+		if err := g.PlayTurn(t); err != nil {
+			return "", err
+		}
+		mv, score, err := moveText(evt)
+		if err != nil {
+			return "", err
+		}
+		highlight := map[int]bool{}
+		for i, ml := range g.Board().GetSquares() {
+			if ml != 0 && last[i] == 0 {
+				highlight[i] = true
+			}
+		}
+		copy(last, g.Board().GetSquares())
+		svg, err := boardSVG(g.Board(), ld, highlight)
+		if err != nil {
+			return "", err
+		}
+		sh.Turns = append(sh.Turns, sheetTurn{
+			Number: t + 1,
+			Player: h.Players[evt.PlayerIndex].Nickname,
+			Rack:   evt.Rack,
+			Move:   mv,
+			Score:  fmt.Sprintf("%+d", score),
+			Total:  evt.Cumulative,
+			Note:   evt.Note,
+			Board:  template.HTML(svg),
+		})
+	}
+	if len(h.FinalScores) == len(h.Players) {
+		scores := make([]string, len(h.Players))
+		for i, p := range sh.Players {
+			scores[i] = fmt.Sprintf("%s %d", p, h.FinalScores[i])
+		}
+		sh.Final = "Final score: " + strings.Join(scores, ", ")
+	}
+
+	var s strings.Builder
+	if err := sheetTemplate.Execute(&s, sh); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
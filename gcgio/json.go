@@ -0,0 +1,66 @@
+package gcgio
+
+import (
+	"errors"
+	"io"
+
+	"github.com/domino14/word-golib/cache"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+// GameHistoryToJSON returns the GameHistory as a JSON game record.
+func GameHistoryToJSON(h *pb.GameHistory) ([]byte, error) {
+	return protojson.MarshalOptions{Multiline: true, Indent: "  ", UseProtoNames: true}.Marshal(h)
+}
+
+// ParseJSONFromReader reads a JSON game record, such as the ones that
+// GameHistoryToJSON writes. The game in it must be playable.
+func ParseJSONFromReader(cfg *config.Config, reader io.Reader) (*pb.GameHistory, error) {
+	bts, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	history := &pb.GameHistory{}
+	if err := protojson.Unmarshal(bts, history); err != nil {
+		return nil, err
+	}
+	if _, err := replay(cfg, history, len(history.Events)); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ParseJSON reads a JSON game record from a file.
+func ParseJSON(cfg *config.Config, filename string) (*pb.GameHistory, error) {
+	f, _, err := cache.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSONFromReader(cfg, f)
+}
+
+// replay plays a copy of the history up to the given turn. Like the GCG
+// parser, it does not load a lexicon, so the plays are not checked against
+// one.
+func replay(cfg *config.Config, h *pb.GameHistory, turnnum int) (*game.Game, error) {
+	if len(h.Players) != 2 {
+		return nil, errors.New("wrong number of players defined")
+	}
+	h = proto.Clone(h).(*pb.GameHistory)
+	if h.ChallengeRule == pb.ChallengeRule_VOID {
+		// Otherwise every play would be checked against the lexicon.
+		h.ChallengeRule = pb.ChallengeRule_SINGLE
+	}
+	boardLayout, letterDistributionName, variant := game.HistoryToVariant(h)
+	rules, err := game.NewBasicGameRules(cfg, "", boardLayout, letterDistributionName,
+		game.CrossScoreOnly, variant)
+	if err != nil {
+		return nil, err
+	}
+	return game.NewFromHistory(h, rules, turnnum)
+}
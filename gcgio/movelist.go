@@ -0,0 +1,301 @@
+package gcgio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/domino14/word-golib/cache"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+// A move list is a plain text game record, with a header and then one
+// numbered line per event:
+//
+//	Player 1: doug (Doug B)
+//	Player 2: emely
+//	Lexicon: NWL18
+//
+//	  1. doug   DINNVWY  8D WINDY        +32   32
+//	  2. emely  ADEEGIL  7C GALE         +16   16
+//	  3. emely  DEIILTZ  (phony)         -24   -8
+//
+// Moves that don't place tiles are in parentheses. A rack can be given as
+// "-" if it isn't known. Notes and other annotations are not written.
+
+// The move list keywords for the events that don't place tiles.
+const (
+	moveListPass           = "(pass)"
+	moveListPhony          = "(phony)"
+	moveListChallengeBonus = "(challenge)"
+	moveListEndRackPoints  = "(end rack)"
+	moveListTimePenalty    = "(time)"
+	moveListEndRackPenalty = "(rack penalty)"
+	moveListLostChallenge  = "(lost challenge)"
+	moveListExchangePrefix = "(exch "
+)
+
+const moveListUnknownRack = "-"
+
+var (
+	moveListPlayerRegex = regexp.MustCompile(`^Player ([12]): (\S+)(?: \((.*)\))?$`)
+	moveListHeaderRegex = regexp.MustCompile(`^([A-Za-z ]+): (.*)$`)
+	moveListEventRegex  = regexp.MustCompile(`^\s*\d+\.\s+(\S+)\s+(\S+)\s+(.+?)\s+([+-]\d+)\s+(-?\d+)$`)
+)
+
+// moveText returns the move of the event, the way a move list has it, and
+// the score it made.
+func moveText(evt *pb.GameEvent) (string, int32, error) {
+	switch evt.Type {
+	case pb.GameEvent_TILE_PLACEMENT_MOVE:
+		return evt.Position + " " + evt.PlayedTiles, evt.Score, nil
+	case pb.GameEvent_PHONY_TILES_RETURNED:
+		return moveListPhony, -evt.LostScore, nil
+	case pb.GameEvent_PASS:
+		return moveListPass, 0, nil
+	case pb.GameEvent_CHALLENGE_BONUS:
+		return moveListChallengeBonus, evt.Bonus, nil
+	case pb.GameEvent_EXCHANGE:
+		return moveListExchangePrefix + evt.Exchanged + ")", 0, nil
+	case pb.GameEvent_END_RACK_PTS:
+		return moveListEndRackPoints, evt.EndRackPoints, nil
+	case pb.GameEvent_TIME_PENALTY:
+		return moveListTimePenalty, -evt.LostScore, nil
+	case pb.GameEvent_END_RACK_PENALTY:
+		return moveListEndRackPenalty, -evt.LostScore, nil
+	case pb.GameEvent_UNSUCCESSFUL_CHALLENGE_TURN_LOSS:
+		return moveListLostChallenge, 0, nil
+	}
+	return "", 0, fmt.Errorf("event type %v not supported", evt.Type)
+}
+
+// GameHistoryToMoveList returns a plain move list of the GameHistory.
+func GameHistoryToMoveList(h *pb.GameHistory) (string, error) {
+	var s strings.Builder
+	nickWidth := 0
+	for i, p := range h.Players {
+		fmt.Fprintf(&s, "Player %d: %s", i+1, p.Nickname)
+		if p.RealName != "" && p.RealName != p.Nickname {
+			fmt.Fprintf(&s, " (%s)", p.RealName)
+		}
+		s.WriteString("\n")
+		nickWidth = max(nickWidth, len([]rune(p.Nickname)))
+	}
+	if h.Title != "" {
+		s.WriteString("Title: " + h.Title + "\n")
+	}
+	if h.Lexicon != "" {
+		s.WriteString("Lexicon: " + h.Lexicon + "\n")
+	}
+	if h.Variant != "" {
+		s.WriteString("Variant: " + h.Variant + "\n")
+	}
+	if h.BoardLayout != "" && h.BoardLayout != board.CrosswordGameLayout {
+		s.WriteString("Board: " + h.BoardLayout + "\n")
+	}
+	if h.LetterDistribution != "" && h.LetterDistribution != "english" {
+		s.WriteString("Distribution: " + h.LetterDistribution + "\n")
+	}
+	s.WriteString("\n")
+
+	for i, evt := range h.Events {
+		mv, score, err := moveText(evt)
+		if err != nil {
+			return "", err
+		}
+		rack := evt.Rack
+		if rack == "" {
+			rack = moveListUnknownRack
+		}
+		fmt.Fprintf(&s, "%3d. %-*s  %-9s %-16s %+4d %5d\n", i+1, nickWidth,
+			h.Players[evt.PlayerIndex].Nickname, rack, mv, score, evt.Cumulative)
+	}
+	return s.String(), nil
+}
+
+// parseMoveListEvent turns an event line of a move list into an event.
+func parseMoveListEvent(h *pb.GameHistory, match []string) (*pb.GameEvent, error) {
+	evt := &pb.GameEvent{}
+	var err error
+	evt.PlayerIndex, err = nickToPIndex(match[1], h.Players)
+	if err != nil {
+		return nil, err
+	}
+	if match[2] != moveListUnknownRack {
+		evt.Rack = match[2]
+	}
+	score, err := matchToInt32(strings.TrimPrefix(match[4], "+"))
+	if err != nil {
+		return nil, err
+	}
+	evt.Cumulative, err = matchToInt32(match[5])
+	if err != nil {
+		return nil, err
+	}
+	mv := match[3]
+	switch {
+	case mv == moveListPass:
+		evt.Type = pb.GameEvent_PASS
+	case mv == moveListLostChallenge:
+		evt.Type = pb.GameEvent_UNSUCCESSFUL_CHALLENGE_TURN_LOSS
+	case mv == moveListPhony:
+		if len(h.Events) == 0 {
+			return nil, errors.New("phony tiles returned without play")
+		}
+		evt.Type = pb.GameEvent_PHONY_TILES_RETURNED
+		evt.LostScore = -score
+		evt.PlayedTiles = h.Events[len(h.Events)-1].PlayedTiles
+	case mv == moveListChallengeBonus:
+		evt.Type = pb.GameEvent_CHALLENGE_BONUS
+		evt.Bonus = score
+	case mv == moveListEndRackPoints:
+		evt.Type = pb.GameEvent_END_RACK_PTS
+		evt.EndRackPoints = score
+	case mv == moveListTimePenalty:
+		evt.Type = pb.GameEvent_TIME_PENALTY
+		evt.LostScore = -score
+	case mv == moveListEndRackPenalty:
+		evt.Type = pb.GameEvent_END_RACK_PENALTY
+		evt.LostScore = -score
+	case strings.HasPrefix(mv, moveListExchangePrefix) && strings.HasSuffix(mv, ")"):
+		evt.Type = pb.GameEvent_EXCHANGE
+		evt.Exchanged = strings.TrimSuffix(strings.TrimPrefix(mv, moveListExchangePrefix), ")")
+		if evt.Rack == "" {
+			evt.Rack = evt.Exchanged
+		}
+	default:
+		fields := strings.Fields(mv)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("cannot understand move %q", mv)
+		}
+		evt.Type = pb.GameEvent_TILE_PLACEMENT_MOVE
+		evt.Position = fields[0]
+		evt.PlayedTiles = fields[1]
+		evt.Score = score
+		game.CalculateCoordsFromStringPosition(evt)
+		if evt.Rack == "" {
+			// The rack had at least the tiles that were played.
+			evt.Rack = strings.Map(func(r rune) rune {
+				if r == '.' {
+					return -1
+				}
+				if r >= 'a' && r <= 'z' {
+					return '?'
+				}
+				return r
+			}, evt.PlayedTiles)
+		}
+	}
+	return evt, nil
+}
+
+// ParseMoveListFromReader reads a move list, such as the ones that
+// GameHistoryToMoveList writes, into a GameHistory.
+func ParseMoveListFromReader(cfg *config.Config, reader io.Reader) (*pb.GameHistory, error) {
+	history := &pb.GameHistory{
+		Events:  []*pb.GameEvent{},
+		Players: []*pb.PlayerInfo{},
+		Version: game.CurrentGameHistoryVersion,
+	}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if match := moveListEventRegex.FindStringSubmatch(line); match != nil {
+			if len(history.Players) != 2 {
+				return nil, errors.New("wrong number of players defined")
+			}
+			evt, err := parseMoveListEvent(history, match)
+			if err != nil {
+				return nil, fmt.Errorf("error in line `%s`: %w", line, err)
+			}
+			history.Events = append(history.Events, evt)
+			continue
+		}
+		if len(history.Events) > 0 {
+			return nil, fmt.Errorf("no match found for line '%v'", line)
+		}
+		if match := moveListPlayerRegex.FindStringSubmatch(line); match != nil {
+			if int(match[1][0]-'0') != len(history.Players)+1 {
+				return nil, errPlayerNotSupported
+			}
+			if len(history.Players) == 1 && history.Players[0].Nickname == match[2] {
+				return nil, errDuplicateNames
+			}
+			realname := match[3]
+			if realname == "" {
+				realname = match[2]
+			}
+			history.Players = append(history.Players, &pb.PlayerInfo{
+				Nickname: match[2], RealName: realname})
+			continue
+		}
+		match := moveListHeaderRegex.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("no match found for line '%v'", line)
+		}
+		switch match[1] {
+		case "Title":
+			history.Title = match[2]
+		case "Lexicon":
+			history.Lexicon = match[2]
+		case "Variant":
+			history.Variant = match[2]
+		case "Board":
+			history.BoardLayout = match[2]
+		case "Distribution":
+			history.LetterDistribution = match[2]
+		default:
+			return nil, fmt.Errorf("unknown header %q", match[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if history.Lexicon == "" {
+		history.Lexicon = cfg.GetString(config.ConfigDefaultLexicon)
+	}
+
+	g, err := replay(cfg, history, len(history.Events))
+	if err != nil {
+		return nil, err
+	}
+	if n := len(history.Events); n > 0 {
+		switch history.Events[n-1].Type {
+		case pb.GameEvent_END_RACK_PTS, pb.GameEvent_END_RACK_PENALTY, pb.GameEvent_TIME_PENALTY:
+			// As in a GCG file, these end the game.
+			g.SetPlaying(pb.PlayState_GAME_OVER)
+			g.AddFinalScoresToHistory()
+		}
+	}
+	// Fill in what the replay worked out.
+	for i, evt := range g.History().Events {
+		history.Events[i].WordsFormed = evt.WordsFormed
+		if evt.Type == pb.GameEvent_TILE_PLACEMENT_MOVE {
+			history.Events[i].IsBingo = g.Rules().RuleSet().IsBingo(
+				len(strings.ReplaceAll(evt.PlayedTiles, ".", "")))
+		}
+	}
+	history.PlayState = g.Playing()
+	history.FinalScores = g.History().FinalScores
+	history.Winner = g.History().Winner
+	return history, nil
+}
+
+// ParseMoveList reads a move list from a file.
+func ParseMoveList(cfg *config.Config, filename string) (*pb.GameHistory, error) {
+	f, _, err := cache.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMoveListFromReader(cfg, f)
+}
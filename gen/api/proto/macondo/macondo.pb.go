@@ -442,6 +442,14 @@ type GameHistory struct {
 	LetterDistribution string `protobuf:"bytes,18,opt,name=letter_distribution,json=letterDistribution,proto3" json:"letter_distribution,omitempty"`
 	// If provided, the starting CGP is a crossword-game position string.
 	StartingCgp string `protobuf:"bytes,19,opt,name=starting_cgp,json=startingCgp,proto3" json:"starting_cgp,omitempty"`
+	// The tournament or club event the game was played at, its round, and
+	// the date it was played on, as written in the GCG file.
+	EventName string `protobuf:"bytes,20,opt,name=event_name,json=eventName,proto3" json:"event_name,omitempty"`
+	Round     string `protobuf:"bytes,21,opt,name=round,proto3" json:"round,omitempty"`
+	Date      string `protobuf:"bytes,22,opt,name=date,proto3" json:"date,omitempty"`
+	// unknown_pragmas are the lines of the GCG header that macondo does not
+	// understand. They are kept as they are.
+	UnknownPragmas []string `protobuf:"bytes,23,rep,name=unknown_pragmas,json=unknownPragmas,proto3" json:"unknown_pragmas,omitempty"`
 }
 
 func (x *GameHistory) Reset() {
@@ -610,6 +618,34 @@ func (x *GameHistory) GetStartingCgp() string {
 	return ""
 }
 
+func (x *GameHistory) GetEventName() string {
+	if x != nil {
+		return x.EventName
+	}
+	return ""
+}
+
+func (x *GameHistory) GetRound() string {
+	if x != nil {
+		return x.Round
+	}
+	return ""
+}
+
+func (x *GameHistory) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *GameHistory) GetUnknownPragmas() []string {
+	if x != nil {
+		return x.UnknownPragmas
+	}
+	return nil
+}
+
 // This should be merged into Move.
 type GameEvent struct {
 	state         protoimpl.MessageState
@@ -645,6 +681,9 @@ type GameEvent struct {
 	// num_tiles_from_rack lets us know how many tiles from the rack were either
 	// played or exchanged. It is only populated for those two fields.
 	NumTilesFromRack uint32 `protobuf:"varint,20,opt,name=num_tiles_from_rack,json=numTilesFromRack,proto3" json:"num_tiles_from_rack,omitempty"`
+	// unknown_pragmas are GCG pragma lines that followed this event and that
+	// macondo does not understand. They are kept as they are.
+	UnknownPragmas []string `protobuf:"bytes,21,rep,name=unknown_pragmas,json=unknownPragmas,proto3" json:"unknown_pragmas,omitempty"`
 }
 
 func (x *GameEvent) Reset() {
@@ -820,6 +859,13 @@ func (x *GameEvent) GetNumTilesFromRack() uint32 {
 	return 0
 }
 
+func (x *GameEvent) GetUnknownPragmas() []string {
+	if x != nil {
+		return x.UnknownPragmas
+	}
+	return nil
+}
+
 type PlayerInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -830,6 +876,7 @@ type PlayerInfo struct {
 	// user_id is an internal, unchangeable user ID, whereas the other two user
 	// identifiers might possibly be mutable.
 	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Rating int32  `protobuf:"varint,4,opt,name=rating,proto3" json:"rating,omitempty"`
 }
 
 func (x *PlayerInfo) Reset() {
@@ -885,6 +932,13 @@ func (x *PlayerInfo) GetUserId() string {
 	return ""
 }
 
+func (x *PlayerInfo) GetRating() int32 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
 type BotRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -894,6 +948,11 @@ type BotRequest struct {
 	EvaluationRequest *EvaluationRequest `protobuf:"bytes,2,opt,name=evaluation_request,json=evaluationRequest,proto3" json:"evaluation_request,omitempty"`
 	BotType           BotRequest_BotCode `protobuf:"varint,3,opt,name=bot_type,json=botType,proto3,enum=macondo.BotRequest_BotCode" json:"bot_type,omitempty"`
 	MillisRemaining   int32              `protobuf:"varint,4,opt,name=millis_remaining,json=millisRemaining,proto3" json:"millis_remaining,omitempty"`
+	// personality is the name of a bot personality in the personalities
+	// file. If set, it replaces the built-in personality for the bot type.
+	Personality string `protobuf:"bytes,5,opt,name=personality,proto3" json:"personality,omitempty"`
+	// phony_options, if set, overrides the personality's phony options.
+	PhonyOptions *PhonyOptions `protobuf:"bytes,6,opt,name=phony_options,json=phonyOptions,proto3" json:"phony_options,omitempty"`
 }
 
 func (x *BotRequest) Reset() {
@@ -956,6 +1015,102 @@ func (x *BotRequest) GetMillisRemaining() int32 {
 	return 0
 }
 
+func (x *BotRequest) GetPersonality() string {
+	if x != nil {
+		return x.Personality
+	}
+	return ""
+}
+
+func (x *BotRequest) GetPhonyOptions() *PhonyOptions {
+	if x != nil {
+		return x.PhonyOptions
+	}
+	return nil
+}
+
+// PhonyOptions configures how a bot deals with phonies.
+type PhonyOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// known_words_lexicon is the name of a lexicon with the words that the
+	// bot knows, usually a subset of the game's lexicon. If it is empty, the
+	// bot knows every word, and challenges exactly the phonies.
+	KnownWordsLexicon string `protobuf:"bytes,1,opt,name=known_words_lexicon,json=knownWordsLexicon,proto3" json:"known_words_lexicon,omitempty"`
+	// unknown_word_phony_prob is the chance that a word the bot does not know
+	// is phony.
+	UnknownWordPhonyProb float64 `protobuf:"fixed64,2,opt,name=unknown_word_phony_prob,json=unknownWordPhonyProb,proto3" json:"unknown_word_phony_prob,omitempty"`
+	// bluff_lexicon is the name of a lexicon to take phonies from, usually a
+	// bigger one than the game's. If it is empty, the bot never plays phonies
+	// on purpose.
+	BluffLexicon string `protobuf:"bytes,3,opt,name=bluff_lexicon,json=bluffLexicon,proto3" json:"bluff_lexicon,omitempty"`
+	// opponent_challenge_prob is the chance that the opponent challenges a
+	// phony.
+	OpponentChallengeProb float64 `protobuf:"fixed64,4,opt,name=opponent_challenge_prob,json=opponentChallengeProb,proto3" json:"opponent_challenge_prob,omitempty"`
+}
+
+func (x *PhonyOptions) Reset() {
+	*x = PhonyOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PhonyOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PhonyOptions) ProtoMessage() {}
+
+func (x *PhonyOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PhonyOptions.ProtoReflect.Descriptor instead.
+func (*PhonyOptions) Descriptor() ([]byte, []int) {
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PhonyOptions) GetKnownWordsLexicon() string {
+	if x != nil {
+		return x.KnownWordsLexicon
+	}
+	return ""
+}
+
+func (x *PhonyOptions) GetUnknownWordPhonyProb() float64 {
+	if x != nil {
+		return x.UnknownWordPhonyProb
+	}
+	return 0
+}
+
+func (x *PhonyOptions) GetBluffLexicon() string {
+	if x != nil {
+		return x.BluffLexicon
+	}
+	return ""
+}
+
+func (x *PhonyOptions) GetOpponentChallengeProb() float64 {
+	if x != nil {
+		return x.OpponentChallengeProb
+	}
+	return 0
+}
+
 type EvaluationRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -968,7 +1123,7 @@ type EvaluationRequest struct {
 func (x *EvaluationRequest) Reset() {
 	*x = EvaluationRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[4]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -981,7 +1136,7 @@ func (x *EvaluationRequest) String() string {
 func (*EvaluationRequest) ProtoMessage() {}
 
 func (x *EvaluationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[4]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -994,7 +1149,7 @@ func (x *EvaluationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EvaluationRequest.ProtoReflect.Descriptor instead.
 func (*EvaluationRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{4}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *EvaluationRequest) GetUser() string {
@@ -1015,7 +1170,7 @@ type Evaluation struct {
 func (x *Evaluation) Reset() {
 	*x = Evaluation{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[5]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1028,7 +1183,7 @@ func (x *Evaluation) String() string {
 func (*Evaluation) ProtoMessage() {}
 
 func (x *Evaluation) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[5]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1041,7 +1196,7 @@ func (x *Evaluation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Evaluation.ProtoReflect.Descriptor instead.
 func (*Evaluation) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{5}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Evaluation) GetPlayEval() []*SingleEvaluation {
@@ -1067,7 +1222,7 @@ type SingleEvaluation struct {
 func (x *SingleEvaluation) Reset() {
 	*x = SingleEvaluation{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[6]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1080,7 +1235,7 @@ func (x *SingleEvaluation) String() string {
 func (*SingleEvaluation) ProtoMessage() {}
 
 func (x *SingleEvaluation) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[6]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1093,7 +1248,7 @@ func (x *SingleEvaluation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SingleEvaluation.ProtoReflect.Descriptor instead.
 func (*SingleEvaluation) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{6}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *SingleEvaluation) GetEquityLoss() float64 {
@@ -1155,7 +1310,7 @@ type BotResponse struct {
 func (x *BotResponse) Reset() {
 	*x = BotResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[7]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1168,7 +1323,7 @@ func (x *BotResponse) String() string {
 func (*BotResponse) ProtoMessage() {}
 
 func (x *BotResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[7]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1181,7 +1336,7 @@ func (x *BotResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BotResponse.ProtoReflect.Descriptor instead.
 func (*BotResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{7}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{8}
 }
 
 func (m *BotResponse) GetResponse() isBotResponse_Response {
@@ -1250,7 +1405,7 @@ type PuzzleCreationResponse struct {
 func (x *PuzzleCreationResponse) Reset() {
 	*x = PuzzleCreationResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[8]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1263,7 +1418,7 @@ func (x *PuzzleCreationResponse) String() string {
 func (*PuzzleCreationResponse) ProtoMessage() {}
 
 func (x *PuzzleCreationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[8]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1276,7 +1431,7 @@ func (x *PuzzleCreationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PuzzleCreationResponse.ProtoReflect.Descriptor instead.
 func (*PuzzleCreationResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{8}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *PuzzleCreationResponse) GetGameId() string {
@@ -1328,7 +1483,7 @@ type PuzzleBucket struct {
 func (x *PuzzleBucket) Reset() {
 	*x = PuzzleBucket{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[9]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1341,7 +1496,7 @@ func (x *PuzzleBucket) String() string {
 func (*PuzzleBucket) ProtoMessage() {}
 
 func (x *PuzzleBucket) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[9]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1354,7 +1509,7 @@ func (x *PuzzleBucket) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PuzzleBucket.ProtoReflect.Descriptor instead.
 func (*PuzzleBucket) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{9}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *PuzzleBucket) GetIndex() int32 {
@@ -1396,7 +1551,7 @@ type PuzzleGenerationRequest struct {
 func (x *PuzzleGenerationRequest) Reset() {
 	*x = PuzzleGenerationRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_macondo_macondo_proto_msgTypes[10]
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1409,7 +1564,7 @@ func (x *PuzzleGenerationRequest) String() string {
 func (*PuzzleGenerationRequest) ProtoMessage() {}
 
 func (x *PuzzleGenerationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_macondo_macondo_proto_msgTypes[10]
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1422,7 +1577,7 @@ func (x *PuzzleGenerationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PuzzleGenerationRequest.ProtoReflect.Descriptor instead.
 func (*PuzzleGenerationRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{10}
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *PuzzleGenerationRequest) GetBuckets() []*PuzzleBucket {
@@ -1432,12 +1587,203 @@ func (x *PuzzleGenerationRequest) GetBuckets() []*PuzzleBucket {
 	return nil
 }
 
+// TrainingCandidate is a single candidate play considered in a position.
+type TrainingCandidate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Play   string  `protobuf:"bytes,1,opt,name=play,proto3" json:"play,omitempty"`
+	Score  int32   `protobuf:"varint,2,opt,name=score,proto3" json:"score,omitempty"`
+	Equity float64 `protobuf:"fixed64,3,opt,name=equity,proto3" json:"equity,omitempty"`
+	// win_pct is the simmed win probability (0 to 1). It is only meaningful
+	// if simmed is true.
+	WinPct float64 `protobuf:"fixed64,4,opt,name=win_pct,json=winPct,proto3" json:"win_pct,omitempty"`
+	Simmed bool    `protobuf:"varint,5,opt,name=simmed,proto3" json:"simmed,omitempty"`
+}
+
+func (x *TrainingCandidate) Reset() {
+	*x = TrainingCandidate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrainingCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrainingCandidate) ProtoMessage() {}
+
+func (x *TrainingCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrainingCandidate.ProtoReflect.Descriptor instead.
+func (*TrainingCandidate) Descriptor() ([]byte, []int) {
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TrainingCandidate) GetPlay() string {
+	if x != nil {
+		return x.Play
+	}
+	return ""
+}
+
+func (x *TrainingCandidate) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *TrainingCandidate) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *TrainingCandidate) GetWinPct() float64 {
+	if x != nil {
+		return x.WinPct
+	}
+	return 0
+}
+
+func (x *TrainingCandidate) GetSimmed() bool {
+	if x != nil {
+		return x.Simmed
+	}
+	return false
+}
+
+// TrainingRecord is a single position from a self-play game, meant for
+// training evaluation models.
+type TrainingRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Turn   int32  `protobuf:"varint,2,opt,name=turn,proto3" json:"turn,omitempty"`
+	Cgp    string `protobuf:"bytes,3,opt,name=cgp,proto3" json:"cgp,omitempty"`
+	// on_turn is the index of the player to move.
+	OnTurn     int32                `protobuf:"varint,4,opt,name=on_turn,json=onTurn,proto3" json:"on_turn,omitempty"`
+	Candidates []*TrainingCandidate `protobuf:"bytes,5,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Chosen     string               `protobuf:"bytes,6,opt,name=chosen,proto3" json:"chosen,omitempty"`
+	// final_result is 1 for a win, 0.5 for a tie and 0 for a loss, from the
+	// perspective of the player to move.
+	FinalResult float64 `protobuf:"fixed64,7,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`
+	// final_spread is also from the perspective of the player to move.
+	FinalSpread int32 `protobuf:"varint,8,opt,name=final_spread,json=finalSpread,proto3" json:"final_spread,omitempty"`
+}
+
+func (x *TrainingRecord) Reset() {
+	*x = TrainingRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_macondo_macondo_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrainingRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrainingRecord) ProtoMessage() {}
+
+func (x *TrainingRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_macondo_macondo_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrainingRecord.ProtoReflect.Descriptor instead.
+func (*TrainingRecord) Descriptor() ([]byte, []int) {
+	return file_api_proto_macondo_macondo_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TrainingRecord) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *TrainingRecord) GetTurn() int32 {
+	if x != nil {
+		return x.Turn
+	}
+	return 0
+}
+
+func (x *TrainingRecord) GetCgp() string {
+	if x != nil {
+		return x.Cgp
+	}
+	return ""
+}
+
+func (x *TrainingRecord) GetOnTurn() int32 {
+	if x != nil {
+		return x.OnTurn
+	}
+	return 0
+}
+
+func (x *TrainingRecord) GetCandidates() []*TrainingCandidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *TrainingRecord) GetChosen() string {
+	if x != nil {
+		return x.Chosen
+	}
+	return ""
+}
+
+func (x *TrainingRecord) GetFinalResult() float64 {
+	if x != nil {
+		return x.FinalResult
+	}
+	return 0
+}
+
+func (x *TrainingRecord) GetFinalSpread() int32 {
+	if x != nil {
+		return x.FinalSpread
+	}
+	return 0
+}
+
 var File_api_proto_macondo_macondo_proto protoreflect.FileDescriptor
 
 var file_api_proto_macondo_macondo_proto_rawDesc = []byte{
 	0x0a, 0x1f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x61, 0x63, 0x6f,
 	0x6e, 0x64, 0x6f, 0x2f, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x07, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x22, 0xba, 0x05, 0x0a, 0x0b, 0x47,
+	0x6f, 0x12, 0x07, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x22, 0xac, 0x06, 0x0a, 0x0b, 0x47,
 	0x61, 0x6d, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x2a, 0x0a, 0x06, 0x65, 0x76,
 	0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63,
 	0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06,
@@ -1481,189 +1827,244 @@ var file_api_proto_macondo_macondo_proto_rawDesc = []byte{
 	0x52, 0x12, 0x6c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75,
 	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x69, 0x6e, 0x67,
 	0x5f, 0x63, 0x67, 0x70, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72,
-	0x74, 0x69, 0x6e, 0x67, 0x43, 0x67, 0x70, 0x22, 0x94, 0x07, 0x0a, 0x09, 0x47, 0x61, 0x6d, 0x65,
-	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x08, 0x6e, 0x69, 0x63, 0x6b, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x02, 0x18, 0x01, 0x52, 0x08, 0x6e, 0x69, 0x63,
-	0x6b, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x63,
-	0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x61, 0x63, 0x6b, 0x12, 0x2b, 0x0a,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x6d, 0x61,
-	0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e,
-	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x75,
-	0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
-	0x63, 0x75, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x6f,
-	0x77, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x72, 0x6f, 0x77, 0x12, 0x16, 0x0a, 0x06,
-	0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x63, 0x6f,
-	0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x3a, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64,
-	0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x44, 0x69, 0x72, 0x65,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c,
-	0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0b, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x54, 0x69, 0x6c, 0x65, 0x73, 0x12,
-	0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x14, 0x0a,
-	0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x63,
-	0x6f, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6f, 0x6e, 0x75, 0x73, 0x18, 0x0d, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x05, 0x62, 0x6f, 0x6e, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x65, 0x6e, 0x64,
-	0x5f, 0x72, 0x61, 0x63, 0x6b, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0d, 0x65, 0x6e, 0x64, 0x52, 0x61, 0x63, 0x6b, 0x50, 0x6f, 0x69, 0x6e, 0x74,
-	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x73, 0x74, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18,
-	0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x73, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65,
-	0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x62, 0x69, 0x6e, 0x67, 0x6f, 0x18, 0x10, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x42, 0x69, 0x6e, 0x67, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x77,
-	0x6f, 0x72, 0x64, 0x73, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x65, 0x64, 0x18, 0x11, 0x20, 0x03, 0x28,
-	0x09, 0x52, 0x0b, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x46, 0x6f, 0x72, 0x6d, 0x65, 0x64, 0x12, 0x29,
-	0x0a, 0x10, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69,
-	0x6e, 0x67, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73,
-	0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x6c, 0x61,
-	0x79, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0b, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2d, 0x0a, 0x13,
-	0x6e, 0x75, 0x6d, 0x5f, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x72,
-	0x61, 0x63, 0x6b, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x6e, 0x75, 0x6d, 0x54, 0x69,
-	0x6c, 0x65, 0x73, 0x46, 0x72, 0x6f, 0x6d, 0x52, 0x61, 0x63, 0x6b, 0x22, 0xd5, 0x01, 0x0a, 0x04,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x17, 0x0a, 0x13, 0x54, 0x49, 0x4c, 0x45, 0x5f, 0x50, 0x4c, 0x41,
-	0x43, 0x45, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x4d, 0x4f, 0x56, 0x45, 0x10, 0x00, 0x12, 0x18, 0x0a,
-	0x14, 0x50, 0x48, 0x4f, 0x4e, 0x59, 0x5f, 0x54, 0x49, 0x4c, 0x45, 0x53, 0x5f, 0x52, 0x45, 0x54,
-	0x55, 0x52, 0x4e, 0x45, 0x44, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x41, 0x53, 0x53, 0x10,
-	0x02, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47, 0x45, 0x5f, 0x42,
-	0x4f, 0x4e, 0x55, 0x53, 0x10, 0x03, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x58, 0x43, 0x48, 0x41, 0x4e,
-	0x47, 0x45, 0x10, 0x04, 0x12, 0x10, 0x0a, 0x0c, 0x45, 0x4e, 0x44, 0x5f, 0x52, 0x41, 0x43, 0x4b,
-	0x5f, 0x50, 0x54, 0x53, 0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x49, 0x4d, 0x45, 0x5f, 0x50,
-	0x45, 0x4e, 0x41, 0x4c, 0x54, 0x59, 0x10, 0x06, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4e, 0x44, 0x5f,
-	0x52, 0x41, 0x43, 0x4b, 0x5f, 0x50, 0x45, 0x4e, 0x41, 0x4c, 0x54, 0x59, 0x10, 0x07, 0x12, 0x24,
-	0x0a, 0x20, 0x55, 0x4e, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x46, 0x55, 0x4c, 0x5f, 0x43,
-	0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x55, 0x52, 0x4e, 0x5f, 0x4c, 0x4f,
-	0x53, 0x53, 0x10, 0x08, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47,
-	0x45, 0x10, 0x09, 0x22, 0x29, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x0e, 0x0a, 0x0a, 0x48, 0x4f, 0x52, 0x49, 0x5a, 0x4f, 0x4e, 0x54, 0x41, 0x4c, 0x10, 0x00,
-	0x12, 0x0c, 0x0a, 0x08, 0x56, 0x45, 0x52, 0x54, 0x49, 0x43, 0x41, 0x4c, 0x10, 0x01, 0x22, 0x5e,
-	0x0a, 0x0a, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08,
-	0x6e, 0x69, 0x63, 0x6b, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x6e, 0x69, 0x63, 0x6b, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x6c,
-	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x61,
-	0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0xc3,
-	0x04, 0x0a, 0x0a, 0x42, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x37, 0x0a,
-	0x0c, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61,
-	0x6d, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x0b, 0x67, 0x61, 0x6d, 0x65, 0x48,
-	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x49, 0x0a, 0x12, 0x65, 0x76, 0x61, 0x6c, 0x75, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x45, 0x76, 0x61,
-	0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x11,
-	0x65, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x6f, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x42, 0x6f,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x42, 0x6f, 0x74, 0x43, 0x6f, 0x64, 0x65,
-	0x52, 0x07, 0x62, 0x6f, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x69, 0x6c,
-	0x6c, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x0f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x52, 0x65, 0x6d, 0x61, 0x69,
-	0x6e, 0x69, 0x6e, 0x67, 0x22, 0xcd, 0x02, 0x0a, 0x07, 0x42, 0x6f, 0x74, 0x43, 0x6f, 0x64, 0x65,
-	0x12, 0x0d, 0x0a, 0x09, 0x48, 0x41, 0x53, 0x54, 0x59, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x00, 0x12,
-	0x12, 0x0a, 0x0e, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x31, 0x5f, 0x43, 0x45, 0x4c, 0x5f, 0x42, 0x4f,
-	0x54, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x32, 0x5f, 0x43, 0x45,
-	0x4c, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x45, 0x56, 0x45, 0x4c,
-	0x33, 0x5f, 0x43, 0x45, 0x4c, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x4c,
-	0x45, 0x56, 0x45, 0x4c, 0x34, 0x5f, 0x43, 0x45, 0x4c, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x04, 0x12,
-	0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x31, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42,
-	0x49, 0x4c, 0x49, 0x53, 0x54, 0x49, 0x43, 0x10, 0x05, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56,
-	0x45, 0x4c, 0x32, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x53, 0x54, 0x49,
-	0x43, 0x10, 0x06, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x33, 0x5f, 0x50, 0x52,
-	0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x53, 0x54, 0x49, 0x43, 0x10, 0x07, 0x12, 0x18, 0x0a,
-	0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x34, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c,
-	0x49, 0x53, 0x54, 0x49, 0x43, 0x10, 0x08, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c,
-	0x35, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x53, 0x54, 0x49, 0x43, 0x10,
-	0x09, 0x12, 0x10, 0x0a, 0x0c, 0x4e, 0x4f, 0x5f, 0x4c, 0x45, 0x41, 0x56, 0x45, 0x5f, 0x42, 0x4f,
-	0x54, 0x10, 0x0a, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x49, 0x4d, 0x4d, 0x49, 0x4e, 0x47, 0x5f, 0x42,
-	0x4f, 0x54, 0x10, 0x0b, 0x12, 0x1a, 0x0a, 0x16, 0x48, 0x41, 0x53, 0x54, 0x59, 0x5f, 0x50, 0x4c,
-	0x55, 0x53, 0x5f, 0x45, 0x4e, 0x44, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x0c,
-	0x12, 0x15, 0x0a, 0x11, 0x53, 0x49, 0x4d, 0x4d, 0x49, 0x4e, 0x47, 0x5f, 0x49, 0x4e, 0x46, 0x45,
-	0x52, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x0d, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
-	0x57, 0x4e, 0x10, 0x64, 0x22, 0x27, 0x0a, 0x11, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x22, 0x44, 0x0a,
-	0x0a, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x09, 0x70,
-	0x6c, 0x61, 0x79, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19,
-	0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x53, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x45,
-	0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x45,
-	0x76, 0x61, 0x6c, 0x22, 0xf2, 0x01, 0x0a, 0x10, 0x53, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x45, 0x76,
-	0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x71, 0x75, 0x69,
-	0x74, 0x79, 0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x65,
-	0x71, 0x75, 0x69, 0x74, 0x79, 0x4c, 0x6f, 0x73, 0x73, 0x12, 0x20, 0x0a, 0x0c, 0x77, 0x69, 0x6e,
-	0x5f, 0x70, 0x63, 0x74, 0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
-	0x0a, 0x77, 0x69, 0x6e, 0x50, 0x63, 0x74, 0x4c, 0x6f, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6d,
-	0x69, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x69, 0x6e, 0x67, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x0b, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x42, 0x69, 0x6e, 0x67, 0x6f, 0x12, 0x2c,
-	0x0a, 0x12, 0x70, 0x6f, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x5f,
-	0x70, 0x6c, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x70, 0x6f, 0x73, 0x73,
-	0x69, 0x62, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x12, 0x28, 0x0a, 0x10,
-	0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x5f, 0x70, 0x6c, 0x61, 0x79,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x53, 0x74,
-	0x61, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x12, 0x20, 0x0a, 0x0c, 0x74, 0x6f, 0x70, 0x5f, 0x69, 0x73,
-	0x5f, 0x62, 0x69, 0x6e, 0x67, 0x6f, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74, 0x6f,
-	0x70, 0x49, 0x73, 0x42, 0x69, 0x6e, 0x67, 0x6f, 0x22, 0x9d, 0x01, 0x0a, 0x0b, 0x42, 0x6f, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x6d, 0x6f, 0x76, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f,
-	0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x04, 0x6d, 0x6f,
-	0x76, 0x65, 0x12, 0x16, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x27, 0x0a, 0x04, 0x65, 0x76,
-	0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e,
-	0x64, 0x6f, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65,
-	0x76, 0x61, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x42, 0x0a, 0x0a, 0x08,
-	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xc9, 0x01, 0x0a, 0x16, 0x50, 0x75, 0x7a,
-	0x7a, 0x6c, 0x65, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b,
-	0x74, 0x75, 0x72, 0x6e, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x0a, 0x74, 0x75, 0x72, 0x6e, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x2a, 0x0a,
-	0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x74, 0x69, 0x6e, 0x67, 0x43, 0x67, 0x70, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65,
+	0x12, 0x27, 0x0a, 0x0f, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f, 0x70, 0x72, 0x61, 0x67,
+	0x6d, 0x61, 0x73, 0x18, 0x17, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x75, 0x6e, 0x6b, 0x6e, 0x6f,
+	0x77, 0x6e, 0x50, 0x72, 0x61, 0x67, 0x6d, 0x61, 0x73, 0x22, 0xbd, 0x07, 0x0a, 0x09, 0x47, 0x61,
+	0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x08, 0x6e, 0x69, 0x63, 0x6b, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x02, 0x18, 0x01, 0x52, 0x08, 0x6e,
+	0x69, 0x63, 0x6b, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x61, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x61, 0x63, 0x6b, 0x12,
+	0x2b, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e,
 	0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e,
-	0x74, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x04, 0x74, 0x61, 0x67,
+	0x74, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x75, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x63, 0x75, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x72, 0x6f, 0x77, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x72, 0x6f, 0x77, 0x12, 0x16,
+	0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x3a, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x6d, 0x61, 0x63, 0x6f,
+	0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21,
+	0x0a, 0x0c, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x54, 0x69, 0x6c, 0x65,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6f, 0x6e, 0x75, 0x73, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x62, 0x6f, 0x6e, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x65,
+	0x6e, 0x64, 0x5f, 0x72, 0x61, 0x63, 0x6b, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x65, 0x6e, 0x64, 0x52, 0x61, 0x63, 0x6b, 0x50, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x73, 0x74, 0x5f, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x73, 0x74, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x73, 0x5f, 0x62, 0x69, 0x6e, 0x67, 0x6f, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x42, 0x69, 0x6e, 0x67, 0x6f, 0x12, 0x21, 0x0a,
+	0x0c, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x65, 0x64, 0x18, 0x11, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0b, 0x77, 0x6f, 0x72, 0x64, 0x73, 0x46, 0x6f, 0x72, 0x6d, 0x65, 0x64,
+	0x12, 0x29, 0x0a, 0x10, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x18, 0x12, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x6d, 0x69, 0x6c, 0x6c,
+	0x69, 0x73, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0b, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2d,
+	0x0a, 0x13, 0x6e, 0x75, 0x6d, 0x5f, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x66, 0x72, 0x6f, 0x6d,
+	0x5f, 0x72, 0x61, 0x63, 0x6b, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x6e, 0x75, 0x6d,
+	0x54, 0x69, 0x6c, 0x65, 0x73, 0x46, 0x72, 0x6f, 0x6d, 0x52, 0x61, 0x63, 0x6b, 0x12, 0x27, 0x0a,
+	0x0f, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f, 0x70, 0x72, 0x61, 0x67, 0x6d, 0x61, 0x73,
+	0x18, 0x15, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x50,
+	0x72, 0x61, 0x67, 0x6d, 0x61, 0x73, 0x22, 0xd5, 0x01, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x17, 0x0a, 0x13, 0x54, 0x49, 0x4c, 0x45, 0x5f, 0x50, 0x4c, 0x41, 0x43, 0x45, 0x4d, 0x45, 0x4e,
+	0x54, 0x5f, 0x4d, 0x4f, 0x56, 0x45, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x50, 0x48, 0x4f, 0x4e,
+	0x59, 0x5f, 0x54, 0x49, 0x4c, 0x45, 0x53, 0x5f, 0x52, 0x45, 0x54, 0x55, 0x52, 0x4e, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x41, 0x53, 0x53, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f,
+	0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47, 0x45, 0x5f, 0x42, 0x4f, 0x4e, 0x55, 0x53, 0x10,
+	0x03, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x58, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x10, 0x04, 0x12,
+	0x10, 0x0a, 0x0c, 0x45, 0x4e, 0x44, 0x5f, 0x52, 0x41, 0x43, 0x4b, 0x5f, 0x50, 0x54, 0x53, 0x10,
+	0x05, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x49, 0x4d, 0x45, 0x5f, 0x50, 0x45, 0x4e, 0x41, 0x4c, 0x54,
+	0x59, 0x10, 0x06, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4e, 0x44, 0x5f, 0x52, 0x41, 0x43, 0x4b, 0x5f,
+	0x50, 0x45, 0x4e, 0x41, 0x4c, 0x54, 0x59, 0x10, 0x07, 0x12, 0x24, 0x0a, 0x20, 0x55, 0x4e, 0x53,
+	0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x46, 0x55, 0x4c, 0x5f, 0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45,
+	0x4e, 0x47, 0x45, 0x5f, 0x54, 0x55, 0x52, 0x4e, 0x5f, 0x4c, 0x4f, 0x53, 0x53, 0x10, 0x08, 0x12,
+	0x0d, 0x0a, 0x09, 0x43, 0x48, 0x41, 0x4c, 0x4c, 0x45, 0x4e, 0x47, 0x45, 0x10, 0x09, 0x22, 0x29,
+	0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x0a, 0x48,
+	0x4f, 0x52, 0x49, 0x5a, 0x4f, 0x4e, 0x54, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x56,
+	0x45, 0x52, 0x54, 0x49, 0x43, 0x41, 0x4c, 0x10, 0x01, 0x22, 0x76, 0x0a, 0x0a, 0x50, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x69, 0x63, 0x6b, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x69, 0x63, 0x6b, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x6c, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x61, 0x6c, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74,
+	0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x22, 0xa1, 0x05, 0x0a, 0x0a, 0x42, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x37, 0x0a, 0x0c, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f,
+	0x2e, 0x47, 0x61, 0x6d, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x0b, 0x67, 0x61,
+	0x6d, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x49, 0x0a, 0x12, 0x65, 0x76, 0x61,
+	0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e,
+	0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x52, 0x11, 0x65, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x6f, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f,
+	0x2e, 0x42, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x42, 0x6f, 0x74, 0x43,
+	0x6f, 0x64, 0x65, 0x52, 0x07, 0x62, 0x6f, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x29, 0x0a, 0x10,
+	0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x52, 0x65,
+	0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x65,
+	0x72, 0x73, 0x6f, 0x6e, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x3a, 0x0a, 0x0d, 0x70, 0x68, 0x6f,
+	0x6e, 0x79, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x50, 0x68, 0x6f, 0x6e, 0x79,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0c, 0x70, 0x68, 0x6f, 0x6e, 0x79, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xcd, 0x02, 0x0a, 0x07, 0x42, 0x6f, 0x74, 0x43, 0x6f, 0x64,
+	0x65, 0x12, 0x0d, 0x0a, 0x09, 0x48, 0x41, 0x53, 0x54, 0x59, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x00,
+	0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x31, 0x5f, 0x43, 0x45, 0x4c, 0x5f, 0x42,
+	0x4f, 0x54, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x32, 0x5f, 0x43,
+	0x45, 0x4c, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x45, 0x56, 0x45,
+	0x4c, 0x33, 0x5f, 0x43, 0x45, 0x4c, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e,
+	0x4c, 0x45, 0x56, 0x45, 0x4c, 0x34, 0x5f, 0x43, 0x45, 0x4c, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x04,
+	0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x31, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41,
+	0x42, 0x49, 0x4c, 0x49, 0x53, 0x54, 0x49, 0x43, 0x10, 0x05, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45,
+	0x56, 0x45, 0x4c, 0x32, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x53, 0x54,
+	0x49, 0x43, 0x10, 0x06, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x33, 0x5f, 0x50,
+	0x52, 0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x53, 0x54, 0x49, 0x43, 0x10, 0x07, 0x12, 0x18,
+	0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x34, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42, 0x49,
+	0x4c, 0x49, 0x53, 0x54, 0x49, 0x43, 0x10, 0x08, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x45, 0x56, 0x45,
+	0x4c, 0x35, 0x5f, 0x50, 0x52, 0x4f, 0x42, 0x41, 0x42, 0x49, 0x4c, 0x49, 0x53, 0x54, 0x49, 0x43,
+	0x10, 0x09, 0x12, 0x10, 0x0a, 0x0c, 0x4e, 0x4f, 0x5f, 0x4c, 0x45, 0x41, 0x56, 0x45, 0x5f, 0x42,
+	0x4f, 0x54, 0x10, 0x0a, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x49, 0x4d, 0x4d, 0x49, 0x4e, 0x47, 0x5f,
+	0x42, 0x4f, 0x54, 0x10, 0x0b, 0x12, 0x1a, 0x0a, 0x16, 0x48, 0x41, 0x53, 0x54, 0x59, 0x5f, 0x50,
+	0x4c, 0x55, 0x53, 0x5f, 0x45, 0x4e, 0x44, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x42, 0x4f, 0x54, 0x10,
+	0x0c, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x49, 0x4d, 0x4d, 0x49, 0x4e, 0x47, 0x5f, 0x49, 0x4e, 0x46,
+	0x45, 0x52, 0x5f, 0x42, 0x4f, 0x54, 0x10, 0x0d, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e,
+	0x4f, 0x57, 0x4e, 0x10, 0x64, 0x22, 0xd2, 0x01, 0x0a, 0x0c, 0x50, 0x68, 0x6f, 0x6e, 0x79, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f,
+	0x77, 0x6f, 0x72, 0x64, 0x73, 0x5f, 0x6c, 0x65, 0x78, 0x69, 0x63, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x11, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x57, 0x6f, 0x72, 0x64, 0x73, 0x4c,
+	0x65, 0x78, 0x69, 0x63, 0x6f, 0x6e, 0x12, 0x35, 0x0a, 0x17, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x5f, 0x77, 0x6f, 0x72, 0x64, 0x5f, 0x70, 0x68, 0x6f, 0x6e, 0x79, 0x5f, 0x70, 0x72, 0x6f,
+	0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x14, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e,
+	0x57, 0x6f, 0x72, 0x64, 0x50, 0x68, 0x6f, 0x6e, 0x79, 0x50, 0x72, 0x6f, 0x62, 0x12, 0x23, 0x0a,
+	0x0d, 0x62, 0x6c, 0x75, 0x66, 0x66, 0x5f, 0x6c, 0x65, 0x78, 0x69, 0x63, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x62, 0x6c, 0x75, 0x66, 0x66, 0x4c, 0x65, 0x78, 0x69, 0x63,
+	0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x17, 0x6f, 0x70, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x5f, 0x63,
+	0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x62, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x15, 0x6f, 0x70, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x43, 0x68, 0x61,
+	0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x62, 0x22, 0x27, 0x0a, 0x11, 0x45, 0x76,
+	0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75,
+	0x73, 0x65, 0x72, 0x22, 0x44, 0x0a, 0x0a, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x36, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x53,
+	0x69, 0x6e, 0x67, 0x6c, 0x65, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x08, 0x70, 0x6c, 0x61, 0x79, 0x45, 0x76, 0x61, 0x6c, 0x22, 0xf2, 0x01, 0x0a, 0x10, 0x53, 0x69,
+	0x6e, 0x67, 0x6c, 0x65, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f,
+	0x0a, 0x0b, 0x65, 0x71, 0x75, 0x69, 0x74, 0x79, 0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0a, 0x65, 0x71, 0x75, 0x69, 0x74, 0x79, 0x4c, 0x6f, 0x73, 0x73, 0x12,
+	0x20, 0x0a, 0x0c, 0x77, 0x69, 0x6e, 0x5f, 0x70, 0x63, 0x74, 0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x77, 0x69, 0x6e, 0x50, 0x63, 0x74, 0x4c, 0x6f, 0x73,
+	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x69, 0x6e, 0x67,
+	0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x42,
+	0x69, 0x6e, 0x67, 0x6f, 0x12, 0x2c, 0x0a, 0x12, 0x70, 0x6f, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65,
+	0x5f, 0x73, 0x74, 0x61, 0x72, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x10, 0x70, 0x6f, 0x73, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x72, 0x50, 0x6c,
+	0x61, 0x79, 0x12, 0x28, 0x0a, 0x10, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x61,
+	0x72, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6d, 0x69,
+	0x73, 0x73, 0x65, 0x64, 0x53, 0x74, 0x61, 0x72, 0x50, 0x6c, 0x61, 0x79, 0x12, 0x20, 0x0a, 0x0c,
+	0x74, 0x6f, 0x70, 0x5f, 0x69, 0x73, 0x5f, 0x62, 0x69, 0x6e, 0x67, 0x6f, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0a, 0x74, 0x6f, 0x70, 0x49, 0x73, 0x42, 0x69, 0x6e, 0x67, 0x6f, 0x22, 0x9d,
+	0x01, 0x0a, 0x0b, 0x42, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28,
+	0x0a, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d,
+	0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x48, 0x00, 0x52, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x16, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x27, 0x0a, 0x04, 0x65, 0x76, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x04, 0x65, 0x76, 0x61, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65,
+	0x49, 0x64, 0x42, 0x0a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xc9,
+	0x01, 0x0a, 0x16, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65,
+	0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x75, 0x72, 0x6e, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x75, 0x72, 0x6e, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x47, 0x61,
+	0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12,
+	0x26, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x12, 0x2e,
+	0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x54, 0x61,
+	0x67, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x75, 0x63, 0x6b, 0x65,
+	0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x62,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x98, 0x01, 0x0a, 0x0c, 0x50,
+	0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x2e, 0x0a, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64,
+	0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x54, 0x61, 0x67, 0x52, 0x08, 0x69, 0x6e, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x08, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
 	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64,
-	0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x54, 0x61, 0x67, 0x52, 0x04, 0x74, 0x61, 0x67,
-	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65,
-	0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x49,
-	0x6e, 0x64, 0x65, 0x78, 0x22, 0x98, 0x01, 0x0a, 0x0c, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x42,
-	0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x73,
-	0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
-	0x2e, 0x0a, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a,
-	0x6c, 0x65, 0x54, 0x61, 0x67, 0x52, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x12,
-	0x2e, 0x0a, 0x08, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
-	0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a,
-	0x6c, 0x65, 0x54, 0x61, 0x67, 0x52, 0x08, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x22,
-	0x4a, 0x0a, 0x17, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x07, 0x62, 0x75,
-	0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x61,
-	0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x42, 0x75, 0x63, 0x6b,
-	0x65, 0x74, 0x52, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x2a, 0x43, 0x0a, 0x09, 0x50,
-	0x6c, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x4c, 0x41, 0x59,
-	0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x57, 0x41, 0x49, 0x54, 0x49, 0x4e, 0x47,
-	0x5f, 0x46, 0x4f, 0x52, 0x5f, 0x46, 0x49, 0x4e, 0x41, 0x4c, 0x5f, 0x50, 0x41, 0x53, 0x53, 0x10,
-	0x01, 0x12, 0x0d, 0x0a, 0x09, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x4f, 0x56, 0x45, 0x52, 0x10, 0x02,
-	0x2a, 0x5c, 0x0a, 0x0d, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x52, 0x75, 0x6c,
-	0x65, 0x12, 0x08, 0x0a, 0x04, 0x56, 0x4f, 0x49, 0x44, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53,
-	0x49, 0x4e, 0x47, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x4f, 0x55, 0x42, 0x4c,
-	0x45, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x46, 0x49, 0x56, 0x45, 0x5f, 0x50, 0x4f, 0x49, 0x4e,
-	0x54, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x54, 0x45, 0x4e, 0x5f, 0x50, 0x4f, 0x49, 0x4e, 0x54,
-	0x10, 0x04, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x52, 0x49, 0x50, 0x4c, 0x45, 0x10, 0x05, 0x2a, 0x89,
-	0x01, 0x0a, 0x09, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x54, 0x61, 0x67, 0x12, 0x0a, 0x0a, 0x06,
-	0x45, 0x51, 0x55, 0x49, 0x54, 0x59, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x49, 0x4e, 0x47,
-	0x4f, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x4e, 0x4c, 0x59, 0x5f, 0x42, 0x49, 0x4e, 0x47,
-	0x4f, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x4c, 0x41, 0x4e, 0x4b, 0x5f, 0x42, 0x49, 0x4e,
-	0x47, 0x4f, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f, 0x4e, 0x5f, 0x42, 0x49, 0x4e, 0x47,
-	0x4f, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x4f, 0x57, 0x45, 0x52, 0x5f, 0x54, 0x49, 0x4c,
-	0x45, 0x10, 0x05, 0x12, 0x17, 0x0a, 0x13, 0x42, 0x49, 0x4e, 0x47, 0x4f, 0x5f, 0x4e, 0x49, 0x4e,
-	0x45, 0x5f, 0x4f, 0x52, 0x5f, 0x41, 0x42, 0x4f, 0x56, 0x45, 0x10, 0x06, 0x12, 0x0c, 0x0a, 0x08,
-	0x43, 0x45, 0x4c, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10, 0x07, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x6f, 0x31,
-	0x34, 0x2f, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x61, 0x70,
-	0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x54, 0x61, 0x67, 0x52, 0x08, 0x65, 0x78, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x73, 0x22, 0x4a, 0x0a, 0x17, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x2f, 0x0a, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2e, 0x50, 0x75, 0x7a, 0x7a,
+	0x6c, 0x65, 0x42, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x07, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74,
+	0x73, 0x22, 0x86, 0x01, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x43, 0x61,
+	0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6c, 0x61, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6c, 0x61, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x71, 0x75, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x06, 0x65, 0x71, 0x75, 0x69, 0x74, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x77, 0x69, 0x6e,
+	0x5f, 0x70, 0x63, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x50,
+	0x63, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x6d, 0x6d, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x73, 0x69, 0x6d, 0x6d, 0x65, 0x64, 0x22, 0x82, 0x02, 0x0a, 0x0e, 0x54,
+	0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x17, 0x0a,
+	0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74, 0x75, 0x72, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x67,
+	0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x67, 0x70, 0x12, 0x17, 0x0a, 0x07,
+	0x6f, 0x6e, 0x5f, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6f,
+	0x6e, 0x54, 0x75, 0x72, 0x6e, 0x12, 0x3a, 0x0a, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61,
+	0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6d, 0x61, 0x63, 0x6f,
+	0x6e, 0x64, 0x6f, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x43, 0x61, 0x6e, 0x64,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x6f, 0x73, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x63, 0x68, 0x6f, 0x73, 0x65, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x69, 0x6e,
+	0x61, 0x6c, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0b, 0x66, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x21, 0x0a, 0x0c,
+	0x66, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0b, 0x66, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x70, 0x72, 0x65, 0x61, 0x64, 0x2a,
+	0x43, 0x0a, 0x09, 0x50, 0x6c, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07,
+	0x50, 0x4c, 0x41, 0x59, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x57, 0x41, 0x49,
+	0x54, 0x49, 0x4e, 0x47, 0x5f, 0x46, 0x4f, 0x52, 0x5f, 0x46, 0x49, 0x4e, 0x41, 0x4c, 0x5f, 0x50,
+	0x41, 0x53, 0x53, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x4f, 0x56,
+	0x45, 0x52, 0x10, 0x02, 0x2a, 0x5c, 0x0a, 0x0d, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67,
+	0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x56, 0x4f, 0x49, 0x44, 0x10, 0x00, 0x12,
+	0x0a, 0x0a, 0x06, 0x53, 0x49, 0x4e, 0x47, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44,
+	0x4f, 0x55, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x46, 0x49, 0x56, 0x45, 0x5f,
+	0x50, 0x4f, 0x49, 0x4e, 0x54, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x54, 0x45, 0x4e, 0x5f, 0x50,
+	0x4f, 0x49, 0x4e, 0x54, 0x10, 0x04, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x52, 0x49, 0x50, 0x4c, 0x45,
+	0x10, 0x05, 0x2a, 0x89, 0x01, 0x0a, 0x09, 0x50, 0x75, 0x7a, 0x7a, 0x6c, 0x65, 0x54, 0x61, 0x67,
+	0x12, 0x0a, 0x0a, 0x06, 0x45, 0x51, 0x55, 0x49, 0x54, 0x59, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05,
+	0x42, 0x49, 0x4e, 0x47, 0x4f, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4f, 0x4e, 0x4c, 0x59, 0x5f,
+	0x42, 0x49, 0x4e, 0x47, 0x4f, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x42, 0x4c, 0x41, 0x4e, 0x4b,
+	0x5f, 0x42, 0x49, 0x4e, 0x47, 0x4f, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f, 0x4e, 0x5f,
+	0x42, 0x49, 0x4e, 0x47, 0x4f, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x4f, 0x57, 0x45, 0x52,
+	0x5f, 0x54, 0x49, 0x4c, 0x45, 0x10, 0x05, 0x12, 0x17, 0x0a, 0x13, 0x42, 0x49, 0x4e, 0x47, 0x4f,
+	0x5f, 0x4e, 0x49, 0x4e, 0x45, 0x5f, 0x4f, 0x52, 0x5f, 0x41, 0x42, 0x4f, 0x56, 0x45, 0x10, 0x06,
+	0x12, 0x0c, 0x0a, 0x08, 0x43, 0x45, 0x4c, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10, 0x07, 0x42, 0x33,
+	0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x6f, 0x6d,
+	0x69, 0x6e, 0x6f, 0x31, 0x34, 0x2f, 0x6d, 0x61, 0x63, 0x6f, 0x6e, 0x64, 0x6f, 0x2f, 0x67, 0x65,
+	0x6e, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x61, 0x63, 0x6f,
+	0x6e, 0x64, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1679,7 +2080,7 @@ func file_api_proto_macondo_macondo_proto_rawDescGZIP() []byte {
 }
 
 var file_api_proto_macondo_macondo_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_api_proto_macondo_macondo_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_proto_macondo_macondo_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_api_proto_macondo_macondo_proto_goTypes = []interface{}{
 	(PlayState)(0),                  // 0: macondo.PlayState
 	(ChallengeRule)(0),              // 1: macondo.ChallengeRule
@@ -1691,13 +2092,16 @@ var file_api_proto_macondo_macondo_proto_goTypes = []interface{}{
 	(*GameEvent)(nil),               // 7: macondo.GameEvent
 	(*PlayerInfo)(nil),              // 8: macondo.PlayerInfo
 	(*BotRequest)(nil),              // 9: macondo.BotRequest
-	(*EvaluationRequest)(nil),       // 10: macondo.EvaluationRequest
-	(*Evaluation)(nil),              // 11: macondo.Evaluation
-	(*SingleEvaluation)(nil),        // 12: macondo.SingleEvaluation
-	(*BotResponse)(nil),             // 13: macondo.BotResponse
-	(*PuzzleCreationResponse)(nil),  // 14: macondo.PuzzleCreationResponse
-	(*PuzzleBucket)(nil),            // 15: macondo.PuzzleBucket
-	(*PuzzleGenerationRequest)(nil), // 16: macondo.PuzzleGenerationRequest
+	(*PhonyOptions)(nil),            // 10: macondo.PhonyOptions
+	(*EvaluationRequest)(nil),       // 11: macondo.EvaluationRequest
+	(*Evaluation)(nil),              // 12: macondo.Evaluation
+	(*SingleEvaluation)(nil),        // 13: macondo.SingleEvaluation
+	(*BotResponse)(nil),             // 14: macondo.BotResponse
+	(*PuzzleCreationResponse)(nil),  // 15: macondo.PuzzleCreationResponse
+	(*PuzzleBucket)(nil),            // 16: macondo.PuzzleBucket
+	(*PuzzleGenerationRequest)(nil), // 17: macondo.PuzzleGenerationRequest
+	(*TrainingCandidate)(nil),       // 18: macondo.TrainingCandidate
+	(*TrainingRecord)(nil),          // 19: macondo.TrainingRecord
 }
 var file_api_proto_macondo_macondo_proto_depIdxs = []int32{
 	7,  // 0: macondo.GameHistory.events:type_name -> macondo.GameEvent
@@ -1707,21 +2111,23 @@ var file_api_proto_macondo_macondo_proto_depIdxs = []int32{
 	3,  // 4: macondo.GameEvent.type:type_name -> macondo.GameEvent.Type
 	4,  // 5: macondo.GameEvent.direction:type_name -> macondo.GameEvent.Direction
 	6,  // 6: macondo.BotRequest.game_history:type_name -> macondo.GameHistory
-	10, // 7: macondo.BotRequest.evaluation_request:type_name -> macondo.EvaluationRequest
+	11, // 7: macondo.BotRequest.evaluation_request:type_name -> macondo.EvaluationRequest
 	5,  // 8: macondo.BotRequest.bot_type:type_name -> macondo.BotRequest.BotCode
-	12, // 9: macondo.Evaluation.play_eval:type_name -> macondo.SingleEvaluation
-	7,  // 10: macondo.BotResponse.move:type_name -> macondo.GameEvent
-	11, // 11: macondo.BotResponse.eval:type_name -> macondo.Evaluation
-	7,  // 12: macondo.PuzzleCreationResponse.answer:type_name -> macondo.GameEvent
-	2,  // 13: macondo.PuzzleCreationResponse.tags:type_name -> macondo.PuzzleTag
-	2,  // 14: macondo.PuzzleBucket.includes:type_name -> macondo.PuzzleTag
-	2,  // 15: macondo.PuzzleBucket.excludes:type_name -> macondo.PuzzleTag
-	15, // 16: macondo.PuzzleGenerationRequest.buckets:type_name -> macondo.PuzzleBucket
-	17, // [17:17] is the sub-list for method output_type
-	17, // [17:17] is the sub-list for method input_type
-	17, // [17:17] is the sub-list for extension type_name
-	17, // [17:17] is the sub-list for extension extendee
-	0,  // [0:17] is the sub-list for field type_name
+	10, // 9: macondo.BotRequest.phony_options:type_name -> macondo.PhonyOptions
+	13, // 10: macondo.Evaluation.play_eval:type_name -> macondo.SingleEvaluation
+	7,  // 11: macondo.BotResponse.move:type_name -> macondo.GameEvent
+	12, // 12: macondo.BotResponse.eval:type_name -> macondo.Evaluation
+	7,  // 13: macondo.PuzzleCreationResponse.answer:type_name -> macondo.GameEvent
+	2,  // 14: macondo.PuzzleCreationResponse.tags:type_name -> macondo.PuzzleTag
+	2,  // 15: macondo.PuzzleBucket.includes:type_name -> macondo.PuzzleTag
+	2,  // 16: macondo.PuzzleBucket.excludes:type_name -> macondo.PuzzleTag
+	16, // 17: macondo.PuzzleGenerationRequest.buckets:type_name -> macondo.PuzzleBucket
+	18, // 18: macondo.TrainingRecord.candidates:type_name -> macondo.TrainingCandidate
+	19, // [19:19] is the sub-list for method output_type
+	19, // [19:19] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_macondo_macondo_proto_init() }
@@ -1779,7 +2185,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EvaluationRequest); i {
+			switch v := v.(*PhonyOptions); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1791,7 +2197,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Evaluation); i {
+			switch v := v.(*EvaluationRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1803,7 +2209,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SingleEvaluation); i {
+			switch v := v.(*Evaluation); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1815,7 +2221,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BotResponse); i {
+			switch v := v.(*SingleEvaluation); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1827,7 +2233,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PuzzleCreationResponse); i {
+			switch v := v.(*BotResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1839,7 +2245,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PuzzleBucket); i {
+			switch v := v.(*PuzzleCreationResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1851,6 +2257,18 @@ func file_api_proto_macondo_macondo_proto_init() {
 			}
 		}
 		file_api_proto_macondo_macondo_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PuzzleBucket); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_macondo_macondo_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PuzzleGenerationRequest); i {
 			case 0:
 				return &v.state
@@ -1862,8 +2280,32 @@ func file_api_proto_macondo_macondo_proto_init() {
 				return nil
 			}
 		}
+		file_api_proto_macondo_macondo_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrainingCandidate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_macondo_macondo_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrainingRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_api_proto_macondo_macondo_proto_msgTypes[7].OneofWrappers = []interface{}{
+	file_api_proto_macondo_macondo_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*BotResponse_Move)(nil),
 		(*BotResponse_Error)(nil),
 	}
@@ -1873,7 +2315,7 @@ func file_api_proto_macondo_macondo_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_proto_macondo_macondo_proto_rawDesc,
 			NumEnums:      6,
-			NumMessages:   11,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
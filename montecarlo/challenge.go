@@ -0,0 +1,98 @@
+package montecarlo
+
+import (
+	"errors"
+
+	"lukechampine.com/frand"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/lexicon"
+	"github.com/domino14/macondo/move"
+)
+
+// ChallengeModel describes how the opponent challenges the plays being
+// simmed. Only plays with words that are not commonly known can get
+// challenged, and challenges follow the challenge rule of the game.
+type ChallengeModel struct {
+	// KnownWords is the lexicon of commonly known words.
+	KnownWords lexicon.Lexicon
+	// ChallengeProb is the chance that the opponent challenges a play that
+	// has a word that is not in KnownWords.
+	ChallengeProb float64
+}
+
+type challengeOutcome int
+
+const (
+	notChallenged challengeOutcome = iota
+	// The play was phony and comes off the board.
+	challengedOff
+	// The play was valid and stays on the board.
+	challengeFailed
+)
+
+// SetChallengeModel makes the sim model the opponent's challenges. It must
+// be called after PrepareSim. A nil model turns this off.
+func (s *Simmer) SetChallengeModel(cm *ChallengeModel) error {
+	s.challengeModel = cm
+	if cm == nil {
+		return nil
+	}
+	if s.challengeRule() == pb.ChallengeRule_VOID {
+		return errors.New("challenges are not valid in void")
+	}
+	g := s.origGame
+	for _, sp := range s.plays {
+		sp.challengeable, sp.phony = false, false
+		if sp.play.Action() != move.MoveTypePlay {
+			continue
+		}
+		words, err := g.Board().FormedWords(sp.play)
+		if err != nil {
+			return err
+		}
+		sp.challengeable = g.ValidateWords(cm.KnownWords, words) != nil
+		sp.phony = g.ValidateWords(g.Lexicon(), words) != nil
+	}
+	return nil
+}
+
+func (s *Simmer) challengeRule() pb.ChallengeRule {
+	if s.origGame.History() == nil {
+		return pb.ChallengeRule_VOID
+	}
+	return s.origGame.History().ChallengeRule
+}
+
+// challengeOutcome decides whether the opponent challenges the simmed play
+// in this iteration.
+func (s *Simmer) challengeOutcome(sp *SimmedPlay, thread int) challengeOutcome {
+	if s.challengeModel == nil || !sp.challengeable {
+		return notChallenged
+	}
+	var r float64
+	if s.rngs != nil {
+		r = s.rngs[thread].Float64()
+	} else {
+		r = frand.Float64()
+	}
+	if r >= s.challengeModel.ChallengeProb {
+		return notChallenged
+	}
+	if sp.phony {
+		return challengedOff
+	}
+	return challengeFailed
+}
+
+// challengeBonus returns the points that the simmed player gets for a
+// failed challenge.
+func challengeBonus(rule pb.ChallengeRule) int {
+	switch rule {
+	case pb.ChallengeRule_FIVE_POINT:
+		return 5
+	case pb.ChallengeRule_TEN_POINT:
+		return 10
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package montecarlo
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/lexicon"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/variant"
+)
+
+// knowsNothing is a lexicon without any words.
+type knowsNothing struct {
+	lexicon.AcceptAll
+}
+
+func (knowsNothing) HasWord(lexicon.Word) bool    { return false }
+func (knowsNothing) HasAnagram(lexicon.Word) bool { return false }
+
+// challengeSimmer returns a simmer for an opening position, where the
+// opponent challenges every play with the given probability. If
+// withPhony is true, the last two plays are 8D WAADER, a phony, and a pass.
+func challengeSimmer(t *testing.T, rule pb.ChallengeRule, challengeProb float64, withPhony bool) *Simmer {
+	is := is.New(t)
+	players := []*pb.PlayerInfo{
+		{Nickname: "JD", RealName: "Jesse"},
+		{Nickname: "cesar", RealName: "César"},
+	}
+	rules, err := game.NewBasicGameRules(&DefaultConfig, "NWL18", board.CrosswordGameLayout, "English", game.CrossScoreAndSet, variant.VarClassic)
+	is.NoErr(err)
+	g, err := game.NewGame(rules, players)
+	is.NoErr(err)
+	g.StartGame()
+	g.SetChallengeRule(rule)
+	g.SetPlayerOnTurn(0)
+	is.NoErr(g.SetRackFor(0, tilemapping.RackFromString("AAADERW", g.Alphabet())))
+
+	gd, err := kwg.Get(g.Config().AllSettings(), g.LexiconName())
+	is.NoErr(err)
+	generator := movegen.NewGordonGenerator(gd, g.Board(), rules.LetterDistribution())
+	generator.GenAll(g.RackFor(0), false)
+	plays := generator.Plays()[:5]
+	if withPhony {
+		phony, err := g.CreateAndScorePlacementMove("8D", "WAADER", "AAADERW")
+		is.NoErr(err)
+		plays = append(plays, phony, move.NewPassMove(g.RackFor(0).TilesOn(), g.Alphabet()))
+	}
+
+	simmer := &Simmer{}
+	calcs, leaves := defaultSimCalculators("NWL18")
+	simmer.Init(g, calcs, leaves.(*equity.CombinedStaticCalculator), &DefaultConfig)
+	simmer.SetThreads(1)
+	simmer.SetSeed(42)
+	is.NoErr(simmer.PrepareSim(2, plays))
+	if challengeProb > 0 {
+		is.NoErr(simmer.SetChallengeModel(&ChallengeModel{
+			KnownWords:    knowsNothing{lexicon.AcceptAll{Alph: g.Alphabet()}},
+			ChallengeProb: challengeProb,
+		}))
+	}
+	return simmer
+}
+
+func meanEquities(s *Simmer) map[string]float64 {
+	eqs := map[string]float64{}
+	for _, p := range s.plays {
+		eqs[p.play.ShortDescription()] = p.equityStats.Mean()
+	}
+	return eqs
+}
+
+func TestChallengeBonusInSim(t *testing.T) {
+	is := is.New(t)
+	plain := challengeSimmer(t, pb.ChallengeRule_TEN_POINT, 0, false)
+	plain.SimSingleThread(20)
+	challenged := challengeSimmer(t, pb.ChallengeRule_TEN_POINT, 1, false)
+	challenged.SimSingleThread(20)
+
+	// Every play is valid, so every challenge gets us 10 points.
+	plainEqs := meanEquities(plain)
+	for play, eq := range meanEquities(challenged) {
+		is.True(eq-plainEqs[play] > 9.999 && eq-plainEqs[play] < 10.001)
+	}
+}
+
+func TestPhonyChallengedOffInSim(t *testing.T) {
+	is := is.New(t)
+	s := challengeSimmer(t, pb.ChallengeRule_SINGLE, 1, true)
+	s.SimSingleThread(20)
+	n := len(s.plays)
+	is.True(s.plays[n-2].phony)
+	// The phony comes off the board every time, which is a pass.
+	is.Equal(s.plays[n-2].equityStats.Mean(), s.plays[n-1].equityStats.Mean())
+	is.Equal(s.plays[n-2].winPctStats.Mean(), s.plays[n-1].winPctStats.Mean())
+}
+
+func TestChallengeTurnLossInSim(t *testing.T) {
+	is := is.New(t)
+	s := challengeSimmer(t, pb.ChallengeRule_DOUBLE, 1, false)
+	s.SimSingleThread(10)
+	for _, p := range s.plays {
+		// The opponent loses their turn every time.
+		is.Equal(p.scoreStats[0].Mean(), 0.0)
+		is.Equal(p.scoreStats[0].Iterations(), 10)
+	}
+}
+
+func TestTripleChallengeInSim(t *testing.T) {
+	is := is.New(t)
+	s := challengeSimmer(t, pb.ChallengeRule_TRIPLE, 1, false)
+	s.SimSingleThread(10)
+	for _, p := range s.plays {
+		is.Equal(p.WinProb(), 1.0)
+	}
+	// Board should be reset back to empty after the simulation.
+	is.True(s.gameCopies[0].Board().IsEmpty())
+}
+
+func TestChallengeModelNeedsChallenges(t *testing.T) {
+	is := is.New(t)
+	s := challengeSimmer(t, pb.ChallengeRule_VOID, 0, false)
+	is.True(s.SetChallengeModel(&ChallengeModel{ChallengeProb: 1}) != nil)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand/v2"
 	"runtime"
 	"sort"
 	"strings"
@@ -29,6 +30,7 @@ import (
 	"github.com/domino14/macondo/game"
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/progress"
 	"github.com/domino14/macondo/stats"
 )
 
@@ -101,6 +103,10 @@ type SimmedPlay struct {
 	// Actually this is win probability (0 to 1), not percent:
 	winPctStats stats.Statistic
 	ignore      bool
+	// challengeable is true if the opponent might challenge this play
+	// (see ChallengeModel), and phony is true if the play is phony.
+	challengeable bool
+	phony         bool
 }
 
 func (sp *SimmedPlay) String() string {
@@ -186,10 +192,61 @@ func (sp *SimmedPlay) addWinPctStat(spread int, leftover float64, gameover bool,
 	sp.winPctStats.Push(float64(winPct))
 }
 
+// addDecidedGameStats adds the stats for an iteration where the game was
+// decided right away, as with a challenge under the TRIPLE rule.
+func (sp *SimmedPlay) addDecidedGameStats(initialSpread, spread int, won bool) {
+	sp.Lock()
+	defer sp.Unlock()
+	sp.equityStats.Push(float64(spread - initialSpread))
+	sp.leftoverStats.Push(0)
+	if won {
+		sp.winPctStats.Push(1)
+	} else {
+		sp.winPctStats.Push(0)
+	}
+}
+
 func (s *SimmedPlay) Move() *move.Move {
 	return s.play
 }
 
+// WinProb returns the mean simmed win probability (0 to 1) for this play.
+func (s *SimmedPlay) WinProb() float64 {
+	s.RLock()
+	defer s.RUnlock()
+	return s.winPctStats.Mean()
+}
+
+// EquityMean returns the mean simmed equity for this play.
+func (s *SimmedPlay) EquityMean() float64 {
+	s.RLock()
+	defer s.RUnlock()
+	return s.equityStats.Mean()
+}
+
+// WinProbStdErr returns the 99% confidence interval half-width of the
+// simmed win probability for this play.
+func (s *SimmedPlay) WinProbStdErr() float64 {
+	s.RLock()
+	defer s.RUnlock()
+	return s.winPctStats.StandardError(stats.Z99)
+}
+
+// EquityStdErr returns the 99% confidence interval half-width of the
+// simmed equity for this play.
+func (s *SimmedPlay) EquityStdErr() float64 {
+	s.RLock()
+	defer s.RUnlock()
+	return s.equityStats.StandardError(stats.Z99)
+}
+
+// Ignored is true if the play was cut off early by the stopping condition.
+func (s *SimmedPlay) Ignored() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.ignore
+}
+
 // Simmer implements the actual look-ahead search
 type Simmer struct {
 	origGame *game.Game
@@ -216,11 +273,20 @@ type Simmer struct {
 	knownOppRack []tilemapping.MachineLetter
 
 	logStream         io.Writer
+	progress          *progress.Reporter
 	stoppingCondition StoppingCondition
 
 	// See rangefinder.
 	inferences    [][]tilemapping.MachineLetter
 	inferenceMode InferenceMode
+
+	// seed is used for the game copies if it is non-zero. Otherwise, if the
+	// original game is seeded, a seed is derived from it.
+	seed uint64
+	// rngs has one seeded generator per thread, if the sim is seeded.
+	rngs []*rand.Rand
+
+	challengeModel *ChallengeModel
 }
 
 func (s *Simmer) Init(game *game.Game, eqCalcs []equity.EquityCalculator,
@@ -261,10 +327,23 @@ func (s *Simmer) Threads() int {
 	return s.threads
 }
 
+// SetSeed makes the sim repeatable: with the same seed, thread count and
+// number of iterations per thread, a sim will produce the same results.
+// A seed of 0 turns this off.
+func (s *Simmer) SetSeed(seed uint64) {
+	s.seed = seed
+}
+
 func (s *Simmer) SetLogStream(l io.Writer) {
 	s.logStream = l
 }
 
+// SetProgressListener sends the progress of sims to l, at most once per
+// interval, and once more when a sim ends.
+func (s *Simmer) SetProgressListener(l progress.Listener, interval time.Duration) {
+	s.progress = progress.NewReporter(l, interval)
+}
+
 func (s *Simmer) SetKnownOppRack(r []tilemapping.MachineLetter) {
 	s.knownOppRack = r
 }
@@ -278,12 +357,27 @@ func (s *Simmer) makeGameCopies() error {
 	log.Debug().Int("threads", s.threads).Msg("makeGameCopies")
 	s.gameCopies = []*game.Game{}
 	s.aiplayers = []aiturnplayer.AITurnPlayer{}
-	// Pre-shuffle bag so we can make identical copies of it with fixedOrder
-	s.origGame.Bag().Shuffle()
+	s.rngs = nil
+	seed := s.seed
+	if seed == 0 {
+		seed, _ = s.origGame.DeriveSeed()
+	}
+	// Pre-shuffle bag so we can make identical copies of it with fixedOrder.
+	// Shuffle a copy, so that the tile order of the original game (which
+	// might be fixed, for paired games) stays intact.
+	bag := s.origGame.Bag().Copy()
+	bag.Shuffle()
 
 	for i := 0; i < s.threads; i++ {
 		s.gameCopies = append(s.gameCopies, s.origGame.Copy())
+		s.gameCopies[i].Bag().CopyFrom(bag)
 		s.gameCopies[i].Bag().SetFixedOrder(true)
+		if seed != 0 {
+			// Every thread gets its own seed; the bag order then only
+			// depends on this seed.
+			s.gameCopies[i].SetSeed(seed + uint64(i))
+			s.rngs = append(s.rngs, rand.New(rand.NewPCG(seed+uint64(i), 0)))
+		}
 
 		player, err := aiturnplayer.NewAIStaticTurnPlayerFromGame(s.gameCopies[i], s.origGame.Config(), s.equityCalculators)
 		if err != nil {
@@ -334,6 +428,7 @@ func (s *Simmer) PrepareSim(plies int, plays []*move.Move) error {
 	s.readyToSim = true
 	s.knownOppRack = nil
 	s.inferenceMode = InferenceOff
+	s.challengeModel = nil
 	return nil
 }
 
@@ -412,6 +507,7 @@ func (s *Simmer) Simulate(ctx context.Context) error {
 			}
 		})
 	}
+	s.progress.Start(ctx, progress.SourceSim)
 	tstart := time.Now()
 	g := errgroup.Group{}
 	playSimilarityCache := map[string]bool{}
@@ -441,6 +537,9 @@ func (s *Simmer) Simulate(ctx context.Context) error {
 						}
 					}
 				}
+				if s.progress.Due() {
+					s.progress.Update(false, s.fillProgress)
+				}
 
 				select {
 				case v := <-syncExitChan:
@@ -470,6 +569,7 @@ func (s *Simmer) Simulate(ctx context.Context) error {
 	logger.Debug().Msgf("ctrl errgroup returned err %v", ctrlErr)
 	// sort plays at the end anyway.
 	s.sortPlaysByWinRate(false)
+	s.progress.Finish(s.fillProgress)
 	if ctrlErr == context.Canceled || ctrlErr == context.DeadlineExceeded {
 		// Not actually an error
 		logger.Debug().AnErr("ctrlErr", ctrlErr).Msg("montecarlo-it's ok, not an error")
@@ -505,7 +605,11 @@ func (s *Simmer) simSingleIteration(ctx context.Context, plies, thread int, iter
 	if s.inferenceMode == InferenceCycle {
 		rackToSet = s.inferences[int(iterationCount)%len(s.inferences)]
 	} else if s.inferenceMode == InferenceRandom {
-		rackToSet = s.inferences[frand.Intn(len(s.inferences))]
+		if s.rngs != nil {
+			rackToSet = s.inferences[s.rngs[thread].IntN(len(s.inferences))]
+		} else {
+			rackToSet = s.inferences[frand.Intn(len(s.inferences))]
+		}
 	}
 	_, err := g.SetRandomRack(opp, rackToSet)
 	if err != nil {
@@ -531,9 +635,33 @@ func (s *Simmer) simSingleIteration(ctx context.Context, plies, thread int, iter
 		// log.Debug().Msgf("Playing move %v", play)'
 		// Set the backup mode to simulation mode only to back up the first move:
 		g.SetBackupMode(game.SimulationMode)
-		g.PlayMove(simmedPlay.play, false, 0)
+		outcome := s.challengeOutcome(simmedPlay, thread)
+		if outcome == challengedOff {
+			// The phony tiles are returned; this is the same as a pass.
+			g.PlayMove(move.NewPassMove(g.RackFor(s.initialPlayer).TilesOn(), g.Alphabet()), false, 0)
+		} else {
+			g.PlayMove(simmedPlay.play, false, 0)
+		}
 		s.nodeCount.Add(1)
 		g.SetBackupMode(game.NoBackup)
+		oppLosesTurn := false
+		if outcome != notChallenged {
+			rule := s.challengeRule()
+			if rule == pb.ChallengeRule_TRIPLE {
+				// Whoever was wrong loses the game right away.
+				simmedPlay.addDecidedGameStats(s.initialSpread, g.SpreadFor(s.initialPlayer),
+					outcome == challengeFailed)
+				g.ResetToFirstState()
+				continue
+			}
+			if outcome == challengeFailed {
+				if rule == pb.ChallengeRule_DOUBLE {
+					oppLosesTurn = true
+				} else {
+					g.SetPointsFor(s.initialPlayer, g.PointsFor(s.initialPlayer)+challengeBonus(rule))
+				}
+			}
+		}
 		// Further plies will NOT be backed up.
 		for ply := 0; ply < plies; ply++ {
 			// Each ply is a player taking a turn
@@ -543,7 +671,12 @@ func (s *Simmer) simSingleIteration(ctx context.Context, plies, thread int, iter
 			}
 			// Assume there are exactly two players.
 
-			bestPlay := s.bestStaticTurn(onTurn, thread)
+			var bestPlay *move.Move
+			if ply == 0 && oppLosesTurn {
+				bestPlay = move.NewUnsuccessfulChallengePassMove(g.RackFor(onTurn).TilesOn(), g.Alphabet())
+			} else {
+				bestPlay = s.bestStaticTurn(onTurn, thread)
+			}
 			// log.Debug().Msgf("Ply %v, Best play: %v", ply+1, bestPlay)
 			g.PlayMove(bestPlay, false, 0)
 			s.nodeCount.Add(1)
@@ -690,9 +823,45 @@ func (s *Simmer) SimSingleThread(iters int) {
 		iters := s.iterationCount.Add(1)
 
 		s.simSingleIteration(ctx, s.maxPlies, 0, iters-1, nil)
+		if s.progress.Due() {
+			s.progress.Update(false, s.fillProgress)
+		}
+	}
+}
+
+// fillProgress sets the iterations and the leader of a progress event. It
+// can run while the sim does, so it does not sort the plays.
+func (s *Simmer) fillProgress(e *progress.Event) {
+	iters := s.iterationCount.Load()
+	e.Iterations = iters
+	if s.stoppingCondition != StopNone {
+		// The sim may stop sooner than this, if a play is clearly best.
+		e.Remaining = progress.EstimateRemaining(e.Elapsed, iters,
+			uint64(IterationsCutoff+s.maxPlies*PerPlyStopScaling))
+	}
+	var leader *SimmedPlay
+	best := -1.0
+	for _, p := range s.plays {
+		p.RLock()
+		wp := p.winPctStats.Mean()
+		ignore := p.ignore
+		p.RUnlock()
+		if !ignore && wp > best {
+			leader, best = p, wp
+		}
+	}
+	if leader != nil {
+		e.Leader = leader.play.ShortDescription()
+		e.Value = best
 	}
 }
 
+// PlaysByWinProb returns the simmed plays, sorted by win probability.
+func (s *Simmer) PlaysByWinProb() []*SimmedPlay {
+	s.sortPlaysByWinRate(false)
+	return s.plays
+}
+
 func (s *Simmer) WinningPlay() *SimmedPlay {
 	s.sortPlaysByWinRate(true)
 	return s.plays[0]
@@ -0,0 +1,520 @@
+package movegen
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/samber/lo"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/lexicon"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/tinymove"
+	"github.com/domino14/macondo/tinymove/conversions"
+	"github.com/domino14/macondo/variant"
+)
+
+// BruteForceGenerator is a slow but simple move generator, meant to check
+// the GordonGenerator. It tries every arrangement of rack tiles on every
+// stretch of every line, and checks every word it makes with the lexicon.
+// It does not use the GADDAG, anchors, cross-sets or cross-scores; it
+// scores plays by adding up the tiles itself.
+//
+// It is far too slow to play with, especially with blanks on the rack.
+type BruteForceGenerator struct {
+	lex      lexicon.Lexicon
+	board    *board.GameBoard
+	boardDim int
+	ld       *tilemapping.LetterDistribution
+	ruleSet  variant.RuleSet
+	variant  variant.Variant
+
+	sortingParameter  SortBy
+	playRecorder      PlayRecorderFunc
+	equityCalculators []equity.EquityCalculator
+	game              *game.Game
+	genPass           bool
+	maxTileUsage      int
+	constraints       *Constraints
+
+	plays      []*move.Move
+	smallPlays []tinymove.SmallMove
+
+	// State of the line being filled in.
+	vertical  bool
+	line      int
+	start     int
+	word      tilemapping.MachineWord
+	tiles     tilemapping.MachineWord
+	empties   []int
+	found     []*move.Move
+	quitEarly bool
+	stopEarly bool
+}
+
+// NewBruteForceGenerator returns a brute-force move generator. Words are
+// looked up in lex, which must use the alphabet of ld.
+func NewBruteForceGenerator(lex lexicon.Lexicon, board *board.GameBoard,
+	ld *tilemapping.LetterDistribution) *BruteForceGenerator {
+
+	return &BruteForceGenerator{
+		lex:              lex,
+		board:            board,
+		boardDim:         board.Dim(),
+		ld:               ld,
+		ruleSet:          variant.ClassicRuleSet,
+		sortingParameter: SortByScore,
+		playRecorder:     AllPlaysRecorder,
+		maxTileUsage:     100, // basically unlimited
+	}
+}
+
+func (gen *BruteForceGenerator) SetSortingParameter(s SortBy) {
+	gen.sortingParameter = s
+}
+
+// SetPlayRecorder picks what GenAll keeps, as for the GordonGenerator. Only
+// the recorders in this package are understood: AllPlaysRecorder,
+// AllPlaysSmallRecorder, TopPlayOnlyRecorder and NullPlayRecorder. Any
+// other recorder is treated as AllPlaysRecorder.
+func (gen *BruteForceGenerator) SetPlayRecorder(pr PlayRecorderFunc) {
+	gen.playRecorder = pr
+}
+
+func (gen *BruteForceGenerator) SetEquityCalculators(calcs []equity.EquityCalculator) {
+	gen.equityCalculators = calcs
+}
+
+func (gen *BruteForceGenerator) SetRuleSet(rs *variant.RuleSet) {
+	gen.ruleSet = *rs
+}
+
+func (gen *BruteForceGenerator) SetVariant(va variant.Variant) {
+	gen.variant = va
+}
+
+// SetGame sets the game used by the equity calculators of
+// TopPlayOnlyRecorder.
+func (gen *BruteForceGenerator) SetGame(g *game.Game) {
+	gen.game = g
+}
+
+func (gen *BruteForceGenerator) SetGenPass(p bool) {
+	gen.genPass = p
+}
+
+func (gen *BruteForceGenerator) SetMaxTileUsage(t int) {
+	gen.maxTileUsage = t
+}
+
+func (gen *BruteForceGenerator) SetConstraints(c *Constraints) {
+	gen.constraints = c
+}
+
+func (gen *BruteForceGenerator) Plays() []*move.Move {
+	return gen.plays
+}
+
+func (gen *BruteForceGenerator) SmallPlays() []tinymove.SmallMove {
+	return gen.smallPlays
+}
+
+func sameRecorder(a, b PlayRecorderFunc) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// GenAll generates all moves on the board.
+func (gen *BruteForceGenerator) GenAll(rack *tilemapping.Rack, addExchange bool) []*move.Move {
+	gen.plays = nil
+	gen.smallPlays = nil
+	gen.found = nil
+	gen.stopEarly = false
+	gen.genTilePlays(rack)
+
+	small := sameRecorder(gen.playRecorder, AllPlaysSmallRecorder)
+	if len(gen.found) == 0 || gen.genPass {
+		gen.found = append(gen.found, move.NewPassMove(rack.TilesOn(), gen.ld.TileMapping()))
+	}
+	if gen.sortingParameter == SortByScore {
+		sort.SliceStable(gen.found, func(i, j int) bool {
+			return gen.found[i].Score() > gen.found[j].Score()
+		})
+	}
+	// Like the GordonGenerator, the small recorder cannot hold exchanges.
+	if addExchange && !small {
+		gen.genExchanges(rack)
+	}
+
+	switch {
+	case small:
+		for _, m := range gen.found {
+			if m.Action() == move.MoveTypePass {
+				gen.smallPlays = append(gen.smallPlays, tinymove.PassMove())
+				continue
+			}
+			gen.smallPlays = append(gen.smallPlays, tinymove.TilePlayMove(
+				conversions.MoveToTinyMove(m), int16(m.Score()),
+				uint8(m.TilesPlayed()), uint8(len(m.Tiles()))))
+		}
+	case sameRecorder(gen.playRecorder, TopPlayOnlyRecorder):
+		gen.plays = gen.topPlay()
+	case sameRecorder(gen.playRecorder, NullPlayRecorder):
+	default:
+		gen.plays = gen.found
+	}
+	gen.found = nil
+	return gen.plays
+}
+
+// AtLeastOneTileMove returns whether any move plays tiles.
+func (gen *BruteForceGenerator) AtLeastOneTileMove(rack *tilemapping.Rack) bool {
+	gen.found = nil
+	gen.stopEarly = true
+	gen.genTilePlays(rack)
+	gen.stopEarly = false
+	found := len(gen.found) > 0
+	gen.found = nil
+	return found
+}
+
+// topPlay returns the move with the most equity, or the highest score if
+// there are no equity calculators.
+func (gen *BruteForceGenerator) topPlay() []*move.Move {
+	var best *move.Move
+	for _, m := range gen.found {
+		eq := float64(m.Score())
+		if len(gen.equityCalculators) > 0 {
+			eq = lo.SumBy(gen.equityCalculators, func(c equity.EquityCalculator) float64 {
+				return c.Equity(m, gen.board, gen.game.Bag(), gen.game.RackFor(gen.game.NextPlayer()))
+			})
+		}
+		if best == nil || eq > best.Equity() {
+			best = m
+			best.SetEquity(eq)
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return []*move.Move{best}
+}
+
+func (gen *BruteForceGenerator) genTilePlays(rack *tilemapping.Rack) {
+	maxTiles := min(gen.maxTileUsage, int(rack.NumTiles()))
+	gen.quitEarly = false
+	if gen.constraints != nil && !gen.constraints.possible(rack, gen.ruleSet.RackSize) {
+		return
+	}
+	for _, vertical := range []bool{false, true} {
+		if vertical && gen.board.IsEmpty() {
+			// As with the GordonGenerator, opening plays are only
+			// generated horizontally.
+			break
+		}
+		gen.vertical = vertical
+		for line := 0; line < gen.boardDim; line++ {
+			if gen.constraints != nil && gen.constraints.skipLine(line, vertical) {
+				continue
+			}
+			gen.line = line
+			gen.genLine(rack, maxTiles)
+		}
+	}
+}
+
+// at returns the row and column of the idx-th square of the current line.
+func (gen *BruteForceGenerator) at(idx int) (int, int) {
+	if gen.vertical {
+		return idx, gen.line
+	}
+	return gen.line, idx
+}
+
+func (gen *BruteForceGenerator) letterAt(idx int) tilemapping.MachineLetter {
+	row, col := gen.at(idx)
+	return gen.board.GetLetter(row, col)
+}
+
+// genLine tries every stretch of the current line that is not next to a
+// tile at either end.
+func (gen *BruteForceGenerator) genLine(rack *tilemapping.Rack, maxTiles int) {
+	for start := 0; start < gen.boardDim; start++ {
+		if start > 0 && gen.letterAt(start-1) != 0 {
+			continue
+		}
+		gen.empties = gen.empties[:0]
+		for end := start; end < gen.boardDim; end++ {
+			if gen.letterAt(end) == 0 {
+				gen.empties = append(gen.empties, end)
+			}
+			if len(gen.empties) > maxTiles {
+				break
+			}
+			if end == start || len(gen.empties) == 0 {
+				continue
+			}
+			if end < gen.boardDim-1 && gen.letterAt(end+1) != 0 {
+				continue
+			}
+			if !gen.connected(start, end) {
+				continue
+			}
+			gen.start = start
+			gen.word = make(tilemapping.MachineWord, end-start+1)
+			gen.tiles = make(tilemapping.MachineWord, end-start+1)
+			for i := range gen.word {
+				gen.word[i] = gen.letterAt(start + i)
+			}
+			gen.fill(rack, 0)
+			if gen.quitEarly {
+				return
+			}
+		}
+	}
+}
+
+// connected returns whether a play over start..end touches the tiles on the
+// board, or covers the center square of an empty board.
+func (gen *BruteForceGenerator) connected(start, end int) bool {
+	if gen.board.IsEmpty() {
+		c := gen.boardDim / 2
+		return gen.line == c && start <= c && c <= end
+	}
+	for i := start; i <= end; i++ {
+		if gen.letterAt(i) != 0 {
+			return true
+		}
+		if len(gen.crossWord(i, 0)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// crossWord returns the word made across the current line at idx, with ml
+// placed on idx. It has only ml in it if there are no tiles on either side.
+func (gen *BruteForceGenerator) crossWord(idx int, ml tilemapping.MachineLetter) tilemapping.MachineWord {
+	row, col := gen.at(idx)
+	dr, dc := 1, 0
+	if gen.vertical {
+		dr, dc = 0, 1
+	}
+	r, c := row, col
+	for r-dr >= 0 && c-dc >= 0 && gen.board.HasLetter(r-dr, c-dc) {
+		r, c = r-dr, c-dc
+	}
+	var word tilemapping.MachineWord
+	for ; r < gen.boardDim && c < gen.boardDim; r, c = r+dr, c+dc {
+		if r == row && c == col {
+			word = append(word, ml)
+			continue
+		}
+		if !gen.board.HasLetter(r, c) {
+			break
+		}
+		word = append(word, gen.board.GetLetter(r, c))
+	}
+	return word
+}
+
+// hasAcrossNeighbor returns whether a square of the current, vertical line
+// has a tile to its left or right.
+func (gen *BruteForceGenerator) hasAcrossNeighbor(idx int) bool {
+	row, col := gen.at(idx)
+	return (col > 0 && gen.board.HasLetter(row, col-1)) ||
+		(col < gen.boardDim-1 && gen.board.HasLetter(row, col+1))
+}
+
+// valid looks up a word the way the game checks played words.
+func (gen *BruteForceGenerator) valid(word tilemapping.MachineWord) bool {
+	w := unblank(word)
+	if gen.variant.IsWordSmog() {
+		return gen.lex.HasAnagram(w)
+	}
+	return gen.multiplicity(w) > 0
+}
+
+func unblank(word tilemapping.MachineWord) tilemapping.MachineWord {
+	w := make(tilemapping.MachineWord, len(word))
+	for i, ml := range word {
+		w[i] = ml.Unblank()
+	}
+	return w
+}
+
+// multiplicity is how many times a word scores. In Gmo, a word scores once
+// for each way, forwards or backwards, that it is a word.
+func (gen *BruteForceGenerator) multiplicity(word tilemapping.MachineWord) int {
+	n := 0
+	if gen.lex.HasWord(word) {
+		n++
+	}
+	if gen.variant != variant.VarGmo {
+		return n
+	}
+	reverse := slices.Clone(word)
+	slices.Reverse(reverse)
+	if gen.lex.HasWord(reverse) {
+		n++
+	}
+	return n
+}
+
+// wordMultiplier combines the word bonuses under newly placed tiles. They
+// multiply, except in Gmo, where they add up.
+func (gen *BruteForceGenerator) wordMultiplier(wms []int) int {
+	wm := 1
+	if gen.variant == variant.VarGmo {
+		wm = 0
+		for _, m := range wms {
+			if m > 1 {
+				wm += m
+			}
+		}
+		return max(wm, 1)
+	}
+	for _, m := range wms {
+		wm *= m
+	}
+	return wm
+}
+
+// fill puts every rack tile that can go there on the idx-th empty square
+// of the stretch, and goes on to the next one.
+func (gen *BruteForceGenerator) fill(rack *tilemapping.Rack, idx int) {
+	if gen.quitEarly {
+		return
+	}
+	if idx == len(gen.empties) {
+		gen.record(rack)
+		return
+	}
+	sq := gen.empties[idx]
+	try := func(ml tilemapping.MachineLetter) {
+		cw := gen.crossWord(sq, ml)
+		if len(cw) > 1 && !gen.valid(cw) {
+			return
+		}
+		gen.word[sq-gen.start] = ml
+		gen.tiles[sq-gen.start] = ml
+		gen.fill(rack, idx+1)
+		gen.word[sq-gen.start] = 0
+		gen.tiles[sq-gen.start] = 0
+	}
+	for i := range rack.LetArr {
+		if rack.LetArr[i] == 0 {
+			continue
+		}
+		ml := tilemapping.MachineLetter(i)
+		rack.Take(ml)
+		if ml == 0 {
+			for l := tilemapping.MachineLetter(1); l < tilemapping.MachineLetter(len(rack.LetArr)); l++ {
+				try(l.Blank())
+			}
+		} else {
+			try(ml)
+		}
+		rack.Add(ml)
+	}
+}
+
+func (gen *BruteForceGenerator) record(rack *tilemapping.Rack) {
+	if gen.vertical && len(gen.empties) == 1 && gen.hasAcrossNeighbor(gen.empties[0]) {
+		// A one-tile play that makes a word across is made across, as
+		// with the GordonGenerator.
+		return
+	}
+	row, col := gen.at(gen.start)
+	if gen.constraints != nil && !gen.constraints.allows(gen.board, gen.tiles,
+		row, col, gen.vertical, false, gen.ruleSet.RackSize) {
+		return
+	}
+	if !gen.valid(gen.word) {
+		return
+	}
+	if gen.stopEarly {
+		gen.quitEarly = true
+	}
+	tiles := make(tilemapping.MachineWord, len(gen.tiles))
+	copy(tiles, gen.tiles)
+	gen.found = append(gen.found, move.NewScoringMove(gen.score(), tiles, rack.TilesOn(),
+		gen.vertical, len(gen.empties), gen.ld.TileMapping(), row, col))
+}
+
+// score adds up the main word and every cross word of the stretch being
+// filled in. Letter and word bonuses only count under newly placed tiles.
+func (gen *BruteForceGenerator) score() int {
+	value := func(ml tilemapping.MachineLetter) int {
+		if ml.IsBlanked() {
+			return 0
+		}
+		return gen.ld.Score(ml)
+	}
+	times := func(w tilemapping.MachineWord) int {
+		if gen.variant != variant.VarGmo {
+			return 1
+		}
+		return gen.multiplicity(unblank(w))
+	}
+	mainScore, crossScores := 0, 0
+	var wms []int
+	for i, ml := range gen.word {
+		if gen.tiles[i] == 0 {
+			mainScore += value(ml)
+			continue
+		}
+		row, col := gen.at(gen.start + i)
+		sqIdx := gen.board.GetSqIdx(row, col)
+		lm := gen.board.GetLetterMultiplier(sqIdx)
+		wm := gen.board.GetWordMultiplier(sqIdx)
+		mainScore += value(ml) * lm
+		wms = append(wms, wm)
+		cw := gen.crossWord(gen.start+i, ml)
+		if len(cw) > 1 {
+			cs := value(ml) * (lm - 1)
+			for _, l := range cw {
+				cs += value(l)
+			}
+			crossScores += cs * gen.wordMultiplier([]int{wm}) * times(cw)
+		}
+	}
+	score := mainScore*gen.wordMultiplier(wms)*times(gen.word) + crossScores
+	if gen.ruleSet.IsBingo(len(gen.empties)) {
+		score += gen.ruleSet.BingoBonus
+	}
+	return score
+}
+
+// genExchanges adds every exchange of one or more tiles.
+func (gen *BruteForceGenerator) genExchanges(rack *tilemapping.Rack) {
+	var exch tilemapping.MachineWord
+	var rec func(ml int)
+	rec = func(ml int) {
+		if ml == len(rack.LetArr) {
+			if len(exch) > 0 {
+				gen.found = append(gen.found, move.NewExchangeMove(
+					append(tilemapping.MachineWord(nil), exch...), rack.TilesOn(), gen.ld.TileMapping()))
+			}
+			return
+		}
+		n := rack.LetArr[ml]
+		for i := 0; ; i++ {
+			rec(ml + 1)
+			if i == n {
+				break
+			}
+			rack.Take(tilemapping.MachineLetter(ml))
+			exch = append(exch, tilemapping.MachineLetter(ml))
+		}
+		for i := 0; i < n; i++ {
+			rack.Add(tilemapping.MachineLetter(ml))
+		}
+		exch = exch[:len(exch)-n]
+	}
+	rec(0)
+}
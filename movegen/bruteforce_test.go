@@ -0,0 +1,128 @@
+package movegen
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/cross_set"
+	"github.com/domino14/macondo/lexicon"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/variant"
+)
+
+func TestBruteForceOpening(t *testing.T) {
+	is := is.New(t)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	alph := ld.TileMapping()
+	bd := board.MakeBoard(board.CrosswordGameBoard)
+	generator := NewBruteForceGenerator(lexicon.AcceptAll{Alph: alph}, bd, ld)
+
+	plays := generator.GenAll(tilemapping.RackFromString("BC", alph), true)
+	// BC and CB, at 8G and 8H, and three exchanges.
+	is.Equal(len(scoringPlays(plays)), 4)
+	is.Equal(len(nonScoringPlays(plays)), 3)
+	for _, m := range scoringPlays(plays) {
+		// Both tiles are worth 3, on the double word square in the middle.
+		is.Equal(m.Score(), 12)
+		_, _, vertical := m.CoordsAndVertical()
+		is.True(!vertical)
+	}
+
+	generator.SetRuleSet(&variant.RuleSet{RackSize: 2, BingoBonus: 50})
+	plays = generator.GenAll(tilemapping.RackFromString("BC", alph), false)
+	is.Equal(plays[0].Score(), 62)
+}
+
+func TestBruteForceOneTilePlays(t *testing.T) {
+	is := is.New(t)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	alph := ld.TileMapping()
+	bd := board.MakeBoard(board.CrosswordGameBoard)
+	// A at 8H and C at 7I.
+	bd.SetLetter(7, 7, 1)
+	bd.SetLetter(6, 8, 3)
+	bd.TestSetTilesPlayed(2)
+	generator := NewBruteForceGenerator(lexicon.AcceptAll{Alph: alph}, bd, ld)
+
+	plays := scoringPlays(generator.GenAll(tilemapping.RackFromString("B", alph), false))
+	// A B at 7H or 8I makes words both ways, but is only made across.
+	is.Equal(len(plays), 6)
+	keys := map[string]bool{}
+	for _, m := range plays {
+		keys[playKey(m)] = true
+	}
+	is.Equal(len(keys), 6)
+}
+
+func TestBruteForceMatchesGordon(t *testing.T) {
+	is := is.New(t)
+	gd, err := GaddagFromLexicon("NWL20")
+	is.NoErr(err)
+	k := gd.(*kwg.KWG)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	alph := k.GetAlphabet()
+
+	for _, tc := range []struct {
+		game board.VsWho
+		rack string
+		slow bool
+	}{
+		{board.VsMatt, "AABDELT", false},
+		// A full rack with a blank takes the brute-force generator long.
+		{board.VsEd, "EIRSTU?", true},
+		{board.VsCanik, "DEHILOR", false},
+		{board.VsOxy, "OXPBAZE", false},
+	} {
+		if tc.slow && testing.Short() {
+			continue
+		}
+		bd := board.MakeBoard(board.CrosswordGameBoard)
+		bd.SetToGame(alph, tc.game)
+		cross_set.GenAllCrossSets(bd, gd, ld)
+
+		gordon := NewGordonGenerator(gd, bd, ld)
+		brute := NewBruteForceGenerator(kwg.Lexicon{KWG: *k}, bd, ld)
+		rack := tilemapping.RackFromString(tc.rack, alph)
+
+		plays := scoringPlays(gordon.GenAll(rack, false))
+		scores := map[string]int{}
+		for _, m := range plays {
+			scores[playKey(m)] = m.Score()
+		}
+		brutePlays := scoringPlays(brute.GenAll(rack, false))
+		bruteScores := map[string]int{}
+		for _, m := range brutePlays {
+			bruteScores[playKey(m)] = m.Score()
+		}
+		is.True(len(scores) > 0)
+		is.Equal(bruteScores, scores)
+		// Neither makes a play twice, not even a one-tile play.
+		is.Equal(len(brutePlays), len(plays))
+		is.Equal(len(plays), len(scores))
+	}
+}
+
+// playKey names a play by the tiles it puts on the board, so that a
+// one-tile play is the same across and down.
+func playKey(m *move.Move) string {
+	row, col, vertical := m.CoordsAndVertical()
+	key := ""
+	for _, t := range m.Tiles() {
+		if t != 0 {
+			key += move.ToBoardGameCoords(row, col, false) + t.UserVisible(m.Alphabet(), false) + " "
+		}
+		if vertical {
+			row++
+		} else {
+			col++
+		}
+	}
+	return key
+}
@@ -0,0 +1,192 @@
+package movegen
+
+import (
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/board"
+)
+
+// Square is a square of the board, counting from 0.
+type Square struct {
+	Row, Col int
+}
+
+// HookRule says whether plays must form hooks. A play forms a hook if one
+// of its tiles is next to a tile on the board, across from the direction
+// of the play; that is, if it makes a word besides its main word.
+type HookRule int
+
+const (
+	HooksAllowed HookRule = iota
+	HooksRequired
+	HooksForbidden
+)
+
+// Constraints restrict the tile plays a move generator makes. They are
+// checked before a play is recorded, so plays that do not fit are never
+// made, and whole lines of the board are skipped where possible. The zero
+// value of every field does not restrict anything. Exchanges and passes
+// are not affected.
+type Constraints struct {
+	// Square, if set, must be covered by the play, either with a new tile
+	// or by playing through the tile on it.
+	Square *Square
+	// Row and Col, if set, are a row and a column that the play must
+	// cover.
+	Row *int
+	Col *int
+	// Tiles must all be played from the rack. A blank, 0, means that a
+	// blank must be played.
+	Tiles tilemapping.MachineWord
+	// MinLength is the least length of the main word, counting the tiles
+	// played through.
+	MinLength int
+	// Bingo plays use the whole rack.
+	Bingo bool
+	// Bonus, if set, is a kind of bonus square that must get a new tile.
+	Bonus board.BonusSquare
+	Hooks HookRule
+	// Through, if set, is a letter on the board that the play must play
+	// through. A blank on the board counts as the letter it stands for.
+	Through tilemapping.MachineLetter
+}
+
+// possible returns whether any play with this rack can fit the
+// constraints.
+func (c *Constraints) possible(rack *tilemapping.Rack, rackSize int) bool {
+	if c.Bingo && int(rack.NumTiles()) < rackSize {
+		return false
+	}
+	need := make([]int, len(rack.LetArr))
+	for _, t := range c.Tiles {
+		if int(t) >= len(need) {
+			return false
+		}
+		need[t]++
+		if need[t] > rack.LetArr[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// skipLine returns whether no play along this row (or column, if
+// vertical) can fit the constraints.
+func (c *Constraints) skipLine(line int, vertical bool) bool {
+	if c.Square != nil {
+		if (!vertical && c.Square.Row != line) || (vertical && c.Square.Col != line) {
+			return true
+		}
+	}
+	if !vertical && c.Row != nil && *c.Row != line {
+		return true
+	}
+	if vertical && c.Col != nil && *c.Col != line {
+		return true
+	}
+	return false
+}
+
+// allows returns whether a play fits the constraints. The play puts tiles
+// on bd from (row, col), going down if down is set and across otherwise;
+// a 0 in tiles is a tile played through. If bd is transposed, row and col
+// are in its transposed orientation, and transposed must be set.
+func (c *Constraints) allows(bd *board.GameBoard, tiles []tilemapping.MachineLetter,
+	row, col int, down, transposed bool, rackSize int) bool {
+
+	if len(tiles) < c.MinLength {
+		return false
+	}
+	dr, dc := 0, 1
+	if down {
+		dr, dc = 1, 0
+	}
+	dim := bd.Dim()
+	played := 0
+	var used []int
+	if len(c.Tiles) > 0 {
+		used = make([]int, 0, len(tiles))
+	}
+	coveredSquare := c.Square == nil
+	coveredRow := c.Row == nil
+	coveredCol := c.Col == nil
+	hitBonus := c.Bonus == 0
+	hooked := false
+	through := c.Through == 0
+	for i, t := range tiles {
+		r, cl := row+i*dr, col+i*dc
+		realRow, realCol := r, cl
+		if transposed {
+			realRow, realCol = cl, r
+		}
+		if c.Square != nil && realRow == c.Square.Row && realCol == c.Square.Col {
+			coveredSquare = true
+		}
+		if c.Row != nil && realRow == *c.Row {
+			coveredRow = true
+		}
+		if c.Col != nil && realCol == *c.Col {
+			coveredCol = true
+		}
+		if t == 0 {
+			if !through && bd.GetLetter(r, cl).Unblank() == c.Through.Unblank() {
+				through = true
+			}
+			continue
+		}
+		played++
+		if len(c.Tiles) > 0 {
+			used = append(used, int(t.IntrinsicTileIdx()))
+		}
+		if !hitBonus && bd.GetBonus(r, cl) == c.Bonus {
+			hitBonus = true
+		}
+		if !hooked {
+			// The neighbours across from the direction of the play.
+			hr, hc := r-dc, cl-dr
+			if hr >= 0 && hc >= 0 && bd.HasLetter(hr, hc) {
+				hooked = true
+			}
+			hr, hc = r+dc, cl+dr
+			if hr < dim && hc < dim && bd.HasLetter(hr, hc) {
+				hooked = true
+			}
+		}
+	}
+	if !coveredSquare || !coveredRow || !coveredCol || !hitBonus || !through {
+		return false
+	}
+	if c.Bingo && played != rackSize {
+		return false
+	}
+	switch c.Hooks {
+	case HooksRequired:
+		if !hooked {
+			return false
+		}
+	case HooksForbidden:
+		if hooked {
+			return false
+		}
+	}
+	return c.hasTiles(used)
+}
+
+// hasTiles returns whether the played tiles, given by their intrinsic tile
+// index, include all of c.Tiles.
+func (c *Constraints) hasTiles(used []int) bool {
+	for _, t := range c.Tiles {
+		found := false
+		for i, u := range used {
+			if u == int(t) {
+				used[i] = -1
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
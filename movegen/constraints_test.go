@@ -0,0 +1,154 @@
+package movegen
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/cross_set"
+	"github.com/domino14/macondo/lexicon"
+	"github.com/domino14/macondo/move"
+)
+
+// placed returns the squares a play puts new tiles on, and the tiles.
+func placed(m *move.Move) ([]Square, []tilemapping.MachineLetter) {
+	row, col, vertical := m.CoordsAndVertical()
+	var sqs []Square
+	var tiles []tilemapping.MachineLetter
+	for _, t := range m.Tiles() {
+		if t != 0 {
+			sqs = append(sqs, Square{row, col})
+			tiles = append(tiles, t)
+		}
+		if vertical {
+			row++
+		} else {
+			col++
+		}
+	}
+	return sqs, tiles
+}
+
+func TestConstraints(t *testing.T) {
+	is := is.New(t)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	alph := ld.TileMapping()
+	bd := board.MakeBoard(board.CrosswordGameBoard)
+	bd.SetToGame(alph, board.VsMatt)
+	generator := NewBruteForceGenerator(lexicon.AcceptAll{Alph: alph}, bd, ld)
+	rack := tilemapping.RackFromString("AEIS", alph)
+	all := len(generator.GenAll(rack, false))
+
+	eight := 7
+	s, _ := tilemapping.ToMachineLetters("S", alph)
+	for _, tc := range []struct {
+		name string
+		c    Constraints
+		ok   func(m *move.Move) bool
+	}{
+		{"row", Constraints{Row: &eight}, func(m *move.Move) bool {
+			row, _, vertical := m.CoordsAndVertical()
+			return row == 7 || (vertical && row < 7 && row+m.PlayLength() > 7)
+		}},
+		{"tiles", Constraints{Tiles: s}, func(m *move.Move) bool {
+			_, tiles := placed(m)
+			for _, t := range tiles {
+				if t == s[0] {
+					return true
+				}
+			}
+			return false
+		}},
+		{"minlength", Constraints{MinLength: 6}, func(m *move.Move) bool {
+			return m.PlayLength() >= 6
+		}},
+		{"bingo", Constraints{Bingo: true}, func(m *move.Move) bool {
+			return m.TilesPlayed() == 7
+		}},
+		{"bonus", Constraints{Bonus: board.Bonus3WS}, func(m *move.Move) bool {
+			sqs, _ := placed(m)
+			for _, sq := range sqs {
+				if bd.GetBonus(sq.Row, sq.Col) == board.Bonus3WS {
+					return true
+				}
+			}
+			return false
+		}},
+		{"no hooks", Constraints{Hooks: HooksForbidden}, func(m *move.Move) bool {
+			sqs, _ := placed(m)
+			_, _, vertical := m.CoordsAndVertical()
+			for _, sq := range sqs {
+				for _, d := range []int{-1, 1} {
+					r, c := sq.Row+d, sq.Col
+					if vertical {
+						r, c = sq.Row, sq.Col+d
+					}
+					if r >= 0 && c >= 0 && r < 15 && c < 15 && bd.HasLetter(r, c) {
+						return false
+					}
+				}
+			}
+			return true
+		}},
+	} {
+		generator.SetConstraints(&tc.c)
+		plays := generator.GenAll(rack, false)
+		if tc.name == "bingo" {
+			// A rack of four tiles cannot bingo.
+			is.Equal(len(scoringPlays(plays)), 0)
+			continue
+		}
+		is.True(len(scoringPlays(plays)) > 0)
+		is.True(len(plays) < all)
+		for _, m := range plays {
+			if !tc.ok(m) {
+				t.Errorf("%s: %s does not fit", tc.name, m.ShortDescription())
+			}
+		}
+	}
+	generator.SetConstraints(nil)
+	is.Equal(len(generator.GenAll(rack, false)), all)
+}
+
+func TestGordonConstraints(t *testing.T) {
+	is := is.New(t)
+	gd, err := GaddagFromLexicon("NWL20")
+	is.NoErr(err)
+	k := gd.(*kwg.KWG)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	alph := k.GetAlphabet()
+	bd := board.MakeBoard(board.CrosswordGameBoard)
+	bd.SetToGame(alph, board.VsEd)
+	cross_set.GenAllCrossSets(bd, gd, ld)
+	// No blank, to keep the brute-force generator quick.
+	rack := tilemapping.RackFromString("AEINRST", alph)
+
+	e, _ := tilemapping.ToMachineLetters("E", alph)
+	for _, c := range []*Constraints{
+		{Square: &Square{Row: 7, Col: 7}},
+		{Bingo: true},
+		{Through: e[0], MinLength: 8},
+		{Hooks: HooksRequired, Bonus: board.Bonus2WS},
+	} {
+		gordon := NewGordonGenerator(gd, bd, ld)
+		gordon.SetConstraints(c)
+		brute := NewBruteForceGenerator(kwg.Lexicon{KWG: *k}, bd, ld)
+		brute.SetConstraints(c)
+
+		scores := map[string]int{}
+		for _, m := range scoringPlays(gordon.GenAll(rack, false)) {
+			scores[playKey(m)] = m.Score()
+		}
+		bruteScores := map[string]int{}
+		for _, m := range scoringPlays(brute.GenAll(rack, false)) {
+			bruteScores[playKey(m)] = m.Score()
+		}
+		is.True(len(scores) > 0)
+		is.Equal(bruteScores, scores)
+	}
+}
@@ -0,0 +1,290 @@
+// Package difftest compares the complete move lists of two move generators
+// over many positions, to find bugs in either one. Positions come from
+// self-play games or from the sample boards, and every position where the
+// generators disagree can be saved as a CGP file to reproduce it.
+package difftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/move"
+)
+
+// DefaultEquityTolerance is how far apart two equities can be and still be
+// the same.
+const DefaultEquityTolerance = 1e-6
+
+// A Generator generates every legal move for the player on turn.
+type Generator interface {
+	Name() string
+	// Generate returns all the tile plays, the exchanges if the bag allows
+	// them, and the pass. It must leave the game as it found it. If some
+	// of its moves cannot be played, it returns the others with an
+	// *InvalidMovesError.
+	Generate(g *game.Game) ([]*move.Move, error)
+}
+
+// InvalidMovesError holds the moves a generator made that cannot be
+// played in the position, and why.
+type InvalidMovesError struct {
+	Moves []string
+}
+
+func (e *InvalidMovesError) Error() string {
+	return fmt.Sprintf("%d moves cannot be played: %s", len(e.Moves), strings.Join(e.Moves, "; "))
+}
+
+type Options struct {
+	// PlaysOnly leaves exchanges and passes out of the comparison.
+	PlaysOnly bool
+	// CompareEquity compares equities as well as scores. Both generators
+	// must assign equities.
+	CompareEquity   bool
+	EquityTolerance float64
+	// ReproDir, if set, is where a CGP file, and a text file with the
+	// differences, are written for every position that differs.
+	ReproDir string
+	// MaxDiffs stops the run after this many positions that differ. If it
+	// is 0, there is no limit.
+	MaxDiffs int
+}
+
+// Diff holds the differences between the moves of two generators in one
+// position.
+type Diff struct {
+	CGP   string
+	NameA string
+	NameB string
+	// OnlyA are the moves only the first generator made, and OnlyB the
+	// moves only the second one did.
+	OnlyA []*move.Move
+	OnlyB []*move.Move
+	// Different are moves that both generators made, with a different
+	// score or equity. The first of each pair is the first generator's.
+	Different [][2]*move.Move
+	// InvalidA and InvalidB are the moves each generator made that cannot
+	// be played, and why.
+	InvalidA []string
+	InvalidB []string
+
+	numA int
+}
+
+func (d *Diff) Empty() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0 && len(d.Different) == 0 &&
+		len(d.InvalidA) == 0 && len(d.InvalidB) == 0
+}
+
+func (d *Diff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n", d.CGP)
+	for _, m := range d.OnlyA {
+		fmt.Fprintf(&sb, "only %s: %s\n", d.NameA, describe(m))
+	}
+	for _, m := range d.OnlyB {
+		fmt.Fprintf(&sb, "only %s: %s\n", d.NameB, describe(m))
+	}
+	for _, p := range d.Different {
+		fmt.Fprintf(&sb, "different: %s: %s, %s: %s\n", d.NameA, describe(p[0]), d.NameB, describe(p[1]))
+	}
+	for _, m := range d.InvalidA {
+		fmt.Fprintf(&sb, "invalid %s: %s\n", d.NameA, m)
+	}
+	for _, m := range d.InvalidB {
+		fmt.Fprintf(&sb, "invalid %s: %s\n", d.NameB, m)
+	}
+	return sb.String()
+}
+
+func describe(m *move.Move) string {
+	return fmt.Sprintf("%s (score %d, equity %.3f)", m.ShortDescription(), m.Score(), m.Equity())
+}
+
+// Result sums up a run.
+type Result struct {
+	Positions int
+	// Moves is how many different moves the first generator made, over
+	// all positions.
+	Moves int
+	Diffs []*Diff
+	// ReproFiles are the CGP files that were written.
+	ReproFiles []string
+}
+
+// Compare generates the moves of both generators in a position, and
+// returns their differences.
+func Compare(g *game.Game, a, b Generator, opts Options) (*Diff, error) {
+	ma, invalidA, err := generate(g, a)
+	if err != nil {
+		return nil, err
+	}
+	mb, invalidB, err := generate(g, b)
+	if err != nil {
+		return nil, err
+	}
+	d := diffMoves(ma, mb, opts)
+	d.NameA, d.NameB = a.Name(), b.Name()
+	d.InvalidA, d.InvalidB = invalidA, invalidB
+	if !d.Empty() {
+		d.CGP = g.ToCGP(false)
+	}
+	return d, nil
+}
+
+// generate returns the moves of a generator, and the moves it made that
+// cannot be played.
+func generate(g *game.Game, gen Generator) ([]*move.Move, []string, error) {
+	moves, err := gen.Generate(g)
+	var invalid *InvalidMovesError
+	if errors.As(err, &invalid) {
+		return moves, invalid.Moves, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", gen.Name(), err)
+	}
+	return moves, nil, nil
+}
+
+// Run compares the generators in every position from the source.
+func Run(ctx context.Context, positions PositionSource, a, b Generator, opts Options) (*Result, error) {
+	if opts.ReproDir != "" {
+		if err := os.MkdirAll(opts.ReproDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	res := &Result{}
+	errEnough := fmt.Errorf("found %d positions that differ", opts.MaxDiffs)
+	err := positions(ctx, func(g *game.Game) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d, err := Compare(g, a, b, opts)
+		if err != nil {
+			return err
+		}
+		res.Positions++
+		res.Moves += d.numA
+		if d.Empty() {
+			return nil
+		}
+		res.Diffs = append(res.Diffs, d)
+		if opts.ReproDir != "" {
+			fn, err := writeRepro(opts.ReproDir, len(res.Diffs), d)
+			if err != nil {
+				return err
+			}
+			res.ReproFiles = append(res.ReproFiles, fn)
+		}
+		if opts.MaxDiffs > 0 && len(res.Diffs) >= opts.MaxDiffs {
+			return errEnough
+		}
+		return nil
+	})
+	if err == errEnough {
+		err = nil
+	}
+	return res, err
+}
+
+// writeRepro writes the position as a CGP file, and the differences next
+// to it. It returns the name of the CGP file.
+func writeRepro(dir string, n int, d *Diff) (string, error) {
+	base := filepath.Join(dir, fmt.Sprintf("diff-%04d", n))
+	if err := os.WriteFile(base+".cgp", []byte(d.CGP+"\n"), 0644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(base+".txt", []byte(d.String()), 0644); err != nil {
+		return "", err
+	}
+	return base + ".cgp", nil
+}
+
+func diffMoves(ma, mb []*move.Move, opts Options) *Diff {
+	tol := opts.EquityTolerance
+	if tol == 0 {
+		tol = DefaultEquityTolerance
+	}
+	d := &Diff{}
+	byKey := map[string]*move.Move{}
+	for _, m := range mb {
+		if opts.PlaysOnly && m.Action() != move.MoveTypePlay {
+			continue
+		}
+		byKey[moveKey(m)] = m
+	}
+	seen := map[string]bool{}
+	for _, m := range ma {
+		if opts.PlaysOnly && m.Action() != move.MoveTypePlay {
+			continue
+		}
+		k := moveKey(m)
+		if seen[k] {
+			// A one-tile play can be made both across and down.
+			continue
+		}
+		seen[k] = true
+		d.numA++
+		o, ok := byKey[k]
+		if !ok {
+			d.OnlyA = append(d.OnlyA, m)
+			continue
+		}
+		if m.Score() != o.Score() ||
+			(opts.CompareEquity && math.Abs(m.Equity()-o.Equity()) > tol) {
+			d.Different = append(d.Different, [2]*move.Move{m, o})
+		}
+	}
+	for k, m := range byKey {
+		if !seen[k] {
+			d.OnlyB = append(d.OnlyB, m)
+		}
+	}
+	// Map order is random; keep reports stable.
+	slices.SortFunc(d.OnlyB, func(x, y *move.Move) int {
+		return strings.Compare(moveKey(x), moveKey(y))
+	})
+	return d
+}
+
+// moveKey names a move by what it does, so that the same move made by two
+// generators has the same key. A tile play is named by the tiles it puts
+// on the board and where, so a one-tile play is the same across and down.
+// An exchange is named by the tiles it throws in.
+func moveKey(m *move.Move) string {
+	var sb strings.Builder
+	switch m.Action() {
+	case move.MoveTypePlay:
+		sb.WriteString("play")
+		row, col, vertical := m.CoordsAndVertical()
+		for _, t := range m.Tiles() {
+			if t != 0 {
+				fmt.Fprintf(&sb, " %d,%d,%d", row, col, t)
+			}
+			if vertical {
+				row++
+			} else {
+				col++
+			}
+		}
+	case move.MoveTypeExchange:
+		sb.WriteString("exch")
+		tiles := slices.Clone([]tilemapping.MachineLetter(m.Tiles()))
+		slices.Sort(tiles)
+		for _, t := range tiles {
+			fmt.Fprintf(&sb, " %d", t)
+		}
+	default:
+		fmt.Fprintf(&sb, "action %d", m.Action())
+	}
+	return sb.String()
+}
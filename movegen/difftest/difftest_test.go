@@ -0,0 +1,186 @@
+package difftest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/cgp"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/move"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+func play(score, row, col int, vertical bool, tiles ...tilemapping.MachineLetter) *move.Move {
+	n := 0
+	for _, t := range tiles {
+		if t != 0 {
+			n++
+		}
+	}
+	return move.NewScoringMove(score, tiles, nil, vertical, n, nil, row, col)
+}
+
+func TestDiffMoves(t *testing.T) {
+	is := is.New(t)
+	a := []*move.Move{
+		play(10, 7, 7, false, 1, 2, 3),
+		// A one-tile play, across and down.
+		play(5, 6, 8, false, 4, 0),
+		play(5, 6, 8, true, 4, 0),
+		play(8, 7, 6, false, 5, 0, 0, 0),
+		move.NewExchangeMove([]tilemapping.MachineLetter{3, 1}, nil, nil),
+	}
+	b := []*move.Move{
+		play(10, 7, 7, false, 1, 2, 3),
+		play(5, 6, 8, true, 4, 0),
+		play(9, 7, 6, false, 5, 0, 0, 0),
+		play(12, 8, 7, true, 6, 7),
+		move.NewExchangeMove([]tilemapping.MachineLetter{1, 3}, nil, nil),
+	}
+	d := diffMoves(a, b, Options{})
+	is.Equal(d.numA, 4)
+	is.Equal(len(d.OnlyA), 0)
+	is.Equal(len(d.OnlyB), 1)
+	is.Equal(d.OnlyB[0], b[3])
+	is.Equal(len(d.Different), 1)
+	is.Equal(d.Different[0], [2]*move.Move{a[3], b[2]})
+
+	d = diffMoves(a[:1], b[4:], Options{PlaysOnly: true})
+	is.Equal(len(d.OnlyA), 1)
+	is.Equal(len(d.OnlyB), 0)
+
+	a[0].SetEquity(20)
+	b[0].SetEquity(20.5)
+	is.True(diffMoves(a[:1], b[:1], Options{}).Empty())
+	is.Equal(len(diffMoves(a[:1], b[:1], Options{CompareEquity: true}).Different), 1)
+}
+
+// dropper is a generator that loses its best move, to test that the
+// harness catches it.
+type dropper struct{ Generator }
+
+func (d dropper) Name() string { return "dropper" }
+
+func (d dropper) Generate(g *game.Game) ([]*move.Move, error) {
+	plays, err := d.Generator.Generate(g)
+	if err != nil || len(plays) < 2 {
+		return plays, err
+	}
+	return plays[1:], nil
+}
+
+func TestGordonSmallOnSampleBoards(t *testing.T) {
+	is := is.New(t)
+	gd, err := kwg.Get(DefaultConfig.AllSettings(), "NWL20")
+	is.NoErr(err)
+	res, err := Run(context.Background(), SampleBoards(&DefaultConfig, "NWL20"),
+		NewGordon(gd), NewGordonSmall(gd), Options{PlaysOnly: true})
+	is.NoErr(err)
+	is.True(res.Positions > 20)
+	is.True(res.Moves > 1000)
+	for _, d := range res.Diffs {
+		t.Log(d)
+	}
+	is.Equal(len(res.Diffs), 0)
+}
+
+func TestReproFiles(t *testing.T) {
+	is := is.New(t)
+	// Self-play uses the default lexicon.
+	gd, err := kwg.Get(DefaultConfig.AllSettings(), DefaultConfig.GetString(config.ConfigDefaultLexicon))
+	is.NoErr(err)
+	dir := t.TempDir()
+	res, err := Run(context.Background(), SelfPlay(&DefaultConfig, 1),
+		NewGordon(gd), dropper{NewGordon(gd)}, Options{ReproDir: dir, MaxDiffs: 2})
+	is.NoErr(err)
+	is.Equal(len(res.Diffs), 2)
+	is.Equal(len(res.Diffs[0].OnlyA), 1)
+	is.Equal(res.ReproFiles[1], filepath.Join(dir, "diff-0002.cgp"))
+	bts, err := os.ReadFile(res.ReproFiles[0])
+	is.NoErr(err)
+	is.Equal(string(bts), res.Diffs[0].CGP+"\n")
+}
+
+// fewBlanks leaves out the positions where the player on turn has more than
+// one blank, which take the brute-force generator too long.
+func fewBlanks(positions PositionSource) PositionSource {
+	return func(ctx context.Context, fn func(*game.Game) error) error {
+		return positions(ctx, func(g *game.Game) error {
+			if g.RackFor(g.PlayerOnTurn()).CountOf(0) > 1 {
+				return nil
+			}
+			return fn(g)
+		})
+	}
+}
+
+func TestBruteForce(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the brute-force generator is slow")
+	}
+	is := is.New(t)
+	lexicon := DefaultConfig.GetString(config.ConfigDefaultLexicon)
+	gd, err := kwg.Get(DefaultConfig.AllSettings(), lexicon)
+	is.NoErr(err)
+	for _, positions := range []PositionSource{
+		SampleBoards(&DefaultConfig, lexicon),
+		SelfPlay(&DefaultConfig, 2),
+	} {
+		res, err := Run(context.Background(), fewBlanks(positions),
+			NewGordon(gd), NewBruteForce(gd), Options{})
+		is.NoErr(err)
+		is.True(res.Positions > 20)
+		for _, d := range res.Diffs {
+			t.Log(d)
+		}
+		is.Equal(len(res.Diffs), 0)
+	}
+}
+
+func TestWolges(t *testing.T) {
+	is := is.New(t)
+	gd, err := kwg.Get(DefaultConfig.AllSettings(), "NWL20")
+	is.NoErr(err)
+	g, err := cgp.ParseCGP(&DefaultConfig,
+		"15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 IQ/ 0/0 0 lex NWL20;")
+	is.NoErr(err)
+	g.RecalculateBoard()
+
+	// The opening QI can be played at 8G and 8H. The server scores 8G
+	// wrong, leaves out 8H, and plays a Z it does not have.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"action": "play", "lane": 7, "idx": 6, "word": [17, 9], "score": 24},
+			{"action": "play", "lane": 7, "idx": 6, "word": [26, 9], "score": 22},
+			{"action": "exchange", "tiles": []}]`))
+	}))
+	defer srv.Close()
+	positions := func(ctx context.Context, fn func(*game.Game) error) error {
+		return fn(g.Game)
+	}
+
+	res, err := Run(context.Background(), positions, NewGordon(gd),
+		NewWolges(srv.URL, &DefaultConfig), Options{PlaysOnly: true})
+	is.NoErr(err)
+	is.Equal(res.Positions, 1)
+	is.Equal(len(res.Diffs), 1)
+	d := res.Diffs[0]
+	is.Equal(len(d.OnlyA), 1)
+	is.Equal(d.OnlyA[0].BoardCoords(), "8H")
+	is.Equal(len(d.OnlyB), 0)
+	is.Equal(len(d.Different), 1)
+	is.Equal(d.Different[0][0].Score(), 22)
+	is.Equal(d.Different[0][1].Score(), 24)
+	is.Equal(d.Different[0][1].BoardCoords(), "8G")
+	is.Equal(len(d.InvalidB), 1)
+}
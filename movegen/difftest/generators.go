@@ -0,0 +1,175 @@
+package difftest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/samber/lo"
+
+	aibot "github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/bot"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/tinymove/conversions"
+)
+
+type gordon struct {
+	gd    *kwg.KWG
+	small bool
+	calcs []equity.EquityCalculator
+}
+
+// NewGordon returns a Generator that uses movegen.GordonGenerator. If
+// equity calculators are given, the moves get the sum of their equities.
+func NewGordon(gd *kwg.KWG, calcs ...equity.EquityCalculator) Generator {
+	return &gordon{gd: gd, calcs: calcs}
+}
+
+// NewGordonSmall returns a Generator that uses movegen.GordonGenerator with
+// the small play recorder, as the endgame and pre-endgame solvers do. It
+// only makes tile plays, so compare it with Options.PlaysOnly set.
+func NewGordonSmall(gd *kwg.KWG) Generator {
+	return &gordon{gd: gd, small: true}
+}
+
+func (gg *gordon) Name() string {
+	if gg.small {
+		return "gordon-small"
+	}
+	return "gordon"
+}
+
+func (gg *gordon) Generate(g *game.Game) ([]*move.Move, error) {
+	gen := movegen.NewGordonGenerator(gg.gd, g.Board(), g.Bag().LetterDistribution())
+	gen.SetRuleSet(g.Rules().RuleSet())
+	gen.SetVariant(g.Rules().Variant())
+	rack := g.RackFor(g.PlayerOnTurn())
+	if gg.small {
+		gen.SetPlayRecorder(movegen.AllPlaysSmallRecorder)
+		gen.GenAll(rack, false)
+		var plays []*move.Move
+		for _, sm := range gen.SmallPlays() {
+			if sm.IsPass() {
+				continue
+			}
+			m := &move.Move{}
+			conversions.SmallMoveToMove(sm, m, g.Alphabet(), g.Board(), rack)
+			plays = append(plays, m)
+		}
+		return plays, nil
+	}
+	// The generator reuses its slice of plays.
+	plays := append([]*move.Move(nil), gen.GenAll(rack, ExchangeAllowed(g))...)
+	AssignEquity(g, plays, gg.calcs)
+	return plays, nil
+}
+
+type bruteForce struct {
+	gd    *kwg.KWG
+	calcs []equity.EquityCalculator
+}
+
+// NewBruteForce returns a Generator that uses movegen.BruteForceGenerator,
+// which looks words up in the lexicon instead of walking the GADDAG. It is
+// slow, above all with two blanks on the rack.
+func NewBruteForce(gd *kwg.KWG, calcs ...equity.EquityCalculator) Generator {
+	return &bruteForce{gd: gd, calcs: calcs}
+}
+
+func (bf *bruteForce) Name() string {
+	return "brute"
+}
+
+func (bf *bruteForce) Generate(g *game.Game) ([]*move.Move, error) {
+	gen := movegen.NewBruteForceGenerator(kwg.Lexicon{KWG: *bf.gd}, g.Board(), g.Bag().LetterDistribution())
+	gen.SetRuleSet(g.Rules().RuleSet())
+	gen.SetVariant(g.Rules().Variant())
+	plays := gen.GenAll(g.RackFor(g.PlayerOnTurn()), ExchangeAllowed(g))
+	AssignEquity(g, plays, bf.calcs)
+	return plays, nil
+}
+
+// wolgesMaxMoves is how many moves a wolges server is asked for; it is
+// more than any position has, so that it sends them all.
+const wolgesMaxMoves = 1 << 20
+
+// wolgesTimeout is how long a wolges server has to send every move of a
+// position.
+const wolgesTimeout = time.Minute
+
+type wolges struct {
+	source *bot.WolgesMoveSource
+	cfg    *config.Config
+	calcs  []equity.EquityCalculator
+}
+
+// NewWolges returns a Generator that asks the wolges-awsm server at url
+// for all its moves. The moves keep the scores wolges gave them, and the
+// plays that cannot be made are returned as invalid. If equity calculators
+// are given, the moves get the sum of their equities; if not, they keep
+// the equities wolges gave them.
+func NewWolges(url string, cfg *config.Config, calcs ...equity.EquityCalculator) Generator {
+	return &wolges{source: &bot.WolgesMoveSource{URL: url}, cfg: cfg, calcs: calcs}
+}
+
+func (w *wolges) Name() string {
+	return w.source.Name()
+}
+
+func (w *wolges) Generate(g *game.Game) ([]*move.Move, error) {
+	// The move source needs a player, but only reads the game.
+	p, err := aibot.NewBotTurnPlayerFromGame(g, &aibot.BotConfig{Config: *w.cfg},
+		pb.BotRequest_NO_LEAVE_BOT)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), wolgesTimeout)
+	defer cancel()
+	answer, err := w.source.Analyze(ctx, p, wolgesMaxMoves)
+	if err != nil {
+		return nil, err
+	}
+	var plays []*move.Move
+	var invalid []string
+	for _, wr := range answer {
+		m, err := bot.WolgesMove(p, wr)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%+v: %v", wr, err))
+			continue
+		}
+		// Keep the score wolges gave, so that it is compared.
+		m.SetScore(wr.Score)
+		m.SetEquity(float64(wr.Equity))
+		plays = append(plays, m)
+	}
+	AssignEquity(g, plays, w.calcs)
+	if len(invalid) > 0 {
+		return plays, &InvalidMovesError{Moves: invalid}
+	}
+	return plays, nil
+}
+
+// ExchangeAllowed returns whether the player on turn can exchange.
+func ExchangeAllowed(g *game.Game) bool {
+	return g.Bag().TilesRemaining() >= g.Rules().RuleSet().ExchangeLimit
+}
+
+// AssignEquity sets the equity of each move to the sum of the calculators'
+// equities. It does nothing if there are no calculators.
+func AssignEquity(g *game.Game, plays []*move.Move, calcs []equity.EquityCalculator) {
+	if len(calcs) == 0 {
+		return
+	}
+	opp := g.RackFor(g.NextPlayer())
+	for _, m := range plays {
+		m.SetEquity(lo.SumBy(calcs, func(c equity.EquityCalculator) float64 {
+			return c.Equity(m, g.Board(), g.Bag(), opp)
+		}))
+	}
+}
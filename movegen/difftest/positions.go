@@ -0,0 +1,92 @@
+package difftest
+
+import (
+	"context"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/automatic"
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/variant"
+)
+
+// A PositionSource calls fn with every position it makes, until fn returns
+// an error, which it then returns. fn must not change the game.
+type PositionSource func(ctx context.Context, fn func(*game.Game) error) error
+
+// SelfPlay plays games between two static bots, in the default lexicon of
+// the config, and makes the position before every turn.
+func SelfPlay(cfg *config.Config, games int) PositionSource {
+	return func(ctx context.Context, fn func(*game.Game) error) error {
+		r := automatic.NewGameRunner(nil, cfg)
+		for i := 0; i < games; i++ {
+			r.StartGame(i)
+			g := r.Game()
+			for g.Playing() == pb.PlayState_PLAYING {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := fn(g); err != nil {
+					return err
+				}
+				if err := r.PlayBestTurn(g.PlayerOnTurn(), false); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// SampleBoards makes positions from board.EnglishSampleBoards: for every
+// board, one with each of the two racks given there on turn.
+func SampleBoards(cfg *config.Config, lexicon string) PositionSource {
+	return func(ctx context.Context, fn func(*game.Game) error) error {
+		rules, err := game.NewBasicGameRules(cfg, lexicon, board.CrosswordGameLayout,
+			"english", game.CrossScoreAndSet, variant.VarClassic)
+		if err != nil {
+			return err
+		}
+		players := []*pb.PlayerInfo{
+			{Nickname: "p1", RealName: "Player 1"},
+			{Nickname: "p2", RealName: "Player 2"},
+		}
+		for _, vs := range board.EnglishSampleBoards {
+			for r := 0; r < 2; r++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				g, err := game.NewGame(rules, players)
+				if err != nil {
+					return err
+				}
+				g.StartGame()
+				g.ThrowRacksIn()
+				tip := g.Board().SetToGame(g.Alphabet(), vs)
+				if err := g.Bag().RemoveTiles(tip.OnBoard); err != nil {
+					return err
+				}
+				g.RecalculateBoard()
+				tiles := tip.Rack1
+				if r == 1 {
+					tiles = tip.Rack2
+				}
+				if len(tiles) == 0 {
+					continue
+				}
+				rack := tilemapping.NewRack(g.Alphabet())
+				rack.Set(tiles)
+				if err := g.SetRackFor(g.PlayerOnTurn(), rack); err != nil {
+					return err
+				}
+				if err := fn(g); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -44,6 +44,9 @@ type MoveGenerator interface {
 	AtLeastOneTileMove(rack *tilemapping.Rack) bool
 	SetMaxTileUsage(int)
 	SetGenPass(bool)
+	SetRuleSet(*variant.RuleSet)
+	SetVariant(variant.Variant)
+	SetConstraints(*Constraints)
 }
 
 // GordonGenerator is the main move generation struct. It implements
@@ -73,6 +76,8 @@ type GordonGenerator struct {
 	boardDim int
 	// Used for scoring:
 	letterDistribution *tilemapping.LetterDistribution
+	rackSize           int
+	bingoBonus         int
 
 	// Used for play-finding without allocation
 	strip         []tilemapping.MachineLetter
@@ -90,6 +95,17 @@ type GordonGenerator struct {
 	genPass      bool
 	quitEarly    bool
 	maxTileUsage int
+	constraints  *Constraints
+
+	// Used for WordSmog play-finding; see wordsmog.go.
+	wordSmog       bool
+	smogCache      map[string][][]tilemapping.MachineLetter
+	smogAnagrammer kwg.KWGAnagrammer
+	smogEmpty      []int
+	smogFixed      []tilemapping.MachineLetter
+	smogChosen     []tilemapping.MachineLetter
+	smogTiles      []tilemapping.MachineLetter
+	smogUsed       []bool
 }
 
 // NewGordonGenerator returns a Gordon move generator.
@@ -103,12 +119,15 @@ func NewGordonGenerator(gd gaddag.WordGraph, board *board.GameBoard,
 		sortingParameter:   SortByScore,
 		letterDistribution: ld,
 		strip:              make([]tilemapping.MachineLetter, board.Dim()),
-		exchangestrip:      make([]tilemapping.MachineLetter, 7), // max rack size. can make a parameter later.
-		leavestrip:         make([]tilemapping.MachineLetter, 7),
+		rackSize:           variant.ClassicRuleSet.RackSize,
+		bingoBonus:         variant.ClassicRuleSet.BingoBonus,
+		exchangestrip:      make([]tilemapping.MachineLetter, variant.ClassicRuleSet.RackSize),
+		leavestrip:         make([]tilemapping.MachineLetter, variant.ClassicRuleSet.RackSize),
 		playRecorder:       AllPlaysRecorder,
 		winner:             new(move.Move),
 		placeholder:        new(move.Move),
 		maxTileUsage:       100, // basically unlimited
+		smogUsed:           make([]bool, board.Dim()),
 	}
 	return gen
 }
@@ -128,6 +147,15 @@ func (gen *GordonGenerator) SetEquityCalculators(calcs []equity.EquityCalculator
 	gen.equityCalculators = calcs
 }
 
+// SetRuleSet sets the rack size and bingo bonus of the generated plays. By
+// default, they are those of the classic rule set.
+func (gen *GordonGenerator) SetRuleSet(rs *variant.RuleSet) {
+	gen.rackSize = rs.RackSize
+	gen.bingoBonus = rs.BingoBonus
+	gen.exchangestrip = make([]tilemapping.MachineLetter, rs.RackSize)
+	gen.leavestrip = make([]tilemapping.MachineLetter, rs.RackSize)
+}
+
 func (gen *GordonGenerator) SetGame(g *game.Game) {
 	gen.game = g
 }
@@ -140,6 +168,19 @@ func (gen *GordonGenerator) SetMaxTileUsage(t int) {
 	gen.maxTileUsage = t
 }
 
+// SetConstraints restricts the tile plays that are generated. Pass nil to
+// generate all of them again.
+func (gen *GordonGenerator) SetConstraints(c *Constraints) {
+	gen.constraints = c
+}
+
+// allowed returns whether the play in gen.strip[leftstrip:rightstrip+1]
+// fits the constraints, if there are any.
+func (gen *GordonGenerator) allowed(leftstrip, rightstrip int) bool {
+	return gen.constraints == nil || gen.constraints.allows(gen.board,
+		gen.strip[leftstrip:rightstrip+1], gen.curRowIdx, leftstrip, false, gen.vertical, gen.rackSize)
+}
+
 // GenAll generates all moves on the board. It assumes anchors have already
 // been updated, as well as cross-sets / cross-scores.
 func (gen *GordonGenerator) GenAll(rack *tilemapping.Rack, addExchange bool) []*move.Move {
@@ -213,8 +254,18 @@ func (gen *GordonGenerator) AtLeastOneTileMove(rack *tilemapping.Rack) bool {
 }
 
 func (gen *GordonGenerator) genByOrientation(rack *tilemapping.Rack, dir board.BoardDirection) {
+	if gen.constraints != nil && !gen.constraints.possible(rack, gen.rackSize) {
+		return
+	}
+	if gen.wordSmog {
+		gen.genSmogByOrientation(rack, dir)
+		return
+	}
 
 	for row := 0; row < gen.boardDim; row++ {
+		if gen.constraints != nil && gen.constraints.skipLine(row, gen.vertical) {
+			continue
+		}
 		gen.curRowIdx = row
 		// A bit of a hack. Set this to a large number at the beginning of
 		// every loop
@@ -329,8 +380,8 @@ func (gen *GordonGenerator) goOn(curCol int, L tilemapping.MachineLetter,
 	rack *tilemapping.Rack, newNodeIdx uint32, accepts bool,
 	leftstrip, rightstrip int, uniquePlay bool, baseScore, crossScores, wordMultiplier int) {
 	var bingoBonus int
-	if gen.tilesPlayed == game.RackTileLimit {
-		bingoBonus = 50
+	if gen.tilesPlayed == gen.rackSize {
+		bingoBonus = gen.bingoBonus
 	}
 	if curCol <= gen.curAnchorCol {
 		if gen.board.HasLetter(gen.curRowIdx, curCol) {
@@ -356,7 +407,8 @@ func (gen *GordonGenerator) goOn(curCol int, L tilemapping.MachineLetter,
 			// Only record the play if it is unique:
 			// if 1 tile has been played, there should be no letters in the across
 			// direction (otherwise the cross-set is not trivial)
-			if (uniquePlay || gen.tilesPlayed > 1) && gen.tilesPlayed <= gen.maxTileUsage {
+			if (uniquePlay || gen.tilesPlayed > 1) && gen.tilesPlayed <= gen.maxTileUsage &&
+				gen.allowed(leftstrip, rightstrip) {
 				gen.playRecorder(gen, rack, leftstrip, rightstrip, move.MoveTypePlay,
 					baseScore*wordMultiplier+crossScores+bingoBonus)
 			}
@@ -396,7 +448,8 @@ func (gen *GordonGenerator) goOn(curCol int, L tilemapping.MachineLetter,
 		noLetterDirectlyRight := curCol == gen.boardDim-1 ||
 			!gen.board.HasLetter(gen.curRowIdx, curCol+1)
 		if accepts && noLetterDirectlyRight && gen.tilesPlayed > 0 {
-			if (uniquePlay || gen.tilesPlayed > 1) && gen.tilesPlayed <= gen.maxTileUsage {
+			if (uniquePlay || gen.tilesPlayed > 1) && gen.tilesPlayed <= gen.maxTileUsage &&
+				gen.allowed(leftstrip, rightstrip) {
 				gen.playRecorder(gen, rack, leftstrip, rightstrip, move.MoveTypePlay,
 					baseScore*wordMultiplier+crossScores+bingoBonus)
 			}
@@ -417,8 +470,9 @@ func (gen *GordonGenerator) crossDirection() board.BoardDirection {
 }
 
 func (gen *GordonGenerator) scoreMove(word tilemapping.MachineWord, row, col, tilesPlayed int, va variant.Variant, lex lexicon.Lexicon) int {
-
-	return gen.board.ScoreWord(word, row, col, tilesPlayed, gen.crossDirection(), gen.letterDistribution, va, lex)
+	rs := va.DefaultRuleSet()
+	rs.RackSize, rs.BingoBonus = gen.rackSize, gen.bingoBonus
+	return gen.board.ScoreWord(word, row, col, tilesPlayed, gen.crossDirection(), gen.letterDistribution, va, &rs, lex)
 }
 
 // Plays returns the generator's generated plays.
@@ -0,0 +1,256 @@
+package movegen
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/variant"
+)
+
+// In the WordSmog variants, a word is valid if its letters are an anagram
+// of a word in the lexicon. The GADDAG cannot be walked for these, so
+// instead every stretch of a row that a play could cover is tried with
+// every combination of rack tiles whose letters, together with the tiles
+// already in the stretch, are an anagram of a word. Each combination is then
+// placed in every order that the cross-sets allow.
+// The plays go through the same play recorders as in the classic game.
+
+var errFoundAnagram = errors.New("found anagram")
+
+// maxSmogCacheSize is how many anagram lookups a generator remembers.
+const maxSmogCacheSize = 1 << 20
+
+// SetVariant sets the variant to generate plays for. Only the WordSmog
+// variants form words differently from the classic game.
+func (gen *GordonGenerator) SetVariant(va variant.Variant) {
+	gen.wordSmog = va.IsWordSmog()
+}
+
+func (gen *GordonGenerator) genSmogByOrientation(rack *tilemapping.Rack, dir board.BoardDirection) {
+	if gen.vertical && gen.board.IsEmpty() {
+		// As in the classic game, opening plays are only generated
+		// horizontally.
+		return
+	}
+	if gen.smogCache == nil || len(gen.smogCache) > maxSmogCacheSize {
+		gen.smogCache = make(map[string][][]tilemapping.MachineLetter)
+	}
+	maxTiles := min(gen.maxTileUsage, int(rack.NumTiles()))
+	for row := 0; row < gen.boardDim; row++ {
+		if gen.constraints != nil && gen.constraints.skipLine(row, gen.vertical) {
+			continue
+		}
+		gen.curRowIdx = row
+		for start := 0; start < gen.boardDim; start++ {
+			if start > 0 && gen.board.HasLetter(row, start-1) {
+				continue
+			}
+			gen.smogEmpty = gen.smogEmpty[:0]
+			gen.smogFixed = gen.smogFixed[:0]
+			connected := false
+			for end := start; end < gen.boardDim; end++ {
+				if ml := gen.board.GetLetter(row, end); ml != 0 {
+					gen.smogFixed = append(gen.smogFixed, ml.Unblank())
+					connected = true
+				} else {
+					sqIdx := gen.board.GetSqIdx(row, end)
+					if gen.board.GetCrossSetIdx(sqIdx, gen.crossDirection()) == 0 ||
+						len(gen.smogEmpty) == maxTiles {
+						break
+					}
+					gen.smogEmpty = append(gen.smogEmpty, end)
+					if gen.board.IsAnchor(row, end, dir) {
+						connected = true
+					}
+				}
+				if !connected || len(gen.smogEmpty) == 0 {
+					continue
+				}
+				// The word must not run into a tile on its right.
+				if end < gen.boardDim-1 && gen.board.HasLetter(row, end+1) {
+					continue
+				}
+				gen.genSmogSpan(rack, start, end)
+				if gen.quitEarly {
+					return
+				}
+			}
+		}
+	}
+}
+
+// genSmogSpan generates all plays that make a word from start to end,
+// filling every empty square in between.
+func (gen *GordonGenerator) genSmogSpan(rack *tilemapping.Rack, start, end int) {
+	n := len(gen.smogEmpty)
+	if n == 1 && gen.vertical && gen.board.GetCrossSet(gen.curRowIdx, gen.smogEmpty[0],
+		board.HorizontalDirection) != board.TrivialCrossSet {
+		// This one-tile play was already generated horizontally.
+		return
+	}
+	for col := start; col <= end; col++ {
+		gen.strip[col] = 0
+	}
+	gen.tilesPlayed = n
+	gen.smogChoose(rack, start, end, 0, n)
+	gen.tilesPlayed = 0
+}
+
+// smogChoose takes every combination of n tiles from the rack, without
+// repeating any, and tries the ones that make an anagram of a word.
+func (gen *GordonGenerator) smogChoose(rack *tilemapping.Rack, start, end int,
+	ml tilemapping.MachineLetter, n int) {
+
+	if n == 0 {
+		for _, blanks := range gen.smogDesignations() {
+			gen.smogTiles = gen.smogTiles[:0]
+			for _, t := range gen.smogChosen {
+				if t != 0 {
+					gen.smogTiles = append(gen.smogTiles, t)
+				}
+			}
+			for _, b := range blanks {
+				gen.smogTiles = append(gen.smogTiles, b.Blank())
+			}
+			slices.Sort(gen.smogTiles)
+			gen.smogPlace(rack, start, end, 0)
+			if gen.quitEarly {
+				return
+			}
+		}
+		return
+	}
+	for ; int(ml) < len(rack.LetArr); ml++ {
+		if rack.LetArr[ml] == 0 {
+			continue
+		}
+		rack.Take(ml)
+		gen.smogChosen = append(gen.smogChosen, ml)
+		gen.smogChoose(rack, start, end, ml, n-1)
+		gen.smogChosen = gen.smogChosen[:len(gen.smogChosen)-1]
+		rack.Add(ml)
+		if gen.quitEarly {
+			return
+		}
+	}
+}
+
+// smogDesignations returns every way to designate the blanks among the
+// chosen tiles, so that together with the tiles on the board they make an
+// anagram of a word. If there are no blanks, it returns one empty
+// designation if the tiles make an anagram, and none otherwise.
+func (gen *GordonGenerator) smogDesignations() [][]tilemapping.MachineLetter {
+	word := make(tilemapping.MachineWord, 0, len(gen.smogFixed)+len(gen.smogChosen))
+	word = append(word, gen.smogFixed...)
+	word = append(word, gen.smogChosen...)
+	slices.Sort(word)
+	key := string(word.ToByteArr())
+	if d, ok := gen.smogCache[key]; ok {
+		return d
+	}
+
+	var designations [][]tilemapping.MachineLetter
+	if err := gen.smogAnagrammer.InitForMachineWord(gen.gaddag, word); err != nil {
+		gen.smogCache[key] = nil
+		return nil
+	}
+	numBlanks := 0
+	counts := make([]int, gen.letterDistribution.TileMapping().NumLetters())
+	for _, ml := range word {
+		if ml == 0 {
+			numBlanks++
+		} else {
+			counts[ml]++
+		}
+	}
+	seen := map[string]bool{}
+	remaining := make([]int, len(counts))
+	gen.smogAnagrammer.Anagram(gen.gaddag, func(w tilemapping.MachineWord) error {
+		if numBlanks == 0 {
+			designations = append(designations, nil)
+			return errFoundAnagram
+		}
+		copy(remaining, counts)
+		blanks := make([]tilemapping.MachineLetter, 0, numBlanks)
+		for _, ml := range w {
+			if remaining[ml] > 0 {
+				remaining[ml]--
+			} else {
+				blanks = append(blanks, ml)
+			}
+		}
+		slices.Sort(blanks)
+		if k := string(tilemapping.MachineWord(blanks).ToByteArr()); !seen[k] {
+			seen[k] = true
+			designations = append(designations, blanks)
+		}
+		return nil
+	})
+	gen.smogCache[key] = designations
+	return designations
+}
+
+// smogPlace places the rest of gen.smogTiles, which is sorted, on the empty
+// squares from the ith one on, in every order that the cross-sets allow.
+func (gen *GordonGenerator) smogPlace(rack *tilemapping.Rack, start, end, i int) {
+	if i == len(gen.smogEmpty) {
+		if gen.tilesPlayed <= gen.maxTileUsage && gen.allowed(start, end) {
+			gen.playRecorder(gen, rack, start, end, move.MoveTypePlay, gen.smogScore(start, end))
+		}
+		return
+	}
+	col := gen.smogEmpty[i]
+	crossSet := gen.board.GetCrossSetIdx(gen.board.GetSqIdx(gen.curRowIdx, col), gen.crossDirection())
+	for j, t := range gen.smogTiles {
+		if gen.smogUsed[j] || (j > 0 && t == gen.smogTiles[j-1] && !gen.smogUsed[j-1]) {
+			// Don't place the same tile here twice.
+			continue
+		}
+		if !crossSet.Allowed(t.Unblank()) {
+			continue
+		}
+		gen.smogUsed[j] = true
+		gen.strip[col] = t
+		gen.smogPlace(rack, start, end, i+1)
+		gen.smogUsed[j] = false
+		if gen.quitEarly {
+			break
+		}
+	}
+	gen.strip[col] = 0
+}
+
+// smogScore scores the play in gen.strip, the same way as recursiveGen.
+func (gen *GordonGenerator) smogScore(start, end int) int {
+	csDirection := gen.crossDirection()
+	baseScore, crossScores, wordMultiplier := 0, 0, 1
+	for col := start; col <= end; col++ {
+		if ml := gen.board.GetLetter(gen.curRowIdx, col); ml != 0 {
+			baseScore += gen.letterDistribution.Score(ml)
+			continue
+		}
+		sqIdx := gen.board.GetSqIdx(gen.curRowIdx, col)
+		lm := gen.board.GetLetterMultiplier(sqIdx)
+		wm := gen.board.GetWordMultiplier(sqIdx)
+		cs := gen.board.GetCrossScoreIdx(sqIdx, csDirection)
+		sml := gen.letterDistribution.Score(gen.strip[col])
+		baseScore += sml * lm
+		if gen.board.GetCrossSetIdx(sqIdx, csDirection) != board.TrivialCrossSet {
+			if wm > 1 {
+				crossScores += wm * (cs + sml)
+			} else {
+				crossScores += cs + sml*lm
+			}
+		}
+		wordMultiplier *= wm
+	}
+	score := baseScore*wordMultiplier + crossScores
+	if gen.tilesPlayed == gen.rackSize {
+		score += gen.bingoBonus
+	}
+	return score
+}
@@ -0,0 +1,106 @@
+package movegen
+
+import (
+	"testing"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/cross_set"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/variant"
+)
+
+func smogGenerator(t *testing.T, game board.VsWho) (*GordonGenerator, *board.GameBoard, *kwg.KWG) {
+	is := is.New(t)
+	gd, err := GaddagFromLexicon("NWL20")
+	is.NoErr(err)
+	k := gd.(*kwg.KWG)
+	bd := board.MakeBoard(board.CrosswordGameBoard)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	if game != "" {
+		bd.SetToGame(k.GetAlphabet(), game)
+	}
+	csgen := cross_set.AnagramCrossSetGenerator{Dist: ld, KWG: k}
+	csgen.GenerateAll(bd)
+	generator := NewGordonGenerator(gd, bd, ld)
+	generator.SetVariant(variant.VarWordSmog)
+	return generator, bd, k
+}
+
+func TestWordSmogPlaysAreValid(t *testing.T) {
+	is := is.New(t)
+	generator, bd, k := smogGenerator(t, board.VsMatt)
+	lex := kwg.Lexicon{KWG: *k}
+
+	plays := scoringPlays(generator.GenAll(tilemapping.RackFromString("AABDEL?", k.GetAlphabet()), false))
+	is.True(len(plays) > 0)
+	seen := map[string]bool{}
+	for _, m := range plays {
+		words, err := bd.FormedWords(m)
+		is.NoErr(err)
+		for _, w := range words {
+			is.True(lex.HasAnagram(w))
+		}
+		desc := m.ShortDescription()
+		is.True(!seen[desc])
+		seen[desc] = true
+	}
+}
+
+func TestWordSmogFindsClassicPlays(t *testing.T) {
+	// Every word is an anagram of itself, so every classic play is also a
+	// WordSmog play, with the same score.
+	is := is.New(t)
+	generator, bd, k := smogGenerator(t, board.VsEd)
+	rack := tilemapping.RackFromString("EIRSTU?", k.GetAlphabet())
+	smogPlays := map[string]int{}
+	for _, m := range scoringPlays(generator.GenAll(rack, false)) {
+		smogPlays[m.ShortDescription()] = m.Score()
+	}
+
+	classicBoard := bd.Copy()
+	ld := generator.letterDistribution
+	cross_set.GenAllCrossSets(classicBoard, k, ld)
+	classic := NewGordonGenerator(k, classicBoard, ld)
+	classicPlays := scoringPlays(classic.GenAll(rack, false))
+	is.True(len(classicPlays) > 0)
+	is.True(len(smogPlays) > len(classicPlays))
+	for _, m := range classicPlays {
+		score, ok := smogPlays[m.ShortDescription()]
+		is.True(ok)
+		is.Equal(score, m.Score())
+	}
+}
+
+func TestWordSmogEmptyBoard(t *testing.T) {
+	is := is.New(t)
+	generator, _, k := smogGenerator(t, "")
+	alph := k.GetAlphabet()
+	lex := kwg.Lexicon{KWG: *k}
+
+	plays := scoringPlays(generator.GenAll(tilemapping.RackFromString("AEINRST", alph), false))
+	is.True(len(plays) > 0)
+	bingos := 0
+	for _, m := range plays {
+		row, col, vertical := m.CoordsAndVertical()
+		is.True(!vertical)
+		is.Equal(row, 7)
+		is.True(col <= 7 && col+m.PlayLength() > 7)
+		is.True(lex.HasAnagram(m.Tiles()))
+		if m.TilesPlayed() == 7 {
+			bingos++
+			is.True(m.Score() > 50)
+		}
+	}
+	// Any order of the letters of a seven-letter word can be played in
+	// any of the 7 columns that cover the center square.
+	is.Equal(bingos%7, 0)
+	is.True(bingos > 0)
+	// With AtLeastOneTileMove, the search stops at the first play.
+	is.True(generator.AtLeastOneTileMove(tilemapping.RackFromString("AEINRST", alph)))
+	is.Equal(len(Filter(plays, func(m *move.Move) bool { return m.TilesPlayed() == 1 })), 0)
+}
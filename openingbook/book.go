@@ -0,0 +1,318 @@
+// Package openingbook stores simmed rankings of opening plays, so that
+// bots don't have to sim the same first- and second-turn positions over
+// and over again.
+//
+// First-turn positions are keyed by the board layout and the rack.
+// Second-turn positions are keyed by the board layout, the first play
+// (always written as a horizontal play; the board is assumed to be
+// symmetric along its diagonal) and the rack of the player on turn.
+package openingbook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/domino14/word-golib/cache"
+	"github.com/domino14/word-golib/tilemapping"
+	"lukechampine.com/frand"
+
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/montecarlo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/variant"
+)
+
+const DefaultFilename = "book.json"
+
+// DefaultTolerance is the largest difference in win probability between
+// the top book play and another book play for them to be considered
+// equally good.
+const DefaultTolerance = 0.005
+
+// Play is a single ranked play in a book position.
+type Play struct {
+	// Move is the short description of the move, such as "8D RETAINS" or
+	// "(exch QV)".
+	Move   string  `json:"move"`
+	WinPct float64 `json:"win_pct"`
+	Equity float64 `json:"equity"`
+}
+
+// Position holds the ranked plays for a single position, best first.
+type Position struct {
+	Plies      int    `json:"plies"`
+	Iterations int    `json:"iterations"`
+	Plays      []Play `json:"plays"`
+}
+
+type Book struct {
+	Lexicon   string               `json:"lexicon"`
+	Positions map[string]*Position `json:"positions"`
+}
+
+func New(lexicon string) *Book {
+	return &Book{Lexicon: lexicon, Positions: map[string]*Position{}}
+}
+
+// Read reads a book in JSON format.
+func Read(r io.Reader) (*Book, error) {
+	b := &Book{}
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, err
+	}
+	if b.Positions == nil {
+		b.Positions = map[string]*Position{}
+	}
+	return b, nil
+}
+
+// Write writes the book in JSON format.
+func (b *Book) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(b)
+}
+
+// ReadFile reads the book at the given path. It returns a new, empty book
+// if the file does not exist.
+func ReadFile(path, lexicon string) (*Book, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(lexicon), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := Read(f)
+	if err != nil {
+		return nil, err
+	}
+	if b.Lexicon != lexicon {
+		return nil, errors.New("book at " + path + " is for lexicon " + b.Lexicon)
+	}
+	return b, nil
+}
+
+// WriteFile writes the book to the given path.
+func (b *Book) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = b.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Path returns the path of the book file for the given lexicon in the
+// strategy directory.
+func Path(cfg *config.Config, lexicon, filename string) string {
+	if filename == "" {
+		filename = DefaultFilename
+	}
+	return filepath.Join(cfg.GetString(config.ConfigDataPath), "strategy", lexicon, filename)
+}
+
+// Load loads (and caches) the book file for the given lexicon from the
+// strategy directory.
+func Load(cfg *config.Config, lexicon, filename string) (*Book, error) {
+	if filename == "" {
+		filename = DefaultFilename
+	}
+	b, err := cache.Load(cfg.AllSettings(), "bookfile:"+lexicon+":"+filename, CacheLoadFunc)
+	if err != nil {
+		return nil, err
+	}
+	book, ok := b.(*Book)
+	if !ok {
+		return nil, errors.New("book not correct type")
+	}
+	return book, nil
+}
+
+func CacheLoadFunc(cfg map[string]any, key string) (interface{}, error) {
+	// Key looks like bookfile:lexicon:filename
+	fields := strings.Split(key, ":")
+	if fields[0] != "bookfile" {
+		return nil, errors.New("bookcacheloadfunc - bad cache key: " + key)
+	}
+	if len(fields) != 3 {
+		return nil, errors.New("cache key missing fields")
+	}
+	path := filepath.Join(cfg[config.ConfigDataPath].(string), "strategy", fields[1], fields[2])
+	f, _, err := cache.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// positionKey returns the book key for the game's current position, and
+// whether the board has to be transposed to match the book. It returns
+// false if the position is not one that can be in the book.
+func positionKey(g *game.Game) (string, bool, bool) {
+	layout := g.Rules().BoardName()
+	switch v := g.Rules().Variant(); v {
+	case "", variant.VarClassic, variant.VarClassicSuper:
+	default:
+		layout += "/" + string(v)
+	}
+	rack := g.RackFor(g.PlayerOnTurn()).String()
+	if g.Board().IsEmpty() {
+		return layout + " " + rack, false, true
+	}
+	if g.Turn() != 1 {
+		return "", false, false
+	}
+	row, col, vertical, word, ok := firstPlay(g)
+	if !ok {
+		return "", false, false
+	}
+	if vertical {
+		row, col = col, row
+	}
+	coords := move.ToBoardGameCoords(row, col, false)
+	return layout + " " + coords + " " + word + " " + rack, vertical, true
+}
+
+// firstPlay finds the only play on the board, if the tiles on the board
+// form a single contiguous word.
+func firstPlay(g *game.Game) (int, int, bool, string, bool) {
+	b := g.Board()
+	dim := b.Dim()
+	minRow, minCol, maxRow, maxCol := dim, dim, -1, -1
+	n := 0
+	for r := 0; r < dim; r++ {
+		for c := 0; c < dim; c++ {
+			if b.GetLetter(r, c) == 0 {
+				continue
+			}
+			n++
+			minRow, maxRow = min(minRow, r), max(maxRow, r)
+			minCol, maxCol = min(minCol, c), max(maxCol, c)
+		}
+	}
+	if n < 2 || (minRow != maxRow && minCol != maxCol) {
+		return 0, 0, false, "", false
+	}
+	vertical := minCol == maxCol
+	if (vertical && maxRow-minRow+1 != n) || (!vertical && maxCol-minCol+1 != n) {
+		return 0, 0, false, "", false
+	}
+	word := make(tilemapping.MachineWord, 0, n)
+	for i := 0; i < n; i++ {
+		if vertical {
+			word = append(word, b.GetLetter(minRow+i, minCol))
+		} else {
+			word = append(word, b.GetLetter(minRow, minCol+i))
+		}
+	}
+	return minRow, minCol, vertical, word.UserVisible(g.Alphabet()), true
+}
+
+// transposeMove transposes the coordinates of a move description.
+// Exchanges and passes are returned unchanged.
+func transposeMove(desc string) string {
+	fields := strings.Fields(desc)
+	if len(fields) != 2 || strings.HasPrefix(fields[0], "(") {
+		return desc
+	}
+	row, col, vertical := move.FromBoardGameCoords(fields[0])
+	return move.ToBoardGameCoords(col, row, !vertical) + " " + fields[1]
+}
+
+// Lookup returns the ranked book plays for the game's current position,
+// with coordinates that match the game's board, or nil if the position
+// is not in the book.
+func (b *Book) Lookup(g *game.Game) []Play {
+	key, transposed, ok := positionKey(g)
+	if !ok {
+		return nil
+	}
+	pos, ok := b.Positions[key]
+	if !ok {
+		return nil
+	}
+	plays := make([]Play, len(pos.Plays))
+	copy(plays, pos.Plays)
+	if transposed {
+		for i := range plays {
+			plays[i].Move = transposeMove(plays[i].Move)
+		}
+	}
+	return plays
+}
+
+// Contains returns whether the game's current position is in the book.
+func (b *Book) Contains(g *game.Game) bool {
+	key, _, ok := positionKey(g)
+	if !ok {
+		return false
+	}
+	_, ok = b.Positions[key]
+	return ok
+}
+
+// Add adds the simmed plays for the game's current position to the book,
+// replacing any plays already there.
+func (b *Book) Add(g *game.Game, plies, iterations int, simmed []*montecarlo.SimmedPlay) error {
+	key, transposed, ok := positionKey(g)
+	if !ok {
+		return errors.New("this position cannot be added to the book")
+	}
+	pos := &Position{Plies: plies, Iterations: iterations}
+	for _, sp := range simmed {
+		desc := strings.TrimSpace(sp.Move().ShortDescription())
+		if transposed {
+			desc = transposeMove(desc)
+		}
+		pos.Plays = append(pos.Plays, Play{
+			Move:   desc,
+			WinPct: sp.WinProb(),
+			Equity: sp.EquityMean(),
+		})
+	}
+	sort.SliceStable(pos.Plays, func(i, j int) bool {
+		if pos.Plays[i].WinPct == pos.Plays[j].WinPct {
+			return pos.Plays[i].Equity > pos.Plays[j].Equity
+		}
+		return pos.Plays[i].WinPct > pos.Plays[j].WinPct
+	})
+	b.Positions[key] = pos
+	return nil
+}
+
+// Pick picks one of the plays whose win probability is within tolerance
+// of the best one, at random. The plays must be sorted best first, as
+// returned by Lookup. If rng is nil, an unseeded generator is used.
+func Pick(plays []Play, tolerance float64, rng *rand.Rand) Play {
+	n := 1
+	for n < len(plays) && plays[0].WinPct-plays[n].WinPct <= tolerance {
+		n++
+	}
+	if rng != nil {
+		return plays[rng.IntN(n)]
+	}
+	return plays[frand.Intn(n)]
+}
+
+// FindMove returns the move among the given moves that matches the
+// description of a book play, or nil if there is none.
+func FindMove(p Play, moves []*move.Move) *move.Move {
+	for _, m := range moves {
+		if strings.TrimSpace(m.ShortDescription()) == p.Move {
+			return m
+		}
+	}
+	return nil
+}
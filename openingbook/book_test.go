@@ -0,0 +1,118 @@
+package openingbook
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+func newGame(t *testing.T) *game.Game {
+	is := is.New(t)
+	rules, err := game.NewBasicGameRules(&DefaultConfig, "NWL20", board.CrosswordGameLayout,
+		"English", game.CrossScoreAndSet, "")
+	is.NoErr(err)
+	g, err := game.NewGame(rules, []*pb.PlayerInfo{
+		{Nickname: "p1", RealName: "Player 1"},
+		{Nickname: "p2", RealName: "Player 2"},
+	})
+	is.NoErr(err)
+	g.StartGame()
+	return g
+}
+
+func mustRack(t *testing.T, g *game.Game, rack string) *tilemapping.Rack {
+	return tilemapping.RackFromString(rack, g.Alphabet())
+}
+
+func playFirst(t *testing.T, coords, word, rack, reply string) *game.Game {
+	is := is.New(t)
+	g := newGame(t)
+	is.NoErr(g.SetRackFor(0, mustRack(t, g, rack)))
+	m, err := g.CreateAndScorePlacementMove(coords, word, rack)
+	is.NoErr(err)
+	is.NoErr(g.PlayMove(m, false, 0))
+	is.NoErr(g.SetRackFor(1, mustRack(t, g, reply)))
+	return g
+}
+
+func TestFirstTurnKey(t *testing.T) {
+	is := is.New(t)
+	g := newGame(t)
+	is.NoErr(g.SetRackFor(0, mustRack(t, g, "RETAINS")))
+	key, transposed, ok := positionKey(g)
+	is.True(ok)
+	is.True(!transposed)
+	is.Equal(key, "CrosswordGame AEINRST")
+}
+
+func TestSecondTurnKeyIsCanonical(t *testing.T) {
+	is := is.New(t)
+	g1 := playFirst(t, "8G", "QI", "QIAEERT", "?ABCDEF")
+	g2 := playFirst(t, "H7", "QI", "QIAEERT", "?ABCDEF")
+	k1, t1, ok1 := positionKey(g1)
+	k2, t2, ok2 := positionKey(g2)
+	is.True(ok1 && ok2)
+	is.Equal(k1, "CrosswordGame 8G QI ?ABCDEF")
+	is.Equal(k1, k2)
+	is.True(!t1)
+	is.True(t2)
+}
+
+func TestLookupTransposes(t *testing.T) {
+	is := is.New(t)
+	book := New("NWL20")
+	book.Positions["CrosswordGame 8G QI ?ABCDEF"] = &Position{
+		Plays: []Play{{Move: "9F FAB", WinPct: 0.5}, {Move: "(exch ABC)", WinPct: 0.4}},
+	}
+	g := playFirst(t, "H7", "QI", "QIAEERT", "?ABCDEF")
+	plays := book.Lookup(g)
+	is.Equal(len(plays), 2)
+	is.Equal(plays[0].Move, "I6 FAB")
+	is.Equal(plays[1].Move, "(exch ABC)")
+	// The book itself is unchanged.
+	is.Equal(book.Positions["CrosswordGame 8G QI ?ABCDEF"].Plays[0].Move, "9F FAB")
+
+	// Not a second-turn position any more.
+	is.NoErr(g.SetRackFor(1, mustRack(t, g, "ABCDEFG")))
+	m, err := g.CreateAndScorePlacementMove("9F", "FAB", "ABCDEFG")
+	is.NoErr(err)
+	is.NoErr(g.PlayMove(m, false, 0))
+	is.True(book.Lookup(g) == nil)
+}
+
+func TestPick(t *testing.T) {
+	is := is.New(t)
+	plays := []Play{
+		{Move: "a", WinPct: 0.55}, {Move: "b", WinPct: 0.548}, {Move: "c", WinPct: 0.53},
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		seen[Pick(plays, DefaultTolerance, rng).Move] = true
+	}
+	is.Equal(seen, map[string]bool{"a": true, "b": true})
+	is.Equal(Pick(plays, 0, rng).Move, "a")
+}
+
+func TestReadWrite(t *testing.T) {
+	is := is.New(t)
+	book := New("NWL20")
+	book.Positions["CrosswordGame AEINRST"] = &Position{
+		Plies: 2, Iterations: 1000, Plays: []Play{{Move: "8D RETAINS", WinPct: 0.7, Equity: 80}},
+	}
+	var buf bytes.Buffer
+	is.NoErr(book.Write(&buf))
+	b2, err := Read(&buf)
+	is.NoErr(err)
+	is.Equal(b2, book)
+}
@@ -0,0 +1,138 @@
+package openingbook
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/rs/zerolog/log"
+
+	aiturnplayer "github.com/domino14/macondo/ai/turnplayer"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/montecarlo"
+	"github.com/domino14/macondo/move"
+)
+
+// BuildOptions configures the offline building of a book.
+type BuildOptions struct {
+	// Turn is 1 to add first-turn positions, or 2 to add second-turn
+	// positions. The first play of a second-turn position is taken from
+	// the book if possible, and is otherwise the best static play.
+	Turn int
+	// Positions is the number of random positions to sim. Positions that
+	// are already in the book are skipped, but still count.
+	Positions int
+	Plies     int
+	// NumPlays is the number of top static plays to sim per position.
+	NumPlays          int
+	Threads           int
+	StoppingCondition montecarlo.StoppingCondition
+}
+
+func (o *BuildOptions) setDefaults() {
+	if o.Plies == 0 {
+		o.Plies = 2
+	}
+	if o.NumPlays == 0 {
+		o.NumPlays = 40
+	}
+	if o.StoppingCondition == montecarlo.StopNone {
+		o.StoppingCondition = montecarlo.Stop99
+	}
+}
+
+// Build sims random positions with the given rules and adds them to the
+// book. Racks are dealt from a full bag, so more common racks are more
+// likely to be added. progress, if not nil, is called after each position.
+func (b *Book) Build(ctx context.Context, rules *game.GameRules, opts BuildOptions,
+	progress func(done int, added bool)) error {
+
+	if opts.Turn != 1 && opts.Turn != 2 {
+		return errors.New("turn must be 1 or 2")
+	}
+	opts.setDefaults()
+	leaveFile := equity.LeavesFilenameFor(rules.BoardName(), rules.Variant())
+	calc, err := equity.NewCombinedStaticCalculator(
+		rules.LexiconName(), rules.Config(), leaveFile, equity.PEGAdjustmentFilename)
+	if err != nil {
+		return err
+	}
+	calcs := []equity.EquityCalculator{calc}
+	players := []*pb.PlayerInfo{
+		{Nickname: "p1", RealName: "Player 1"},
+		{Nickname: "p2", RealName: "Player 2"},
+	}
+
+	for i := 0; i < opts.Positions; i++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		g, err := game.NewGame(rules, players)
+		if err != nil {
+			return err
+		}
+		g.StartGame()
+		player, err := aiturnplayer.NewAIStaticTurnPlayerFromGame(g, rules.Config(), calcs)
+		if err != nil {
+			return err
+		}
+		if opts.Turn == 2 {
+			if err = g.PlayMove(b.firstPlay(g, player), false, 0); err != nil {
+				return err
+			}
+		}
+		added := false
+		if !b.Contains(g) {
+			if added, err = b.simPosition(ctx, g, player, calc, opts); err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			progress(i+1, added)
+		}
+	}
+	return nil
+}
+
+// firstPlay returns the play to make on the first turn, when building
+// second-turn positions.
+func (b *Book) firstPlay(g *game.Game, player *aiturnplayer.AIStaticTurnPlayer) *move.Move {
+	moves := player.GenerateMoves(math.MaxInt)
+	if plays := b.Lookup(g); len(plays) > 0 {
+		if m := FindMove(Pick(plays, DefaultTolerance, g.Rand()), moves); m != nil {
+			return m
+		}
+	}
+	return moves[0]
+}
+
+func (b *Book) simPosition(ctx context.Context, g *game.Game, player *aiturnplayer.AIStaticTurnPlayer,
+	calc *equity.CombinedStaticCalculator, opts BuildOptions) (bool, error) {
+
+	if _, _, ok := positionKey(g); !ok {
+		// For example, if the first play was an exchange.
+		return false, nil
+	}
+	moves := player.GenerateMoves(opts.NumPlays)
+	simmer := &montecarlo.Simmer{}
+	simmer.Init(g, player.Calculators(), calc, g.Config())
+	if opts.Threads != 0 {
+		simmer.SetThreads(opts.Threads)
+	}
+	if err := simmer.PrepareSim(opts.Plies, moves); err != nil {
+		return false, err
+	}
+	simmer.SetStoppingCondition(opts.StoppingCondition)
+	if err := simmer.Simulate(ctx); err != nil {
+		return false, err
+	}
+	if ctx.Err() != nil {
+		// Don't add partial sims.
+		return false, ctx.Err()
+	}
+	log.Debug().Str("rack", g.RackLettersFor(g.PlayerOnTurn())).
+		Int("iterations", simmer.Iterations()).Msg("book-position-simmed")
+	return true, b.Add(g, opts.Plies, simmer.Iterations(), simmer.PlaysByWinProb())
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/domino14/macondo/game"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/progress"
 	"github.com/domino14/macondo/zobrist"
 )
 
@@ -101,6 +102,21 @@ func (p *PreEndgamePlay) Copy() *PreEndgamePlay {
 	}
 }
 
+// WinProb returns the fraction of the possible draws that the play wins,
+// counting a tie as half a win.
+func (p *PreEndgamePlay) WinProb() float64 {
+	p.RLock()
+	defer p.RUnlock()
+	n := 0
+	for _, o := range p.outcomesArray {
+		n += o.ct
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(p.Points) / float64(n)
+}
+
 func (p *PreEndgamePlay) stopAnalyzing() {
 	p.Lock()
 	defer p.Unlock()
@@ -326,6 +342,7 @@ type Solver struct {
 	busy             bool
 	solvingForPlayer int
 	logStream        io.Writer
+	progress         *progress.Reporter
 	solveOnlyMoves   []*move.Move
 
 	earlyCutoffOptim     bool
@@ -364,10 +381,25 @@ func (s *Solver) SetLogStream(l io.Writer) {
 	s.logStream = l
 }
 
+// SetProgressListener sends the progress of solves to l, at most once per
+// interval, and once more when the solve ends.
+func (s *Solver) SetProgressListener(l progress.Listener, interval time.Duration) {
+	s.progress = progress.NewReporter(l, interval)
+}
+
 func (s *Solver) Solve(ctx context.Context) ([]*PreEndgamePlay, error) {
 	s.busy = true
+	var final []*PreEndgamePlay
+	s.progress.Start(ctx, progress.SourcePEG)
 	defer func() {
 		s.busy = false
+		s.progress.Finish(func(e *progress.Event) {
+			e.Iterations = s.numEndgamesSolved.Load()
+			if len(final) > 0 {
+				e.Leader = final[0].Play.ShortDescription()
+				e.Value = final[0].WinProb()
+			}
+		})
 	}()
 	ts := time.Now()
 	log.Info().
@@ -386,7 +418,8 @@ func (s *Solver) Solve(ctx context.Context) ([]*PreEndgamePlay, error) {
 		s.curEndgamePlies = s.maxEndgamePlies
 	}
 
-	if s.game.RackFor(s.solvingForPlayer).NumTiles() < game.RackTileLimit {
+	rs := s.game.Rules().RuleSet()
+	if int(s.game.RackFor(s.solvingForPlayer).NumTiles()) < rs.RackSize {
 		return nil, errors.New("the rack of the player being solved for must be fully specified")
 	}
 
@@ -398,8 +431,10 @@ func (s *Solver) Solve(ctx context.Context) ([]*PreEndgamePlay, error) {
 
 	s.movegen = movegen.NewGordonGenerator(s.gaddag, s.game.Board(), s.game.Bag().LetterDistribution())
 	s.movegen.SetGenPass(true)
-	// Don't allow pre-endgame opponent to use more than 7 tiles.
-	s.movegen.SetMaxTileUsage(7)
+	s.movegen.SetRuleSet(rs)
+	s.movegen.SetVariant(s.game.Rules().Variant())
+	// Don't allow pre-endgame opponent to use more than a rack of tiles.
+	s.movegen.SetMaxTileUsage(rs.RackSize)
 	// Examine high equity plays first.
 	var moves []*move.Move
 	if len(s.solveOnlyMoves) != 0 {
@@ -461,7 +496,7 @@ func (s *Solver) Solve(ctx context.Context) ([]*PreEndgamePlay, error) {
 
 		// Fill opponent's rack for now. Ignore the "known opp rack", if any. That
 		// is handled properly later.
-		if s.game.RackFor(1-s.solvingForPlayer).NumTiles() < game.RackTileLimit {
+		if int(s.game.RackFor(1-s.solvingForPlayer).NumTiles()) < rs.RackSize {
 			_, err := s.game.SetRandomRack(1-s.solvingForPlayer, nil)
 			if err != nil {
 				return nil, err
@@ -521,6 +556,7 @@ func (s *Solver) Solve(ctx context.Context) ([]*PreEndgamePlay, error) {
 		winners, err = s.multithreadSolveGeneric(ctx, moves, logChan)
 		if err != nil {
 			if err == ErrCanceledEarly {
+				final = lastWinners
 				return lastWinners, nil
 			}
 			return winners, err
@@ -535,6 +571,7 @@ func (s *Solver) Solve(ctx context.Context) ([]*PreEndgamePlay, error) {
 	log.Info().Str("ttable-stats", s.ttable.Stats()).
 		Float64("time-elapsed-sec", time.Since(ts).Seconds()).
 		Msg("solve-returning")
+	final = winners
 	return winners, err
 }
 
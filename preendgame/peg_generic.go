@@ -15,10 +15,10 @@ import (
 	"github.com/domino14/word-golib/tilemapping"
 
 	"github.com/domino14/macondo/endgame/negamax"
-	"github.com/domino14/macondo/game"
 	"github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/progress"
 	"github.com/domino14/macondo/tinymove"
 	"github.com/domino14/macondo/tinymove/conversions"
 )
@@ -58,6 +58,7 @@ func (s *Solver) multithreadSolveGeneric(ctx context.Context, moves []*move.Move
 	winnerChan := make(chan *PreEndgamePlay)
 
 	var processed atomic.Uint32
+	depthStart := time.Now()
 
 	for t := 0; t < s.threads; t++ {
 		g.Go(func() error {
@@ -79,12 +80,25 @@ func (s *Solver) multithreadSolveGeneric(ctx context.Context, moves []*move.Move
 				if n%100 == 0 {
 					log.Info().Uint64("cutoffs", s.numCutoffs.Load()).Msgf("processed %d endgames...", n)
 				}
+				if s.progress.Due() {
+					s.progress.Update(false, func(e *progress.Event) {
+						e.Iterations = s.numEndgamesSolved.Load()
+						e.Depth = s.curEndgamePlies
+						e.Remaining = 0
+						if s.curEndgamePlies == s.maxEndgamePlies {
+							// Earlier depths are not counted, so only the
+							// last one has an estimate.
+							e.Remaining = progress.EstimateRemaining(time.Since(depthStart),
+								uint64(n), uint64(len(s.plays)))
+						}
+					})
+				}
 			}
 			return nil
 		})
 	}
 
-	numCombos := combin.NumPermutations(s.numinbag+game.RackTileLimit,
+	numCombos := combin.NumPermutations(s.numinbag+s.game.Rules().RuleSet().RackSize,
 		s.numinbag)
 
 	// The determiner of the winner.
@@ -97,6 +111,13 @@ func (s *Solver) multithreadSolveGeneric(ctx context.Context, moves []*move.Move
 			} else {
 				s.winnerSoFar = p
 			}
+			// The win probability of the leader is only a lower bound
+			// until all of its endgames are solved.
+			w := s.winnerSoFar
+			s.progress.Update(false, func(e *progress.Event) {
+				e.Leader = w.Play.ShortDescription()
+				e.Value = float64(w.Points) / float64(numCombos)
+			})
 			// e.g. if we have three known losses in 4 games, we have at most 7 possible losses.
 			ppotentialLosses := float32(numCombos) - p.Points
 			s.potentialWinnerMutex.Lock()
@@ -0,0 +1,174 @@
+// Package progress is a common way for long analyses (sims, endgames,
+// pre-endgames and inferences) to report how they are going, so that the
+// shell, the server and the wasm frontends do not each have to poll every
+// engine in its own way.
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// The sources of events.
+const (
+	SourceSim     = "sim"
+	SourceEndgame = "endgame"
+	SourcePEG     = "peg"
+	SourceInfer   = "infer"
+)
+
+// An Event is a snapshot of an analysis in progress.
+type Event struct {
+	Source string
+	// Iterations is the amount of work done so far: sim iterations,
+	// endgame nodes, pre-endgame endgames solved, or racks considered by
+	// an inference.
+	Iterations uint64
+	Elapsed    time.Duration
+	// Remaining is an estimate of the time left, or 0 if it is not known.
+	Remaining time.Duration
+	// Leader is the best play so far, and Value is how good it is: its
+	// win probability for sims and pre-endgames, and its spread for
+	// endgames. For inferences, Value is the number of inferred racks.
+	Leader string
+	Value  float64
+	// PV is the best sequence found so far, for endgames, and Depth is the
+	// deepest search completed, in plies.
+	PV    []string
+	Depth int
+	// Done is set on the last event of an analysis.
+	Done bool
+}
+
+// MarshalJSON shows durations in seconds.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Source     string   `json:"source"`
+		Iterations uint64   `json:"iterations"`
+		Elapsed    float64  `json:"elapsed"`
+		Remaining  float64  `json:"remaining,omitempty"`
+		Leader     string   `json:"leader,omitempty"`
+		Value      float64  `json:"value"`
+		PV         []string `json:"pv,omitempty"`
+		Depth      int      `json:"depth,omitempty"`
+		Done       bool     `json:"done"`
+	}{e.Source, e.Iterations, e.Elapsed.Seconds(), e.Remaining.Seconds(),
+		e.Leader, e.Value, e.PV, e.Depth, e.Done})
+}
+
+// A Listener is called with each event. Calls are never concurrent, but
+// they come from the engine's own goroutines, so a Listener should return
+// quickly.
+type Listener func(Event)
+
+// A Reporter keeps the latest event of an analysis and sends it to a
+// listener at most once per interval. A nil Reporter does nothing, so
+// engines can report without checking whether anyone is listening.
+type Reporter struct {
+	mu       sync.Mutex
+	listener Listener
+	interval time.Duration
+	start    time.Time
+	deadline time.Time
+	last     time.Time
+	event    Event
+}
+
+// NewReporter returns a Reporter for l, or nil if l is nil.
+func NewReporter(l Listener, interval time.Duration) *Reporter {
+	if l == nil {
+		return nil
+	}
+	return &Reporter{listener: l, interval: interval}
+}
+
+// Start begins a new analysis from source. If ctx has a deadline, it
+// bounds the estimates of the time remaining.
+func (r *Reporter) Start(ctx context.Context, source string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start = time.Now()
+	r.last = r.start
+	r.deadline, _ = ctx.Deadline()
+	r.event = Event{Source: source}
+}
+
+// Due returns true if an event would be sent now. Engines can use it to
+// skip work, such as finding the leader, that is only needed for events.
+func (r *Reporter) Due() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Since(r.last) >= r.interval
+}
+
+// Update changes the latest event with fn and sends it if it is due, or
+// right away if force is set. The event's Elapsed is already set when fn
+// is called, for estimating Remaining.
+func (r *Reporter) Update(force bool, fn func(e *Event)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.start.IsZero() {
+		// Not started; time from the first update.
+		r.start = now
+		r.last = now
+	}
+	r.event.Elapsed = now.Sub(r.start)
+	fn(&r.event)
+	if !force && now.Sub(r.last) < r.interval {
+		return
+	}
+	r.last = now
+	if !r.deadline.IsZero() && !r.event.Done {
+		left := max(r.deadline.Sub(now), 0)
+		if r.event.Remaining == 0 || left < r.event.Remaining {
+			r.event.Remaining = left
+		}
+	}
+	e := r.event
+	e.PV = append([]string(nil), e.PV...)
+	r.listener(e)
+}
+
+// Finish sends the last event of an analysis.
+func (r *Reporter) Finish(fn func(e *Event)) {
+	r.Update(true, func(e *Event) {
+		if fn != nil {
+			fn(e)
+		}
+		e.Remaining = 0
+		e.Done = true
+	})
+}
+
+// Last returns the latest event, whether or not it was sent.
+func (r *Reporter) Last() Event {
+	if r == nil {
+		return Event{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.event
+	e.PV = append([]string(nil), e.PV...)
+	return e
+}
+
+// EstimateRemaining guesses the time left for an analysis that has done
+// done of total units of work in elapsed time, assuming a steady rate.
+func EstimateRemaining(elapsed time.Duration, done, total uint64) time.Duration {
+	if done == 0 || done >= total {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+}
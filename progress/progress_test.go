@@ -0,0 +1,84 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestNilReporter(t *testing.T) {
+	is := is.New(t)
+	r := NewReporter(nil, time.Second)
+	is.Equal(r, nil)
+	r.Start(context.Background(), SourceSim)
+	r.Update(true, func(e *Event) { e.Iterations = 5 })
+	r.Finish(nil)
+	is.True(!r.Due())
+	is.Equal(r.Last().Iterations, uint64(0))
+}
+
+func TestReporterThrottles(t *testing.T) {
+	is := is.New(t)
+	var events []Event
+	r := NewReporter(func(e Event) { events = append(events, e) }, time.Hour)
+	r.Start(context.Background(), SourceEndgame)
+	is.True(!r.Due())
+
+	r.Update(false, func(e *Event) { e.Iterations = 10 })
+	is.Equal(len(events), 0)
+	// The event is kept even when it is not sent.
+	is.Equal(r.Last().Iterations, uint64(10))
+
+	r.Update(true, func(e *Event) {
+		e.Depth = 2
+		e.PV = append(e.PV[:0], "8D QI", "9C ZA")
+	})
+	is.Equal(len(events), 1)
+	is.Equal(events[0].Source, SourceEndgame)
+	is.Equal(events[0].Iterations, uint64(10))
+	is.Equal(events[0].PV, []string{"8D QI", "9C ZA"})
+	is.True(!events[0].Done)
+
+	r.Finish(func(e *Event) { e.PV[0] = "8D XI" })
+	is.Equal(len(events), 2)
+	is.True(events[1].Done)
+	is.Equal(events[1].Depth, 2)
+	// Listeners get their own copy of the PV.
+	is.Equal(events[0].PV[0], "8D QI")
+}
+
+func TestReporterDeadline(t *testing.T) {
+	is := is.New(t)
+	var last Event
+	r := NewReporter(func(e Event) { last = e }, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	r.Start(ctx, SourceInfer)
+	is.True(r.Due())
+
+	// An estimate longer than the time left is capped by the deadline.
+	r.Update(false, func(e *Event) { e.Remaining = time.Hour })
+	is.True(last.Remaining <= time.Minute)
+	is.True(last.Remaining > 50*time.Second)
+
+	r.Finish(nil)
+	is.Equal(last.Remaining, time.Duration(0))
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	is := is.New(t)
+	is.Equal(EstimateRemaining(10*time.Second, 0, 100), time.Duration(0))
+	is.Equal(EstimateRemaining(10*time.Second, 25, 100), 30*time.Second)
+	is.Equal(EstimateRemaining(10*time.Second, 100, 100), time.Duration(0))
+}
+
+func TestEventJSON(t *testing.T) {
+	is := is.New(t)
+	data, err := json.Marshal(Event{Source: SourceSim, Iterations: 400,
+		Elapsed: 1500 * time.Millisecond, Leader: "8H QI", Value: 0.75})
+	is.NoErr(err)
+	is.Equal(string(data), `{"source":"sim","iterations":400,"elapsed":1.5,"leader":"8H QI","value":0.75,"done":false}`)
+}
@@ -125,16 +125,16 @@ func EquityPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 
 func BingoPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 	m := moves[0]
-	return moveIsBingo(m), pb.PuzzleTag_BINGO
+	return moveIsBingo(g, m), pb.PuzzleTag_BINGO
 }
 
 func OnlyBingoPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 	tag := pb.PuzzleTag_ONLY_BINGO
-	if len(moves) == 0 || !moveIsBingo(moves[0]) {
+	if len(moves) == 0 || !moveIsBingo(g, moves[0]) {
 		return false, tag
 	}
 	for _, m := range moves[1:] {
-		if moveIsBingo(m) && m.Action() == move.MoveTypePlay {
+		if moveIsBingo(g, m) && m.Action() == move.MoveTypePlay {
 			return false, tag
 		}
 	}
@@ -143,11 +143,11 @@ func OnlyBingoPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 
 func BlankBingoPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 	m := moves[0]
-	return moveIsBingo(m) && moveContainsBlank(m), pb.PuzzleTag_BLANK_BINGO
+	return moveIsBingo(g, m) && moveContainsBlank(m), pb.PuzzleTag_BLANK_BINGO
 }
 
 func NonBingoPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
-	return !moveIsBingo(moves[0]), pb.PuzzleTag_NON_BINGO
+	return !moveIsBingo(g, moves[0]), pb.PuzzleTag_NON_BINGO
 }
 
 // XXX: Must be expanded to other languages
@@ -163,7 +163,7 @@ func PowerTilePuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 
 func BingoNineOrAbovePuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
 	m := moves[0]
-	return moveIsBingo(m) && moveLength(m) >= 9, pb.PuzzleTag_BINGO_NINE_OR_ABOVE
+	return moveIsBingo(g, m) && moveLength(m) >= 9, pb.PuzzleTag_BINGO_NINE_OR_ABOVE
 }
 
 func CELOnlyPuzzle(g *game.Game, moves []*move.Move) (bool, pb.PuzzleTag) {
@@ -263,8 +263,8 @@ func moveLength(m *move.Move) int {
 	return len(m.Tiles())
 }
 
-func moveIsBingo(m *move.Move) bool {
-	return m.TilesPlayed() == game.RackTileLimit
+func moveIsBingo(g *game.Game, m *move.Move) bool {
+	return g.Rules().RuleSet().IsBingo(m.TilesPlayed())
 }
 
 func moveContainsBlank(m *move.Move) bool {
@@ -297,10 +297,10 @@ func isPhony(k *kwg.KWG, word, va string) (bool, error) {
 		return false, err
 	}
 	var valid bool
-	switch string(va) {
-	case string(variant.VarWordSmog):
+	switch {
+	case variant.Variant(va).IsWordSmog():
 		valid = lex.HasAnagram(machineWord)
-	case string(variant.VarGmo):
+	case va == string(variant.VarGmo):
 		// TODO: should be possible to look up the word in reverse order directly instead of allocating a separate slice
 		reverse := slices.Clone(machineWord)
 		slices.Reverse(reverse)
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/domino14/word-golib/tilemapping"
 	"github.com/rs/zerolog/log"
@@ -22,6 +23,7 @@ import (
 	"github.com/domino14/macondo/game"
 	"github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/progress"
 )
 
 var ErrMoveTypeNotSupported = errors.New("opponent move type not suitable for inference")
@@ -66,6 +68,10 @@ type RangeFinder struct {
 	inferences           [][]tilemapping.MachineLetter
 
 	logStream io.Writer
+	progress  *progress.Reporter
+	// seed is used for the game copies if it is non-zero. Otherwise, if the
+	// original game is seeded, a seed is derived from it.
+	seed uint64
 }
 
 func (r *RangeFinder) Init(game *game.Game, eqCalcs []equity.EquityCalculator,
@@ -81,10 +87,29 @@ func (r *RangeFinder) SetThreads(t int) {
 	r.threads = t
 }
 
+// SetSeed makes inference repeatable for a given seed and thread count.
+// A seed of 0 turns this off.
+func (r *RangeFinder) SetSeed(seed uint64) {
+	r.seed = seed
+}
+
 func (r *RangeFinder) SetLogStream(l io.Writer) {
 	r.logStream = l
 }
 
+// SetProgressListener sends the progress of inferences to l, at most once
+// per interval, and once more when the inference ends.
+func (r *RangeFinder) SetProgressListener(l progress.Listener, interval time.Duration) {
+	r.progress = progress.NewReporter(l, interval)
+}
+
+// fillProgress sets the racks considered and inferred so far on a progress
+// event. The caller holds the iteration lock.
+func (r *RangeFinder) fillProgress(e *progress.Event) {
+	e.Iterations = uint64(r.iterationCount)
+	e.Value = float64(len(r.inferences))
+}
+
 func (r *RangeFinder) PrepareFinder(myRack []tilemapping.MachineLetter) error {
 	r.inferences = [][]tilemapping.MachineLetter{}
 	evts := r.origGame.History().Events[:r.origGame.Turn()]
@@ -155,7 +180,7 @@ func (r *RangeFinder) PrepareFinder(myRack []tilemapping.MachineLetter) error {
 		return err
 	}
 
-	if r.lastOppMove.TilesPlayed() == game.RackTileLimit {
+	if r.origGame.Rules().RuleSet().IsBingo(r.lastOppMove.TilesPlayed()) {
 		return ErrNoInformation
 	}
 	r.lastOppMoveRackTiles = []tilemapping.MachineLetter{}
@@ -172,6 +197,13 @@ func (r *RangeFinder) PrepareFinder(myRack []tilemapping.MachineLetter) error {
 	sort.Slice(r.lastOppMoveRackTiles, func(i, j int) bool {
 		return r.lastOppMoveRackTiles[i] < r.lastOppMoveRackTiles[j]
 	})
+	seed := r.seed
+	if seed == 0 {
+		seed, _ = r.origGame.DeriveSeed()
+	}
+	if seed != 0 {
+		gameCopy.SetSeed(seed)
+	}
 	gameCopy.ThrowRacksIn()
 
 	if len(myRack) > 0 {
@@ -209,6 +241,9 @@ func (r *RangeFinder) PrepareFinder(myRack []tilemapping.MachineLetter) error {
 
 	for i := 0; i < r.threads; i++ {
 		r.gameCopies = append(r.gameCopies, gameCopy.Copy())
+		if seed != 0 {
+			r.gameCopies[i].SetSeed(seed + uint64(i) + 1)
+		}
 
 		player, err := aiturnplayer.NewAIStaticTurnPlayerFromGame(r.gameCopies[i], r.origGame.Config(), r.equityCalculators)
 		if err != nil {
@@ -274,6 +309,7 @@ func (r *RangeFinder) Infer(ctx context.Context) error {
 		})
 	}
 
+	r.progress.Start(ctx, progress.SourceInfer)
 	g := errgroup.Group{}
 	var iterMutex sync.Mutex
 	for t := 0; t < r.threads; t++ {
@@ -298,6 +334,11 @@ func (r *RangeFinder) Infer(ctx context.Context) error {
 					r.inferences = append(r.inferences, inference...)
 					iterMutex.Unlock()
 				}
+				if r.progress.Due() {
+					iterMutex.Lock()
+					r.progress.Update(false, r.fillProgress)
+					iterMutex.Unlock()
+				}
 				select {
 				case v := <-syncExitChan:
 					log.Debug().Msgf("Thread %v got sync msg %v", t, v)
@@ -311,6 +352,7 @@ func (r *RangeFinder) Infer(ctx context.Context) error {
 
 	err := g.Wait()
 	log.Debug().Msgf("errgroup returned err %v", err)
+	r.progress.Finish(r.fillProgress)
 
 	if r.logStream != nil {
 		close(logDone)
@@ -0,0 +1,218 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	fontOnce            sync.Once
+	regularFnt, boldFnt *opentype.Font
+	errFontParse        error
+)
+
+// face returns a font face of the given pixel size. The Go fonts are
+// compiled in, so that no font files are needed at run time.
+func face(bold bool, size int) (font.Face, error) {
+	fontOnce.Do(func() {
+		regularFnt, errFontParse = opentype.Parse(goregular.TTF)
+		if errFontParse != nil {
+			return
+		}
+		boldFnt, errFontParse = opentype.Parse(gobold.TTF)
+	})
+	if errFontParse != nil {
+		return nil, errFontParse
+	}
+	f := regularFnt
+	if bold {
+		f = boldFnt
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size: float64(size), DPI: 72, Hinting: font.HintingFull,
+	})
+}
+
+type anchor int
+
+const (
+	anchorCenter anchor = iota
+	anchorLeft
+	anchorBottomRight
+)
+
+// pngCanvas draws text and shapes onto an image.
+type pngCanvas struct {
+	img   *image.RGBA
+	faces map[[2]int]font.Face
+}
+
+func (pc *pngCanvas) fillRect(x, y, w, h int, c color.Color) {
+	draw.Draw(pc.img, image.Rect(x, y, x+w, y+h), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func (pc *pngCanvas) strokeRect(x, y, w, h int, c color.Color) {
+	pc.fillRect(x, y, w, 1, c)
+	pc.fillRect(x, y+h-1, w, 1, c)
+	pc.fillRect(x, y, 1, h, c)
+	pc.fillRect(x+w-1, y, 1, h, c)
+}
+
+// fillCircle fills a circle, or only its ring of the given width if
+// width is positive.
+func (pc *pngCanvas) fillCircle(cx, cy, r, width int, c color.Color) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			d := x*x + y*y
+			if d > r*r || (width > 0 && d < (r-width)*(r-width)) {
+				continue
+			}
+			pc.fillRect(cx+x, cy+y, 1, 1, c)
+		}
+	}
+}
+
+// fillTriangle fills a triangle by testing every pixel in its bounding box.
+func (pc *pngCanvas) fillTriangle(pts [3][2]int, c color.Color) {
+	minX, minY, maxX, maxY := pts[0][0], pts[0][1], pts[0][0], pts[0][1]
+	for _, p := range pts[1:] {
+		minX, maxX = min(minX, p[0]), max(maxX, p[0])
+		minY, maxY = min(minY, p[1]), max(maxY, p[1])
+	}
+	edge := func(a, b [2]int, x, y int) int {
+		return (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+	}
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			e0 := edge(pts[0], pts[1], x, y)
+			e1 := edge(pts[1], pts[2], x, y)
+			e2 := edge(pts[2], pts[0], x, y)
+			if (e0 >= 0 && e1 >= 0 && e2 >= 0) || (e0 <= 0 && e1 <= 0 && e2 <= 0) {
+				pc.fillRect(x, y, 1, 1, c)
+			}
+		}
+	}
+}
+
+func (pc *pngCanvas) text(s string, x, y, size int, bold bool, a anchor, c color.Color) error {
+	key := [2]int{size, 0}
+	if bold {
+		key[1] = 1
+	}
+	f, ok := pc.faces[key]
+	if !ok {
+		var err error
+		f, err = face(bold, size)
+		if err != nil {
+			return err
+		}
+		pc.faces[key] = f
+	}
+	dr := &font.Drawer{Dst: pc.img, Src: image.NewUniform(c), Face: f}
+	width := dr.MeasureString(s)
+	m := f.Metrics()
+	switch a {
+	case anchorCenter:
+		dr.Dot = fixed.Point26_6{
+			X: fixed.I(x) - width/2,
+			Y: fixed.I(y) + (m.Ascent-m.Descent)/2,
+		}
+	case anchorLeft:
+		dr.Dot = fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y) + (m.Ascent-m.Descent)/2}
+	case anchorBottomRight:
+		dr.Dot = fixed.Point26_6{X: fixed.I(x) - width, Y: fixed.I(y)}
+	}
+	dr.DrawString(s)
+	return nil
+}
+
+func (d *Diagram) writePNG(w io.Writer, sz int) error {
+	width, height := d.diagramSize(sz)
+	pc := &pngCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		faces: map[[2]int]font.Face{},
+	}
+	defer func() {
+		for _, f := range pc.faces {
+			f.Close()
+		}
+	}()
+	pc.fillRect(0, 0, width, height, backgroundColor)
+
+	n := len(d.Squares)
+	for i := 0; i < n; i++ {
+		if err := pc.text(columnLabel(i), (i+1)*sz+sz/2, sz/2, sz*2/5, false, anchorCenter, labelColor); err != nil {
+			return err
+		}
+		if err := pc.text(fmt.Sprint(i+1), sz/2, (i+1)*sz+sz/2, sz*2/5, false, anchorCenter, labelColor); err != nil {
+			return err
+		}
+	}
+
+	for r, row := range d.Squares {
+		for c, sq := range row {
+			x, y := (c+1)*sz, (r+1)*sz
+			fill, label := squareFill(sq)
+			pc.fillRect(x, y, sz, sz, fill)
+			pc.strokeRect(x, y, sz+1, sz+1, gridColor)
+			if sq.Letter == "" {
+				if label != "" {
+					if err := pc.text(label, x+sz/2, y+sz/2, sz*3/10, false, anchorCenter, letterColor); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			letterFill := letterColor
+			if sq.Blank {
+				letterFill = blankColor
+				pc.fillCircle(x+sz/2, y+sz/2, sz*2/5, max(1, sz/30), blankColor)
+			}
+			fontSize := sz * 3 / 5
+			if len([]rune(sq.Letter)) > 1 {
+				fontSize = sz * 2 / 5
+			}
+			if err := pc.text(sq.Letter, x+sz/2, y+sz/2, fontSize, true, anchorCenter, letterFill); err != nil {
+				return err
+			}
+			if !sq.Blank {
+				if err := pc.text(fmt.Sprint(sq.Score), x+sz-sz/12, y+sz-sz/12, sz/4, false, anchorBottomRight, letterColor); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, a := range d.Arrows {
+		x1, y1, x2, y2 := a.endpoints(sz)
+		t := max(1, sz/8)
+		if a.Vertical {
+			pc.fillRect(x1-t/2, y1, t, y2-y1, arrowColor)
+		} else {
+			pc.fillRect(x1, y1-t/2, x2-x1, t, arrowColor)
+		}
+		pc.fillTriangle(a.head(sz), arrowColor)
+		pc.fillCircle(x1, y1, sz/4, 0, arrowColor)
+		if err := pc.text(a.Label, x1, y1, sz*3/10, true, anchorCenter, backgroundColor); err != nil {
+			return err
+		}
+	}
+
+	if d.Caption != "" {
+		if err := pc.text(d.Caption, sz, (n+1)*sz+sz*3/4, sz*2/5, false, anchorLeft, letterColor); err != nil {
+			return err
+		}
+	}
+	return png.Encode(w, pc.img)
+}
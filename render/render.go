@@ -0,0 +1,229 @@
+// Package render draws board diagrams as SVG or PNG images, for blog posts
+// and puzzle sheets. It only writes to an io.Writer, so it works the same
+// in the shell, the lambda and the wasm build.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/move"
+)
+
+// Format is the kind of image to write.
+type Format string
+
+const (
+	SVG Format = "svg"
+	PNG Format = "png"
+)
+
+// DefaultSquareSize is the side of a square in pixels.
+const DefaultSquareSize = 40
+
+var ErrUnknownFormat = errors.New("unknown image format; try svg or png")
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case SVG, PNG:
+		return f, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// FormatForFilename guesses the format from the extension of a filename.
+// It returns SVG if the extension is not known.
+func FormatForFilename(filename string) Format {
+	f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if err != nil {
+		return SVG
+	}
+	return f
+}
+
+// Square is one square of a diagram.
+type Square struct {
+	Bonus board.BonusSquare
+	// Letter is the tile on the square, or "" if it is empty. A blank is
+	// in lower case.
+	Letter string
+	// Score is the point value of the tile; it is 0 for a blank.
+	Score int
+	Blank bool
+	// LastPlay is true if the tile was placed by the last play.
+	LastPlay bool
+}
+
+// Arrow marks a candidate play: it runs over the squares the play covers.
+type Arrow struct {
+	Row, Col int
+	Vertical bool
+	Length   int
+	Label    string
+}
+
+// Diagram is everything that is drawn. It can be built from a game with
+// NewDiagram, or by hand.
+type Diagram struct {
+	Squares [][]Square
+	Arrows  []Arrow
+	// Caption is written under the board; it can be empty.
+	Caption string
+}
+
+// Options changes what NewDiagram puts in a diagram.
+type Options struct {
+	// Candidates are drawn as arrows, numbered in the order given.
+	Candidates []*move.Move
+	// NoLastPlay turns off the highlighting of the last play.
+	NoLastPlay bool
+	// NoCaption leaves out the scores and the rack under the board.
+	NoCaption bool
+}
+
+// NewDiagram makes a diagram of the game at its current turn.
+func NewDiagram(g *game.Game, opts Options) *Diagram {
+	d := NewBoardDiagram(g.Board(), g.Rules().LetterDistribution())
+	if !opts.NoLastPlay {
+		if evt := lastPlay(g); evt != nil {
+			d.markPlay(evt, g.Alphabet())
+		}
+	}
+	for i, m := range opts.Candidates {
+		if m.Action() != move.MoveTypePlay {
+			continue
+		}
+		row, col, vertical := m.CoordsAndVertical()
+		d.Arrows = append(d.Arrows, Arrow{
+			Row: row, Col: col, Vertical: vertical,
+			Length: m.PlayLength(),
+			Label:  fmt.Sprint(i + 1),
+		})
+	}
+	if !opts.NoCaption {
+		d.Caption = caption(g)
+	}
+	return d
+}
+
+// NewBoardDiagram makes a diagram of a board, with nothing highlighted.
+func NewBoardDiagram(bd *board.GameBoard, ld *tilemapping.LetterDistribution) *Diagram {
+	alph := ld.TileMapping()
+	dim := bd.Dim()
+	d := &Diagram{Squares: make([][]Square, dim)}
+	for r := 0; r < dim; r++ {
+		d.Squares[r] = make([]Square, dim)
+		for c := 0; c < dim; c++ {
+			sq := &d.Squares[r][c]
+			sq.Bonus = bd.GetBonus(r, c)
+			ml := bd.GetLetter(r, c)
+			if ml == 0 {
+				continue
+			}
+			sq.Letter = ml.UserVisible(alph, false)
+			sq.Blank = ml.IsBlanked()
+			sq.Score = ld.Score(ml)
+		}
+	}
+	return d
+}
+
+// lastPlay returns the last tile placement before the current turn, or nil
+// if the last play put no tiles on the board. For a game that was loaded
+// from a CGP and has no turns yet, it uses the lp opcode.
+func lastPlay(g *game.Game) *pb.GameEvent {
+	h := g.History()
+	if h == nil {
+		return nil
+	}
+	for i := g.Turn() - 1; i >= 0 && i < len(h.Events); i-- {
+		evt := h.Events[i]
+		switch evt.Type {
+		case pb.GameEvent_TILE_PLACEMENT_MOVE:
+			return evt
+		case pb.GameEvent_CHALLENGE_BONUS, pb.GameEvent_END_RACK_PTS,
+			pb.GameEvent_END_RACK_PENALTY, pb.GameEvent_TIME_PENALTY:
+			// Not plays; the play is before them.
+			continue
+		}
+		return nil
+	}
+	if g.Turn() > 0 {
+		return nil
+	}
+	lp, _ := game.CGPOpcode(h.StartingCgp, "lp")
+	fields := strings.Fields(lp)
+	if len(fields) != 2 {
+		return nil
+	}
+	evt := &pb.GameEvent{Position: fields[0], PlayedTiles: fields[1]}
+	game.CalculateCoordsFromStringPosition(evt)
+	return evt
+}
+
+// markPlay marks the squares the event put tiles on.
+func (d *Diagram) markPlay(evt *pb.GameEvent, alph *tilemapping.TileMapping) {
+	mls, err := tilemapping.ToMachineLetters(evt.PlayedTiles, alph)
+	if err != nil {
+		return
+	}
+	row, col := int(evt.Row), int(evt.Column)
+	for _, ml := range mls {
+		if row >= len(d.Squares) || col >= len(d.Squares[row]) {
+			return
+		}
+		if ml != 0 && d.Squares[row][col].Letter != "" {
+			d.Squares[row][col].LastPlay = true
+		}
+		if evt.Direction == pb.GameEvent_VERTICAL {
+			row++
+		} else {
+			col++
+		}
+	}
+}
+
+func caption(g *game.Game) string {
+	h := g.History()
+	if h == nil || len(h.Players) < 2 {
+		return ""
+	}
+	onturn := g.PlayerOnTurn()
+	parts := []string{fmt.Sprintf("Turn %d", g.Turn())}
+	for i, p := range h.Players {
+		parts = append(parts, fmt.Sprintf("%s %d", p.Nickname, g.PointsFor(i)))
+	}
+	if rack := g.RackFor(onturn).String(); rack != "" {
+		parts = append(parts, fmt.Sprintf("%s to play %s", h.Players[onturn].Nickname, rack))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Write writes the diagram in the given format. squareSize is the side of
+// a square in pixels; if it is 0, DefaultSquareSize is used.
+func (d *Diagram) Write(w io.Writer, f Format, squareSize int) error {
+	if squareSize <= 0 {
+		squareSize = DefaultSquareSize
+	}
+	switch f {
+	case SVG:
+		return d.writeSVG(w, squareSize)
+	case PNG:
+		return d.writePNG(w, squareSize)
+	}
+	return ErrUnknownFormat
+}
+
+// Render draws the game at its current turn.
+func Render(w io.Writer, g *game.Game, f Format, squareSize int, opts Options) error {
+	return NewDiagram(g, opts).Write(w, f, squareSize)
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/cgp"
+	"github.com/domino14/macondo/config"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+func testDiagram() *Diagram {
+	d := &Diagram{Squares: make([][]Square, 15)}
+	for r := range d.Squares {
+		d.Squares[r] = make([]Square, 15)
+	}
+	d.Squares[0][0].Bonus = board.Bonus3WS
+	d.Squares[7][7] = Square{Bonus: board.Bonus2WS, Letter: "F", Score: 4, LastPlay: true}
+	d.Squares[7][8] = Square{Letter: "o", Blank: true, LastPlay: true}
+	d.Squares[7][9] = Square{Letter: "O", Score: 1}
+	d.Arrows = []Arrow{{Row: 6, Col: 9, Vertical: true, Length: 3, Label: "1"}}
+	d.Caption = "Turn 1 · cesar 0 · josh 18"
+	return d
+}
+
+func TestWriteSVG(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	is.NoErr(testDiagram().Write(&buf, SVG, 0))
+	svg := buf.String()
+	is.True(strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="660" height="700"`))
+	is.True(strings.Contains(svg, ">TW</text>"))
+	is.True(strings.Contains(svg, ">F</text>"))
+	// The blank has no score, and has a circle around it.
+	is.True(strings.Contains(svg, `<circle cx="380" cy="340" r="16" fill="none" stroke="#c62828"/>`))
+	is.Equal(strings.Count(svg, `text-anchor="end"`), 2)
+	// Both tiles of the last play are highlighted, the other is not.
+	is.Equal(strings.Count(svg, `fill="#ffc107"`), 2)
+	is.True(strings.Contains(svg, `<line x1="420" y1="300" x2="420" y2="382"`))
+	is.True(strings.Contains(svg, "josh 18</text>"))
+}
+
+func TestWritePNG(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	is.NoErr(testDiagram().Write(&buf, PNG, 20))
+	img, err := png.Decode(&buf)
+	is.NoErr(err)
+	is.Equal(img.Bounds().Dx(), 330)
+	is.Equal(img.Bounds().Dy(), 350)
+	// The corner of the triple word square.
+	r, g, b, _ := img.At(22, 22).RGBA()
+	is.Equal([]uint32{r >> 8, g >> 8, b >> 8}, []uint32{0xe5, 0x39, 0x35})
+}
+
+func TestFormatForFilename(t *testing.T) {
+	is := is.New(t)
+	is.Equal(FormatForFilename("/tmp/board.PNG"), PNG)
+	is.Equal(FormatForFilename("board.svg"), SVG)
+	is.Equal(FormatForFilename("board"), SVG)
+	_, err := ParseFormat("gif")
+	is.Equal(err, ErrUnknownFormat)
+}
+
+func TestNewDiagram(t *testing.T) {
+	is := is.New(t)
+	g, err := cgp.ParseCGP(&DefaultConfig,
+		"15/15/15/15/15/15/15/7FOo5/15/15/15/15/15/15/15 ABCDEEG/HIJKLMN 0/12 1 lex NWL18; lp 8H FOo;")
+	is.NoErr(err)
+	d := NewDiagram(g.Game, Options{})
+	is.Equal(d.Squares[0][0].Bonus, board.Bonus3WS)
+	is.Equal(d.Squares[7][7], Square{Bonus: board.Bonus2WS, Letter: "F", Score: 4, LastPlay: true})
+	is.Equal(d.Squares[7][9], Square{Bonus: board.NoBonus, Letter: "o", Blank: true, LastPlay: true})
+	is.Equal(d.Squares[7][10], Square{Bonus: board.NoBonus})
+	is.Equal(d.Caption, "Turn 0 · player1 0 · player2 12 · player1 to play ABCDEEG")
+
+	d = NewDiagram(g.Game, Options{NoLastPlay: true, NoCaption: true})
+	is.True(!d.Squares[7][7].LastPlay)
+	is.Equal(d.Caption, "")
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/domino14/macondo/board"
+)
+
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	gridColor       = color.RGBA{0x9e, 0x9e, 0x9e, 0xff}
+	emptyColor      = color.RGBA{0xe8, 0xe6, 0xd9, 0xff}
+	tileColor       = color.RGBA{0xf3, 0xd9, 0xa4, 0xff}
+	lastPlayColor   = color.RGBA{0xff, 0xc1, 0x07, 0xff}
+	letterColor     = color.RGBA{0x21, 0x21, 0x21, 0xff}
+	blankColor      = color.RGBA{0xc6, 0x28, 0x28, 0xff}
+	labelColor      = color.RGBA{0x42, 0x42, 0x42, 0xff}
+	arrowColor      = color.RGBA{0x15, 0x65, 0xc0, 0xd0}
+)
+
+type bonusStyle struct {
+	fill  color.RGBA
+	label string
+}
+
+var bonusStyles = map[board.BonusSquare]bonusStyle{
+	board.Bonus4WS: {color.RGBA{0xef, 0x6c, 0x00, 0xff}, "QW"},
+	board.Bonus3WS: {color.RGBA{0xe5, 0x39, 0x35, 0xff}, "TW"},
+	board.Bonus2WS: {color.RGBA{0xf8, 0xbb, 0xd0, 0xff}, "DW"},
+	board.Bonus4LS: {color.RGBA{0x8e, 0x24, 0xaa, 0xff}, "QL"},
+	board.Bonus3LS: {color.RGBA{0x1e, 0x88, 0xe5, 0xff}, "TL"},
+	board.Bonus2LS: {color.RGBA{0xb3, 0xe5, 0xfc, 0xff}, "DL"},
+}
+
+// squareFill returns the colour of a square and the label of its bonus.
+func squareFill(sq Square) (color.RGBA, string) {
+	switch {
+	case sq.LastPlay:
+		return lastPlayColor, ""
+	case sq.Letter != "":
+		return tileColor, ""
+	}
+	if st, ok := bonusStyles[sq.Bonus]; ok {
+		return st.fill, st.label
+	}
+	return emptyColor, ""
+}
+
+// columnLabel returns the letter of a column, as in board coordinates.
+func columnLabel(col int) string {
+	return string(rune('A' + col))
+}
+
+// diagramSize returns the width and height of the image, with a margin
+// of one square for the coordinates, and a line for the caption.
+func (d *Diagram) diagramSize(sz int) (int, int) {
+	n := len(d.Squares)
+	w := (n + 1) * sz
+	h := (n + 1) * sz
+	if d.Caption != "" {
+		h += sz
+	}
+	return w + sz/2, h + sz/2
+}
+
+// endpoints returns the centre of the first square of the arrow and the
+// end of its shaft, where the head starts.
+func (a Arrow) endpoints(sz int) (x1, y1, x2, y2 int) {
+	x1, y1 = (a.Col+1)*sz+sz/2, (a.Row+1)*sz+sz/2
+	tip := (a.Length+1)*sz - sz/8 - sz/3
+	if a.Vertical {
+		return x1, y1, x1, (a.Row)*sz + tip
+	}
+	return x1, y1, (a.Col)*sz + tip, y1
+}
+
+// head returns the three corners of the arrow head; the first is its tip.
+func (a Arrow) head(sz int) [3][2]int {
+	_, _, x2, y2 := a.endpoints(sz)
+	hw := sz / 5
+	if a.Vertical {
+		return [3][2]int{{x2, y2 + sz/3}, {x2 - hw, y2}, {x2 + hw, y2}}
+	}
+	return [3][2]int{{x2 + sz/3, y2}, {x2, y2 - hw}, {x2, y2 + hw}}
+}
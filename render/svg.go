@@ -0,0 +1,85 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+)
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (d *Diagram) writeSVG(w io.Writer, sz int) error {
+	bw := bufio.NewWriter(w)
+	width, height := d.diagramSize(sz)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", width, height, hexColor(backgroundColor))
+
+	n := len(d.Squares)
+	for i := 0; i < n; i++ {
+		// Coordinates: letters across the top, numbers down the side.
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`+"\n",
+			(i+1)*sz+sz/2, sz/2, sz*2/5, hexColor(labelColor), columnLabel(i))
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%d</text>`+"\n",
+			sz/2, (i+1)*sz+sz/2, sz*2/5, hexColor(labelColor), i+1)
+	}
+
+	for r, row := range d.Squares {
+		for c, sq := range row {
+			x, y := (c+1)*sz, (r+1)*sz
+			fill, label := squareFill(sq)
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s"/>`+"\n",
+				x, y, sz, sz, hexColor(fill), hexColor(gridColor))
+			if sq.Letter == "" {
+				if label != "" {
+					fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`+"\n",
+						x+sz/2, y+sz/2, sz*3/10, hexColor(letterColor), label)
+				}
+				continue
+			}
+			letterFill := letterColor
+			if sq.Blank {
+				letterFill = blankColor
+				// A blank is drawn as a circle around its letter.
+				fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="%d" fill="none" stroke="%s"/>`+"\n",
+					x+sz/2, y+sz/2, sz*2/5, hexColor(blankColor))
+			}
+			fontSize := sz * 3 / 5
+			if len([]rune(sq.Letter)) > 1 {
+				fontSize = sz * 2 / 5
+			}
+			fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" font-weight="bold" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`+"\n",
+				x+sz/2, y+sz/2, fontSize, hexColor(letterFill), html.EscapeString(sq.Letter))
+			if !sq.Blank {
+				fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" text-anchor="end" fill="%s">%d</text>`+"\n",
+					x+sz-sz/12, y+sz-sz/12, sz/4, hexColor(letterColor), sq.Score)
+			}
+		}
+	}
+
+	for _, a := range d.Arrows {
+		x1, y1, x2, y2 := a.endpoints(sz)
+		fmt.Fprintf(bw, `<g stroke="%s" fill="%s" opacity="%.2f">`+"\n",
+			hexColor(arrowColor), hexColor(arrowColor), float64(arrowColor.A)/255)
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke-width="%d"/>`+"\n",
+			x1, y1, x2, y2, sz/8)
+		head := a.head(sz)
+		fmt.Fprintf(bw, `<polygon points="%d,%d %d,%d %d,%d"/>`+"\n",
+			head[0][0], head[0][1], head[1][0], head[1][1], head[2][0], head[2][1])
+		fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="%d"/>`+"\n", x1, y1, sz/4)
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s" stroke="none">%s</text>`+"\n",
+			x1, y1, sz*3/10, hexColor(backgroundColor), html.EscapeString(a.Label))
+		fmt.Fprintln(bw, `</g>`)
+	}
+
+	if d.Caption != "" {
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" dominant-baseline="central" fill="%s">%s</text>`+"\n",
+			sz, (n+1)*sz+sz*3/4, sz*2/5, hexColor(letterColor), html.EscapeString(d.Caption))
+	}
+	fmt.Fprintln(bw, `</svg>`)
+	return bw.Flush()
+}
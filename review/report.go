@@ -0,0 +1,148 @@
+package review
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+// Report is the result of a review.
+type Report struct {
+	// Players are the nicknames of the players, in the order of the game.
+	Players []string
+	// Turns are the reviewed turns, in the order they were played.
+	Turns []*TurnReview
+}
+
+// PlayerTotals sums up the reviewed turns of one player.
+type PlayerTotals struct {
+	Player       string
+	Turns        int
+	EquityLoss   float64
+	WinPctLoss   float64
+	MissedBingos int
+	Phonies      int
+	Blunders     int
+}
+
+// Blunders returns the turns that are blunders, worst first. They are
+// ranked by win percentage loss, and then by equity loss.
+func (r *Report) Blunders(blunderWinPct, blunderEquity float64) []*TurnReview {
+	var bl []*TurnReview
+	for _, t := range r.Turns {
+		if t.IsBlunder(blunderWinPct, blunderEquity) {
+			bl = append(bl, t)
+		}
+	}
+	slices.SortStableFunc(bl, func(a, b *TurnReview) int {
+		if c := cmp.Compare(b.WinPctLoss(), a.WinPctLoss()); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.EquityLoss, a.EquityLoss)
+	})
+	return bl
+}
+
+// Totals returns the totals of every player, in the order of the game.
+// A player with no reviewed turns has zero totals.
+func (r *Report) Totals(blunderWinPct, blunderEquity float64) []PlayerTotals {
+	totals := make([]PlayerTotals, len(r.Players))
+	for i, p := range r.Players {
+		totals[i].Player = p
+	}
+	for _, t := range r.Turns {
+		if t.PlayerIndex < 0 || t.PlayerIndex >= len(totals) {
+			continue
+		}
+		pt := &totals[t.PlayerIndex]
+		pt.Turns++
+		pt.EquityLoss += t.EquityLoss
+		pt.WinPctLoss += t.WinPctLoss()
+		if t.MissedBingo {
+			pt.MissedBingos++
+		}
+		if t.Phony {
+			pt.Phonies++
+		}
+		if t.IsBlunder(blunderWinPct, blunderEquity) {
+			pt.Blunders++
+		}
+	}
+	return totals
+}
+
+// Note returns a short description of the turn's analysis, for a GCG note.
+func (t *TurnReview) Note() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "review (%s): ", t.Method)
+	if t.Played == t.Best && t.EquityLoss == 0 {
+		sb.WriteString("best play")
+	} else {
+		fmt.Fprintf(&sb, "best was %s", t.Best)
+	}
+	if t.Method != MethodStatic {
+		fmt.Fprintf(&sb, "; win %.1f%% (best %.1f%%)", t.PlayedWinPct, t.BestWinPct)
+	}
+	if t.EquityLoss > 0 {
+		fmt.Fprintf(&sb, "; equity loss %.1f", t.EquityLoss)
+	}
+	if t.MissedBingo {
+		sb.WriteString("; missed bingo")
+	}
+	if t.Phony {
+		sb.WriteString("; phony")
+	}
+	return sb.String()
+}
+
+// String returns the report as text: the totals, and then the blunders,
+// worst first.
+func (r *Report) String() string {
+	return r.Format(DefaultBlunderWinPct, DefaultBlunderEquity)
+}
+
+// Format returns the report as text, with the given blunder thresholds.
+func (r *Report) Format(blunderWinPct, blunderEquity float64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-20s%8s%12s%12s%10s%10s%10s\n",
+		"Player", "Turns", "Eq. loss", "Win% loss", "Bingos", "Phonies", "Blunders")
+	for _, pt := range r.Totals(blunderWinPct, blunderEquity) {
+		fmt.Fprintf(&sb, "%-20s%8d%12.1f%12.1f%10d%10d%10d\n",
+			pt.Player, pt.Turns, pt.EquityLoss, pt.WinPctLoss, pt.MissedBingos, pt.Phonies, pt.Blunders)
+	}
+	blunders := r.Blunders(blunderWinPct, blunderEquity)
+	if len(blunders) == 0 {
+		sb.WriteString("\nNo blunders.\n")
+		return sb.String()
+	}
+	sb.WriteString("\nBlunders:\n")
+	fmt.Fprintf(&sb, "%-6s%-20s%-10s%-20s%-20s%10s%10s  %s\n",
+		"Event", "Player", "Rack", "Played", "Best", "Win% loss", "Eq. loss", "Method")
+	for _, t := range blunders {
+		fmt.Fprintf(&sb, "%-6d%-20s%-10s%-20s%-20s%10.1f%10.1f  %s\n",
+			t.EventIndex+1, t.Player, t.Rack, t.Played, t.Best, t.WinPctLoss(), t.EquityLoss, t.Method)
+	}
+	return sb.String()
+}
+
+// Annotate returns a copy of the history with the analysis of each
+// reviewed turn added to the note of its event.
+func (r *Report) Annotate(history *pb.GameHistory) *pb.GameHistory {
+	h := proto.Clone(history).(*pb.GameHistory)
+	for _, t := range r.Turns {
+		if t.EventIndex >= len(h.Events) {
+			continue
+		}
+		evt := h.Events[t.EventIndex]
+		if evt.Note != "" {
+			evt.Note += "\n"
+		}
+		evt.Note += t.Note()
+	}
+	return h
+}
@@ -0,0 +1,521 @@
+// Package review analyzes every turn of a finished game, and reports the
+// mistakes each player made. Turns are analyzed with a Monte Carlo sim
+// while the bag is open, with the pre-endgame solver when one tile is left
+// in the bag, and with the endgame solver after that.
+package review
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/endgame/negamax"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/montecarlo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+	"github.com/domino14/macondo/preendgame"
+)
+
+// Method is how a turn was analyzed.
+type Method string
+
+const (
+	MethodStatic     Method = "static"
+	MethodSim        Method = "sim"
+	MethodPreendgame Method = "peg"
+	MethodEndgame    Method = "endgame"
+)
+
+const (
+	DefaultCandidates   = 10
+	DefaultSimPlies     = 2
+	DefaultTurnTime     = 10 * time.Second
+	DefaultEndgamePlies = 6
+	// DefaultBlunderWinPct is how many win percentage points a play must
+	// lose to be called a blunder.
+	DefaultBlunderWinPct = 5.0
+	// DefaultBlunderEquity is how much equity a play must lose to be
+	// called a blunder, on turns that were only analyzed statically.
+	DefaultBlunderEquity = 10.0
+)
+
+type Options struct {
+	// Players are the nicknames of the players whose turns are analyzed.
+	// If empty, the turns of both players are.
+	Players []string
+	// Candidates is how many of the best static plays are simmed along
+	// with the play that was made.
+	Candidates int
+	// SimPlies is how many plies are simmed. If it is negative, turns
+	// with an open bag are only analyzed statically.
+	SimPlies int
+	// TurnTime is the most time spent on one turn.
+	TurnTime time.Duration
+	// Budget, if not zero, is the most time spent on the whole game. It
+	// is shared out among the turns that are left, so a turn can get less
+	// than TurnTime.
+	Budget       time.Duration
+	EndgamePlies int
+	Threads      int
+	// OnTurn, if set, is called after every turn is analyzed.
+	OnTurn func(*TurnReview)
+}
+
+func (o *Options) setDefaults() {
+	if o.Candidates == 0 {
+		o.Candidates = DefaultCandidates
+	}
+	if o.SimPlies == 0 {
+		o.SimPlies = DefaultSimPlies
+	}
+	if o.TurnTime == 0 {
+		o.TurnTime = DefaultTurnTime
+	}
+	if o.EndgamePlies == 0 {
+		o.EndgamePlies = DefaultEndgamePlies
+	}
+	if o.Threads == 0 {
+		o.Threads = max(1, runtime.NumCPU())
+	}
+}
+
+// TurnReview is the analysis of one turn.
+type TurnReview struct {
+	// EventIndex is the index of the turn's event in the game history.
+	EventIndex  int
+	PlayerIndex int
+	Player      string
+	Rack        string
+	Played      string
+	Best        string
+	Method      Method
+	// Phony is true if the play was a phony that came off the board. It
+	// is analyzed as a pass.
+	Phony bool
+	// EquityLoss is how much equity the play gave up against the best
+	// play; for an endgame, it is spread. It is never negative.
+	EquityLoss float64
+	// PlayedWinPct and BestWinPct are win percentages, from 0 to 100.
+	// They are zero for a turn that was only analyzed statically.
+	PlayedWinPct float64
+	BestWinPct   float64
+	MissedBingo  bool
+}
+
+// WinPctLoss is how many win percentage points the play gave up.
+func (t *TurnReview) WinPctLoss() float64 {
+	return max(0, t.BestWinPct-t.PlayedWinPct)
+}
+
+// IsBlunder returns whether the turn lost at least blunderWinPct win
+// percentage points, or, for a static analysis, blunderEquity equity.
+func (t *TurnReview) IsBlunder(blunderWinPct, blunderEquity float64) bool {
+	if t.Method == MethodStatic {
+		return t.EquityLoss >= blunderEquity
+	}
+	return t.WinPctLoss() >= blunderWinPct
+}
+
+type reviewer struct {
+	cfg     *config.Config
+	opts    Options
+	history *pb.GameHistory
+	player  *bot.BotTurnPlayer
+	gd      *kwg.KWG
+	calcs   []equity.EquityCalculator
+}
+
+// Review analyzes the turns of a game. If ctx is canceled, the turns
+// analyzed so far are returned in the report, along with the error.
+func Review(ctx context.Context, cfg *config.Config, history *pb.GameHistory, opts Options) (*Report, error) {
+	if len(history.Players) != 2 {
+		return nil, errors.New("only two-player games can be reviewed")
+	}
+	opts.setDefaults()
+	// Work on a copy, so that the caller's history is not changed.
+	history = proto.Clone(history).(*pb.GameHistory)
+	boardLayout, ldName, va := game.HistoryToVariant(history)
+	rules, err := game.NewBasicGameRules(cfg, history.Lexicon, boardLayout, ldName, game.CrossScoreAndSet, va)
+	if err != nil {
+		return nil, err
+	}
+	g, err := game.NewFromHistory(history, rules, 0)
+	if err != nil {
+		return nil, err
+	}
+	conf := &bot.BotConfig{Config: *cfg, LeavesFile: equity.LeavesFilenameFor(boardLayout, va)}
+	p, err := bot.NewBotTurnPlayerFromGame(g, conf, pb.BotRequest_HASTY_BOT)
+	if err != nil {
+		return nil, err
+	}
+	gd, err := kwg.Get(cfg.AllSettings(), p.LexiconName())
+	if err != nil {
+		return nil, err
+	}
+	c, err := equity.NewCombinedStaticCalculator(p.LexiconName(), cfg,
+		equity.LeavesFilenameFor(boardLayout, va), equity.PEGAdjustmentFilename)
+	if err != nil {
+		return nil, err
+	}
+	r := &reviewer{cfg: cfg, opts: opts, history: history, player: p, gd: gd,
+		calcs: []equity.EquityCalculator{c}}
+
+	report := &Report{}
+	for _, pl := range history.Players {
+		report.Players = append(report.Players, pl.Nickname)
+	}
+	turns := r.turnsToReview()
+	start := time.Now()
+	for i, idx := range turns {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		turnTime := opts.TurnTime
+		if opts.Budget > 0 {
+			left := opts.Budget - time.Since(start)
+			if left <= 0 {
+				return report, errors.New("ran out of time before reviewing every turn")
+			}
+			turnTime = min(turnTime, left/time.Duration(len(turns)-i))
+		}
+		tctx, cancel := context.WithTimeout(ctx, turnTime)
+		tr, err := r.reviewTurn(tctx, idx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			return report, err
+		}
+		report.Turns = append(report.Turns, tr)
+		if opts.OnTurn != nil {
+			opts.OnTurn(tr)
+		}
+	}
+	return report, nil
+}
+
+// turnsToReview returns the indexes of the events to review: the plays,
+// exchanges and passes of the chosen players, with a known rack.
+func (r *reviewer) turnsToReview() []int {
+	var turns []int
+	for idx, evt := range r.history.Events {
+		switch evt.Type {
+		case pb.GameEvent_TILE_PLACEMENT_MOVE, pb.GameEvent_EXCHANGE, pb.GameEvent_PASS:
+		default:
+			continue
+		}
+		if evt.Rack == "" {
+			continue
+		}
+		nick := r.history.Players[evt.PlayerIndex].Nickname
+		if len(r.opts.Players) > 0 && !slices.ContainsFunc(r.opts.Players, func(p string) bool {
+			return strings.EqualFold(p, nick)
+		}) {
+			continue
+		}
+		turns = append(turns, idx)
+	}
+	return turns
+}
+
+func (r *reviewer) reviewTurn(ctx context.Context, idx int) (*TurnReview, error) {
+	p := r.player
+	evt := r.history.Events[idx]
+	if err := p.PlayToTurn(idx); err != nil {
+		return nil, err
+	}
+	tr := &TurnReview{
+		EventIndex:  idx,
+		PlayerIndex: int(evt.PlayerIndex),
+		Player:      r.history.Players[evt.PlayerIndex].Nickname,
+		Rack:        evt.Rack,
+	}
+	played, err := p.MoveFromEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+	if idx+1 < len(r.history.Events) && r.history.Events[idx+1].Type == pb.GameEvent_PHONY_TILES_RETURNED {
+		tr.Phony = true
+		tr.Played = played.ShortDescription()
+		// The tiles came back; all the play did was use up the turn.
+		played = move.NewPassMove(p.RackFor(p.PlayerOnTurn()).TilesOn(), p.Alphabet())
+	}
+
+	rs := p.Rules().RuleSet()
+	unseen := int(p.RackFor(p.NextPlayer()).NumTiles()) + p.Bag().TilesRemaining()
+	switch {
+	case unseen <= rs.RackSize:
+		// Every unseen tile must be on the opponent's rack.
+		p.ThrowRacksInFor(p.NextPlayer())
+		if _, err := p.SetRandomRack(p.NextPlayer(), nil); err != nil {
+			return nil, err
+		}
+		err = r.endgame(ctx, tr, played)
+	case unseen == rs.RackSize+1 && int(p.RackFor(p.PlayerOnTurn()).NumTiles()) == rs.RackSize:
+		err = r.preendgame(ctx, tr, played)
+	case r.opts.SimPlies > 0:
+		err = r.sim(ctx, tr, played)
+	default:
+		err = r.static(tr, played)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tr.Played == "" {
+		tr.Played = played.ShortDescription()
+	}
+	return tr, nil
+}
+
+// candidates returns the best static plays, and the played move, which
+// is added to them if it is not there already. The played move is given
+// its static equity.
+func (r *reviewer) candidates(played *move.Move) ([]*move.Move, *move.Move) {
+	p := r.player
+	plays := p.GenerateMoves(math.MaxInt)
+	var found *move.Move
+	for _, m := range plays {
+		if samePlay(m, played) {
+			found = m
+			break
+		}
+	}
+	n := min(r.opts.Candidates, len(plays))
+	cands := slices.Clone(plays[:n])
+	if found == nil {
+		p.AssignEquity([]*move.Move{played}, p.Board(), p.Bag(), p.RackFor(p.NextPlayer()))
+		found = played
+	}
+	if !slices.Contains(cands, found) {
+		cands = append(cands, found)
+	}
+	return cands, found
+}
+
+func (r *reviewer) static(tr *TurnReview, played *move.Move) error {
+	cands, found := r.candidates(played)
+	best := cands[0]
+	tr.Method = MethodStatic
+	tr.Best = best.ShortDescription()
+	tr.EquityLoss = max(0, best.Equity()-found.Equity())
+	tr.MissedBingo = isBingo(r.player, best) && !isBingo(r.player, found)
+	return nil
+}
+
+func (r *reviewer) sim(ctx context.Context, tr *TurnReview, played *move.Move) error {
+	p := r.player
+	cands, found := r.candidates(played)
+	simmer := &montecarlo.Simmer{}
+	simmer.Init(p.Game, r.calcs, r.calcs[0].(*equity.CombinedStaticCalculator), r.cfg)
+	simmer.SetThreads(r.opts.Threads)
+	if err := simmer.PrepareSim(r.opts.SimPlies, cands); err != nil {
+		return err
+	}
+	simmer.SetStoppingCondition(montecarlo.Stop99)
+	if err := simmer.Simulate(ctx); err != nil {
+		return err
+	}
+	plays := simmer.PlaysByWinProb()
+	best := plays[0]
+	var mine *montecarlo.SimmedPlay
+	for _, sp := range plays {
+		if sp.Move() == found {
+			mine = sp
+		}
+	}
+	if mine == nil {
+		return errors.New("the played move was not simmed")
+	}
+	tr.Method = MethodSim
+	tr.Best = best.Move().ShortDescription()
+	tr.BestWinPct = 100 * best.WinProb()
+	tr.PlayedWinPct = 100 * mine.WinProb()
+	tr.EquityLoss = max(0, best.EquityMean()-mine.EquityMean())
+	tr.MissedBingo = isBingo(p, best.Move()) && !isBingo(p, found)
+	return nil
+}
+
+func (r *reviewer) preendgame(ctx context.Context, tr *TurnReview, played *move.Move) error {
+	p := r.player
+	cands, found := r.candidates(played)
+	solver := &preendgame.Solver{}
+	if err := solver.Init(p.Game, r.gd); err != nil {
+		return err
+	}
+	solver.SetThreads(r.opts.Threads)
+	solver.SetEndgamePlies(min(4, r.opts.EndgamePlies))
+	// Every candidate must be solved in full to compare them.
+	solver.SetEarlyCutoffOptim(false)
+	solver.SetSolveOnly(cands)
+	plays, err := solver.Solve(ctx)
+	if err != nil {
+		return err
+	}
+	if len(plays) == 0 {
+		return errors.New("the pre-endgame found no plays")
+	}
+	best := plays[0]
+	var mine *preendgame.PreEndgamePlay
+	for _, pp := range plays {
+		if pp.Play == found {
+			mine = pp
+		}
+	}
+	if mine == nil {
+		// Without the played move's win percentage, the turn would look
+		// like it lost all of the best one's.
+		log.Debug().Str("played", found.ShortDescription()).Msg("review-peg-missed-played-move")
+		return r.static(tr, played)
+	}
+	tr.Method = MethodPreendgame
+	tr.Best = best.Play.ShortDescription()
+	tr.BestWinPct = 100 * best.WinProb()
+	tr.PlayedWinPct = 100 * mine.WinProb()
+	tr.EquityLoss = max(0, best.Play.Equity()-found.Equity())
+	tr.MissedBingo = isBingo(p, best.Play) && !isBingo(p, found)
+	return nil
+}
+
+func (r *reviewer) endgame(ctx context.Context, tr *TurnReview, played *move.Move) error {
+	p := r.player
+	deadline, ok := ctx.Deadline()
+	half := ctx
+	if ok {
+		var cancel context.CancelFunc
+		half, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		defer cancel()
+	}
+	bestVal, seq, err := r.solveEndgame(half, p.Game)
+	if err != nil {
+		return err
+	}
+	if len(seq) == 0 {
+		return errors.New("the endgame found no plays")
+	}
+	playedVal, err := r.endgameValue(ctx, played)
+	if err != nil {
+		return err
+	}
+	spread := p.SpreadFor(p.PlayerOnTurn())
+	tr.Method = MethodEndgame
+	tr.Best = seq[0].ShortDescription()
+	tr.BestWinPct = winPct(spread + bestVal)
+	tr.PlayedWinPct = winPct(spread + playedVal)
+	tr.EquityLoss = float64(max(0, bestVal-playedVal))
+	tr.MissedBingo = isBingo(p, seq[0]) && !isBingo(p, played)
+	return nil
+}
+
+// endgameValue returns how much spread the player on turn gains from the
+// rest of the game after making the given move, if both sides play their
+// best from then on.
+func (r *reviewer) endgameValue(ctx context.Context, m *move.Move) (int, error) {
+	g := r.player.Game.Copy()
+	onturn := g.PlayerOnTurn()
+	before := g.SpreadFor(onturn)
+	if err := g.PlayMove(m, false, 0); err != nil {
+		return 0, err
+	}
+	gained := g.SpreadFor(onturn) - before
+	if g.Playing() == pb.PlayState_GAME_OVER {
+		return gained, nil
+	}
+	oppVal, _, err := r.solveEndgame(ctx, g)
+	if err != nil {
+		return 0, err
+	}
+	return gained - oppVal, nil
+}
+
+// solveEndgame returns the spread the player on turn gains from the best
+// sequence, and the sequence.
+func (r *reviewer) solveEndgame(ctx context.Context, g *game.Game) (int, []*move.Move, error) {
+	gc := g.Copy()
+	gc.SetBackupMode(game.SimulationMode)
+	gc.SetStateStackLength(r.opts.EndgamePlies + 1)
+	gen := movegen.NewGordonGenerator(r.gd, gc.Board(), gc.Rules().LetterDistribution())
+	solver := &negamax.Solver{}
+	if err := solver.Init(gen, gc); err != nil {
+		return 0, nil, err
+	}
+	solver.SetThreads(min(r.opts.Threads, negamax.MaxLazySMPThreads))
+	v, seq, err := solver.Solve(ctx, r.opts.EndgamePlies)
+	if err != nil {
+		return 0, nil, err
+	}
+	log.Debug().Int16("val", v).Int("seq-len", len(seq)).Msg("review-endgame-solved")
+	return int(v), seq, nil
+}
+
+func winPct(finalSpread int) float64 {
+	switch {
+	case finalSpread > 0:
+		return 100
+	case finalSpread == 0:
+		return 50
+	}
+	return 0
+}
+
+func isBingo(p *bot.BotTurnPlayer, m *move.Move) bool {
+	return m.Action() == move.MoveTypePlay && p.Rules().RuleSet().IsBingo(m.TilesPlayed())
+}
+
+// samePlay returns whether two moves are the same play. A one-tile play
+// can be written across or down, so tile placements are compared by the
+// squares they cover.
+func samePlay(a, b *move.Move) bool {
+	if a.Action() != b.Action() || a.Score() != b.Score() || a.TilesPlayed() != b.TilesPlayed() {
+		return false
+	}
+	if !sameTiles(a.Leave(), b.Leave()) {
+		return false
+	}
+	if a.Action() != move.MoveTypePlay {
+		return true
+	}
+	return slices.Equal(placedTiles(a), placedTiles(b))
+}
+
+func sameTiles(a, b []tilemapping.MachineLetter) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+type placedTile struct {
+	row, col int
+	tile     tilemapping.MachineLetter
+}
+
+func placedTiles(m *move.Move) []placedTile {
+	row, col, vertical := m.CoordsAndVertical()
+	var placed []placedTile
+	for _, t := range m.Tiles() {
+		if t != 0 {
+			placed = append(placed, placedTile{row, col, t})
+		}
+		if vertical {
+			row++
+		} else {
+			col++
+		}
+	}
+	return placed
+}
@@ -0,0 +1,75 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+)
+
+func testReport() *Report {
+	return &Report{
+		Players: []string{"cesar", "josh"},
+		Turns: []*TurnReview{
+			{EventIndex: 0, PlayerIndex: 0, Player: "cesar", Rack: "AEINRST", Played: "8D RETAINS",
+				Best: "8D RETAINS", Method: MethodSim, PlayedWinPct: 60, BestWinPct: 60},
+			{EventIndex: 1, PlayerIndex: 1, Player: "josh", Rack: "ADEEGIL", Played: "9A GLIDE",
+				Best: "I3 ELEGIAC", Method: MethodSim, PlayedWinPct: 30, BestWinPct: 45,
+				EquityLoss: 40.5, MissedBingo: true},
+			{EventIndex: 2, PlayerIndex: 0, Player: "cesar", Rack: "QUV", Played: "-QV",
+				Best: "2C QUA", Method: MethodStatic, EquityLoss: 12},
+			{EventIndex: 3, PlayerIndex: 1, Player: "josh", Rack: "EEE", Played: "-EEE",
+				Best: "1A EE", Method: MethodEndgame, PlayedWinPct: 0, BestWinPct: 100,
+				EquityLoss: 8},
+			{EventIndex: 4, PlayerIndex: 0, Player: "cesar", Rack: "ABC", Played: "3A CAB",
+				Best: "3B CAB", Method: MethodSim, PlayedWinPct: 40, BestWinPct: 42,
+				EquityLoss: 20},
+		},
+	}
+}
+
+func TestBlunders(t *testing.T) {
+	is := is.New(t)
+	bl := testReport().Blunders(DefaultBlunderWinPct, DefaultBlunderEquity)
+	is.Equal(len(bl), 3)
+	// The endgame turn lost the most win percentage; the static turn lost
+	// none, so it comes last.
+	is.Equal(bl[0].EventIndex, 3)
+	is.Equal(bl[1].EventIndex, 1)
+	is.Equal(bl[2].EventIndex, 2)
+}
+
+func TestTotals(t *testing.T) {
+	is := is.New(t)
+	totals := testReport().Totals(DefaultBlunderWinPct, DefaultBlunderEquity)
+	is.Equal(totals, []PlayerTotals{
+		{Player: "cesar", Turns: 3, EquityLoss: 32, WinPctLoss: 2, Blunders: 1},
+		{Player: "josh", Turns: 2, EquityLoss: 48.5, WinPctLoss: 115, MissedBingos: 1, Blunders: 2},
+	})
+}
+
+func TestAnnotate(t *testing.T) {
+	is := is.New(t)
+	h := &pb.GameHistory{Events: make([]*pb.GameEvent, 5)}
+	for i := range h.Events {
+		h.Events[i] = &pb.GameEvent{}
+	}
+	h.Events[1].Note = "ouch"
+	ann := testReport().Annotate(h)
+	is.Equal(h.Events[1].Note, "ouch")
+	is.Equal(ann.Events[0].Note, "review (sim): best play; win 60.0% (best 60.0%)")
+	is.Equal(ann.Events[1].Note,
+		"ouch\nreview (sim): best was I3 ELEGIAC; win 30.0% (best 45.0%); equity loss 40.5; missed bingo")
+	is.Equal(ann.Events[2].Note, "review (static): best was 2C QUA; equity loss 12.0")
+}
+
+func TestFormat(t *testing.T) {
+	is := is.New(t)
+	out := testReport().String()
+	is.True(strings.Contains(out, "Blunders:"))
+	is.True(strings.Index(out, "-EEE") < strings.Index(out, "9A GLIDE"))
+	is.True(!strings.Contains(out, "3A CAB"))
+	is.True(strings.Contains((&Report{Players: []string{"a", "b"}}).String(), "No blunders."))
+}
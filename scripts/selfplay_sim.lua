@@ -0,0 +1,32 @@
+-- play a game against itself, simming the top plays of each turn, and
+-- print what each sim thought of the play it chose.
+-- usage: script scripts/selfplay_sim.lua
+
+macondo_new()
+
+macondo_on('sim', function(iterations, plays)
+    print(string.format('%d iterations, leading: %s (%.1f%%)',
+        iterations, plays[1].move, 100 * plays[1].win_prob))
+    -- stop early once the leader is clear.
+    if #plays > 1 and plays[1].win_prob - plays[1].win_prob_err >
+        plays[2].win_prob + plays[2].win_prob_err then
+        return false
+    end
+end)
+
+macondo_on('move', function(m, state)
+    local p1, p2 = state.players[1], state.players[2]
+    print(string.format('played %s for %d; %s %d - %s %d, %d in bag',
+        m.move, m.score, p1.nickname, p1.score, p2.nickname, p2.score,
+        state.tiles_in_bag))
+end)
+
+while macondo_state().playing do
+    local moves = macondo_moves(10)
+    if macondo_state().tiles_in_bag > 0 and #moves > 1 then
+        local results = macondo_simulate('-plies 2 -seconds 10 -interval 2')
+        macondo_commit('#' .. results[1].index)
+    else
+        macondo_commit('#1')
+    end
+end
@@ -0,0 +1,8 @@
+-- print the hooks of the likeliest 7-letter words, in order of probability.
+-- usage: script scripts/study_hooks.lua
+for _, a in ipairs(macondo_problist(7, 1, 50)) do
+  for _, word in ipairs(a.words) do
+    local front, back = macondo_hooks(word)
+    print(string.format("%4d %8s %s %s", a.rank, front, word, back))
+  end
+end
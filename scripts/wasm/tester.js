@@ -77,6 +77,18 @@ async function thingy() {
   let end = +new Date();
   console.log(lol.simState(analyzer));
   console.log('time', end-start, 'milliseconds')
+
+  // Inference needs the history of the position.
+  await lol.analyzerAnalyze(analyzer, `{
+    "version": 2,
+    "onturn": 1,
+    "size": 15,
+    "rack": "EINRSTZ",
+    "lexicon": "CSW21",
+    "history": [{"Player": 0, "Move": "8D HELLO"}]}`);
+  const inferences = await lol.infer(analyzer, '{"time": 3}',
+    (e) => console.log('progress', e));
+  console.log('inferences', inferences);
 }
 
 thingy();
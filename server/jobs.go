@@ -0,0 +1,295 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/analyzer"
+	"github.com/domino14/macondo/montecarlo"
+	"github.com/domino14/macondo/progress"
+)
+
+// The kinds of job. Each has the methods <kind>.start, <kind>.stop and
+// <kind>.status.
+const (
+	JobSim     = "sim"
+	JobEndgame = "endgame"
+	JobPEG     = "peg"
+	JobInfer   = "infer"
+)
+
+const (
+	defaultSimPlies = 2
+	// progressEventInterval is how often jobs record their progress. It
+	// is shorter than the shortest interval of GET /progress.
+	progressEventInterval = minProgressInterval / 2
+)
+
+// A job is a long analysis of a session's game, run in its own goroutine.
+// Its err, result and end are set before done is closed.
+type job struct {
+	kind   string
+	start  time.Time
+	end    time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	result any
+	// progress returns the result so far, for the jobs that have one.
+	progress func() any
+
+	mu    sync.Mutex
+	event *progress.Event
+}
+
+// A progressSource is an engine that reports its progress.
+type progressSource interface {
+	SetProgressListener(l progress.Listener, interval time.Duration)
+}
+
+// observe records the latest progress event of a job.
+func (j *job) observe(e progress.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.event = &e
+}
+
+func (j *job) running() bool {
+	select {
+	case <-j.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (j *job) status() *JobStatus {
+	st := &JobStatus{Kind: j.kind, Running: j.running()}
+	j.mu.Lock()
+	st.Progress = j.event
+	j.mu.Unlock()
+	if st.Running {
+		st.Elapsed = time.Since(j.start).Seconds()
+		if j.progress != nil {
+			st.Result = j.progress()
+		}
+		return st
+	}
+	st.Elapsed = j.end.Sub(j.start).Seconds()
+	st.Result = j.result
+	if j.err != nil {
+		st.Error = j.err.Error()
+	}
+	return st
+}
+
+// startJob runs fn in its own goroutine, cancelling it after maxSeconds if
+// that is positive. The job records the progress events of src.
+func (s *Session) startJob(kind string, maxSeconds int, src progressSource, progress func() any,
+	fn func(ctx context.Context) (any, error)) *JobStatus {
+
+	ctx, cancel := analyzer.WithMaxTime(context.Background(), maxSeconds)
+	j := &job{kind: kind, start: time.Now(), cancel: cancel, done: make(chan struct{}),
+		progress: progress}
+	src.SetProgressListener(j.observe, progressEventInterval)
+	s.job = j
+	go func() {
+		defer close(j.done)
+		j.result, j.err = fn(ctx)
+		j.end = time.Now()
+		cancel()
+	}()
+	return j.status()
+}
+
+// currentJob returns the session's job if it is of the given kind.
+func (s *Session) currentJob(kind string) (*job, error) {
+	if s.job == nil || s.job.kind != kind {
+		return nil, errorf(CodeFailed, "no %s has been started", kind)
+	}
+	return s.job, nil
+}
+
+func (s *Session) jobStatus(kind string) (*JobStatus, error) {
+	j, err := s.currentJob(kind)
+	if err != nil {
+		return nil, err
+	}
+	return j.status(), nil
+}
+
+// stopJob cancels a job and waits for it to finish.
+func (s *Session) stopJob(kind string) (*JobStatus, error) {
+	j, err := s.currentJob(kind)
+	if err != nil {
+		return nil, err
+	}
+	if !j.running() {
+		return nil, errorf(CodeFailed, "the %s is not running", kind)
+	}
+	j.cancel()
+	<-j.done
+	return j.status(), nil
+}
+
+func (s *Session) startSim(p *SimParams) (*JobStatus, error) {
+	if err := s.needGame(); err != nil {
+		return nil, err
+	}
+	if err := s.busy(); err != nil {
+		return nil, err
+	}
+	if len(s.plays) == 0 {
+		return nil, errors.New("please generate some plays first")
+	}
+	stop := montecarlo.StopNone
+	switch p.Stop {
+	case 0:
+	case 95:
+		stop = montecarlo.Stop95
+	case 98:
+		stop = montecarlo.Stop98
+	case 99:
+		stop = montecarlo.Stop99
+	default:
+		return nil, errorf(CodeInvalidParams, "only allowed values are 95, 98, and 99 for stopping condition")
+	}
+	if stop == montecarlo.StopNone && p.MaxSeconds <= 0 {
+		return nil, errorf(CodeInvalidParams, "a sim needs a stop condition or max_seconds")
+	}
+	plies := p.Plies
+	if plies == 0 {
+		plies = defaultSimPlies
+	}
+	if p.Threads != 0 {
+		s.simmer.SetThreads(p.Threads)
+	}
+	s.simmer.SetSeed(p.Seed)
+	if err := s.simmer.PrepareSim(plies, s.plays); err != nil {
+		return nil, err
+	}
+	s.simmer.SetStoppingCondition(stop)
+	if p.OppRack != "" {
+		r, err := tilemapping.ToMachineLetters(strings.ToUpper(p.OppRack), s.game.Alphabet())
+		if err != nil {
+			return nil, err
+		}
+		s.simmer.SetKnownOppRack(r)
+	}
+	simmer := s.simmer
+	result := func() any {
+		res := &SimResult{Iterations: simmer.Iterations(), Plays: []SimPlay{}}
+		for _, sp := range simmer.PlaysByWinProb() {
+			res.Plays = append(res.Plays, SimPlay{
+				Play:         toPlay(sp.Move()),
+				WinProb:      sp.WinProb(),
+				WinProbErr:   sp.WinProbStdErr(),
+				SimEquity:    sp.EquityMean(),
+				SimEquityErr: sp.EquityStdErr(),
+				Ignored:      sp.Ignored(),
+			})
+		}
+		return res
+	}
+	return s.startJob(JobSim, p.MaxSeconds, simmer, result, func(ctx context.Context) (any, error) {
+		err := simmer.Simulate(ctx)
+		return result(), err
+	}), nil
+}
+
+func (s *Session) startEndgame(p *EndgameParams) (*JobStatus, error) {
+	if err := s.needGame(); err != nil {
+		return nil, err
+	}
+	if err := s.busy(); err != nil {
+		return nil, err
+	}
+	// The endgame is solved on a copy of the game, so the session's game
+	// can still be read while it runs.
+	solve, err := analyzer.NewEndgameSolve(s.cfg, s.game.Game, analyzer.EndgameOptions{
+		Plies: p.Plies, Threads: p.Threads, FirstWin: p.FirstWin})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startJob(JobEndgame, p.MaxSeconds, solve, nil, func(ctx context.Context) (any, error) {
+		spread, finalSpread, seq, err := solve.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res := &EndgameResult{Spread: spread, FinalSpread: finalSpread, Sequence: []Play{}}
+		for _, m := range seq {
+			res.Sequence = append(res.Sequence, toPlay(m))
+		}
+		return res, nil
+	}), nil
+}
+
+func (s *Session) startPEG(p *PEGParams) (*JobStatus, error) {
+	if err := s.needGame(); err != nil {
+		return nil, err
+	}
+	if err := s.busy(); err != nil {
+		return nil, err
+	}
+	solve, err := analyzer.NewPEGSolve(s.cfg, s.game, analyzer.PEGOptions{
+		EndgamePlies: p.EndgamePlies, Threads: p.Threads, MaxSolutions: p.MaxSolutions,
+		OppRack: p.OppRack, EarlyCutoff: p.EarlyCutoff, SkipLoss: p.SkipLoss})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startJob(JobPEG, p.MaxSeconds, solve, nil, func(ctx context.Context) (any, error) {
+		plays, err := solve.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res := &PEGResult{Plays: []PEGPlay{}}
+		for _, pp := range plays {
+			res.Plays = append(res.Plays, PEGPlay{
+				Play:    toPlay(pp.Play),
+				WinProb: pp.WinProb(),
+				Points:  pp.Points,
+				Spread:  pp.Spread,
+				Ignored: pp.Ignore,
+			})
+		}
+		return res, nil
+	}), nil
+}
+
+func (s *Session) startInfer(p *InferParams) (*JobStatus, error) {
+	if err := s.needGame(); err != nil {
+		return nil, err
+	}
+	if err := s.busy(); err != nil {
+		return nil, err
+	}
+	if p.Threads != 0 {
+		s.rangefinder.SetThreads(p.Threads)
+	}
+	err := s.rangefinder.PrepareFinder(s.game.RackFor(s.game.PlayerOnTurn()).TilesOn())
+	if err != nil {
+		return nil, err
+	}
+	maxSeconds := p.MaxSeconds
+	if maxSeconds == 0 {
+		maxSeconds = analyzer.DefaultInferSeconds
+	}
+	rf := s.rangefinder
+	alph := s.game.Alphabet()
+
+	return s.startJob(JobInfer, maxSeconds, rf, nil, func(ctx context.Context) (any, error) {
+		if err := rf.Infer(ctx); err != nil {
+			return nil, err
+		}
+		inferences := rf.Inferences()
+		return &InferResult{Inferences: len(inferences),
+			Leaves: analyzer.CountLeaves(inferences, alph)}, nil
+	}), nil
+}
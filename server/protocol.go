@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/domino14/macondo/analyzer"
+	"github.com/domino14/macondo/progress"
+)
+
+// The server speaks JSON-RPC 2.0. See https://www.jsonrpc.org/specification.
+
+const jsonrpcVersion = "2.0"
+
+// Error codes. The first four are from the JSON-RPC spec; the rest are ours.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	// CodeFailed is an error from macondo itself, such as an unknown
+	// lexicon or a badly formatted move.
+	CodeFailed = -32000
+	// CodeNoSession is returned for a session that does not exist.
+	CodeNoSession = -32001
+	// CodeBusy is returned if a session is running a job that must be
+	// stopped first.
+	CodeBusy = -32002
+)
+
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+func errorf(code int, format string, a ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// SessionParams names the session that a request is for. Every method but
+// session.new takes it.
+type SessionParams struct {
+	Session string `json:"session"`
+}
+
+type NewSessionResult struct {
+	Session string `json:"session"`
+}
+
+// LoadParams loads a game from the text of a GCG file, or a position from
+// a CGP string. If Turn is set, the game is set to that turn.
+type LoadParams struct {
+	SessionParams
+	GCG  string `json:"gcg,omitempty"`
+	CGP  string `json:"cgp,omitempty"`
+	Turn *int   `json:"turn,omitempty"`
+}
+
+type TurnParams struct {
+	SessionParams
+	Turn int `json:"turn"`
+}
+
+type Player struct {
+	Nickname string `json:"nickname"`
+	Score    int    `json:"score"`
+	Rack     string `json:"rack"`
+}
+
+// GameState is the position of a session's game. History is the game's
+// GameHistory protobuf, in its JSON form.
+type GameState struct {
+	Turn       int             `json:"turn"`
+	OnTurn     int             `json:"on_turn"`
+	Playing    bool            `json:"playing"`
+	Players    []Player        `json:"players"`
+	Bag        string          `json:"bag"`
+	TilesInBag int             `json:"tiles_in_bag"`
+	Board      []string        `json:"board"`
+	CGP        string          `json:"cgp"`
+	History    json.RawMessage `json:"history"`
+}
+
+type GenParams struct {
+	SessionParams
+	NumPlays int `json:"num_plays,omitempty"`
+}
+
+type Play struct {
+	Move   string  `json:"move"`
+	Action string  `json:"action"`
+	Coords string  `json:"coords,omitempty"`
+	Tiles  string  `json:"tiles,omitempty"`
+	Leave  string  `json:"leave"`
+	Score  int     `json:"score"`
+	Equity float64 `json:"equity"`
+}
+
+type GenResult struct {
+	Plays []Play `json:"plays"`
+}
+
+// SimParams starts a sim of the plays from the last gen. Stop is the
+// confidence at which the sim stops on its own: 95, 98 or 99, or 0 to go
+// on until it is stopped or runs out of time.
+type SimParams struct {
+	SessionParams
+	Plies      int    `json:"plies,omitempty"`
+	Threads    int    `json:"threads,omitempty"`
+	Stop       int    `json:"stop,omitempty"`
+	MaxSeconds int    `json:"max_seconds,omitempty"`
+	Seed       uint64 `json:"seed,omitempty"`
+	OppRack    string `json:"opp_rack,omitempty"`
+}
+
+type SimPlay struct {
+	Play
+	WinProb      float64 `json:"win_prob"`
+	WinProbErr   float64 `json:"win_prob_err"`
+	SimEquity    float64 `json:"sim_equity"`
+	SimEquityErr float64 `json:"sim_equity_err"`
+	Ignored      bool    `json:"ignored,omitempty"`
+}
+
+type SimResult struct {
+	Iterations int       `json:"iterations"`
+	Plays      []SimPlay `json:"plays"`
+}
+
+type EndgameParams struct {
+	SessionParams
+	Plies      int  `json:"plies,omitempty"`
+	Threads    int  `json:"threads,omitempty"`
+	MaxSeconds int  `json:"max_seconds,omitempty"`
+	FirstWin   bool `json:"first_win,omitempty"`
+}
+
+// EndgameResult is the best sequence found. Spread is the spread it gains
+// for the player on turn, and FinalSpread the spread after it.
+type EndgameResult struct {
+	Spread      int    `json:"spread"`
+	FinalSpread int    `json:"final_spread"`
+	Sequence    []Play `json:"sequence"`
+}
+
+type PEGParams struct {
+	SessionParams
+	EndgamePlies int    `json:"endgame_plies,omitempty"`
+	Threads      int    `json:"threads,omitempty"`
+	MaxSeconds   int    `json:"max_seconds,omitempty"`
+	MaxSolutions int    `json:"max_solutions,omitempty"`
+	OppRack      string `json:"opp_rack,omitempty"`
+	EarlyCutoff  bool   `json:"early_cutoff,omitempty"`
+	SkipLoss     bool   `json:"skip_loss,omitempty"`
+}
+
+type PEGPlay struct {
+	Play
+	WinProb float64 `json:"win_prob"`
+	Points  float32 `json:"points"`
+	Spread  int     `json:"spread"`
+	Ignored bool    `json:"ignored,omitempty"`
+}
+
+type PEGResult struct {
+	Plays []PEGPlay `json:"plays"`
+}
+
+type InferParams struct {
+	SessionParams
+	Threads    int `json:"threads,omitempty"`
+	MaxSeconds int `json:"max_seconds,omitempty"`
+}
+
+type InferredLeave = analyzer.InferredLeave
+
+// InferResult is what the opponent may have kept after their last play,
+// most often inferred first.
+type InferResult struct {
+	Inferences int             `json:"inferences"`
+	Leaves     []InferredLeave `json:"leaves"`
+}
+
+// JobStatus is the status of a session's sim, endgame, pre-endgame or
+// inference. Result is set while a sim runs, and for every kind of job
+// once it is done. Progress is the latest progress event from the job.
+type JobStatus struct {
+	Kind     string          `json:"kind"`
+	Running  bool            `json:"running"`
+	Elapsed  float64         `json:"elapsed"`
+	Error    string          `json:"error,omitempty"`
+	Progress *progress.Event `json:"progress,omitempty"`
+	Result   any             `json:"result,omitempty"`
+}
@@ -0,0 +1,374 @@
+// Package server runs macondo as a headless analysis service. Clients
+// drive it with JSON-RPC 2.0 requests, over HTTP or over a stream such as
+// stdin and stdout. Each client works in its own session, with its own
+// game, and many sessions can analyze at once.
+//
+// The methods are:
+//
+//	session.new                        start a session
+//	session.close                      stop a session's job and forget it
+//	game.load                          load a GCG or a CGP position
+//	game.turn                          go to a turn of the loaded game
+//	game.state                         the board, racks, bag and history
+//	gen                                generate plays
+//	sim.start, sim.stop, sim.status    sim the generated plays
+//	endgame.start, .stop, .status      solve an endgame
+//	peg.start, .stop, .status          solve a pre-endgame
+//	infer.start, .stop, .status        infer the opponent's leave
+//
+// A session runs one job (a sim, endgame, pre-endgame or inference) at a
+// time, in the background. Its progress can be polled with <kind>.status,
+// or streamed as lines of JSON from GET /progress?session=<id>. Either way,
+// the status has the job's latest progress event (see package progress):
+// its iterations, leader, best line, depth and estimated time left.
+//
+// A session that gets no requests for an hour is closed, as if by
+// session.close, so that clients that go away do not leave it behind.
+package server
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"lukechampine.com/frand"
+
+	"github.com/domino14/macondo/config"
+)
+
+const (
+	defaultProgressInterval = time.Second
+	minProgressInterval     = 50 * time.Millisecond
+	// maxRequestSize is the largest request body that is read. GCG files
+	// are much smaller than this.
+	maxRequestSize = 1 << 20
+	// sessionIdleTimeout is how long a session is kept without requests.
+	sessionIdleTimeout = time.Hour
+	// sessionSweepInterval is how often idle sessions are looked for.
+	sessionSweepInterval = time.Minute
+)
+
+type Server struct {
+	cfg *config.Config
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func New(cfg *config.Config) *Server {
+	sv := &Server{cfg: cfg, sessions: map[string]*Session{}, stop: make(chan struct{})}
+	go sv.sweep()
+	return sv
+}
+
+type method func(sv *Server, params json.RawMessage) (any, error)
+
+var methods = map[string]method{
+	"session.new":   newSessionMethod,
+	"session.close": closeSessionMethod,
+	"game.load": withSession(func(s *Session, p *LoadParams) (any, error) {
+		return s.load(p)
+	}),
+	"game.turn": withSession(func(s *Session, p *TurnParams) (any, error) {
+		if err := s.setTurn(p.Turn); err != nil {
+			return nil, err
+		}
+		return s.state()
+	}),
+	"game.state": withSession(func(s *Session, p *SessionParams) (any, error) {
+		return s.state()
+	}),
+	"gen": withSession(func(s *Session, p *GenParams) (any, error) {
+		return s.gen(p.NumPlays)
+	}),
+	"sim.start": withSession(func(s *Session, p *SimParams) (any, error) {
+		return s.startSim(p)
+	}),
+	"endgame.start": withSession(func(s *Session, p *EndgameParams) (any, error) {
+		return s.startEndgame(p)
+	}),
+	"peg.start": withSession(func(s *Session, p *PEGParams) (any, error) {
+		return s.startPEG(p)
+	}),
+	"infer.start": withSession(func(s *Session, p *InferParams) (any, error) {
+		return s.startInfer(p)
+	}),
+}
+
+func init() {
+	for _, kind := range []string{JobSim, JobEndgame, JobPEG, JobInfer} {
+		methods[kind+".stop"] = withSession(func(s *Session, p *SessionParams) (any, error) {
+			return s.stopJob(kind)
+		})
+		methods[kind+".status"] = withSession(func(s *Session, p *SessionParams) (any, error) {
+			return s.jobStatus(kind)
+		})
+	}
+}
+
+func decodeParams(params json.RawMessage, p any) error {
+	if len(params) == 0 {
+		return errorf(CodeInvalidParams, "missing params")
+	}
+	if err := json.Unmarshal(params, p); err != nil {
+		return errorf(CodeInvalidParams, "bad params: %v", err)
+	}
+	return nil
+}
+
+// withSession makes a method that decodes its params, then calls fn with
+// the session they name, locked.
+func withSession[P any](fn func(s *Session, p *P) (any, error)) method {
+	return func(sv *Server, params json.RawMessage) (any, error) {
+		var sp SessionParams
+		if err := decodeParams(params, &sp); err != nil {
+			return nil, err
+		}
+		p := new(P)
+		if err := decodeParams(params, p); err != nil {
+			return nil, err
+		}
+		s, err := sv.session(sp.Session)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.lastUsed = time.Now()
+		return fn(s, p)
+	}
+}
+
+func (sv *Server) session(id string) (*Session, error) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	s, ok := sv.sessions[id]
+	if !ok {
+		return nil, errorf(CodeNoSession, "no session %q", id)
+	}
+	return s, nil
+}
+
+func newSessionMethod(sv *Server, params json.RawMessage) (any, error) {
+	id := hex.EncodeToString(frand.Bytes(8))
+	sv.mu.Lock()
+	sv.sessions[id] = newSession(id, sv.cfg)
+	sv.mu.Unlock()
+	log.Info().Str("session", id).Msg("new-session")
+	return &NewSessionResult{Session: id}, nil
+}
+
+func closeSessionMethod(sv *Server, params json.RawMessage) (any, error) {
+	var sp SessionParams
+	if err := decodeParams(params, &sp); err != nil {
+		return nil, err
+	}
+	sv.mu.Lock()
+	s, ok := sv.sessions[sp.Session]
+	delete(sv.sessions, sp.Session)
+	sv.mu.Unlock()
+	if !ok {
+		return nil, errorf(CodeNoSession, "no session %q", sp.Session)
+	}
+	s.close()
+	log.Info().Str("session", sp.Session).Msg("closed-session")
+	return struct{}{}, nil
+}
+
+// Call handles one request. It returns nil for a notification, which is a
+// request without an ID.
+func (sv *Server) Call(req *Request) *Response {
+	resp := &Response{JSONRPC: jsonrpcVersion, ID: req.ID}
+	if req.JSONRPC != jsonrpcVersion || req.Method == "" {
+		resp.Error = errorf(CodeInvalidRequest, "not a JSON-RPC 2.0 request")
+		return resp
+	}
+	m, ok := methods[req.Method]
+	if !ok {
+		resp.Error = errorf(CodeMethodNotFound, "no method %q", req.Method)
+	} else {
+		result, err := m(sv, req.Params)
+		if err != nil {
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &Error{Code: CodeFailed, Message: err.Error()}
+			}
+			log.Debug().Str("method", req.Method).Err(err).Msg("request-failed")
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+	}
+	if len(req.ID) == 0 {
+		return nil
+	}
+	return resp
+}
+
+// callJSON handles a request in JSON.
+func (sv *Server) callJSON(data []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return &Response{JSONRPC: jsonrpcVersion, ID: json.RawMessage("null"),
+			Error: errorf(CodeParseError, "%v", err)}
+	}
+	return sv.Call(&req)
+}
+
+// Handler returns the HTTP interface of the server. Requests are POSTed to
+// /rpc, and GET /progress streams the progress of a session's job.
+func (sv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rpc", sv.serveRPC)
+	mux.HandleFunc("GET /progress", sv.serveProgress)
+	return mux
+}
+
+func (sv *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	resp := sv.callJSON(data)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveProgress writes the status of a session's job as a line of JSON
+// every interval (in milliseconds, default 1000), until the job is done.
+// The last line has the final result.
+func (sv *Server) serveProgress(w http.ResponseWriter, r *http.Request) {
+	s, err := sv.session(r.URL.Query().Get("session"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	interval := defaultProgressInterval
+	if ms := r.URL.Query().Get("interval"); ms != "" {
+		n, err := strconv.Atoi(ms)
+		if err != nil {
+			http.Error(w, "bad interval: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		interval = max(time.Duration(n)*time.Millisecond, minProgressInterval)
+	}
+	s.mu.Lock()
+	j := s.job
+	s.lastUsed = time.Now()
+	s.mu.Unlock()
+	if j == nil {
+		http.Error(w, "the session has no job", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		done := !j.running()
+		if err := enc.Encode(j.status()); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if done {
+			return
+		}
+		// A session is in use while its progress is streamed.
+		s.mu.Lock()
+		s.lastUsed = time.Now()
+		s.mu.Unlock()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-j.done:
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeStream handles requests from r, one JSON object per line, and
+// writes their responses to w, one per line, until r is done.
+func (sv *Server) ServeStream(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRequestSize)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		resp := sv.callJSON([]byte(line))
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// sweep closes idle sessions every sessionSweepInterval, until the server
+// is closed.
+func (sv *Server) sweep() {
+	ticker := time.NewTicker(sessionSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sv.stop:
+			return
+		case now := <-ticker.C:
+			sv.closeIdle(now)
+		}
+	}
+}
+
+// closeIdle closes the sessions that have had no requests for
+// sessionIdleTimeout before now.
+func (sv *Server) closeIdle(now time.Time) {
+	var idle []*Session
+	sv.mu.Lock()
+	for id, s := range sv.sessions {
+		s.mu.Lock()
+		if now.Sub(s.lastUsed) >= sessionIdleTimeout {
+			idle = append(idle, s)
+			delete(sv.sessions, id)
+		}
+		s.mu.Unlock()
+	}
+	sv.mu.Unlock()
+	for _, s := range idle {
+		s.close()
+		log.Info().Str("session", s.id).Msg("closed-idle-session")
+	}
+}
+
+// Close stops the jobs of all sessions.
+func (sv *Server) Close() {
+	sv.closeOnce.Do(func() { close(sv.stop) })
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	for id, s := range sv.sessions {
+		s.close()
+		delete(sv.sessions, id)
+	}
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/config"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+const catCGP = "15/15/15/15/15/15/15/7CAT5/15/15/15/15/15/15/15 ABORSTV/ 0/0 0 lex NWL20;"
+
+// call makes a request and decodes its result into result, if it has one.
+func call(t *testing.T, sv *Server, method string, params any, result any) *Error {
+	t.Helper()
+	is := is.New(t)
+	req := &Request{JSONRPC: jsonrpcVersion, ID: json.RawMessage("1"), Method: method}
+	if params != nil {
+		var err error
+		req.Params, err = json.Marshal(params)
+		is.NoErr(err)
+	}
+	resp := sv.Call(req)
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil {
+		data, err := json.Marshal(resp.Result)
+		is.NoErr(err)
+		is.NoErr(json.Unmarshal(data, result))
+	}
+	return nil
+}
+
+func newTestSession(t *testing.T, sv *Server) string {
+	var res NewSessionResult
+	is.New(t).Equal(call(t, sv, "session.new", nil, &res), nil)
+	return res.Session
+}
+
+func TestProtocolErrors(t *testing.T) {
+	is := is.New(t)
+	sv := New(&DefaultConfig)
+
+	resp := sv.callJSON([]byte(`{not json`))
+	is.Equal(resp.Error.Code, CodeParseError)
+	resp = sv.callJSON([]byte(`{"jsonrpc":"1.0","id":1,"method":"gen"}`))
+	is.Equal(resp.Error.Code, CodeInvalidRequest)
+	resp = sv.callJSON([]byte(`{"jsonrpc":"2.0","id":1,"method":"nothing"}`))
+	is.Equal(resp.Error.Code, CodeMethodNotFound)
+	// A notification has no response, even if it fails.
+	is.Equal(sv.callJSON([]byte(`{"jsonrpc":"2.0","method":"nothing"}`)), nil)
+
+	is.Equal(call(t, sv, "gen", nil, nil).Code, CodeInvalidParams)
+	is.Equal(call(t, sv, "gen", SessionParams{Session: "nope"}, nil).Code, CodeNoSession)
+
+	id := newTestSession(t, sv)
+	is.Equal(call(t, sv, "game.state", SessionParams{Session: id}, nil).Code, CodeFailed)
+	is.Equal(call(t, sv, "game.load", LoadParams{SessionParams: SessionParams{Session: id}}, nil).Code,
+		CodeInvalidParams)
+	err := call(t, sv, "sim.status", SessionParams{Session: id}, nil)
+	is.Equal(err.Message, "no sim has been started")
+
+	is.Equal(call(t, sv, "session.close", SessionParams{Session: id}, nil), nil)
+	is.Equal(call(t, sv, "game.state", SessionParams{Session: id}, nil).Code, CodeNoSession)
+}
+
+func TestSessionsAreSeparate(t *testing.T) {
+	is := is.New(t)
+	sv := New(&DefaultConfig)
+	a := newTestSession(t, sv)
+	b := newTestSession(t, sv)
+	is.True(a != b)
+	is.Equal(call(t, sv, "session.close", SessionParams{Session: a}, nil), nil)
+	is.Equal(call(t, sv, "session.close", SessionParams{Session: a}, nil).Code, CodeNoSession)
+	is.Equal(call(t, sv, "game.state", SessionParams{Session: b}, nil).Message, errNoGame.Error())
+}
+
+func TestIdleSessionsAreClosed(t *testing.T) {
+	is := is.New(t)
+	sv := New(&DefaultConfig)
+	defer sv.Close()
+	idle := newTestSession(t, sv)
+	used := newTestSession(t, sv)
+	sv.sessions[used].lastUsed = time.Now().Add(sessionIdleTimeout / 2)
+
+	sv.closeIdle(time.Now().Add(sessionIdleTimeout))
+	is.Equal(call(t, sv, "game.state", SessionParams{Session: idle}, nil).Code, CodeNoSession)
+	is.Equal(call(t, sv, "game.state", SessionParams{Session: used}, nil).Message, errNoGame.Error())
+}
+
+func TestHTTPAndStream(t *testing.T) {
+	is := is.New(t)
+	sv := New(&DefaultConfig)
+	ts := httptest.NewServer(sv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/rpc", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":"x","method":"session.new"}`))
+	is.NoErr(err)
+	defer resp.Body.Close()
+	var r struct {
+		ID     string           `json:"id"`
+		Result NewSessionResult `json:"result"`
+	}
+	is.NoErr(json.NewDecoder(resp.Body).Decode(&r))
+	is.Equal(r.ID, "x")
+	is.True(r.Result.Session != "")
+
+	resp, err = http.Get(ts.URL + "/progress?session=" + r.Result.Session)
+	is.NoErr(err)
+	resp.Body.Close()
+	is.Equal(resp.StatusCode, http.StatusNotFound)
+
+	var out bytes.Buffer
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"session.new"}
+
+{"jsonrpc":"2.0","method":"session.new"}
+{"jsonrpc":"2.0","id":2,"method":"game.state","params":{"session":"nope"}}
+`)
+	is.NoErr(sv.ServeStream(in, &out))
+	scanner := bufio.NewScanner(&out)
+	var lines []Response
+	for scanner.Scan() {
+		var resp Response
+		is.NoErr(json.Unmarshal(scanner.Bytes(), &resp))
+		lines = append(lines, resp)
+	}
+	is.Equal(len(lines), 2)
+	is.Equal(lines[0].Error, nil)
+	is.Equal(lines[1].Error.Code, CodeNoSession)
+}
+
+func TestGenAndSim(t *testing.T) {
+	is := is.New(t)
+	sv := New(&DefaultConfig)
+	defer sv.Close()
+	id := newTestSession(t, sv)
+	sp := SessionParams{Session: id}
+
+	var st GameState
+	is.Equal(call(t, sv, "game.load", LoadParams{SessionParams: sp, CGP: catCGP}, &st), nil)
+	is.Equal(st.Players[0].Rack, "ABORSTV")
+	is.Equal(st.Board[7], ".......CAT.....")
+	is.True(len(st.History) > 0)
+
+	var gen GenResult
+	is.Equal(call(t, sv, "gen", GenParams{SessionParams: sp, NumPlays: 5}, &gen), nil)
+	is.Equal(len(gen.Plays), 5)
+
+	is.Equal(call(t, sv, "sim.start", SimParams{SessionParams: sp}, nil).Code, CodeInvalidParams)
+	var status JobStatus
+	is.Equal(call(t, sv, "sim.start", SimParams{SessionParams: sp, Stop: 95, MaxSeconds: 30, Threads: 2}, &status), nil)
+	is.Equal(status.Kind, JobSim)
+	// The game cannot change while it is being simmed.
+	is.Equal(call(t, sv, "gen", GenParams{SessionParams: sp}, nil).Code, CodeBusy)
+
+	var res struct {
+		JobStatus
+		Result SimResult `json:"result"`
+	}
+	is.Equal(call(t, sv, "sim.stop", sp, &res), nil)
+	is.True(!res.Running)
+	is.Equal(len(res.Result.Plays), 5)
+}
+
+func TestStateDuringEndgame(t *testing.T) {
+	is := is.New(t)
+	sv := New(&DefaultConfig)
+	defer sv.Close()
+	id := newTestSession(t, sv)
+	sp := SessionParams{Session: id}
+
+	const endgame = "4EXODE6/1DOFF1KERATIN1U/1OHO8YEN/1POOJA1B3MEWS/5SQUINTY2A/4RHINO1e3V/" +
+		"2B4C2R3E/GOAT1D1E2ZIN1d/1URACILS2E4/1PIG1S4T4/2L2R4T4/2L2A1GENII3/2A2T1L7/5E1A7/" +
+		"5D1M7 AEEIRUW/V 410/409 0 lex CSW19;"
+	var st GameState
+	is.Equal(call(t, sv, "game.load", LoadParams{SessionParams: sp, CGP: endgame}, &st), nil)
+	is.Equal(call(t, sv, "endgame.start", EndgameParams{SessionParams: sp, Plies: 8, MaxSeconds: 30}, nil), nil)
+	// The game can be looked at, but not changed, while the endgame runs.
+	for i := 0; i < 20; i++ {
+		var during GameState
+		is.Equal(call(t, sv, "game.state", sp, &during), nil)
+		is.Equal(during.CGP, st.CGP)
+	}
+	is.Equal(call(t, sv, "gen", GenParams{SessionParams: sp}, nil).Code, CodeBusy)
+	call(t, sv, "endgame.stop", sp, nil)
+	var after GameState
+	is.Equal(call(t, sv, "game.state", sp, &after), nil)
+	is.Equal(after.CGP, st.CGP)
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/domino14/macondo/ai/bot"
+	"github.com/domino14/macondo/cgp"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/gcgio"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/montecarlo"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/rangefinder"
+)
+
+const defaultNumPlays = 15
+
+var errNoGame = errors.New("please load a game first")
+
+// A Session is one client's game, with the plays generated for it and
+// the job, if any, analyzing it. Sessions are independent of each other;
+// the methods of one Session are serialized by its mutex.
+type Session struct {
+	mu  sync.Mutex
+	id  string
+	cfg *config.Config
+	// lastUsed is when the session last had a request.
+	lastUsed time.Time
+
+	game        *bot.BotTurnPlayer
+	simmer      *montecarlo.Simmer
+	rangefinder *rangefinder.RangeFinder
+	plays       []*move.Move
+	job         *job
+}
+
+func newSession(id string, cfg *config.Config) *Session {
+	return &Session{id: id, cfg: cfg, lastUsed: time.Now()}
+}
+
+// close stops the session's job, if it is running.
+func (s *Session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.job != nil && s.job.running() {
+		s.job.cancel()
+		<-s.job.done
+	}
+}
+
+// busy returns an error if a job is running on the session's game.
+func (s *Session) busy() error {
+	if s.job != nil && s.job.running() {
+		return errorf(CodeBusy, "a %s is running; stop it first", s.job.kind)
+	}
+	return nil
+}
+
+func (s *Session) needGame() error {
+	if s.game == nil {
+		return errNoGame
+	}
+	return nil
+}
+
+func (s *Session) load(p *LoadParams) (*GameState, error) {
+	if err := s.busy(); err != nil {
+		return nil, err
+	}
+	var g *game.Game
+	var err error
+	switch {
+	case p.GCG != "" && p.CGP != "":
+		return nil, errorf(CodeInvalidParams, "give either gcg or cgp, not both")
+	case p.GCG != "":
+		g, err = s.gameFromGCG(p.GCG)
+	case p.CGP != "":
+		g, err = s.gameFromCGP(p.CGP)
+	default:
+		return nil, errorf(CodeInvalidParams, "need a gcg or cgp to load")
+	}
+	if err != nil {
+		return nil, err
+	}
+	leavesFile := equity.LeavesFilenameFor(g.Rules().BoardName(), g.Rules().Variant())
+	conf := &bot.BotConfig{Config: *s.cfg, LeavesFile: leavesFile}
+	tp, err := bot.NewBotTurnPlayerFromGame(g, conf, pb.BotRequest_HASTY_BOT)
+	if err != nil {
+		return nil, err
+	}
+	tp.SetBackupMode(game.InteractiveGameplayMode)
+	tp.SetStateStackLength(1)
+
+	c, err := equity.NewCombinedStaticCalculator(tp.LexiconName(), s.cfg, "", equity.PEGAdjustmentFilename)
+	if err != nil {
+		return nil, err
+	}
+	s.game = tp
+	s.simmer = &montecarlo.Simmer{}
+	s.simmer.Init(tp.Game, []equity.EquityCalculator{c}, c, s.cfg)
+	s.rangefinder = &rangefinder.RangeFinder{}
+	s.rangefinder.Init(tp.Game, []equity.EquityCalculator{c}, s.cfg)
+	s.plays = nil
+	s.job = nil
+
+	if p.Turn != nil {
+		if err := s.setTurn(*p.Turn); err != nil {
+			return nil, err
+		}
+	}
+	return s.state()
+}
+
+func (s *Session) gameFromGCG(gcg string) (*game.Game, error) {
+	history, err := gcgio.ParseGCGFromReader(s.cfg, strings.NewReader(gcg))
+	if err != nil {
+		return nil, err
+	}
+	lexicon := history.Lexicon
+	if lexicon == "" {
+		lexicon = s.cfg.GetString(config.ConfigDefaultLexicon)
+		log.Info().Msgf("gcg file had no lexicon, so using default lexicon %v", lexicon)
+	}
+	boardLayout, ldName, variant := game.HistoryToVariant(history)
+	rules, err := game.NewBasicGameRules(s.cfg, lexicon, boardLayout, ldName, game.CrossScoreAndSet, variant)
+	if err != nil {
+		return nil, err
+	}
+	g, err := game.NewFromHistory(history, rules, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Set challenge rule to double by default, as the shell does.
+	g.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	return g, nil
+}
+
+func (s *Session) gameFromCGP(cgpstr string) (*game.Game, error) {
+	parsed, err := cgp.ParseCGP(s.cfg, cgpstr)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := parsed.Opcodes["cr"]; !ok {
+		parsed.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	}
+	parsed.RecalculateBoard()
+	return parsed.Game, nil
+}
+
+func (s *Session) setTurn(turn int) error {
+	if err := s.needGame(); err != nil {
+		return err
+	}
+	if err := s.busy(); err != nil {
+		return err
+	}
+	if err := s.game.PlayToTurn(turn); err != nil {
+		return err
+	}
+	s.plays = nil
+	s.simmer.Reset()
+	s.rangefinder.Reset()
+	return nil
+}
+
+func (s *Session) state() (*GameState, error) {
+	if err := s.needGame(); err != nil {
+		return nil, err
+	}
+	g := s.game
+	alph := g.Alphabet()
+	history, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(g.History())
+	if err != nil {
+		return nil, err
+	}
+	st := &GameState{
+		Turn:       g.Turn(),
+		OnTurn:     g.PlayerOnTurn(),
+		Playing:    g.IsPlaying(),
+		TilesInBag: g.Bag().TilesRemaining(),
+		CGP:        g.ToCGP(false),
+		History:    history,
+	}
+	for i := 0; i < g.NumPlayers(); i++ {
+		st.Players = append(st.Players, Player{
+			Nickname: g.History().Players[i].Nickname,
+			Score:    g.PointsFor(i),
+			Rack:     g.RackLettersFor(i),
+		})
+	}
+	st.Bag = sortedTiles(g.Bag().Peek(), alph)
+
+	bd := g.Board()
+	for r := 0; r < bd.Dim(); r++ {
+		var sb strings.Builder
+		for c := 0; c < bd.Dim(); c++ {
+			ml := bd.GetLetter(r, c)
+			if ml == 0 {
+				sb.WriteString(".")
+			} else {
+				sb.WriteString(ml.UserVisible(alph, false))
+			}
+		}
+		st.Board = append(st.Board, sb.String())
+	}
+	return st, nil
+}
+
+func (s *Session) gen(numPlays int) (*GenResult, error) {
+	if err := s.needGame(); err != nil {
+		return nil, err
+	}
+	if err := s.busy(); err != nil {
+		return nil, err
+	}
+	if numPlays <= 0 {
+		numPlays = defaultNumPlays
+	}
+	s.plays = s.game.GenerateMoves(numPlays)
+	s.simmer.Reset()
+	res := &GenResult{Plays: []Play{}}
+	for _, m := range s.plays {
+		res.Plays = append(res.Plays, toPlay(m))
+	}
+	return res, nil
+}
+
+// sortedTiles shows tiles in alphabetical order, as racks are shown.
+func sortedTiles(tiles []tilemapping.MachineLetter, alph *tilemapping.TileMapping) string {
+	mw := tilemapping.MachineWord(slices.Clone(tiles))
+	slices.Sort(mw)
+	return mw.UserVisible(alph)
+}
+
+func toPlay(m *move.Move) Play {
+	p := Play{
+		Move:   m.ShortDescription(),
+		Action: m.MoveTypeString(),
+		Leave:  m.Leave().UserVisible(m.Alphabet()),
+		Score:  m.Score(),
+		Equity: m.Equity(),
+	}
+	switch m.Action() {
+	case move.MoveTypePlay:
+		p.Coords = m.BoardCoords()
+		p.Tiles = m.TilesString()
+	case move.MoveTypeExchange:
+		p.Tiles = m.TilesString()
+	}
+	return p
+}
@@ -13,11 +13,11 @@ import (
 	"github.com/domino14/word-golib/kwg"
 	"github.com/domino14/word-golib/tilemapping"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
 	"lukechampine.com/frand"
 
 	"github.com/domino14/macondo/ai/bot"
 	"github.com/domino14/macondo/automatic"
-	"github.com/domino14/macondo/board"
 	"github.com/domino14/macondo/config"
 	"github.com/domino14/macondo/endgame/negamax"
 	"github.com/domino14/macondo/equity"
@@ -26,6 +26,7 @@ import (
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 	"github.com/domino14/macondo/preendgame"
+	"github.com/domino14/macondo/render"
 )
 
 const defaultEndgamePlies = 4
@@ -59,6 +60,14 @@ func (c CmdOptions) IntDefault(key string, defaultI int) (int, error) {
 	return strconv.Atoi(v[0])
 }
 
+func (c CmdOptions) FloatDefault(key string, defaultF float64) (float64, error) {
+	v := c[key]
+	if len(v) == 0 {
+		return defaultF, nil
+	}
+	return strconv.ParseFloat(v[0], 64)
+}
+
 func (c CmdOptions) Bool(key string) bool {
 	v := c[key]
 	if len(v) == 0 {
@@ -118,10 +127,7 @@ func (sc *ShellController) newGame(cmd *shellcmd) (*Response, error) {
 	}
 
 	opts := sc.options.GameOptions
-	leavesFile := ""
-	if opts.BoardLayoutName == board.SuperCrosswordGameLayout {
-		leavesFile = "super-leaves.klv2"
-	}
+	leavesFile := equity.LeavesFilenameFor(opts.BoardLayoutName, opts.Variant)
 
 	conf := &bot.BotConfig{Config: *sc.config, LeavesFile: leavesFile}
 
@@ -308,6 +314,15 @@ func (sc *ShellController) generate(cmd *shellcmd) (*Response, error) {
 			return nil, err
 		}
 	}
+	constraints, err := genConstraints(cmd.options, sc.game.Alphabet(), sc.game.Board().Dim())
+	if err != nil {
+		return nil, err
+	}
+	if constraints != nil {
+		gen := sc.game.MoveGenerator()
+		gen.SetConstraints(constraints)
+		defer gen.SetConstraints(nil)
+	}
 
 	return msg(sc.genMovesAndDescription(numPlays)), nil
 }
@@ -420,6 +435,7 @@ func (sc *ShellController) endgame(cmd *shellcmd) (*Response, error) {
 	// clear out the last value of this endgame node; gc should
 	// delete the tree.
 	sc.endgameSolver = new(negamax.Solver)
+	sc.endgameSolver.SetProgressListener(logProgress, progressInterval)
 
 	if cmd.options.Bool("log") {
 		sc.endgameLogFile, err = os.Create(EndgameLog)
@@ -550,6 +566,7 @@ func (sc *ShellController) preendgame(cmd *shellcmd) (*Response, error) {
 	sc.showMessage(sc.game.ToDisplayText())
 	sc.preendgameSolver = new(preendgame.Solver)
 	sc.preendgameSolver.Init(sc.game.Game, gd)
+	sc.preendgameSolver.SetProgressListener(logProgress, progressInterval)
 
 	if maxthreads != 0 {
 		sc.preendgameSolver.SetThreads(maxthreads)
@@ -707,8 +724,30 @@ func (sc *ShellController) export(cmd *shellcmd) (*Response, error) {
 	if cmd.args == nil {
 		return nil, errors.New("please provide a filename to save to")
 	}
+	if sc.game == nil {
+		return nil, errors.New("please load a game first with the `load` command")
+	}
 	filename := cmd.args[0]
-	contents, err := gcgio.GameHistoryToGCG(sc.game.History(), true)
+	format := cmd.options.String("format")
+	if format == "" {
+		format = "gcg"
+	}
+	var contents string
+	var err error
+	switch format {
+	case "gcg":
+		contents, err = gcgio.GameHistoryToGCG(sc.game.History(), true)
+	case "json":
+		var bts []byte
+		bts, err = gcgio.GameHistoryToJSON(sc.game.History())
+		contents = string(bts)
+	case "movelist":
+		contents, err = gcgio.GameHistoryToMoveList(sc.game.History())
+	case "html":
+		contents, err = gcgio.GameHistoryToHTML(sc.config, sc.game.History())
+	default:
+		return nil, fmt.Errorf("unknown export format %q; try gcg, json, movelist or html", format)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -716,16 +755,82 @@ func (sc *ShellController) export(cmd *shellcmd) (*Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.Debug().Interface("game-history", sc.game.History()).Msg("converted game history to gcg")
+	log.Debug().Interface("game-history", sc.game.History()).Str("format", format).Msg("converted game history")
 	f.WriteString(contents)
 	f.Close()
-	return msg("gcg written to " + filename), nil
+	return msg(format + " written to " + filename), nil
+}
+
+func (sc *ShellController) render(cmd *shellcmd) (*Response, error) {
+	if cmd.args == nil {
+		return nil, errors.New("please provide a filename to save to")
+	}
+	if sc.game == nil {
+		return nil, errors.New("please load or create a game first")
+	}
+	filename := cmd.args[0]
+	format := render.FormatForFilename(filename)
+	if f := cmd.options.String("format"); f != "" {
+		var err error
+		format, err = render.ParseFormat(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	size, err := cmd.options.IntDefault("size", render.DefaultSquareSize)
+	if err != nil {
+		return nil, err
+	}
+	numArrows, err := cmd.options.IntDefault("arrows", 0)
+	if err != nil {
+		return nil, err
+	}
+	if numArrows < 0 {
+		return nil, errors.New("the number of arrows must not be negative")
+	}
+	opts := render.Options{NoCaption: cmd.options.Bool("nocaption")}
+	g := sc.game.Game
+	turn, err := cmd.options.IntDefault("turn", g.Turn())
+	if err != nil {
+		return nil, err
+	}
+	if turn != g.Turn() {
+		if numArrows > 0 {
+			// The play list is for the current turn.
+			return nil, errors.New("arrows can only be drawn at the current turn")
+		}
+		// Render a copy, so that the shell stays at the current turn.
+		h := proto.Clone(g.History()).(*pb.GameHistory)
+		g, err = game.NewFromHistory(h, g.Rules(), turn)
+		if err != nil {
+			return nil, err
+		}
+	} else if numArrows > 0 {
+		if len(sc.curPlayList) == 0 {
+			return nil, errors.New("generate or add some plays first to draw them as arrows")
+		}
+		opts.Candidates = sc.curPlayList[:min(numArrows, len(sc.curPlayList))]
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err = render.Render(f, g, format, size, opts); err != nil {
+		return nil, err
+	}
+	return msg(fmt.Sprintf("turn %d written to %s", turn, filename)), nil
 }
 
 func (sc *ShellController) autoAnalyze(cmd *shellcmd) (*Response, error) {
 	if cmd.args == nil {
 		return nil, errors.New("please provide a filename to analyze")
 	}
+	// The log files of autoplay have no variant; they are replayed as
+	// classic games.
+	if err := sc.classicOnly("autoanalyze"); err != nil {
+		return nil, err
+	}
 	filename := cmd.args[0]
 	options := cmd.options
 	if options.String("export") != "" {
@@ -793,7 +898,10 @@ func (sc *ShellController) leave(cmd *shellcmd) (*Response, error) {
 }
 
 func (sc *ShellController) cgp(cmd *shellcmd) (*Response, error) {
-	cgpstr := sc.game.ToCGP(false)
+	if sc.game == nil {
+		return nil, errors.New("please load or create a game first")
+	}
+	cgpstr := sc.game.WriteCGP(game.CGPOptions{WithBag: cmd.options.Bool("bag")})
 	return msg(cgpstr), nil
 }
 
@@ -811,6 +919,10 @@ func (sc *ShellController) check(cmd *shellcmd) (*Response, error) {
 		return nil, err
 	}
 	lex := kwg.Lexicon{KWG: *k}
+	va := sc.options.Variant
+	if sc.game != nil {
+		va = sc.game.Rules().Variant()
+	}
 
 	playValid := true
 	wordsFriendly := []string{}
@@ -823,7 +935,12 @@ func (sc *ShellController) check(cmd *shellcmd) (*Response, error) {
 		if err != nil {
 			return nil, err
 		}
-		valid := lex.HasWord(word)
+		var valid bool
+		if va.IsWordSmog() {
+			valid = lex.HasAnagram(word)
+		} else {
+			valid = lex.HasWord(word)
+		}
 		if !valid {
 			playValid = false
 		}
@@ -833,6 +950,10 @@ func (sc *ShellController) check(cmd *shellcmd) (*Response, error) {
 		validStr = "INVALID"
 	}
 
-	return msg(fmt.Sprintf("The play (%v) is %v in %v", strings.Join(wordsFriendly, ","), validStr, sc.config.GetString(config.ConfigDefaultLexicon))), nil
+	lexName := sc.config.GetString(config.ConfigDefaultLexicon)
+	if va.IsWordSmog() {
+		lexName += " (" + string(va) + ")"
+	}
+	return msg(fmt.Sprintf("The play (%v) is %v in %v", strings.Join(wordsFriendly, ","), validStr, lexName)), nil
 
 }
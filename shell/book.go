@@ -0,0 +1,153 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/game"
+	"github.com/domino14/macondo/montecarlo"
+	"github.com/domino14/macondo/openingbook"
+)
+
+func (sc *ShellController) book(cmd *shellcmd) (*Response, error) {
+	if len(cmd.args) == 0 {
+		return nil, errors.New("need an argument: build, show, or stop")
+	}
+	switch cmd.args[0] {
+	case "build":
+		return sc.buildBook(cmd)
+	case "show":
+		return sc.showBook(cmd)
+	case "stop":
+		if !sc.gameRunnerRunning {
+			return nil, errors.New("book is not being built")
+		}
+		sc.gameRunnerCancel()
+		return nil, nil
+	}
+	return nil, errors.New("argument " + cmd.args[0] + " not recognized")
+}
+
+// bookRules returns the rules of the current game, or the default rules
+// if there is no game.
+func (sc *ShellController) bookRules() (*game.GameRules, error) {
+	if sc.game != nil {
+		return sc.game.Rules(), nil
+	}
+	return game.NewBasicGameRules(sc.config, sc.config.GetString(config.ConfigDefaultLexicon),
+		board.CrosswordGameLayout, sc.config.GetString(config.ConfigDefaultLetterDistribution),
+		game.CrossScoreAndSet, "")
+}
+
+func (sc *ShellController) buildBook(cmd *shellcmd) (*Response, error) {
+	if sc.gameRunnerRunning {
+		return nil, errors.New("please stop automatic game runner before building a book")
+	}
+	if sc.solving() {
+		return nil, errMacondoSolving
+	}
+	rules, err := sc.bookRules()
+	if err != nil {
+		return nil, err
+	}
+	opts := openingbook.BuildOptions{}
+	if opts.Turn, err = cmd.options.IntDefault("turn", 1); err != nil {
+		return nil, err
+	}
+	if opts.Positions, err = cmd.options.IntDefault("positions", 100); err != nil {
+		return nil, err
+	}
+	if opts.Plies, err = cmd.options.IntDefault("plies", 2); err != nil {
+		return nil, err
+	}
+	if opts.NumPlays, err = cmd.options.IntDefault("plays", 40); err != nil {
+		return nil, err
+	}
+	if opts.Threads, err = cmd.options.IntDefault("threads", 0); err != nil {
+		return nil, err
+	}
+	stop, err := cmd.options.IntDefault("stop", 99)
+	if err != nil {
+		return nil, err
+	}
+	switch stop {
+	case 95:
+		opts.StoppingCondition = montecarlo.Stop95
+	case 98:
+		opts.StoppingCondition = montecarlo.Stop98
+	case 99:
+		opts.StoppingCondition = montecarlo.Stop99
+	default:
+		return nil, errors.New("only allowed values are 95, 98, and 99 for stopping condition")
+	}
+	out := cmd.options.String("out")
+	if out == "" {
+		out = openingbook.Path(sc.config, rules.LexiconName(), "")
+	}
+	book, err := openingbook.ReadFile(out, rules.LexiconName())
+	if err != nil {
+		return nil, err
+	}
+
+	sc.gameRunnerCtx, sc.gameRunnerCancel = context.WithCancel(context.Background())
+	sc.gameRunnerRunning = true
+	run := func() error {
+		defer func() { sc.gameRunnerRunning = false }()
+		added := 0
+		err := book.Build(sc.gameRunnerCtx, rules, opts, func(done int, a bool) {
+			if a {
+				added++
+			}
+			if done%10 == 0 {
+				sc.showMessage(fmt.Sprintf("Book: %d/%d positions done, %d added", done, opts.Positions, added))
+			}
+		})
+		// Save what we have, even if we were stopped.
+		if werr := book.WriteFile(out); werr != nil {
+			return werr
+		}
+		sc.showMessage(fmt.Sprintf("Added %d positions; the book at %s now has %d positions",
+			added, out, len(book.Positions)))
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	if cmd.options.Bool("block") {
+		return nil, run()
+	}
+	go func() {
+		if err := run(); err != nil {
+			sc.showError(err)
+		}
+	}()
+	return msg(fmt.Sprintf("Started building book with %d positions...", opts.Positions)), nil
+}
+
+func (sc *ShellController) showBook(cmd *shellcmd) (*Response, error) {
+	if sc.game == nil {
+		return nil, errors.New("please load or create a game first")
+	}
+	path := cmd.options.String("in")
+	if path == "" {
+		path = openingbook.Path(sc.config, sc.game.LexiconName(), "")
+	}
+	book, err := openingbook.ReadFile(path, sc.game.LexiconName())
+	if err != nil {
+		return nil, err
+	}
+	plays := book.Lookup(sc.game.Game)
+	if len(plays) == 0 {
+		return msg("This position is not in the book."), nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-20s%-9s%s\n", "Play", "Win%", "Equity")
+	for _, p := range plays {
+		fmt.Fprintf(&sb, "%-20s%-9.2f%.2f\n", p.Move, p.WinPct*100, p.Equity)
+	}
+	return msg(sb.String()), nil
+}
@@ -0,0 +1,105 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/board"
+	"github.com/domino14/macondo/move"
+	"github.com/domino14/macondo/movegen"
+)
+
+var bonusNames = map[string]board.BonusSquare{
+	"2LS": board.Bonus2LS,
+	"3LS": board.Bonus3LS,
+	"4LS": board.Bonus4LS,
+	"2WS": board.Bonus2WS,
+	"3WS": board.Bonus3WS,
+	"4WS": board.Bonus4WS,
+}
+
+// genConstraints makes move generation constraints out of the options of
+// the gen command. It returns nil if there are none.
+func genConstraints(opts CmdOptions, alph *tilemapping.TileMapping, dim int) (*movegen.Constraints, error) {
+	c := &movegen.Constraints{}
+	set := false
+	if sq := opts.String("square"); sq != "" {
+		row, col, vertical := move.FromBoardGameCoords(sq)
+		if !strings.EqualFold(move.ToBoardGameCoords(row, col, vertical), sq) ||
+			row < 0 || col < 0 || row >= dim || col >= dim {
+			return nil, fmt.Errorf("%q is not a square; try something like 8H", sq)
+		}
+		c.Square = &movegen.Square{Row: row, Col: col}
+		set = true
+	}
+	if opts.String("row") != "" {
+		row, err := opts.Int("row")
+		if err != nil || row < 1 || row > dim {
+			return nil, fmt.Errorf("row must be a number from 1 to %d", dim)
+		}
+		row--
+		c.Row = &row
+		set = true
+	}
+	if s := strings.ToUpper(opts.String("col")); s != "" {
+		col := int(s[0]) - 'A'
+		if len(s) != 1 || col < 0 || col >= dim {
+			return nil, fmt.Errorf("col must be a letter from A to %c", 'A'+dim-1)
+		}
+		c.Col = &col
+		set = true
+	}
+	if s := opts.String("tiles"); s != "" {
+		tiles, err := tilemapping.ToMachineWord(strings.ToUpper(s), alph)
+		if err != nil {
+			return nil, err
+		}
+		c.Tiles = tiles
+		set = true
+	}
+	minLength, err := opts.IntDefault("minlength", 0)
+	if err != nil {
+		return nil, err
+	}
+	if minLength > 0 {
+		c.MinLength = minLength
+		set = true
+	}
+	if opts.Bool("bingo") {
+		c.Bingo = true
+		set = true
+	}
+	if s := opts.String("bonus"); s != "" {
+		b, ok := bonusNames[strings.ToUpper(s)]
+		if !ok {
+			return nil, fmt.Errorf("unknown bonus square %q; try 2LS, 3LS, 2WS or 3WS", s)
+		}
+		c.Bonus = b
+		set = true
+	}
+	switch s := strings.ToLower(opts.String("hooks")); s {
+	case "":
+	case "required":
+		c.Hooks = movegen.HooksRequired
+		set = true
+	case "forbidden":
+		c.Hooks = movegen.HooksForbidden
+		set = true
+	default:
+		return nil, fmt.Errorf("hooks must be required or forbidden, not %q", s)
+	}
+	if s := opts.String("through"); s != "" {
+		ml, err := alph.Val(strings.ToUpper(s))
+		if err != nil || ml == 0 {
+			return nil, fmt.Errorf("%q is not a letter", s)
+		}
+		c.Through = ml
+		set = true
+	}
+	if !set {
+		return nil, nil
+	}
+	return c, nil
+}
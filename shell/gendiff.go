@@ -0,0 +1,89 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/domino14/word-golib/kwg"
+
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/equity"
+	"github.com/domino14/macondo/movegen/difftest"
+)
+
+func (sc *ShellController) gendiff(cmd *shellcmd) (*Response, error) {
+	if err := sc.classicOnly("gendiff"); err != nil {
+		return nil, err
+	}
+	if sc.solving() {
+		return nil, errMacondoSolving
+	}
+	lexicon := sc.config.GetString(config.ConfigDefaultLexicon)
+	gd, err := kwg.Get(sc.config.AllSettings(), lexicon)
+	if err != nil {
+		return nil, err
+	}
+	opts := difftest.Options{ReproDir: cmd.options.String("reprodir")}
+	if opts.MaxDiffs, err = cmd.options.IntDefault("max", 10); err != nil {
+		return nil, err
+	}
+	var calcs []equity.EquityCalculator
+	if cmd.options.Bool("equity") {
+		c, err := equity.NewCombinedStaticCalculator(lexicon, sc.config, "", equity.PEGAdjustmentFilename)
+		if err != nil {
+			return nil, err
+		}
+		calcs = []equity.EquityCalculator{c}
+		opts.CompareEquity = true
+	}
+	var other difftest.Generator
+	switch against := cmd.options.String("against"); against {
+	case "", "small":
+		if opts.CompareEquity {
+			return nil, errors.New("the small generator does not assign equities; try brute or wolges")
+		}
+		other = difftest.NewGordonSmall(gd)
+		opts.PlaysOnly = true
+	case "brute":
+		other = difftest.NewBruteForce(gd, calcs...)
+	case "wolges":
+		url := sc.config.GetString(config.ConfigWolgesAwsmUrl)
+		if url == "" {
+			return nil, fmt.Errorf("comparing with wolges needs %s to be set", config.ConfigWolgesAwsmUrl)
+		}
+		other = difftest.NewWolges(url, sc.config, calcs...)
+	default:
+		return nil, fmt.Errorf("unknown generator %q; try small, brute or wolges", against)
+	}
+
+	var positions difftest.PositionSource
+	if cmd.options.Bool("samples") {
+		positions = difftest.SampleBoards(sc.config, lexicon)
+	} else {
+		games, err := cmd.options.IntDefault("games", 10)
+		if err != nil {
+			return nil, err
+		}
+		if games < 1 {
+			return nil, errors.New("need at least one game")
+		}
+		positions = difftest.SelfPlay(sc.config, games)
+	}
+
+	res, err := difftest.Run(context.Background(), positions, difftest.NewGordon(gd, calcs...), other, opts)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Compared %d moves in %d positions; %d positions differ.\n",
+		res.Moves, res.Positions, len(res.Diffs))
+	for _, d := range res.Diffs {
+		sb.WriteString("\n" + d.String())
+	}
+	for _, fn := range res.ReproFiles {
+		sb.WriteString("\nWrote " + fn)
+	}
+	return msg(sb.String()), nil
+}
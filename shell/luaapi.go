@@ -0,0 +1,332 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/domino14/word-golib/tilemapping"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/domino14/macondo/move"
+)
+
+// The functions in this file make up the structured Lua API. Unlike the
+// text commands in script.go, they take Lua values and return tables, and
+// they raise a Lua error if something goes wrong, which a script can catch
+// with pcall.
+
+// luaHandlers is the global table of event handlers, keyed by event name.
+const luaHandlers = "macondo_handlers"
+
+// simPollInterval is how often a scripted sim checks its limits.
+const simPollInterval = 50 * time.Millisecond
+
+func luaCheck(L *lua.LState, err error) {
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+}
+
+// luaGame returns the shell of the script, or raises a Lua error if no
+// game is loaded.
+func luaGame(L *lua.LState) *ShellController {
+	sc := getShell(L)
+	if sc.game == nil {
+		L.RaiseError("please load or create a game first")
+	}
+	return sc
+}
+
+// luaCmd parses the options of a command given as one string.
+func luaCmd(L *lua.LState, name string, idx int) *shellcmd {
+	cmd, err := extractFields(strings.TrimSpace(name + " " + L.OptString(idx, "")))
+	luaCheck(L, err)
+	return cmd
+}
+
+func moveTable(L *lua.LState, m *move.Move, alph *tilemapping.TileMapping) *lua.LTable {
+	t := L.NewTable()
+	t.RawSetString("move", lua.LString(m.ShortDescription()))
+	t.RawSetString("action", lua.LString(m.MoveTypeString()))
+	t.RawSetString("coords", lua.LString(m.BoardCoords()))
+	t.RawSetString("tiles", lua.LString(m.TilesString()))
+	t.RawSetString("leave", lua.LString(m.Leave().UserVisible(alph)))
+	t.RawSetString("score", lua.LNumber(m.Score()))
+	t.RawSetString("equity", lua.LNumber(m.Equity()))
+	t.RawSetString("tiles_played", lua.LNumber(m.TilesPlayed()))
+	t.RawSetString("bingo", lua.LBool(m.BingoPlayed()))
+	return t
+}
+
+func (sc *ShellController) movesTable(L *lua.LState) *lua.LTable {
+	t := L.NewTable()
+	for i, m := range sc.curPlayList {
+		mt := moveTable(L, m, sc.game.Alphabet())
+		mt.RawSetString("index", lua.LNumber(i+1))
+		t.Append(mt)
+	}
+	return t
+}
+
+func (sc *ShellController) simTable(L *lua.LState) *lua.LTable {
+	t := L.NewTable()
+	if !sc.simmer.Ready() {
+		return t
+	}
+	for _, sp := range sc.simmer.PlaysByWinProb() {
+		pt := moveTable(L, sp.Move(), sc.game.Alphabet())
+		// index is the play's place in the generated moves, for commit.
+		pt.RawSetString("index", lua.LNumber(slices.Index(sc.curPlayList, sp.Move())+1))
+		pt.RawSetString("win_prob", lua.LNumber(sp.WinProb()))
+		pt.RawSetString("win_prob_err", lua.LNumber(sp.WinProbStdErr()))
+		pt.RawSetString("sim_equity", lua.LNumber(sp.EquityMean()))
+		pt.RawSetString("sim_equity_err", lua.LNumber(sp.EquityStdErr()))
+		pt.RawSetString("ignored", lua.LBool(sp.Ignored()))
+		t.Append(pt)
+	}
+	return t
+}
+
+// sortedTiles shows tiles in alphabetical order, as racks are shown.
+func sortedTiles(tiles []tilemapping.MachineLetter, alph *tilemapping.TileMapping) string {
+	mw := tilemapping.MachineWord(slices.Clone(tiles))
+	slices.Sort(mw)
+	return mw.UserVisible(alph)
+}
+
+func (sc *ShellController) stateTable(L *lua.LState) *lua.LTable {
+	g := sc.game
+	alph := g.Alphabet()
+	t := L.NewTable()
+	t.RawSetString("turn", lua.LNumber(g.Turn()))
+	t.RawSetString("onturn", lua.LNumber(g.PlayerOnTurn()+1))
+	t.RawSetString("playing", lua.LBool(sc.IsPlaying()))
+	t.RawSetString("spread", lua.LNumber(g.CurrentSpread()))
+
+	players := L.NewTable()
+	history := g.History()
+	for i := 0; i < g.NumPlayers(); i++ {
+		p := L.NewTable()
+		p.RawSetString("nickname", lua.LString(history.Players[i].Nickname))
+		p.RawSetString("score", lua.LNumber(g.PointsFor(i)))
+		p.RawSetString("rack", lua.LString(g.RackLettersFor(i)))
+		players.Append(p)
+	}
+	t.RawSetString("players", players)
+
+	bag := g.Bag().Peek()
+	t.RawSetString("bag", lua.LString(sortedTiles(bag, alph)))
+	t.RawSetString("tiles_in_bag", lua.LNumber(len(bag)))
+	// The unseen tiles are those in the bag and on the other racks.
+	unseen := slices.Clone(bag)
+	for i := 0; i < g.NumPlayers(); i++ {
+		if i != g.PlayerOnTurn() {
+			unseen = append(unseen, g.RackFor(i).TilesOn()...)
+		}
+	}
+	t.RawSetString("unseen", lua.LString(sortedTiles(unseen, alph)))
+
+	// The board is a table of rows, with a . for an empty square and a
+	// lowercase letter for a blank.
+	bd := g.Board()
+	rows := L.NewTable()
+	for r := 0; r < bd.Dim(); r++ {
+		var sb strings.Builder
+		for c := 0; c < bd.Dim(); c++ {
+			ml := bd.GetLetter(r, c)
+			if ml == 0 {
+				sb.WriteString(".")
+			} else {
+				sb.WriteString(ml.UserVisible(alph, false))
+			}
+		}
+		rows.Append(lua.LString(sb.String()))
+	}
+	t.RawSetString("board", rows)
+	return t
+}
+
+// emit calls the handlers of an event with the given values. It returns
+// false if any handler returned false.
+func emit(L *lua.LState, event string, args ...lua.LValue) bool {
+	handlers, ok := L.GetGlobal(luaHandlers).(*lua.LTable)
+	if !ok {
+		return true
+	}
+	fns, ok := handlers.RawGetString(event).(*lua.LTable)
+	if !ok {
+		return true
+	}
+	keepGoing := true
+	fns.ForEach(func(_, fn lua.LValue) {
+		err := L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, args...)
+		luaCheck(L, err)
+		if ret := L.Get(-1); ret == lua.LFalse {
+			keepGoing = false
+		}
+		L.Pop(1)
+	})
+	return keepGoing
+}
+
+func hasHandlers(L *lua.LState, event string) bool {
+	handlers, ok := L.GetGlobal(luaHandlers).(*lua.LTable)
+	if !ok {
+		return false
+	}
+	fns, ok := handlers.RawGetString(event).(*lua.LTable)
+	return ok && fns.Len() > 0
+}
+
+// On registers a handler for an event: load, turn, gen, move or sim.
+func On(L *lua.LState) int {
+	event := L.CheckString(1)
+	fn := L.CheckFunction(2)
+	switch event {
+	case "load", "turn", "gen", "move", "sim":
+	default:
+		L.ArgError(1, "unknown event "+event)
+	}
+	handlers, ok := L.GetGlobal(luaHandlers).(*lua.LTable)
+	if !ok {
+		handlers = L.NewTable()
+		L.SetGlobal(luaHandlers, handlers)
+	}
+	fns, ok := handlers.RawGetString(event).(*lua.LTable)
+	if !ok {
+		fns = L.NewTable()
+		handlers.RawSetString(event, fns)
+	}
+	fns.Append(fn)
+	return 0
+}
+
+// NewGame starts a new game, as the new command does, and returns its
+// state.
+func NewGame(L *lua.LState) int {
+	sc := getShell(L)
+	_, err := sc.newGame(&shellcmd{cmd: "new"})
+	luaCheck(L, err)
+	state := sc.stateTable(L)
+	emit(L, "load", state)
+	L.Push(state)
+	return 1
+}
+
+// State returns a table with the board, racks, bag and scores.
+func State(L *lua.LState) int {
+	L.Push(luaGame(L).stateTable(L))
+	return 1
+}
+
+// Moves generates moves and returns them as a table. The optional
+// arguments are how many moves to generate and a string with the options
+// of the gen command.
+func Moves(L *lua.LState) int {
+	sc := luaGame(L)
+	cmd := luaCmd(L, "gen", 2)
+	cmd.args = []string{strconv.Itoa(L.OptInt(1, 15))}
+	_, err := sc.generate(cmd)
+	luaCheck(L, err)
+	moves := sc.movesTable(L)
+	emit(L, "gen", moves)
+	L.Push(moves)
+	return 1
+}
+
+// Commit plays a move, given as the commit command takes it; for example
+// "8D QI", "exch ABC" or "#1" for the first generated move. It returns the
+// move as a table.
+func Commit(L *lua.LState) int {
+	sc := luaGame(L)
+	fields := strings.Fields(L.CheckString(1))
+	if len(fields) == 0 {
+		L.ArgError(1, "need a move to commit")
+	}
+	if sc.solving() {
+		luaCheck(L, errMacondoSolving)
+	}
+	m, err := sc.parseCommitMove(sc.game.PlayerOnTurn(), fields)
+	luaCheck(L, err)
+	luaCheck(L, sc.commitMove(m))
+	mt := moveTable(L, m, sc.game.Alphabet())
+	emit(L, "move", mt, sc.stateTable(L))
+	L.Push(mt)
+	return 1
+}
+
+// Simulate sims the generated moves and returns the results as a table,
+// best first. It takes a string with the options of the sim command, and
+// also -iterations and -seconds to limit the sim. Every -interval seconds
+// (default 1) the sim handlers are called with the iteration count and the
+// results so far; the sim stops if one returns false.
+func Simulate(L *lua.LState) int {
+	sc := luaGame(L)
+	cmd := luaCmd(L, "sim", 1)
+	if len(sc.curPlayList) == 0 {
+		luaCheck(L, errors.New("please generate some plays first"))
+	}
+	if sc.simmer.IsSimming() {
+		luaCheck(L, errors.New("simming already, please do a `sim stop` first"))
+	}
+	if sc.solving() {
+		luaCheck(L, errMacondoSolving)
+	}
+	iterations, err := cmd.options.IntDefault("iterations", 0)
+	luaCheck(L, err)
+	seconds, err := cmd.options.FloatDefault("seconds", 0)
+	luaCheck(L, err)
+	interval, err := cmd.options.FloatDefault("interval", 1)
+	luaCheck(L, err)
+	for _, opt := range []string{"iterations", "seconds", "interval"} {
+		delete(cmd.options, opt)
+	}
+	if iterations <= 0 && seconds <= 0 && cmd.options.String("stop") == "" && !hasHandlers(L, "sim") {
+		luaCheck(L, errors.New("the sim needs -iterations, -seconds, -stop or a sim handler to end"))
+	}
+	luaCheck(L, sc.prepareSim(cmd.options))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if seconds > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, time.Duration(seconds*float64(time.Second)))
+		defer cancelTimeout()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- sc.simmer.Simulate(ctx)
+	}()
+
+	ticker := time.NewTicker(simPollInterval)
+	defer ticker.Stop()
+	lastEvent := time.Now()
+	for {
+		select {
+		case err := <-done:
+			luaCheck(L, err)
+			L.Push(sc.simTable(L))
+			return 1
+		case <-ticker.C:
+			if iterations > 0 && sc.simmer.Iterations() >= iterations {
+				cancel()
+			}
+			if time.Since(lastEvent).Seconds() >= interval {
+				lastEvent = time.Now()
+				if !emit(L, "sim", lua.LNumber(sc.simmer.Iterations()), sc.simTable(L)) {
+					cancel()
+				}
+			}
+		}
+	}
+}
+
+// SimResults returns the results of the last sim as a table, best first.
+func SimResults(L *lua.LState) int {
+	L.Push(luaGame(L).simTable(L))
+	return 1
+}
@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/domino14/macondo/progress"
+)
+
+// progressInterval is how often the progress of sims, endgames,
+// pre-endgames and inferences is logged.
+const progressInterval = 10 * time.Second
+
+// logProgress logs a progress event from one of the engines.
+func logProgress(e progress.Event) {
+	l := log.Info().Str("source", e.Source).Uint64("iterations", e.Iterations).
+		Str("elapsed", e.Elapsed.Round(time.Second).String())
+	if e.Remaining > 0 {
+		l = l.Str("remaining", e.Remaining.Round(time.Second).String())
+	}
+	if e.Leader != "" {
+		l = l.Str("leader", e.Leader).Float64("value", e.Value)
+	}
+	if e.Depth > 0 {
+		l = l.Int("depth", e.Depth)
+	}
+	if len(e.PV) > 0 {
+		l = l.Str("pv", strings.Join(e.PV, "; "))
+	}
+	if e.Done {
+		l.Msg("done")
+		return
+	}
+	l.Msg("progress")
+}
@@ -0,0 +1,105 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/domino14/macondo/gcgio"
+	"github.com/domino14/macondo/review"
+)
+
+func (sc *ShellController) review(cmd *shellcmd) (*Response, error) {
+	if len(cmd.args) == 1 && cmd.args[0] == "stop" {
+		if !sc.gameRunnerRunning {
+			return nil, errors.New("review is not running")
+		}
+		sc.gameRunnerCancel()
+		sc.gameRunnerRunning = false
+		return nil, nil
+	}
+	if sc.gameRunnerRunning {
+		return nil, errors.New("please stop automatic game runner before running another one")
+	}
+	if sc.game == nil {
+		return nil, errors.New("please load a game first with the `load` command")
+	}
+	if sc.solving() {
+		return nil, errMacondoSolving
+	}
+	opts := review.Options{Players: cmd.options.StringArray("player")}
+	var err error
+	if opts.Candidates, err = cmd.options.IntDefault("candidates", review.DefaultCandidates); err != nil {
+		return nil, err
+	}
+	if opts.SimPlies, err = cmd.options.IntDefault("plies", review.DefaultSimPlies); err != nil {
+		return nil, err
+	}
+	if opts.SimPlies == 0 {
+		// Zero would mean the default; a negative number turns sims off.
+		opts.SimPlies = -1
+	}
+	if opts.EndgamePlies, err = cmd.options.IntDefault("endgameplies", review.DefaultEndgamePlies); err != nil {
+		return nil, err
+	}
+	if opts.Threads, err = cmd.options.IntDefault("threads", runtime.NumCPU()); err != nil {
+		return nil, err
+	}
+	turnTime, err := cmd.options.IntDefault("turntime", int(review.DefaultTurnTime/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	opts.TurnTime = time.Duration(turnTime) * time.Second
+	budget, err := cmd.options.IntDefault("budget", 0)
+	if err != nil {
+		return nil, err
+	}
+	opts.Budget = time.Duration(budget) * time.Second
+	blunderWinPct, err := cmd.options.FloatDefault("blunderwin", review.DefaultBlunderWinPct)
+	if err != nil {
+		return nil, err
+	}
+	blunderEquity, err := cmd.options.FloatDefault("blunderequity", review.DefaultBlunderEquity)
+	if err != nil {
+		return nil, err
+	}
+	gcgPath := cmd.options.String("gcg")
+	opts.OnTurn = func(t *review.TurnReview) {
+		sc.showMessage(fmt.Sprintf("Event %d (%s): %s", t.EventIndex+1, t.Player, t.Note()))
+	}
+
+	history := sc.game.History()
+	sc.gameRunnerCtx, sc.gameRunnerCancel = context.WithCancel(context.Background())
+	sc.gameRunnerRunning = true
+	run := func() error {
+		defer func() { sc.gameRunnerRunning = false }()
+		report, err := review.Review(sc.gameRunnerCtx, sc.config, history, opts)
+		if report == nil {
+			return err
+		}
+		sc.showMessage(report.Format(blunderWinPct, blunderEquity))
+		if gcgPath != "" {
+			contents, gerr := gcgio.GameHistoryToGCG(report.Annotate(history), true)
+			if gerr != nil {
+				return gerr
+			}
+			if gerr = os.WriteFile(gcgPath, []byte(contents), 0644); gerr != nil {
+				return gerr
+			}
+			sc.showMessage("Annotated gcg written to " + gcgPath)
+		}
+		return err
+	}
+	if cmd.options.Bool("block") {
+		return nil, run()
+	}
+	go func() {
+		if err := run(); err != nil {
+			sc.showError(err)
+		}
+	}()
+	return msg("Started review..."), nil
+}
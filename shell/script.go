@@ -6,6 +6,8 @@ import (
 
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
+
+	"github.com/domino14/macondo/wordstudy"
 )
 
 func getShell(L *lua.LState) *ShellController {
@@ -50,6 +52,7 @@ func Load(L *lua.LState) int {
 		L.Push(lua.LString("ERROR: " + err.Error()))
 		return 1
 	}
+	emit(L, "load", sc.stateTable(L))
 	L.Push(lua.LString(r.message))
 	// return number of results pushed to stack.
 	return 1
@@ -81,6 +84,7 @@ func Turn(L *lua.LState) int {
 		log.Err(err).Msg("error-executing-turn")
 		return 0
 	}
+	emit(L, "turn", sc.stateTable(L))
 	L.Push(lua.LString(r.message))
 	return 1
 }
@@ -132,6 +136,89 @@ func Sim(L *lua.LState) int {
 	return 1
 }
 
+func wordTable(L *lua.LState, words []string) *lua.LTable {
+	table := L.NewTable()
+	for _, w := range words {
+		table.Append(lua.LString(w))
+	}
+	return table
+}
+
+// luaWordStudy returns the word study of the shell, or raises a Lua error.
+func luaWordStudy(L *lua.LState) *wordstudy.Study {
+	study, err := getShell(L).wordStudy()
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	return study
+}
+
+// Anagram returns a table of the anagrams of the letters.
+func Anagram(L *lua.LState) int {
+	words, err := luaWordStudy(L).Anagrams(L.CheckString(1))
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	L.Push(wordTable(L, words))
+	return 1
+}
+
+// Subanagram returns a table of the sub-anagrams of the letters, with an
+// optional least length.
+func Subanagram(L *lua.LState) int {
+	words, err := luaWordStudy(L).SubAnagrams(L.CheckString(1), L.OptInt(2, 2))
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	L.Push(wordTable(L, words))
+	return 1
+}
+
+// Hooks returns the front and back hooks of a word, as two strings.
+func Hooks(L *lua.LState) int {
+	h, err := luaWordStudy(L).Hooks(L.CheckString(1))
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	L.Push(lua.LString(h.Front))
+	L.Push(lua.LString(h.Back))
+	return 2
+}
+
+// Pattern returns a table of the words that match a pattern.
+func Pattern(L *lua.LState) int {
+	words, err := luaWordStudy(L).Pattern(L.CheckString(1))
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	L.Push(wordTable(L, words))
+	return 1
+}
+
+// Problist returns a table of alphagrams of a length, ranked from and to,
+// in order of probability. Each has the fields alphagram, words, rank and
+// combinations.
+func Problist(L *lua.LState) int {
+	length := L.CheckInt(1)
+	from := L.OptInt(2, 1)
+	to := L.OptInt(3, from+99)
+	list, err := luaWordStudy(L).ProbabilityList(length, from, to)
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	table := L.NewTable()
+	for _, a := range list {
+		t := L.NewTable()
+		t.RawSetString("alphagram", lua.LString(a.Alphagram))
+		t.RawSetString("words", wordTable(L, a.Words))
+		t.RawSetString("rank", lua.LNumber(a.Rank))
+		t.RawSetString("combinations", lua.LNumber(a.Combinations))
+		table.Append(t)
+	}
+	L.Push(table)
+	return 1
+}
+
 func (sc *ShellController) script(cmd *shellcmd) (*Response, error) {
 	if cmd.args == nil {
 		return nil, errors.New("need arguments for script")
@@ -156,6 +243,18 @@ func (sc *ShellController) script(cmd *shellcmd) (*Response, error) {
 	L.SetGlobal("macondo_turn", L.NewFunction(Turn))
 	L.SetGlobal("macondo_endgame", L.NewFunction(Endgame))
 	L.SetGlobal("macondo_sim", L.NewFunction(Sim))
+	L.SetGlobal("macondo_anagram", L.NewFunction(Anagram))
+	L.SetGlobal("macondo_subanagram", L.NewFunction(Subanagram))
+	L.SetGlobal("macondo_hooks", L.NewFunction(Hooks))
+	L.SetGlobal("macondo_pattern", L.NewFunction(Pattern))
+	L.SetGlobal("macondo_problist", L.NewFunction(Problist))
+	L.SetGlobal("macondo_on", L.NewFunction(On))
+	L.SetGlobal("macondo_new", L.NewFunction(NewGame))
+	L.SetGlobal("macondo_state", L.NewFunction(State))
+	L.SetGlobal("macondo_moves", L.NewFunction(Moves))
+	L.SetGlobal("macondo_commit", L.NewFunction(Commit))
+	L.SetGlobal("macondo_simulate", L.NewFunction(Simulate))
+	L.SetGlobal("macondo_sim_results", L.NewFunction(SimResults))
 	if len(cmd.args) > 1 {
 		table := L.NewTable()
 		joinedStr := strings.Join(cmd.args[1:], " ")
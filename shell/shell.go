@@ -25,7 +25,6 @@ import (
 
 	"github.com/domino14/macondo/ai/bot"
 	"github.com/domino14/macondo/automatic"
-	"github.com/domino14/macondo/board"
 	"github.com/domino14/macondo/cgp"
 	"github.com/domino14/macondo/config"
 	"github.com/domino14/macondo/endgame/negamax"
@@ -39,6 +38,8 @@ import (
 	"github.com/domino14/macondo/preendgame"
 	"github.com/domino14/macondo/rangefinder"
 	"github.com/domino14/macondo/turnplayer"
+	"github.com/domino14/macondo/variant"
+	"github.com/domino14/macondo/wordstudy"
 )
 
 const (
@@ -57,6 +58,10 @@ var (
 type ShellOptions struct {
 	turnplayer.GameOptions
 	lowercaseMoves bool
+	// seed seeds new games if it is non-zero.
+	seed uint64
+	// ruleset names the rule set of new games, if it is not empty.
+	ruleset string
 }
 
 func NewShellOptions() *ShellOptions {
@@ -80,13 +85,28 @@ func (opts *ShellOptions) Show(key string) (bool, string) {
 		return true, fmt.Sprintf("%v", rule)
 	case "board":
 		return true, opts.BoardLayoutName
+	case "seed":
+		if opts.seed == 0 {
+			return true, "off"
+		}
+		return true, strconv.FormatUint(opts.seed, 10)
+	case "variant":
+		if opts.Variant == "" {
+			return true, string(variant.VarClassic)
+		}
+		return true, string(opts.Variant)
+	case "ruleset":
+		if opts.ruleset == "" {
+			return true, "off"
+		}
+		return true, opts.ruleset
 	default:
 		return false, "No such option: " + key
 	}
 }
 
 func (opts *ShellOptions) ToDisplayText() string {
-	keys := []string{"lexicon", "challenge", "lower", "board"}
+	keys := []string{"lexicon", "challenge", "lower", "board", "variant", "seed", "ruleset"}
 	out := strings.Builder{}
 	out.WriteString("Settings:\n")
 	for _, key := range keys {
@@ -106,12 +126,10 @@ type ShellController struct {
 
 	game *bot.BotTurnPlayer
 
-	simmer        *montecarlo.Simmer
-	simCtx        context.Context
-	simCancel     context.CancelFunc
-	simTicker     *time.Ticker
-	simTickerDone chan bool
-	simLogFile    *os.File
+	simmer     *montecarlo.Simmer
+	simCtx     context.Context
+	simCancel  context.CancelFunc
+	simLogFile *os.File
 
 	rangefinder     *rangefinder.RangeFinder
 	rangefinderFile *os.File
@@ -140,6 +158,8 @@ type ShellController struct {
 	botCtx       context.Context
 	botCtxCancel context.CancelFunc
 	botBusy      bool
+
+	quizState *wordstudy.Quiz
 }
 
 type Mode int
@@ -189,6 +209,7 @@ func NewShellController(cfg *config.Config, execPath string) *ShellController {
 	execPath = config.FindBasePath(execPath)
 	opts := NewShellOptions()
 	opts.SetDefaults(cfg)
+	opts.seed = cfg.GetUint64(config.ConfigSeed)
 
 	return &ShellController{l: l, config: cfg, execPath: execPath, options: opts}
 }
@@ -223,6 +244,13 @@ func (sc *ShellController) Set(key string, args []string) (string, error) {
 			err = sc.options.SetBoardLayoutName(args[0])
 			_, ret = sc.options.Show("board")
 		}
+	case "variant":
+		if sc.IsPlaying() {
+			err = errors.New("Cannot change the variant while a game is active (try `unload` to quit game)")
+		} else {
+			err = sc.options.SetVariant(args[0])
+			_, ret = sc.options.Show("variant")
+		}
 	case "challenge":
 		err = sc.options.SetChallenge(args[0])
 		_, ret = sc.options.Show("challenge")
@@ -230,6 +258,36 @@ func (sc *ShellController) Set(key string, args []string) (string, error) {
 		if err != nil && sc.game != nil {
 			sc.game.SetChallengeRule(sc.options.ChallengeRule)
 		}
+	case "seed":
+		var seed uint64
+		if args[0] != "off" {
+			seed, err = strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				break
+			}
+		}
+		sc.options.seed = seed
+		// New games pick up the seed from the config.
+		sc.config.Set(config.ConfigSeed, seed)
+		if seed != 0 && sc.game != nil {
+			sc.game.SetSeed(seed)
+		}
+		_, ret = sc.options.Show("seed")
+	case "ruleset":
+		if sc.IsPlaying() {
+			err = errors.New("Cannot change the rule set while a game is active (try `unload` to quit game)")
+			break
+		}
+		name := args[0]
+		if name == "off" {
+			name = ""
+		} else if _, err = game.LoadRuleSet(sc.config, name); err != nil {
+			break
+		}
+		sc.options.ruleset = name
+		// New games pick up the rule set from the config.
+		sc.config.Set(config.ConfigDefaultRuleSet, name)
+		_, ret = sc.options.Show("ruleset")
 	case "lower":
 		val, err := strconv.ParseBool(args[0])
 		if err == nil {
@@ -248,6 +306,17 @@ func (sc *ShellController) Set(key string, args []string) (string, error) {
 	}
 }
 
+// classicOnly returns an error if new games are not classic games. The
+// automatic game runner only plays classic games so far.
+func (sc *ShellController) classicOnly(feature string) error {
+	switch sc.options.Variant {
+	case "", variant.VarClassic:
+		return nil
+	}
+	return fmt.Errorf("%s only supports the classic variant, not %s (try `set variant classic`)",
+		feature, sc.options.Variant)
+}
+
 func (sc *ShellController) initGameDataStructures() error {
 	sc.simmer = &montecarlo.Simmer{}
 	c, err := equity.NewCombinedStaticCalculator(
@@ -257,6 +326,7 @@ func (sc *ShellController) initGameDataStructures() error {
 		return err
 	}
 	sc.simmer.Init(sc.game.Game, []equity.EquityCalculator{c}, c, sc.config)
+	sc.simmer.SetProgressListener(logProgress, progressInterval)
 	sc.gen = sc.game.MoveGenerator()
 
 	gd, err := kwg.Get(sc.config.AllSettings(), sc.game.LexiconName())
@@ -265,16 +335,16 @@ func (sc *ShellController) initGameDataStructures() error {
 	}
 
 	sc.backupgen = movegen.NewGordonGenerator(gd, sc.game.Board(), sc.game.Bag().LetterDistribution())
+	sc.backupgen.SetRuleSet(sc.game.Rules().RuleSet())
+	sc.backupgen.SetVariant(sc.game.Rules().Variant())
 
 	sc.rangefinder = &rangefinder.RangeFinder{}
 	sc.rangefinder.Init(sc.game.Game, []equity.EquityCalculator{c}, sc.config)
+	sc.rangefinder.SetProgressListener(logProgress, progressInterval)
 
 	// initialize the elite bot
 
-	leavesFile := ""
-	if sc.game.Board().Dim() == 21 { // ghetto
-		leavesFile = "super-leaves.klv2"
-	}
+	leavesFile := equity.LeavesFilenameFor(sc.game.Rules().BoardName(), sc.game.Rules().Variant())
 
 	conf := &bot.BotConfig{Config: *sc.config, MinSimPlies: 5, LeavesFile: leavesFile,
 		UseOppRacksInAnalysis: false}
@@ -293,6 +363,18 @@ func (sc *ShellController) IsPlaying() bool {
 	return sc.game != nil && sc.game.IsPlaying()
 }
 
+// expandPath expands a path that starts with ~/ to the home directory.
+func expandPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, path[2:]), nil
+}
+
 func (sc *ShellController) loadGCG(args []string) error {
 	var err error
 	var history *pb.GameHistory
@@ -370,15 +452,26 @@ func (sc *ShellController) loadGCG(args []string) error {
 		if err != nil {
 			return err
 		}
+	} else if args[0] == "json" || args[0] == "movelist" {
+		if len(args) < 2 {
+			return errors.New("need to provide a file path")
+		}
+		path, err := expandPath(args[1])
+		if err != nil {
+			return err
+		}
+		if args[0] == "json" {
+			history, err = gcgio.ParseJSON(sc.config, path)
+		} else {
+			history, err = gcgio.ParseMoveList(sc.config, path)
+		}
+		if err != nil {
+			return err
+		}
 	} else {
-		path := args[0]
-		if strings.HasPrefix(path, "~/") {
-			usr, err := user.Current()
-			if err != nil {
-				return err
-			}
-			dir := usr.HomeDir
-			path = filepath.Join(dir, path[2:])
+		path, err := expandPath(args[0])
+		if err != nil {
+			return err
 		}
 		history, err = gcgio.ParseGCG(sc.config, path)
 		if err != nil {
@@ -401,10 +494,7 @@ func (sc *ShellController) loadGCG(args []string) error {
 	if err != nil {
 		return err
 	}
-	leavesFile := ""
-	if strings.HasSuffix(ldName, "_super") {
-		leavesFile = "super-leaves.klv2"
-	}
+	leavesFile := equity.LeavesFilenameFor(boardLayout, variant)
 
 	conf := &bot.BotConfig{Config: *sc.config, LeavesFile: leavesFile}
 	sc.game, err = bot.NewBotTurnPlayerFromGame(g, conf, pb.BotRequest_HASTY_BOT)
@@ -432,10 +522,7 @@ func (sc *ShellController) loadCGP(cgpstr string) error {
 			lexicon)
 	}
 
-	leavesFile := ""
-	if g.History().BoardLayout == board.SuperCrosswordGameLayout {
-		leavesFile = "super-leaves.klv2"
-	}
+	leavesFile := equity.LeavesFilenameFor(g.History().BoardLayout, g.Rules().Variant())
 
 	conf := &bot.BotConfig{Config: *sc.config, LeavesFile: leavesFile}
 	sc.game, err = bot.NewBotTurnPlayerFromGame(g.Game, conf, pb.BotRequest_HASTY_BOT)
@@ -445,9 +532,11 @@ func (sc *ShellController) loadCGP(cgpstr string) error {
 	sc.game.SetBackupMode(game.InteractiveGameplayMode)
 	sc.game.SetStateStackLength(1)
 
-	// Set challenge rule to double by default. This can be overridden.
-	// XXX: can read from cgp file.
-	sc.game.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	// Set challenge rule to double by default, unless the CGP has one.
+	// This can be overridden.
+	if _, ok := g.Opcodes["cr"]; !ok {
+		sc.game.SetChallengeRule(pb.ChallengeRule_DOUBLE)
+	}
 
 	sc.game.RecalculateBoard()
 	return sc.initGameDataStructures()
@@ -667,6 +756,9 @@ func (sc *ShellController) handleAutoplay(args []string, options CmdOptions) err
 	var botcode1, botcode2 pb.BotRequest_BotCode
 	var minsimplies1, minsimplies2 int
 	var err error
+	if err = sc.classicOnly("autoplay"); err != nil {
+		return err
+	}
 	if options.String("logfile") == "" {
 		logfile = "/tmp/autoplay.txt"
 	} else {
@@ -740,6 +832,29 @@ func (sc *ShellController) handleAutoplay(args []string, options CmdOptions) err
 		return errMacondoSolving
 	}
 
+	var dataset *automatic.DatasetOptions
+	if options.String("dataset") != "" {
+		format, err := automatic.ParseDatasetFormat(options.String("datasetformat"))
+		if err != nil {
+			return err
+		}
+		shardsize, err := options.IntDefault("shardsize", 0)
+		if err != nil {
+			return err
+		}
+		candidates, err := options.IntDefault("candidates", automatic.DefaultDatasetCandidates)
+		if err != nil {
+			return err
+		}
+		dataset = &automatic.DatasetOptions{
+			Prefix:          options.String("dataset"),
+			Format:          format,
+			RecordsPerShard: shardsize,
+			NumCandidates:   candidates,
+		}
+		sc.showMessage("training data will be written to " + options.String("dataset") + "-*")
+	}
+
 	sc.showMessage("automatic game runner will log to " + logfile)
 	sc.gameRunnerCtx, sc.gameRunnerCancel = context.WithCancel(context.Background())
 	err = automatic.StartCompVCompStaticGames(
@@ -748,7 +863,7 @@ func (sc *ShellController) handleAutoplay(args []string, options CmdOptions) err
 		[]automatic.AutomaticRunnerPlayer{
 			{LeaveFile: leavefile1, PEGFile: pegfile1, BotCode: botcode1, MinSimPlies: minsimplies1},
 			{LeaveFile: leavefile2, PEGFile: pegfile2, BotCode: botcode2, MinSimPlies: minsimplies2},
-		})
+		}, dataset)
 
 	if err != nil {
 		return err
@@ -840,6 +955,14 @@ func (sc *ShellController) standardModeSwitch(line string, sig chan os.Signal) (
 		return sc.generate(cmd)
 	case "autoplay":
 		return sc.autoplay(cmd)
+	case "tournament":
+		return sc.tournament(cmd)
+	case "review":
+		return sc.review(cmd)
+	case "gendiff":
+		return sc.gendiff(cmd)
+	case "book":
+		return sc.book(cmd)
 	case "sim":
 		return sc.sim(cmd)
 	case "infer":
@@ -876,8 +999,20 @@ func (sc *ShellController) standardModeSwitch(line string, sig chan os.Signal) (
 		return sc.leave(cmd)
 	case "cgp":
 		return sc.cgp(cmd)
+	case "render":
+		return sc.render(cmd)
 	case "check":
 		return sc.check(cmd)
+	case "anagram":
+		return sc.anagram(cmd)
+	case "hooks":
+		return sc.hooks(cmd)
+	case "pattern":
+		return sc.pattern(cmd)
+	case "problist":
+		return sc.problist(cmd)
+	case "quiz":
+		return sc.quiz(cmd)
 	default:
 		msg := fmt.Sprintf("command %v not found", strconv.Quote(cmd.cmd))
 		log.Info().Msg(msg)
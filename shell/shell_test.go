@@ -1,9 +1,14 @@
 package shell
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/wordstudy"
 )
 
 func TestExtractFields(t *testing.T) {
@@ -36,3 +41,110 @@ func TestExtractFields(t *testing.T) {
 		is.Equal(err, t.expErr)
 	}
 }
+
+func TestParseTournamentPlayer(t *testing.T) {
+	is := is.New(t)
+	p, err := parseTournamentPlayer("HASTY_BOT")
+	is.NoErr(err)
+	is.Equal(p.BotCode.String(), "HASTY_BOT")
+	is.Equal(p.LeaveFile, "")
+
+	p, err = parseTournamentPlayer("SIMMING_BOT:trial.klv2::3")
+	is.NoErr(err)
+	is.Equal(p.BotCode.String(), "SIMMING_BOT")
+	is.Equal(p.LeaveFile, "trial.klv2")
+	is.Equal(p.PEGFile, "")
+	is.Equal(p.MinSimPlies, 3)
+
+	_, err = parseTournamentPlayer("FOO_BOT")
+	is.Equal(err.Error(), "bot code FOO_BOT does not exist")
+}
+
+func TestLuaEvents(t *testing.T) {
+	is := is.New(t)
+	L := lua.NewState()
+	defer L.Close()
+	L.SetGlobal("macondo_on", L.NewFunction(On))
+	is.True(emit(L, "sim", lua.LNumber(1)))
+	is.True(!hasHandlers(L, "sim"))
+
+	err := L.DoString(`
+		calls = 0
+		macondo_on('sim', function(n) calls = calls + n end)
+		macondo_on('sim', function(n) return n < 10 end)
+	`)
+	is.NoErr(err)
+	is.True(hasHandlers(L, "sim"))
+	is.True(!hasHandlers(L, "move"))
+	is.True(emit(L, "sim", lua.LNumber(3)))
+	is.True(!emit(L, "sim", lua.LNumber(20)))
+	is.Equal(L.GetGlobal("calls"), lua.LNumber(23))
+
+	err = L.DoString(`macondo_on('nothing', function() end)`)
+	is.True(err != nil)
+}
+
+func TestLuaMoves(t *testing.T) {
+	is := is.New(t)
+	cfg := config.DefaultConfig()
+	opts := NewShellOptions()
+	opts.SetDefaults(&cfg)
+	sc := &ShellController{config: &cfg, options: opts}
+	is.NoErr(sc.loadCGP("15/15/15/15/15/15/15/15/15/15/15/15/15/15/15 AEINRST/ 0/0 0 lex NWL20;"))
+
+	L := lua.NewState()
+	defer L.Close()
+	lsc := L.NewUserData()
+	lsc.Value = sc
+	L.SetGlobal("macondo_shell", lsc)
+	L.SetGlobal("macondo_moves", L.NewFunction(Moves))
+
+	is.NoErr(L.DoString(`moves = macondo_moves(3)`))
+	is.Equal(L.GetGlobal("moves").(*lua.LTable).Len(), 3)
+	is.NoErr(L.DoString(`moves = macondo_moves()`))
+	is.Equal(L.GetGlobal("moves").(*lua.LTable).Len(), 15)
+	is.NoErr(L.DoString(`moves = macondo_moves(5, '-bingo true')`))
+	moves := L.GetGlobal("moves").(*lua.LTable)
+	is.Equal(moves.Len(), 5)
+	is.Equal(moves.RawGetInt(5).(*lua.LTable).RawGetString("tiles_played"), lua.LNumber(7))
+}
+
+func TestQuizStopAndSkip(t *testing.T) {
+	is := is.New(t)
+	sc := &ShellController{}
+	quiz := func(line string) (string, error) {
+		cmd, err := extractFields(line)
+		is.NoErr(err)
+		resp, err := sc.quiz(cmd)
+		if err != nil {
+			return "", err
+		}
+		return resp.message, nil
+	}
+	_, err := quiz("quiz -stop true")
+	is.True(err != nil)
+
+	sc.quizState = wordstudy.NewQuiz([]*wordstudy.Alphagram{
+		{Alphagram: "OPST", Words: []string{"OPTS", "POST", "POTS", "SPOT", "STOP", "TOPS"}},
+		{Alphagram: "IKPS", Words: []string{"SKIP"}},
+		{Alphagram: "AT", Words: []string{"AT", "TA"}},
+	}, false)
+	// STOP and SKIP are answers.
+	out, err := quiz("quiz stop")
+	is.NoErr(err)
+	is.True(strings.Contains(out, "Missed: OPTS POST POTS SPOT TOPS"))
+	out, err = quiz("quiz skip")
+	is.NoErr(err)
+	is.True(strings.HasPrefix(out, "Correct! IKPS"))
+	out, err = quiz("quiz -skip true")
+	is.NoErr(err)
+	is.True(strings.Contains(out, "Missed: AT TA"))
+	// That was the last question.
+	is.Equal(sc.quizState, nil)
+
+	sc.quizState = wordstudy.NewQuiz([]*wordstudy.Alphagram{{Alphagram: "AT", Words: []string{"AT", "TA"}}}, false)
+	out, err = quiz("quiz -stop true")
+	is.NoErr(err)
+	is.Equal(out, "Solved 0 of 0 asked; 1 left.")
+	is.Equal(sc.quizState, nil)
+}
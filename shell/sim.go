@@ -7,8 +7,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
+	"github.com/domino14/word-golib/kwg"
 	"github.com/domino14/word-golib/tilemapping"
 	"github.com/rs/zerolog/log"
 
@@ -16,9 +16,6 @@ import (
 )
 
 func (sc *ShellController) handleSim(args []string, options CmdOptions) error {
-	var plies, threads int
-	var err error
-	stoppingCondition := montecarlo.StopNone
 	if sc.simmer == nil {
 		return errors.New("load a game or something")
 	}
@@ -36,9 +33,26 @@ func (sc *ShellController) handleSim(args []string, options CmdOptions) error {
 	if sc.solving() {
 		return errMacondoSolving
 	}
+	err := sc.prepareSim(options)
+	if err != nil {
+		return err
+	}
+	sc.startSim()
+	return nil
+}
+
+// prepareSim sets the simmer up with the options of the `sim` command, to
+// sim the current play list.
+func (sc *ShellController) prepareSim(options CmdOptions) error {
+	var plies, threads int
+	var err error
+	stoppingCondition := montecarlo.StopNone
 
 	inferMode := montecarlo.InferenceOff
 	knownOppRack := ""
+	knownWords := ""
+	challengeProb := 0.0
+	var seed uint64
 	for opt := range options {
 		switch opt {
 		case "plies":
@@ -68,6 +82,18 @@ func (sc *ShellController) handleSim(args []string, options CmdOptions) error {
 			}
 		case "opprack":
 			knownOppRack = options.String(opt)
+		case "knownwords":
+			knownWords = options.String(opt)
+		case "challengeprob":
+			challengeProb, err = strconv.ParseFloat(options.String(opt), 64)
+			if err != nil {
+				return err
+			}
+		case "seed":
+			seed, err = strconv.ParseUint(options.String(opt), 10, 64)
+			if err != nil {
+				return err
+			}
 
 		case "useinferences":
 			inferences := sc.rangefinder.Inferences()
@@ -99,36 +125,48 @@ func (sc *ShellController) handleSim(args []string, options CmdOptions) error {
 	log.Debug().Int("plies", plies).Int("threads", threads).
 		Int("stoppingCondition", int(stoppingCondition)).Msg("will start sim")
 
-	if sc.game != nil {
-		if threads != 0 {
-			sc.simmer.SetThreads(threads)
-		}
-		err := sc.simmer.PrepareSim(plies, sc.curPlayList)
+	if threads != 0 {
+		sc.simmer.SetThreads(threads)
+	}
+	sc.simmer.SetSeed(seed)
+	err = sc.simmer.PrepareSim(plies, sc.curPlayList)
+	if err != nil {
+		return err
+	}
+	sc.simmer.SetStoppingCondition(stoppingCondition)
+
+	if knownOppRack != "" {
+		knownOppRack = strings.ToUpper(knownOppRack)
+		r, err := tilemapping.ToMachineLetters(knownOppRack, sc.game.Alphabet())
 		if err != nil {
 			return err
 		}
-		sc.simmer.SetStoppingCondition(stoppingCondition)
-
-		if knownOppRack != "" {
-			knownOppRack = strings.ToUpper(knownOppRack)
-			r, err := tilemapping.ToMachineLetters(knownOppRack, sc.game.Alphabet())
-			if err != nil {
-				return err
-			}
-			sc.simmer.SetKnownOppRack(r)
+		sc.simmer.SetKnownOppRack(r)
+	}
+	if inferMode != montecarlo.InferenceOff {
+		sc.simmer.SetInferences(sc.rangefinder.Inferences(), inferMode)
+	}
+	if challengeProb > 0 {
+		if knownWords == "" {
+			return errors.New("need a -knownwords lexicon to model challenges")
+		}
+		gd, err := kwg.Get(sc.config.AllSettings(), knownWords)
+		if err != nil {
+			return err
 		}
-		if inferMode != montecarlo.InferenceOff {
-			sc.simmer.SetInferences(sc.rangefinder.Inferences(), inferMode)
+		err = sc.simmer.SetChallengeModel(&montecarlo.ChallengeModel{
+			KnownWords:    kwg.Lexicon{KWG: *gd},
+			ChallengeProb: challengeProb,
+		})
+		if err != nil {
+			return err
 		}
-		sc.startSim()
 	}
 	return nil
 }
 
 func (sc *ShellController) startSim() {
 	sc.simCtx, sc.simCancel = context.WithCancel(context.Background())
-	sc.simTicker = time.NewTicker(10 * time.Second)
-	sc.simTickerDone = make(chan bool)
 	sc.showMessage("Simulation started. Please do `sim show` and `sim details` to see more info")
 
 	go func() {
@@ -136,22 +174,8 @@ func (sc *ShellController) startSim() {
 		if err != nil {
 			sc.showError(err)
 		}
-		sc.simTickerDone <- true
 		log.Debug().Msg("simulation thread exiting...")
 	}()
-
-	go func() {
-		for {
-			select {
-			case <-sc.simTickerDone:
-				log.Debug().Msg("ticker thread exiting...")
-				return
-			case <-sc.simTicker.C:
-				log.Info().Msgf("Simmer is at %v iterations...",
-					sc.simmer.Iterations())
-			}
-		}
-	}()
 }
 
 func (sc *ShellController) simControlArguments(args []string) error {
@@ -171,8 +195,6 @@ func (sc *ShellController) simControlArguments(args []string) error {
 		if !sc.simmer.IsSimming() {
 			return errors.New("no running sim to stop")
 		}
-		sc.simTicker.Stop()
-		sc.simTickerDone <- true
 		sc.simCancel()
 		if sc.simLogFile != nil {
 			err := sc.simLogFile.Close()
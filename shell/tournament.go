@@ -0,0 +1,143 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/domino14/macondo/automatic"
+	"github.com/domino14/macondo/config"
+	pb "github.com/domino14/macondo/gen/api/proto/macondo"
+	"github.com/domino14/macondo/stats"
+)
+
+// parseTournamentPlayer parses a player spec that looks like
+// BOT_CODE[:leavefile[:pegfile[:minsimplies]]]
+func parseTournamentPlayer(spec string) (automatic.AutomaticRunnerPlayer, error) {
+	p := automatic.AutomaticRunnerPlayer{}
+	fields := strings.Split(spec, ":")
+	if len(fields) > 4 {
+		return p, fmt.Errorf("player spec %s has too many fields", spec)
+	}
+	code, exists := pb.BotRequest_BotCode_value[fields[0]]
+	if !exists {
+		return p, fmt.Errorf("bot code %s does not exist", fields[0])
+	}
+	p.BotCode = pb.BotRequest_BotCode(code)
+	if len(fields) > 1 {
+		p.LeaveFile = fields[1]
+	}
+	if len(fields) > 2 {
+		p.PEGFile = fields[2]
+	}
+	if len(fields) > 3 {
+		plies, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return p, err
+		}
+		p.MinSimPlies = plies
+	}
+	return p, nil
+}
+
+func (sc *ShellController) tournament(cmd *shellcmd) (*Response, error) {
+	if len(cmd.args) == 1 && cmd.args[0] == "stop" {
+		if !sc.gameRunnerRunning {
+			return nil, errors.New("tournament is not running")
+		}
+		sc.gameRunnerCancel()
+		sc.gameRunnerRunning = false
+		return nil, nil
+	}
+	if sc.gameRunnerRunning {
+		return nil, errors.New("please stop automatic game runner before running another one")
+	}
+	if err := sc.classicOnly("tournament"); err != nil {
+		return nil, err
+	}
+	if sc.solving() {
+		return nil, errMacondoSolving
+	}
+	specs := cmd.options.StringArray("player")
+	if len(specs) < 2 {
+		return nil, errors.New("need at least two players; use the -player option for each one")
+	}
+	runnerPlayers := []automatic.AutomaticRunnerPlayer{}
+	for _, spec := range specs {
+		p, err := parseTournamentPlayer(spec)
+		if err != nil {
+			return nil, err
+		}
+		runnerPlayers = append(runnerPlayers, p)
+	}
+	names := automatic.PlayerNames(runnerPlayers)
+	players := make([]automatic.TournamentPlayer, len(runnerPlayers))
+	for i := range runnerPlayers {
+		players[i] = automatic.TournamentPlayer{Name: names[i], AutomaticRunnerPlayer: runnerPlayers[i]}
+	}
+
+	opts := automatic.TournamentOptions{
+		Lexicon:            cmd.options.String("lexicon"),
+		LetterDistribution: cmd.options.String("letterdistribution"),
+	}
+	if opts.Lexicon == "" {
+		opts.Lexicon = sc.config.GetString(config.ConfigDefaultLexicon)
+	}
+	if opts.LetterDistribution == "" {
+		opts.LetterDistribution = sc.config.GetString(config.ConfigDefaultLetterDistribution)
+	}
+	var err error
+	if opts.PairsPerMatch, err = cmd.options.IntDefault("pairs", 1000); err != nil {
+		return nil, err
+	}
+	if opts.Threads, err = cmd.options.IntDefault("threads", runtime.NumCPU()); err != nil {
+		return nil, err
+	}
+	if seed := cmd.options.String("seed"); seed != "" {
+		if opts.Seed, err = strconv.ParseUint(seed, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.options.Bool("sprt") {
+		s := &stats.SPRT{}
+		if s.Elo0, err = cmd.options.FloatDefault("elo0", 0); err != nil {
+			return nil, err
+		}
+		if s.Elo1, err = cmd.options.FloatDefault("elo1", 10); err != nil {
+			return nil, err
+		}
+		if s.Alpha, err = cmd.options.FloatDefault("alpha", 0.05); err != nil {
+			return nil, err
+		}
+		if s.Beta, err = cmd.options.FloatDefault("beta", 0.05); err != nil {
+			return nil, err
+		}
+		if s.Elo1 <= s.Elo0 {
+			return nil, errors.New("elo1 must be greater than elo0")
+		}
+		opts.SPRT = s
+	}
+
+	sc.gameRunnerCtx, sc.gameRunnerCancel = context.WithCancel(context.Background())
+	sc.gameRunnerRunning = true
+	run := func() error {
+		defer func() { sc.gameRunnerRunning = false }()
+		results, err := automatic.RunTournament(sc.gameRunnerCtx, sc.config, players, opts)
+		if results != nil {
+			sc.showMessage(automatic.TournamentReport(results))
+		}
+		return err
+	}
+	if cmd.options.Bool("block") {
+		return nil, run()
+	}
+	go func() {
+		if err := run(); err != nil {
+			sc.showError(err)
+		}
+	}()
+	return msg(fmt.Sprintf("Started tournament with %d players...", len(players))), nil
+}
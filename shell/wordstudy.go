@@ -0,0 +1,190 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+
+	"github.com/domino14/macondo/config"
+	"github.com/domino14/macondo/wordstudy"
+)
+
+// wordStudy returns a Study of the default lexicon.
+func (sc *ShellController) wordStudy() (*wordstudy.Study, error) {
+	dist, err := tilemapping.GetDistribution(sc.config.AllSettings(),
+		sc.config.GetString(config.ConfigDefaultLetterDistribution))
+	if err != nil {
+		return nil, err
+	}
+	k, err := kwg.Get(sc.config.AllSettings(), sc.config.GetString(config.ConfigDefaultLexicon))
+	if err != nil {
+		return nil, err
+	}
+	return wordstudy.New(k, dist), nil
+}
+
+// wordsByLength shows words on one line per length, longest first. The
+// words must already be sorted that way.
+func wordsByLength(words []string) string {
+	var sb strings.Builder
+	for i, w := range words {
+		n := len([]rune(w))
+		if i == 0 || n != len([]rune(words[i-1])) {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(&sb, "%d:", n)
+		}
+		sb.WriteString(" " + w)
+	}
+	return sb.String()
+}
+
+func (sc *ShellController) anagram(cmd *shellcmd) (*Response, error) {
+	if len(cmd.args) != 1 {
+		return nil, errors.New("please provide the letters to anagram, with ? for a blank")
+	}
+	study, err := sc.wordStudy()
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	if cmd.options.Bool("sub") {
+		minLen, err := cmd.options.IntDefault("min", 2)
+		if err != nil {
+			return nil, err
+		}
+		words, err = study.SubAnagrams(cmd.args[0], minLen)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		words, err = study.Anagrams(cmd.args[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(words) == 0 {
+		return msg("No words found."), nil
+	}
+	return msg(fmt.Sprintf("%s\n%d word(s)", wordsByLength(words), len(words))), nil
+}
+
+func (sc *ShellController) hooks(cmd *shellcmd) (*Response, error) {
+	if len(cmd.args) == 0 {
+		return nil, errors.New("please provide a word or space-separated list of words")
+	}
+	study, err := sc.wordStudy()
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, w := range cmd.args {
+		h, err := study.Hooks(w)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(h.String() + "\n")
+	}
+	return msg(strings.TrimSuffix(sb.String(), "\n")), nil
+}
+
+func (sc *ShellController) pattern(cmd *shellcmd) (*Response, error) {
+	if len(cmd.args) != 1 {
+		return nil, errors.New("please provide a pattern, such as Q?[AEIOU]*")
+	}
+	study, err := sc.wordStudy()
+	if err != nil {
+		return nil, err
+	}
+	words, err := study.Pattern(cmd.args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return msg("No words found."), nil
+	}
+	return msg(fmt.Sprintf("%s\n%d word(s)", strings.Join(words, " "), len(words))), nil
+}
+
+// probabilityList reads the -length, -from and -to options.
+func (sc *ShellController) probabilityList(opts CmdOptions) ([]*wordstudy.Alphagram, error) {
+	length, err := opts.IntDefault("length", 7)
+	if err != nil {
+		return nil, err
+	}
+	from, err := opts.IntDefault("from", 1)
+	if err != nil {
+		return nil, err
+	}
+	to, err := opts.IntDefault("to", from+99)
+	if err != nil {
+		return nil, err
+	}
+	study, err := sc.wordStudy()
+	if err != nil {
+		return nil, err
+	}
+	return study.ProbabilityList(length, from, to)
+}
+
+func (sc *ShellController) problist(cmd *shellcmd) (*Response, error) {
+	list, err := sc.probabilityList(cmd.options)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return msg("No alphagrams found."), nil
+	}
+	var sb strings.Builder
+	for _, a := range list {
+		fmt.Fprintf(&sb, "%5d %-15s %8d  %s\n", a.Rank, a.Alphagram, a.Combinations, strings.Join(a.Words, " "))
+	}
+	return msg(strings.TrimSuffix(sb.String(), "\n")), nil
+}
+
+func (sc *ShellController) quiz(cmd *shellcmd) (*Response, error) {
+	// stop and skip are options, so that any word can be an answer.
+	stop, skip := cmd.options.Bool("stop"), cmd.options.Bool("skip")
+	if !stop && !skip && len(cmd.args) == 0 && (len(cmd.options) > 0 || sc.quizState == nil) {
+		list, err := sc.probabilityList(cmd.options)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, errors.New("no alphagrams to quiz on")
+		}
+		sc.quizState = wordstudy.NewQuiz(list, !cmd.options.Bool("inorder"))
+		return msg(sc.quizState.Question()), nil
+	}
+	if sc.quizState == nil {
+		return nil, errors.New("no quiz is running; start one with `quiz` or `quiz -length 7`")
+	}
+	var r *wordstudy.QuizResult
+	switch {
+	case stop:
+		score := sc.quizState.Score()
+		sc.quizState = nil
+		return msg(score), nil
+	case skip:
+		r = sc.quizState.Skip()
+	case len(cmd.args) == 0:
+		return msg(sc.quizState.Question()), nil
+	default:
+		r = sc.quizState.Answer(cmd.args)
+	}
+	if r == nil {
+		return msg(sc.quizState.Question()), nil
+	}
+	out := r.String() + "\n"
+	if sc.quizState.Done() {
+		out += sc.quizState.Score()
+		sc.quizState = nil
+	} else {
+		out += sc.quizState.Question()
+	}
+	return msg(out), nil
+}
@@ -0,0 +1,107 @@
+package stats
+
+import "math"
+
+// EloFromScore converts an expected score (0 to 1) to an Elo difference.
+func EloFromScore(score float64) float64 {
+	if score <= 0 {
+		return math.Inf(-1)
+	}
+	if score >= 1 {
+		return math.Inf(1)
+	}
+	return -400 * math.Log10(1/score-1)
+}
+
+// ScoreFromElo converts an Elo difference to an expected score (0 to 1).
+func ScoreFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// scoreMeanVariance returns the mean and variance of the per-game score for
+// the given results, with a draw counting as half a win.
+func scoreMeanVariance(wins, draws, losses int) (float64, float64) {
+	n := float64(wins + draws + losses)
+	if n == 0 {
+		return 0, 0
+	}
+	mean := (float64(wins) + float64(draws)/2) / n
+	variance := (float64(wins)*math.Pow(1-mean, 2) +
+		float64(draws)*math.Pow(0.5-mean, 2) +
+		float64(losses)*math.Pow(mean, 2)) / n
+	return mean, variance
+}
+
+// EloDifference returns the Elo difference implied by the given results,
+// along with the low and high bounds of its confidence interval. z is the
+// z-value for the interval, e.g. Z95.
+func EloDifference(wins, draws, losses int, z float64) (elo, lo, hi float64) {
+	n := wins + draws + losses
+	mean, variance := scoreMeanVariance(wins, draws, losses)
+	if n == 0 {
+		return 0, math.Inf(-1), math.Inf(1)
+	}
+	se := math.Sqrt(variance / float64(n))
+	return EloFromScore(mean), EloFromScore(mean - z*se), EloFromScore(mean + z*se)
+}
+
+type SPRTResult int
+
+const (
+	SPRTContinue SPRTResult = iota
+	// SPRTAcceptH0 means the Elo difference is likely at most Elo0.
+	SPRTAcceptH0
+	// SPRTAcceptH1 means the Elo difference is likely at least Elo1.
+	SPRTAcceptH1
+)
+
+func (r SPRTResult) String() string {
+	switch r {
+	case SPRTAcceptH0:
+		return "H0 accepted"
+	case SPRTAcceptH1:
+		return "H1 accepted"
+	}
+	return "inconclusive"
+}
+
+// SPRT is a sequential probability ratio test for the hypotheses
+// H0: elo = Elo0 vs H1: elo = Elo1. Alpha and Beta are the maximum
+// false positive and false negative rates.
+type SPRT struct {
+	Elo0  float64
+	Elo1  float64
+	Alpha float64
+	Beta  float64
+}
+
+// Bounds returns the lower and upper bounds for the log-likelihood ratio.
+func (s SPRT) Bounds() (float64, float64) {
+	return math.Log(s.Beta / (1 - s.Alpha)), math.Log((1 - s.Beta) / s.Alpha)
+}
+
+// LLR returns the log-likelihood ratio of the given results. It uses a
+// normal approximation of the per-game score distribution.
+func (s SPRT) LLR(wins, draws, losses int) float64 {
+	mean, variance := scoreMeanVariance(wins, draws, losses)
+	if variance == 0 {
+		return 0
+	}
+	s0 := ScoreFromElo(s.Elo0)
+	s1 := ScoreFromElo(s.Elo1)
+	n := float64(wins + draws + losses)
+	return n * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// Test returns the result of the test for the given results.
+func (s SPRT) Test(wins, draws, losses int) SPRTResult {
+	llr := s.LLR(wins, draws, losses)
+	lo, hi := s.Bounds()
+	if llr <= lo {
+		return SPRTAcceptH0
+	}
+	if llr >= hi {
+		return SPRTAcceptH1
+	}
+	return SPRTContinue
+}
@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestEloFromScore(t *testing.T) {
+	is := is.New(t)
+	is.True(FuzzyEqual(EloFromScore(0.5), 0))
+	is.True(math.Abs(EloFromScore(0.75)-190.849) < 0.001)
+	is.True(FuzzyEqual(ScoreFromElo(EloFromScore(0.3)), 0.3))
+	is.True(math.IsInf(EloFromScore(1), 1))
+}
+
+func TestEloDifference(t *testing.T) {
+	is := is.New(t)
+	elo, lo, hi := EloDifference(60, 0, 40, Z95)
+	is.True(math.Abs(elo-70.437) < 0.001)
+	is.True(lo < elo && elo < hi)
+	is.True(lo > -30 && hi < 180)
+
+	elo, lo, hi = EloDifference(50, 20, 50, Z95)
+	is.True(FuzzyEqual(elo, 0))
+	is.True(FuzzyEqual(lo, -hi))
+}
+
+func TestSPRT(t *testing.T) {
+	is := is.New(t)
+	s := SPRT{Elo0: 0, Elo1: 20, Alpha: 0.05, Beta: 0.05}
+	lo, hi := s.Bounds()
+	is.True(math.Abs(lo+2.944) < 0.001)
+	is.True(math.Abs(hi-2.944) < 0.001)
+
+	is.Equal(s.Test(0, 0, 0), SPRTContinue)
+	is.Equal(s.Test(6, 0, 4), SPRTContinue)
+	// A clear improvement.
+	is.Equal(s.Test(600, 0, 400), SPRTAcceptH1)
+	// A clear regression.
+	is.Equal(s.Test(400, 0, 600), SPRTAcceptH0)
+}
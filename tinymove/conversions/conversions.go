@@ -137,7 +137,7 @@ func TinyMoveToMove(t tinymove.TinyMove, b *board.GameBoard, om *move.Move) {
 }
 
 func TinyMoveToFullMove(t tinymove.TinyMove, bd *board.GameBoard, ld *tilemapping.LetterDistribution,
-	onTurnRack *tilemapping.Rack, va variant.Variant, lex lexicon.Lexicon) (*move.Move, error) {
+	onTurnRack *tilemapping.Rack, va variant.Variant, rs *variant.RuleSet, lex lexicon.Lexicon) (*move.Move, error) {
 
 	m := &move.Move{}
 	TinyMoveToMove(t, bd, m)
@@ -159,7 +159,7 @@ func TinyMoveToFullMove(t tinymove.TinyMove, bd *board.GameBoard, ld *tilemappin
 		bd.Transpose()
 	}
 
-	m.SetScore(bd.ScoreWord(m.Tiles(), r, c, m.TilesPlayed(), crossDir, ld, va, lex))
+	m.SetScore(bd.ScoreWord(m.Tiles(), r, c, m.TilesPlayed(), crossDir, ld, va, rs, lex))
 
 	if v {
 		bd.Transpose()
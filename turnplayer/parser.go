@@ -6,30 +6,13 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/domino14/macondo/game"
 	pb "github.com/domino14/macondo/gen/api/proto/macondo"
 	"github.com/domino14/macondo/move"
 )
 
 func ParseChallengeRule(rule string) (pb.ChallengeRule, error) {
-	var challRule pb.ChallengeRule
-	switch rule {
-	case "void":
-		challRule = pb.ChallengeRule_VOID
-	case "single":
-		challRule = pb.ChallengeRule_SINGLE
-	case "double":
-		challRule = pb.ChallengeRule_DOUBLE
-	case "triple":
-		challRule = pb.ChallengeRule_TRIPLE
-	case "5pt":
-		challRule = pb.ChallengeRule_FIVE_POINT
-	case "10pt":
-		challRule = pb.ChallengeRule_TEN_POINT
-	default:
-		msg := "Valid options: 'void', 'single', 'double', '5pt', '10pt'"
-		return pb.ChallengeRule_VOID, errors.New(msg)
-	}
-	return challRule, nil
+	return game.ParseChallengeRuleName(rule)
 }
 
 func flipCharCase(r rune) rune {
@@ -0,0 +1,109 @@
+package variant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EndRackScoring is how unplayed tiles are scored when a player goes out.
+type EndRackScoring string
+
+const (
+	// EndRackDouble gives the player who went out twice the value of their
+	// opponent's rack. This is the tournament rule.
+	EndRackDouble EndRackScoring = "double"
+	// EndRackTransfer gives the player who went out the value of their
+	// opponent's rack, and takes it away from the opponent. This is the
+	// rule in the box. The spread is the same as with EndRackDouble.
+	EndRackTransfer EndRackScoring = "transfer"
+)
+
+// RuleSet describes the rules of a game that are not about its board,
+// tiles or lexicon.
+type RuleSet struct {
+	Name     string `json:"name"`
+	RackSize int    `json:"rack_size"`
+	// BingoBonus is scored for playing RackSize tiles in one turn.
+	BingoBonus int `json:"bingo_bonus"`
+	// ExchangeLimit is the least number of tiles that must be in the bag
+	// to exchange.
+	ExchangeLimit int `json:"exchange_limit"`
+	// MaxScorelessTurns in a row end the game.
+	MaxScorelessTurns int            `json:"max_scoreless_turns"`
+	EndRackScoring    EndRackScoring `json:"end_rack_scoring"`
+}
+
+// ClassicRuleSet is the rule set of a regular tournament game.
+var ClassicRuleSet = RuleSet{
+	Name:              "classic",
+	RackSize:          7,
+	BingoBonus:        50,
+	ExchangeLimit:     7,
+	MaxScorelessTurns: 6,
+	EndRackScoring:    EndRackDouble,
+}
+
+// DefaultRuleSet returns the rules that the variant is played with, unless
+// others are picked.
+func (v Variant) DefaultRuleSet() RuleSet {
+	rs := ClassicRuleSet
+	if v == VarGmo {
+		rs.Name = string(VarGmo)
+		rs.BingoBonus = 35
+	}
+	return rs
+}
+
+// IsBingo returns whether playing this many tiles gets the bingo bonus.
+func (rs *RuleSet) IsBingo(tilesPlayed int) bool {
+	return tilesPlayed == rs.RackSize
+}
+
+// Validate returns an error if the rule set cannot be played.
+func (rs *RuleSet) Validate() error {
+	if rs.RackSize < 1 {
+		return fmt.Errorf("rule set %q: rack size must be positive", rs.Name)
+	}
+	if rs.BingoBonus < 0 || rs.ExchangeLimit < 0 {
+		return fmt.Errorf("rule set %q: bingo bonus and exchange limit cannot be negative", rs.Name)
+	}
+	if rs.MaxScorelessTurns < 2 {
+		return fmt.Errorf("rule set %q: the game needs at least 2 scoreless turns to end", rs.Name)
+	}
+	switch rs.EndRackScoring {
+	case EndRackDouble, EndRackTransfer:
+	default:
+		return fmt.Errorf("rule set %q: unknown end rack scoring %q", rs.Name, rs.EndRackScoring)
+	}
+	return nil
+}
+
+// ReadRuleSets reads a JSON list of rule sets. Fields that a rule set
+// leaves out are the same as in ClassicRuleSet.
+func ReadRuleSets(r io.Reader) (map[string]RuleSet, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	rulesets := map[string]RuleSet{}
+	for _, msg := range raw {
+		rs := ClassicRuleSet
+		rs.Name = ""
+		if err := json.Unmarshal(msg, &rs); err != nil {
+			return nil, err
+		}
+		if rs.Name == "" {
+			return nil, errors.New("every rule set needs a name")
+		}
+		if _, ok := rulesets[rs.Name]; ok {
+			return nil, fmt.Errorf("rule set %q is defined twice", rs.Name)
+		}
+		if err := rs.Validate(); err != nil {
+			return nil, err
+		}
+		rulesets[rs.Name] = rs
+	}
+	return rulesets, nil
+}
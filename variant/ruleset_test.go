@@ -0,0 +1,52 @@
+package variant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReadRuleSets(t *testing.T) {
+	is := is.New(t)
+	rulesets, err := ReadRuleSets(strings.NewReader(`[
+		{"name": "box", "end_rack_scoring": "transfer"},
+		{"name": "eight", "rack_size": 8, "exchange_limit": 8, "bingo_bonus": 60}
+	]`))
+	is.NoErr(err)
+	is.Equal(len(rulesets), 2)
+
+	box := ClassicRuleSet
+	box.Name = "box"
+	box.EndRackScoring = EndRackTransfer
+	is.Equal(rulesets["box"], box)
+
+	eight := rulesets["eight"]
+	is.Equal(eight.RackSize, 8)
+	is.Equal(eight.BingoBonus, 60)
+	is.Equal(eight.MaxScorelessTurns, ClassicRuleSet.MaxScorelessTurns)
+	is.True(eight.IsBingo(8))
+	is.True(!eight.IsBingo(7))
+}
+
+func TestReadRuleSetsErrors(t *testing.T) {
+	is := is.New(t)
+	for _, js := range []string{
+		`[{"rack_size": 8}]`,
+		`[{"name": "a"}, {"name": "a"}]`,
+		`[{"name": "a", "rack_size": 0}]`,
+		`[{"name": "a", "max_scoreless_turns": 1}]`,
+		`[{"name": "a", "end_rack_scoring": "none"}]`,
+		`{"name": "a"}`,
+	} {
+		_, err := ReadRuleSets(strings.NewReader(js))
+		is.True(err != nil)
+	}
+}
+
+func TestDefaultRuleSet(t *testing.T) {
+	is := is.New(t)
+	is.Equal(VarClassic.DefaultRuleSet(), ClassicRuleSet)
+	is.Equal(VarGmo.DefaultRuleSet().BingoBonus, 35)
+	is.Equal(VarGmo.GetBingoBonus(), 35)
+}
@@ -13,9 +13,13 @@ const (
 	VarGmo Variant = "gmowords"
 )
 
+// GetBingoBonus returns the bingo bonus of the variant's default rule set.
 func (v Variant) GetBingoBonus() int {
-	if v == VarGmo {
-		return 35
-	}
-	return 50
+	return v.DefaultRuleSet().BingoBonus
+}
+
+// IsWordSmog returns whether words only need to be anagrams of words in the
+// lexicon.
+func (v Variant) IsWordSmog() bool {
+	return v == VarWordSmog || v == VarWordSmogSuper
 }
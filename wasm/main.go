@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"sync"
 	"sync/atomic"
 	"syscall/js"
+	"time"
 	"unsafe"
 
 	"github.com/domino14/word-golib/cache"
 	"github.com/rs/zerolog"
 
 	"github.com/domino14/macondo/analyzer"
+	"github.com/domino14/macondo/progress"
+	"github.com/domino14/macondo/render"
 )
 
 func precache(this js.Value, args []js.Value) interface{} {
@@ -73,6 +78,19 @@ func analyzerAnalyze(this js.Value, args []js.Value) (interface{}, error) {
 	return jsonMovesStr, nil
 }
 
+// (int32) => string
+func analyzerPosition(this js.Value, args []js.Value) (interface{}, error) {
+	an, err := getAnalyzer(int32(args[0].Int()))
+	if err != nil {
+		return nil, err
+	}
+	pos, err := an.Position()
+	if err != nil {
+		return nil, err
+	}
+	return string(pos), nil
+}
+
 // (int32) => null
 func simInit(this js.Value, args []js.Value) (interface{}, error) {
 	an, err := getAnalyzer(int32(args[0].Int()))
@@ -111,15 +129,100 @@ func simState(this js.Value, args []js.Value) (interface{}, error) {
 	return retStr, nil
 }
 
+// cancels holds the cancel function of each analyzer's running endgame,
+// pre-endgame or inference.
+var cancels sync.Map
+
+type analysis func(an *analyzer.Analyzer, ctx context.Context, opts []byte,
+	l progress.Listener) ([]byte, error)
+
+// runAnalysis makes a function that runs an analysis with the options in
+// its second argument, and calls its optional third argument with each
+// progress event, as a JSON string.
+// (int32, string, ?(string) => void) => string
+func runAnalysis(f analysis) func(js.Value, []js.Value) (interface{}, error) {
+	return func(this js.Value, args []js.Value) (interface{}, error) {
+		k := int32(args[0].Int())
+		an, err := getAnalyzer(k)
+		if err != nil {
+			return nil, err
+		}
+		var opts []byte
+		if len(args) > 1 && args[1].Type() == js.TypeString {
+			opts = []byte(args[1].String())
+		}
+		var callback js.Value
+		if len(args) > 2 && args[2].Type() == js.TypeFunction {
+			callback = args[2]
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if _, loaded := cancels.LoadOrStore(k, cancel); loaded {
+			return nil, errors.New("an analysis is already running")
+		}
+		defer cancels.Delete(k)
+
+		listener := func(e progress.Event) {
+			if !callback.IsUndefined() {
+				data, err := json.Marshal(e)
+				if err == nil {
+					callback.Invoke(string(data))
+				}
+			}
+			// The engines are never preempted here, so let the event loop
+			// run for a moment. This is when analysisCancel can be called.
+			time.Sleep(time.Millisecond)
+		}
+		ret, err := f(an, ctx, opts, listener)
+		if err != nil {
+			return nil, err
+		}
+		return string(ret), nil
+	}
+}
+
+// (int32) => null
+func analysisCancel(this js.Value, args []js.Value) (interface{}, error) {
+	cancel, loaded := cancels.Load(int32(args[0].Int()))
+	if !loaded {
+		return nil, errors.New("no analysis is running")
+	}
+	cancel.(context.CancelFunc)()
+	return nil, nil
+}
+
+// (int32, string, int) => string for svg, Uint8Array for png
+func analyzerRender(this js.Value, args []js.Value) (interface{}, error) {
+	an, err := getAnalyzer(int32(args[0].Int()))
+	if err != nil {
+		return nil, err
+	}
+	format := args[1].String()
+	img, err := an.Render(format, args[2].Int())
+	if err != nil {
+		return nil, err
+	}
+	if f, _ := render.ParseFormat(format); f == render.PNG {
+		return makeJSBytes(img), nil
+	}
+	return string(img), nil
+}
+
 func registerCallbacks() {
 	js.Global().Get("resMacondo").Invoke(map[string]interface{}{
-		"precache":        js.FuncOf(precache),
-		"newAnalyzer":     js.FuncOf(asyncFunc(newAnalyzer)),
-		"delAnalyzer":     js.FuncOf(asyncFunc(delAnalyzer)),
-		"analyzerAnalyze": js.FuncOf(asyncFunc(analyzerAnalyze)),
-		"simInit":         js.FuncOf(asyncFunc(simInit)),
-		"simSingleThread": js.FuncOf(asyncFunc(simSingleThread)),
-		"simState":        js.FuncOf(asyncFunc(simState)),
+		"precache":         js.FuncOf(precache),
+		"newAnalyzer":      js.FuncOf(asyncFunc(newAnalyzer)),
+		"delAnalyzer":      js.FuncOf(asyncFunc(delAnalyzer)),
+		"analyzerAnalyze":  js.FuncOf(asyncFunc(analyzerAnalyze)),
+		"analyzerRender":   js.FuncOf(asyncFunc(analyzerRender)),
+		"analyzerPosition": js.FuncOf(asyncFunc(analyzerPosition)),
+		"simInit":          js.FuncOf(asyncFunc(simInit)),
+		"simSingleThread":  js.FuncOf(asyncFunc(simSingleThread)),
+		"simState":         js.FuncOf(asyncFunc(simState)),
+		"endgame":          js.FuncOf(jobFunc(runAnalysis((*analyzer.Analyzer).Endgame))),
+		"preendgame":       js.FuncOf(jobFunc(runAnalysis((*analyzer.Analyzer).PreEndgame))),
+		"infer":            js.FuncOf(jobFunc(runAnalysis((*analyzer.Analyzer).Infer))),
+		"analysisCancel":   js.FuncOf(asyncFunc(analysisCancel)),
 	})
 }
 
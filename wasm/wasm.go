@@ -40,3 +40,24 @@ func asyncFunc(f func(js.Value, []js.Value) (interface{}, error)) func(js.Value,
 		}))
 	}
 }
+
+// jobFunc is like asyncFunc, but f runs in its own goroutine, so that a
+// long analysis does not hold up the caller, and the promise is settled
+// when f is done.
+func jobFunc(f func(js.Value, []js.Value) (interface{}, error)) func(js.Value, []js.Value) interface{} {
+	return func(this js.Value, args []js.Value) interface{} {
+		return js.Global().Get("Promise").New(js.FuncOf(func(_ js.Value, settlers []js.Value) interface{} {
+			res, rej := settlers[0], settlers[1]
+			go func() {
+				ret, err := f(this, args)
+				if err != nil {
+					// throw a string.
+					rej.Invoke(err.Error())
+				} else {
+					res.Invoke(ret)
+				}
+			}()
+			return nil
+		}))
+	}
+}
@@ -0,0 +1,138 @@
+package wordstudy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/domino14/word-golib/tilemapping"
+)
+
+// maxPatternTokens is how long a pattern can be. The positions in a pattern
+// that a prefix can have reached are kept in a uint64.
+const maxPatternTokens = 63
+
+// A patternToken matches one letter, or any number of letters if star is
+// set. letters is the set of letters it matches; nil matches any letter.
+type patternToken struct {
+	star    bool
+	letters []bool
+}
+
+func (t patternToken) matches(ml tilemapping.MachineLetter) bool {
+	return t.letters == nil || (int(ml) < len(t.letters) && t.letters[ml])
+}
+
+// parsePattern splits a pattern into tokens. A ? is any one letter, a * is
+// any number of letters, and [ABC] is one of A, B or C. Anything else is
+// a letter that must be there.
+func (s *Study) parsePattern(pattern string) ([]patternToken, error) {
+	var tokens []patternToken
+	pattern = strings.ToUpper(pattern)
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '?':
+			tokens = append(tokens, patternToken{})
+			pattern = pattern[1:]
+		case '*':
+			tokens = append(tokens, patternToken{star: true})
+			pattern = pattern[1:]
+		case '[':
+			end := strings.IndexByte(pattern, ']')
+			if end < 0 {
+				return nil, errors.New("missing ] in pattern")
+			}
+			mls, err := tilemapping.ToMachineLetters(pattern[1:end], s.alph)
+			if err != nil {
+				return nil, err
+			}
+			if len(mls) == 0 {
+				return nil, errors.New("empty [] in pattern")
+			}
+			t := patternToken{letters: make([]bool, s.alph.NumLetters())}
+			for _, ml := range mls {
+				if ml == 0 {
+					return nil, errors.New("a blank cannot go in []")
+				}
+				t.letters[ml] = true
+			}
+			tokens = append(tokens, t)
+			pattern = pattern[end+1:]
+		default:
+			// A run of letters. They are converted together, so that
+			// letters written with more than one character work.
+			end := strings.IndexAny(pattern, "?*[")
+			if end < 0 {
+				end = len(pattern)
+			}
+			mls, err := tilemapping.ToMachineLetters(pattern[:end], s.alph)
+			if err != nil {
+				return nil, err
+			}
+			for _, ml := range mls {
+				t := patternToken{letters: make([]bool, s.alph.NumLetters())}
+				t.letters[ml] = true
+				tokens = append(tokens, t)
+			}
+			pattern = pattern[end:]
+		}
+	}
+	if len(tokens) > maxPatternTokens {
+		return nil, fmt.Errorf("pattern is too long; it can have at most %d letters and wildcards", maxPatternTokens)
+	}
+	return tokens, nil
+}
+
+// closure adds the positions that can be reached from the given ones
+// without a letter, by skipping stars.
+func closure(tokens []patternToken, states uint64) uint64 {
+	for i, t := range tokens {
+		if t.star && states&(1<<i) != 0 {
+			states |= 1 << (i + 1)
+		}
+	}
+	return states
+}
+
+// step returns the positions reached after one more letter.
+func step(tokens []patternToken, states uint64, ml tilemapping.MachineLetter) uint64 {
+	var next uint64
+	for i, t := range tokens {
+		if states&(1<<i) == 0 {
+			continue
+		}
+		if t.star {
+			next |= 1 << i
+		} else if t.matches(ml) {
+			next |= 1 << (i + 1)
+		}
+	}
+	return closure(tokens, next)
+}
+
+// Pattern returns the words that match a pattern, in alphabetical order.
+// A ? in the pattern is any one letter, a * is any number of letters, and
+// [ABC] is one of A, B or C. For example, Q?[AEIOU]* finds the words with
+// a vowel in their third letter that start with Q.
+func (s *Study) Pattern(pattern string) ([]string, error) {
+	tokens, err := s.parsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	final := uint64(1) << len(tokens)
+	// states[n] are the positions in the pattern that the current prefix of
+	// n letters can have reached.
+	states := []uint64{closure(tokens, 1)}
+	var words []tilemapping.MachineWord
+	s.walk(func(prefix tilemapping.MachineWord) bool {
+		n := len(prefix)
+		states = append(states[:n], step(tokens, states[n-1], prefix[n-1]))
+		return states[n] != 0
+	}, func(w tilemapping.MachineWord) {
+		if states[len(w)]&final != 0 {
+			words = append(words, slices.Clone(w))
+		}
+	})
+	return s.toStrings(words), nil
+}
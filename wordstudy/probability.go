@@ -0,0 +1,102 @@
+package wordstudy
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/domino14/word-golib/tilemapping"
+)
+
+// An Alphagram is a set of letters, written in alphabetical order, with the
+// words that can be made from all of them.
+type Alphagram struct {
+	Alphagram string
+	Words     []string
+	// Combinations is the number of ways to draw the letters from a full
+	// bag, without blanks. More combinations make a likelier alphagram.
+	Combinations uint64
+	// Rank is the place of the alphagram in its probability list, from 1.
+	Rank int
+}
+
+// choose returns n choose k.
+func choose(n, k uint64) uint64 {
+	if k > n {
+		return 0
+	}
+	r := uint64(1)
+	for i := uint64(1); i <= k; i++ {
+		r = r * (n - k + i) / i
+	}
+	return r
+}
+
+// Combinations returns the number of ways to draw the letters of a word
+// from a full bag, without using blanks.
+func (s *Study) Combinations(word tilemapping.MachineWord) uint64 {
+	dist := s.ld.Distribution()
+	counts := make([]uint64, len(dist))
+	for _, ml := range word {
+		counts[ml.IntrinsicTileIdx()]++
+	}
+	r := uint64(1)
+	for ml, k := range counts {
+		if k > 0 {
+			r *= choose(uint64(dist[ml]), k)
+		}
+	}
+	return r
+}
+
+// ProbabilityList returns the alphagrams of the given length ranked from
+// from to to, in order of probability, most likely first. Alphagrams with
+// as many combinations are put in alphabetical order.
+func (s *Study) ProbabilityList(length, from, to int) ([]*Alphagram, error) {
+	if length < 2 {
+		return nil, fmt.Errorf("words have at least 2 letters, not %d", length)
+	}
+	if from < 1 || to < from {
+		return nil, fmt.Errorf("bad range of ranks %d to %d", from, to)
+	}
+	type group struct {
+		alphagram tilemapping.MachineWord
+		words     []tilemapping.MachineWord
+		combos    uint64
+	}
+	byAlphagram := map[string]*group{}
+	var groups []*group
+	for _, w := range s.WordsOfLength(length) {
+		a := slices.Clone(w)
+		slices.Sort(a)
+		key := string(a.ToByteArr())
+		g, ok := byAlphagram[key]
+		if !ok {
+			g = &group{alphagram: a, combos: s.Combinations(a)}
+			byAlphagram[key] = g
+			groups = append(groups, g)
+		}
+		g.words = append(g.words, w)
+	}
+	slices.SortFunc(groups, func(a, b *group) int {
+		if c := cmp.Compare(b.combos, a.combos); c != 0 {
+			return c
+		}
+		return slices.Compare(a.alphagram, b.alphagram)
+	})
+	if from > len(groups) {
+		return nil, nil
+	}
+	to = min(to, len(groups))
+	list := make([]*Alphagram, 0, to-from+1)
+	for i := from - 1; i < to; i++ {
+		g := groups[i]
+		list = append(list, &Alphagram{
+			Alphagram:    g.alphagram.UserVisible(s.alph),
+			Words:        s.toStrings(g.words),
+			Combinations: g.combos,
+			Rank:         i + 1,
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,124 @@
+package wordstudy
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// A Quiz asks for the words of one alphagram at a time.
+type Quiz struct {
+	questions []*Alphagram
+	pos       int
+	// Solved counts the alphagrams answered with every word and no wrong
+	// ones; Asked counts those answered or skipped.
+	Solved int
+	Asked  int
+}
+
+// QuizResult is how an answer went.
+type QuizResult struct {
+	Alphagram *Alphagram
+	Correct   []string
+	Missed    []string
+	Wrong     []string
+}
+
+func (r *QuizResult) Solved() bool {
+	return len(r.Missed) == 0 && len(r.Wrong) == 0
+}
+
+func (r *QuizResult) String() string {
+	var sb strings.Builder
+	if r.Solved() {
+		fmt.Fprintf(&sb, "Correct! %s: %s", r.Alphagram.Alphagram, strings.Join(r.Alphagram.Words, " "))
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "%s: %s", r.Alphagram.Alphagram, strings.Join(r.Alphagram.Words, " "))
+	if len(r.Missed) > 0 {
+		fmt.Fprintf(&sb, "\nMissed: %s", strings.Join(r.Missed, " "))
+	}
+	if len(r.Wrong) > 0 {
+		fmt.Fprintf(&sb, "\nNot words: %s", strings.Join(r.Wrong, " "))
+	}
+	return sb.String()
+}
+
+// NewQuiz returns a quiz on the alphagrams, in a random order if shuffle
+// is set.
+func NewQuiz(questions []*Alphagram, shuffle bool) *Quiz {
+	q := &Quiz{questions: slices.Clone(questions)}
+	if shuffle {
+		rand.Shuffle(len(q.questions), func(i, j int) {
+			q.questions[i], q.questions[j] = q.questions[j], q.questions[i]
+		})
+	}
+	return q
+}
+
+// Current returns the alphagram being asked, or nil if the quiz is over.
+func (q *Quiz) Current() *Alphagram {
+	if q.Done() {
+		return nil
+	}
+	return q.questions[q.pos]
+}
+
+func (q *Quiz) Done() bool {
+	return q.pos >= len(q.questions)
+}
+
+// Question shows the alphagram being asked, with how many words it has.
+func (q *Quiz) Question() string {
+	a := q.Current()
+	if a == nil {
+		return "The quiz is over."
+	}
+	return fmt.Sprintf("#%d of %d (probability %d): %s, %d word(s)",
+		q.pos+1, len(q.questions), a.Rank, a.Alphagram, len(a.Words))
+}
+
+// Answer checks the guesses for the current alphagram, and moves on to the
+// next one.
+func (q *Quiz) Answer(guesses []string) *QuizResult {
+	a := q.Current()
+	if a == nil {
+		return nil
+	}
+	r := &QuizResult{Alphagram: a}
+	seen := map[string]bool{}
+	for _, g := range guesses {
+		g = strings.ToUpper(strings.TrimSpace(g))
+		if g == "" || seen[g] {
+			continue
+		}
+		seen[g] = true
+		if slices.Contains(a.Words, g) {
+			r.Correct = append(r.Correct, g)
+		} else {
+			r.Wrong = append(r.Wrong, g)
+		}
+	}
+	for _, w := range a.Words {
+		if !seen[w] {
+			r.Missed = append(r.Missed, w)
+		}
+	}
+	q.pos++
+	q.Asked++
+	if r.Solved() {
+		q.Solved++
+	}
+	return r
+}
+
+// Skip gives up on the current alphagram, and moves on to the next one.
+func (q *Quiz) Skip() *QuizResult {
+	return q.Answer(nil)
+}
+
+// Score shows how many alphagrams were solved.
+func (q *Quiz) Score() string {
+	return fmt.Sprintf("Solved %d of %d asked; %d left.", q.Solved, q.Asked, len(q.questions)-q.pos)
+}
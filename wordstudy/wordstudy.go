@@ -0,0 +1,183 @@
+// Package wordstudy has tools to study the words of a lexicon: anagrams
+// and sub-anagrams, hooks, pattern searches, probability lists and
+// alphagram quizzes. It works directly on the KWG of the lexicon.
+package wordstudy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+)
+
+// Study looks up the words of one lexicon.
+type Study struct {
+	kwg  *kwg.KWG
+	alph *tilemapping.TileMapping
+	ld   *tilemapping.LetterDistribution
+}
+
+// New returns a Study of the lexicon in k. The letter distribution is used
+// for word probabilities; it must use the same alphabet as the lexicon.
+func New(k *kwg.KWG, ld *tilemapping.LetterDistribution) *Study {
+	return &Study{kwg: k, alph: k.GetAlphabet(), ld: ld}
+}
+
+func (s *Study) toWord(letters string) (tilemapping.MachineWord, error) {
+	return tilemapping.ToMachineWord(strings.ToUpper(letters), s.alph)
+}
+
+func (s *Study) toStrings(words []tilemapping.MachineWord) []string {
+	strs := make([]string, len(words))
+	for i, w := range words {
+		strs[i] = w.UserVisible(s.alph)
+	}
+	return strs
+}
+
+// sortWords sorts words longest first, then alphabetically.
+func sortWords(words []tilemapping.MachineWord) {
+	slices.SortFunc(words, func(a, b tilemapping.MachineWord) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return slices.Compare(a, b)
+	})
+}
+
+func (s *Study) anagram(letters string, sub bool, minLen int) ([]string, error) {
+	word, err := s.toWord(letters)
+	if err != nil {
+		return nil, err
+	}
+	var da kwg.KWGAnagrammer
+	if err := da.InitForMachineWord(s.kwg, word); err != nil {
+		return nil, err
+	}
+	var words []tilemapping.MachineWord
+	found := func(w tilemapping.MachineWord) error {
+		if len(w) >= minLen {
+			words = append(words, slices.Clone(w))
+		}
+		return nil
+	}
+	if sub {
+		err = da.Subanagram(s.kwg, found)
+	} else {
+		err = da.Anagram(s.kwg, found)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sortWords(words)
+	return s.toStrings(words), nil
+}
+
+// Anagrams returns the words that use all the letters. A ? is a blank.
+func (s *Study) Anagrams(letters string) ([]string, error) {
+	return s.anagram(letters, false, 0)
+}
+
+// SubAnagrams returns the words of at least minLen letters that can be
+// made with some of the letters, longest first. A ? is a blank.
+func (s *Study) SubAnagrams(letters string, minLen int) ([]string, error) {
+	return s.anagram(letters, true, max(minLen, 2))
+}
+
+// Hooks are the letters that can go in front of and behind a word to make
+// another word.
+type Hooks struct {
+	Word  string
+	Valid bool
+	Front string
+	Back  string
+	// FrontInner and BackInner are set if the word is still a word without
+	// its first or last letter.
+	FrontInner bool
+	BackInner  bool
+}
+
+// String shows the hooks the way word lists usually do: front hooks, the
+// word, and back hooks. A dot next to the word marks an inner hook.
+func (h *Hooks) String() string {
+	word := h.Word
+	if h.FrontInner {
+		word = "·" + word
+	}
+	if h.BackInner {
+		word += "·"
+	}
+	if !h.Valid {
+		word += "*"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%8s %s %s", h.Front, word, h.Back))
+}
+
+// Hooks returns the hooks of a word.
+func (s *Study) Hooks(word string) (*Hooks, error) {
+	w, err := s.toWord(word)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(w, 0) {
+		return nil, errors.New("a word to hook cannot have blanks")
+	}
+	h := &Hooks{Word: w.UserVisible(s.alph), Valid: kwg.FindMachineWord(s.kwg, w)}
+	letters := func(mls []tilemapping.MachineLetter) string {
+		return tilemapping.MachineWord(mls).UserVisible(s.alph)
+	}
+	h.Front = letters(kwg.FindHooks(s.kwg, w, kwg.FrontHooks))
+	h.Back = letters(kwg.FindHooks(s.kwg, w, kwg.BackHooks))
+	if len(w) > 2 {
+		h.FrontInner = kwg.FindInnerHook(s.kwg, w, kwg.FrontInnerHook)
+		h.BackInner = kwg.FindInnerHook(s.kwg, w, kwg.BackInnerHook)
+	}
+	return h, nil
+}
+
+// walk calls fn for every word in the lexicon, in alphabetical order.
+// visit is called with every prefix first; if it returns false, no word
+// starting with the prefix is visited. fn must not keep the word it is
+// passed.
+func (s *Study) walk(visit func(prefix tilemapping.MachineWord) bool,
+	fn func(word tilemapping.MachineWord)) {
+
+	var word tilemapping.MachineWord
+	var rec func(nodeIdx uint32)
+	rec = func(nodeIdx uint32) {
+		for i := nodeIdx; ; i++ {
+			word = append(word, tilemapping.MachineLetter(s.kwg.Tile(i)))
+			if visit(word) {
+				if s.kwg.Accepts(i) {
+					fn(word)
+				}
+				if next := s.kwg.ArcIndex(i); next != 0 {
+					rec(next)
+				}
+			}
+			word = word[:len(word)-1]
+			if s.kwg.IsEnd(i) {
+				return
+			}
+		}
+	}
+	if root := s.kwg.ArcIndex(0); root != 0 {
+		rec(root)
+	}
+}
+
+// WordsOfLength returns every word of the given length.
+func (s *Study) WordsOfLength(length int) []tilemapping.MachineWord {
+	var words []tilemapping.MachineWord
+	s.walk(func(prefix tilemapping.MachineWord) bool {
+		return len(prefix) <= length
+	}, func(w tilemapping.MachineWord) {
+		if len(w) == length {
+			words = append(words, slices.Clone(w))
+		}
+	})
+	return words
+}
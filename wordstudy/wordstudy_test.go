@@ -0,0 +1,137 @@
+package wordstudy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/domino14/word-golib/kwg"
+	"github.com/domino14/word-golib/tilemapping"
+	"github.com/matryer/is"
+
+	"github.com/domino14/macondo/config"
+)
+
+var DefaultConfig = config.DefaultConfig()
+
+func nwl20(t *testing.T) *Study {
+	is := is.New(t)
+	k, err := kwg.Get(DefaultConfig.AllSettings(), "NWL20")
+	is.NoErr(err)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	return New(k, ld)
+}
+
+func TestChoose(t *testing.T) {
+	is := is.New(t)
+	is.Equal(choose(12, 0), uint64(1))
+	is.Equal(choose(12, 2), uint64(66))
+	is.Equal(choose(9, 3), uint64(84))
+	is.Equal(choose(1, 2), uint64(0))
+}
+
+func TestPatternStates(t *testing.T) {
+	is := is.New(t)
+	ld, err := tilemapping.EnglishLetterDistribution(DefaultConfig.AllSettings())
+	is.NoErr(err)
+	s := &Study{alph: ld.TileMapping(), ld: ld}
+	tokens, err := s.parsePattern("q?[aei]*")
+	is.NoErr(err)
+	is.Equal(len(tokens), 4)
+	is.True(tokens[3].star)
+
+	match := func(word string) bool {
+		mls, err := tilemapping.ToMachineLetters(word, s.alph)
+		is.NoErr(err)
+		states := closure(tokens, 1)
+		for _, ml := range mls {
+			states = step(tokens, states, ml)
+		}
+		return states&(1<<len(tokens)) != 0
+	}
+	is.True(match("QUA"))
+	is.True(match("QUINTS"))
+	is.True(!match("QU"))
+	is.True(!match("QUOTE"))
+	is.True(!match("SQUAT"))
+
+	_, err = s.parsePattern("[AB")
+	is.True(err != nil)
+}
+
+func TestQuiz(t *testing.T) {
+	is := is.New(t)
+	q := NewQuiz([]*Alphagram{
+		{Alphagram: "AEINRST", Words: []string{"ANESTRI", "NASTIER", "RATINES", "RETAINS", "RETINAS", "RETSINA", "STAINER", "STEARIN"}, Rank: 1},
+		{Alphagram: "ADEINRS", Words: []string{"RANDIES", "SANDIER", "SARDINE"}, Rank: 2},
+		{Alphagram: "AEILNRT", Words: []string{"LATRINE", "RATLINE", "RELIANT", "RETINAL", "TRENAIL"}, Rank: 3},
+	}, false)
+	is.Equal(q.Current().Rank, 1)
+	r := q.Answer([]string{"retains", "RETINAS", "nastier", "ANESTRI", "RATINES", "RETSINA", "STAINER", "STEARIN"})
+	is.True(r.Solved())
+	r = q.Answer([]string{"sardine", "sandier", "dinears"})
+	is.True(!r.Solved())
+	is.Equal(r.Missed, []string{"RANDIES"})
+	is.Equal(r.Wrong, []string{"DINEARS"})
+	is.True(strings.Contains(q.Question(), "AEILNRT"))
+	r = q.Skip()
+	is.Equal(len(r.Missed), 5)
+	is.True(q.Done())
+	is.Equal(q.Current(), nil)
+	is.Equal(q.Score(), "Solved 1 of 3 asked; 0 left.")
+}
+
+func TestAnagrams(t *testing.T) {
+	is := is.New(t)
+	s := nwl20(t)
+	words, err := s.Anagrams("AEINRST")
+	is.NoErr(err)
+	is.True(slices.Contains(words, "RETAINS"))
+	is.True(slices.Contains(words, "STEARIN"))
+	words, err = s.Anagrams("QZ?")
+	is.NoErr(err)
+	is.Equal(len(words), 0)
+	words, err = s.SubAnagrams("QIZ", 2)
+	is.NoErr(err)
+	is.True(slices.Contains(words, "QI"))
+}
+
+func TestHooksAndPattern(t *testing.T) {
+	is := is.New(t)
+	s := nwl20(t)
+	h, err := s.Hooks("CARE")
+	is.NoErr(err)
+	is.True(h.Valid)
+	is.True(strings.Contains(h.Front, "S"))
+	is.True(strings.Contains(h.Back, "D"))
+	is.True(h.BackInner)
+
+	words, err := s.Pattern("Q?")
+	is.NoErr(err)
+	is.Equal(words, []string{"QI"})
+	words, err = s.Pattern("*ZZ*")
+	is.NoErr(err)
+	is.True(slices.Contains(words, "PIZZA"))
+	for _, w := range words {
+		is.True(strings.Contains(w, "ZZ"))
+	}
+}
+
+func TestProbabilityList(t *testing.T) {
+	is := is.New(t)
+	s := nwl20(t)
+	list, err := s.ProbabilityList(2, 1, 1000)
+	is.NoErr(err)
+	is.True(len(list) > 50)
+	for i, a := range list {
+		is.Equal(a.Rank, i+1)
+		if i > 0 {
+			is.True(a.Combinations <= list[i-1].Combinations)
+		}
+	}
+	list, err = s.ProbabilityList(7, 1, 1)
+	is.NoErr(err)
+	is.Equal(len(list), 1)
+	is.Equal(list[0].Alphagram, "AEINORT")
+}